@@ -26,6 +26,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Embed the IANA time zone database for client-supplied zones
 
 	"go.uber.org/zap"
 
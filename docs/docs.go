@@ -26,23 +26,23 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/todos": {
+        "/projects": {
             "get": {
-                "description": "Retrieves a list of all todo items",
+                "description": "Retrieves every project, ordered by ID, with the number of todos in each",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "todos"
+                    "projects"
                 ],
-                "summary": "List all todo items",
+                "summary": "List projects",
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved todos",
+                        "description": "Successfully retrieved projects",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/v1.TodoResponse"
+                                "$ref": "#/definitions/v1.ProjectResponse"
                             }
                         }
                     },
@@ -55,7 +55,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Creates a new todo item with the provided title",
+                "description": "Creates a new project that todos can be grouped under",
                 "consumes": [
                     "application/json"
                 ],
@@ -63,23 +63,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "todos"
+                    "projects"
                 ],
-                "summary": "Create a new todo item",
+                "summary": "Create a new project",
                 "parameters": [
                     {
-                        "description": "Todo creation request",
-                        "name": "todo",
+                        "description": "Project creation request",
+                        "name": "project",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/v1.CreateTodoRequest"
+                            "$ref": "#/definitions/v1.CreateProjectRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Successfully created todo",
+                        "description": "Successfully created project",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -102,30 +102,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/todos/{id}": {
+        "/projects/{projectID}": {
             "get": {
-                "description": "Retrieves a specific todo item by its ID",
+                "description": "Retrieves a specific project with the number of todos in it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "todos"
+                    "projects"
                 ],
-                "summary": "Get a todo item by ID",
+                "summary": "Get a project by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Todo ID",
-                        "name": "id",
+                        "description": "Project ID",
+                        "name": "projectID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved todo",
+                        "description": "Successfully retrieved project",
                         "schema": {
-                            "$ref": "#/definitions/v1.TodoResponse"
+                            "$ref": "#/definitions/v1.ProjectResponse"
                         }
                     },
                     "400": {
@@ -135,7 +135,64 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Todo not found",
+                        "description": "Project not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the name and description of a project",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Replace a project",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "projectID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Project replacement request",
+                        "name": "project",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.UpdateProjectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated project",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ProjectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationError"
+                        }
+                    },
+                    "404": {
+                        "description": "Project not found",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -149,32 +206,44 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Deletes a specific todo item by its ID",
+                "description": "Deletes a project. A project that still has todos outside the trash is refused with 409\nunless cascade=true is passed, in which case its todos are moved to the trash, together with\ntheir subtasks. Todos in the trash are never deleted by this: they stay in the trash, no\nlonger part of any project, and can be restored until they are purged.",
                 "tags": [
-                    "todos"
+                    "projects"
                 ],
-                "summary": "Delete a todo item",
+                "summary": "Delete a project",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Todo ID",
-                        "name": "id",
+                        "description": "Project ID",
+                        "name": "projectID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also move the todos of the project to the trash",
+                        "name": "cascade",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "Successfully deleted todo"
+                        "description": "Successfully deleted project"
                     },
                     "400": {
-                        "description": "Invalid ID parameter",
+                        "description": "Invalid ID or cascade parameter",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Todo not found",
+                        "description": "Project not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Project still has todos",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -187,54 +256,3259 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "v1.CreateTodoRequest": {
-            "type": "object",
-            "required": [
-                "title"
-            ],
-            "properties": {
-                "title": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 1,
-                    "example": "Buy groceries"
+        },
+        "/projects/{projectID}/todos": {
+            "get": {
+                "description": "Retrieves a filtered, sorted page of the todos in a project. Accepts the same\nfiltering, sorting and pagination parameters as GET /todos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "List the todos of a project",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "projectID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to continue after",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to continue before",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved todos",
+                        "schema": {
+                            "$ref": "#/definitions/v1.TodoListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort or pagination parameters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Project not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new todo item that belongs to the project; project_id in the body is ignored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Create a todo in a project",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "projectID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Todo creation request",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.CreateTodoRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created todo",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationError"
+                        }
+                    },
+                    "404": {
+                        "description": "Project not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with the same Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "v1.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "string"
-                },
-                "error": {
-                    "type": "string"
-                },
-                "trace_id": {
-                    "type": "string"
+        "/tags": {
+            "get": {
+                "description": "Lists every tag in use with the number of todos carrying it, most used first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "List tags",
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved tags",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/v1.TagResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "v1.TodoResponse": {
-            "type": "object",
-            "properties": {
-                "completed": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "created_at": {
-                    "type": "string",
-                    "example": "2023-01-01T12:00:00Z"
-                },
-                "id": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "title": {
-                    "type": "string",
+        "/todos": {
+            "get": {
+                "description": "Retrieves a filtered, sorted page of todo items (ordered by ID by default; sort=position\nfollows the order set by moving todos). Use the returned cursors (also sent as RFC 8288 Link\nheaders) with after or before to move between pages.\nPass all=true to receive every matching todo as a plain array instead.\nThe Accept header may ask for CSV, YAML or MessagePack instead of JSON.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "List todo items",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only todos with this completion state",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "backlog",
+                                "in_progress",
+                                "blocked",
+                                "done"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only todos in one of these statuses",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "low",
+                                "medium",
+                                "high",
+                                "urgent"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only todos with one of these priorities",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only todos in this project",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only todos carrying these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Whether todos need any or all of the tags (default any)",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only todos created after this RFC 3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only todos created before this RFC 3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only todos whose title contains this text (case-insensitive)",
+                        "name": "title_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only open todos whose due date has passed",
+                        "name": "overdue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only todos due today in the tz time zone",
+                        "name": "due_today",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "48h",
+                        "description": "Only todos due within this duration from now",
+                        "name": "due_within",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Europe/Berlin",
+                        "description": "IANA time zone for due_today (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at,title",
+                        "description": "Comma-separated sort fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to continue after",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to continue before",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return all todos as an unpaginated array",
+                        "name": "all",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved todos",
+                        "schema": {
+                            "$ref": "#/definitions/v1.TodoListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort or pagination parameters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted media types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new todo item with the provided title and optional status, priority, due date and reminder.\nStatus defaults to backlog and priority to medium. A todo with a recurrence is the first\noccurrence of a series: completing it creates the next occurrence, due at the next time the\nRFC 5545 rule gives in the rule's time zone.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Create a new todo item",
+                "parameters": [
+                    {
+                        "description": "Todo creation request",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.CreateTodoRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created todo",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationError"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with the same Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos.ics": {
+            "get": {
+                "description": "Renders every todo matching the filters as an iCalendar (RFC 5545) VTODO component, for\ncalendar applications to subscribe to. Done todos have STATUS:COMPLETED and in-progress\nones STATUS:IN-PROCESS; due dates, tags and parents are included. The filters and sort are\nthose of GET /todos. The ETag header changes whenever the feed does and may be sent back in\nIf-None-Match. VTODO components can be imported with POST /todos/import.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Subscribe to todo items as a calendar",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only todos with this completion state",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "backlog",
+                                "in_progress",
+                                "blocked",
+                                "done"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only todos in one of these statuses",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "low",
+                                "medium",
+                                "high",
+                                "urgent"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only todos with one of these priorities",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only todos in this project",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only todos carrying these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Whether todos need any or all of the tags (default any)",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only todos created after this RFC 3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only todos created before this RFC 3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only todos whose title contains this text (case-insensitive)",
+                        "name": "title_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only open todos whose due date has passed",
+                        "name": "overdue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only todos due today in the tz time zone",
+                        "name": "due_today",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "48h",
+                        "description": "Only todos due within this duration from now",
+                        "name": "due_within",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Europe/Berlin",
+                        "description": "IANA time zone for due_today (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at,title",
+                        "description": "Comma-separated sort fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy of the feed",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "iCalendar stream of VTODO components",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the feed"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Feed has not changed since the given ETag"
+                    },
+                    "400": {
+                        "description": "Invalid filter or sort parameters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/export": {
+            "get": {
+                "description": "Streams every todo matching the filters as newline-delimited JSON, one TodoResponse per line,\nwithout paginating. The filters and sort are those of GET /todos. All todos come from one\nsnapshot, so changes made during the export are not part of it. An export that fails once it\nhas started is broken off, so that clients can tell it is incomplete.",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Export todo items",
+                "parameters": [
+                    {
+                        "enum": [
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Export format (default ndjson)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only todos with this completion state",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "backlog",
+                                "in_progress",
+                                "blocked",
+                                "done"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only todos in one of these statuses",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "low",
+                                "medium",
+                                "high",
+                                "urgent"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only todos with one of these priorities",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only todos in this project",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only todos carrying these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Whether todos need any or all of the tags (default any)",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only todos created after this RFC 3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only todos created before this RFC 3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only todos whose title contains this text (case-insensitive)",
+                        "name": "title_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only open todos whose due date has passed",
+                        "name": "overdue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only todos due today in the tz time zone",
+                        "name": "due_today",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "48h",
+                        "description": "Only todos due within this duration from now",
+                        "name": "due_within",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Europe/Berlin",
+                        "description": "IANA time zone for due_today (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at,title",
+                        "description": "Comma-separated sort fields, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One todo per line",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/v1.TodoResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format, filter or sort parameters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/import": {
+            "post": {
+                "description": "Creates todos from a CSV file, a JSON array, a todo.txt file or the VTODO components of an\niCalendar file, all of them or none. The format is taken from the format parameter or else from\nthe Content-Type: text/csv, application/json, text/plain for todo.txt or text/calendar. CSV\nneeds a header row with at least a title column and uses the columns of the CSV export; ids\nare ignored and a parent_id must refer to an existing todo. A VTODO with an RRULE becomes the\nfirst occurrence of a recurring todo, like one created with a recurrence.\nEvery todo is validated like one sent to POST /todos. If any todo is invalid nothing is\nimported and the response lists the errors by line, with status 422. With dry_run=true the\ntodos are only validated.",
+                "consumes": [
+                    "text/plain",
+                    "application/json",
+                    "text/csv",
+                    "text/calendar"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Import todo items",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "json",
+                            "todotxt",
+                            "ical"
+                        ],
+                        "type": "string",
+                        "description": "Import format, overriding the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the todos",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Todos to import",
+                        "name": "todos",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The todos are valid (dry run)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ImportResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "The todos were imported",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed file or invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large or too many todos",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported format",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Some todos are invalid; nothing was imported",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ImportResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/search": {
+            "get": {
+                "description": "Finds the todos whose title or description matches a full-text search, best matches first,\nwith matches in the title ranked above those in the description. Words are\nmatched in any form (\"shopping\" finds \"shop\"); all of them are required. Quote words to find\nthem next to each other, prefix a word or quoted phrase with - to exclude it, end a word with\n* to match words starting with it and separate alternatives with or. Other punctuation is\nignored. The filters are those of GET /todos. Each result carries its score and a snippet of\nHTML in which the matched words are wrapped in \u003cmark\u003e elements.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Search todo items",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "groceries -\"oat milk\"",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of results (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only todos with this completion state",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "backlog",
+                                "in_progress",
+                                "blocked",
+                                "done"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only todos in one of these statuses",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "low",
+                                "medium",
+                                "high",
+                                "urgent"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only todos with one of these priorities",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only todos in this project",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only todos carrying these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Whether todos need any or all of the tags (default any)",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only todos created after this RFC 3339 time",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only todos created before this RFC 3339 time",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only todos whose title contains this text (case-insensitive)",
+                        "name": "title_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only open todos whose due date has passed",
+                        "name": "overdue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only todos due today in the tz time zone",
+                        "name": "due_today",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "48h",
+                        "description": "Only todos due within this duration from now",
+                        "name": "due_within",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Europe/Berlin",
+                        "description": "IANA time zone for due_today (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching todos",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or invalid query, or invalid filters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted media types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}": {
+            "get": {
+                "description": "Retrieves a specific todo item by its ID. With expand=subtasks the response includes\nthe whole tree of subtasks below the todo. With render=html descriptions are also rendered\nfrom Markdown into description_html, sanitized so that it is safe to embed: scripts, event\nhandlers, styles and links other than http, https and mailto are stripped. The ETag header\nholds the version of the todo and may be sent back in If-None-Match; it is omitted when\nsubtasks are expanded. Only plain JSON gets a strong ETag: other formats and rendered\ndescriptions get a weak one, and If-Match takes the etag field of the body instead.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Get a todo item by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "subtasks"
+                        ],
+                        "type": "string",
+                        "description": "Related data to include",
+                        "name": "expand",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "html"
+                        ],
+                        "type": "string",
+                        "description": "Also render descriptions in this format",
+                        "name": "render",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy of the todo",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved todo",
+                        "schema": {
+                            "$ref": "#/definitions/v1.TodoResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the todo"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Todo has not changed since the given ETag"
+                    },
+                    "400": {
+                        "description": "Invalid ID, expand or render parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces a todo item. The new status must be reachable from the current one in the configured workflow;\nthe legacy completed flag may be sent instead of a status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Replace a todo item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only update the todo if its ETag matches",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Todo replacement request",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.UpdateTodoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated todo",
+                        "schema": {
+                            "$ref": "#/definitions/v1.TodoResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the todo"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationError"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Status transition not allowed, todo blocked or update conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Todo has been modified since the given ETag",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Moves a todo item and its subtasks to the trash. They can be restored until they are\npurged after the retention period, or deleted permanently through DELETE /trash/{id}.",
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Delete a todo item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only delete the todo if its ETag matches",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully moved todo to trash"
+                    },
+                    "400": {
+                        "description": "Invalid ID parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Todo has been modified since the given ETag",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Updates only the provided fields of a todo item. The body format is chosen by Content-Type:\napplication/json (PatchTodoRequest), application/merge-patch+json (RFC 7396)\nor application/json-patch+json (RFC 6902, including \"test\" operations).",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Partially update a todo item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only update the todo if its ETag matches",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Todo partial update request",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.PatchTodoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated todo",
+                        "schema": {
+                            "$ref": "#/definitions/v1.TodoResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the todo"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error or malformed patch",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationError"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Patch test failed, transition not allowed, blocked or conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Todo has been modified since the given ETag",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Patch document too large",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported content type",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Patch cannot be applied or yields an invalid todo",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/attachments": {
+            "get": {
+                "description": "Retrieves the files attached to a todo, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "List the files attached to a todo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved attachments",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/v1.AttachmentResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Uploads a file as the file field of a multipart/form-data body. Its type is sniffed from its\ncontents, whatever the client claims, and must be one of the allowed types (by default PNG,\nJPEG, GIF and WebP images, PDF and plain text). Files are limited to ATTACHMENT_MAX_SIZE\nbytes, 10 MiB by default. Todos in the trash cannot have files attached.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Attach a file to a todo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to attach",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "File attached",
+                        "schema": {
+                            "$ref": "#/definitions/v1.AttachmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or empty file",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "File type not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/attachments/{attachmentID}": {
+            "get": {
+                "description": "Retrieves the details of a single file attached to a todo",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Get a file attached to a todo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Attachment ID",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved attachment",
+                        "schema": {
+                            "$ref": "#/definitions/v1.AttachmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo or attachment not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a file from a todo and deletes its contents",
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Delete a file attached to a todo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Attachment ID",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Attachment deleted"
+                    },
+                    "400": {
+                        "description": "Invalid ID parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo or attachment not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/attachments/{attachmentID}/content": {
+            "get": {
+                "description": "Serves the contents of an attached file as a download. Range and conditional requests are\nsupported, so interrupted downloads can be resumed.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Download a file attached to a todo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Attachment ID",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Byte ranges to download, such as bytes=0-1023",
+                        "name": "Range",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File contents",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Requested ranges of the file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo or attachment not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "416": {
+                        "description": "Requested range not satisfiable"
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/comments": {
+            "get": {
+                "description": "Retrieves a page of the comments on a todo, oldest first. Use the returned cursors (also\nsent as RFC 8288 Link headers) with after or before to move between pages.",
+                "produces": [
+                    "application/json",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "List the comments on a todo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to continue after",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to continue before",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved comments",
+                        "schema": {
+                            "$ref": "#/definitions/v1.CommentListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or pagination parameters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted media types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a comment to the discussion of a todo. Todos in the trash cannot be commented on.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Comment on a todo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.CreateCommentRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Comment created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.CommentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationError"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/comments/{commentID}": {
+            "get": {
+                "description": "Retrieves a single comment on a todo",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Get a comment on a todo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "commentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved comment",
+                        "schema": {
+                            "$ref": "#/definitions/v1.CommentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo or comment not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the body of a comment and records when it was edited. The author cannot be changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Edit a comment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "commentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New comment body",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.UpdateCommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated comment",
+                        "schema": {
+                            "$ref": "#/definitions/v1.CommentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationError"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo or comment not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a comment on a todo",
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Delete a comment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "commentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Comment deleted"
+                    },
+                    "400": {
+                        "description": "Invalid ID parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo or comment not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/dependencies": {
+            "post": {
+                "description": "Records that the todo cannot be completed before the blocking todo is done. A dependency\nthat would make a todo wait on itself, directly or through other todos, is rejected.\nAdding a dependency that already exists changes nothing and answers 200 instead of 201.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dependencies"
+                ],
+                "summary": "Make a todo wait on another",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Blocking todo",
+                        "name": "dependency",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.AddDependencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dependency already existed",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DependencyResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Dependency added",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DependencyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationError"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Blocking todo not found or dependency cycle",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/dependencies/{blockerID}": {
+            "delete": {
+                "description": "Removes the dependency of the todo on the blocking todo",
+                "tags": [
+                    "dependencies"
+                ],
+                "summary": "Stop a todo waiting on another",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Blocking todo ID",
+                        "name": "blockerID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Dependency removed"
+                    },
+                    "400": {
+                        "description": "Invalid ID parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo or dependency not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/graph": {
+            "get": {
+                "description": "Returns the todos the todo waits on, directly or through other todos, the todos waiting on\nit in the same way, and the dependencies between them. Todos in the trash are left out. Very\nlarge graphs are cut short and marked as truncated.",
+                "produces": [
+                    "application/json",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "dependencies"
+                ],
+                "summary": "Get the dependency graph of a todo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dependency graph",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DependencyGraphResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted media types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/move": {
+            "post": {
+                "description": "Places the todo directly before or directly after another todo, as seen when listing with\nsort=position. Exactly one of before and after must be given. Moving a todo to where it\nalready is changes nothing.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Move a todo in list order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Todo to move next to",
+                        "name": "move",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.MoveTodoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Todo moved"
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationError"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Position kept being taken by concurrent moves",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Anchor todo not found or todo moved next to itself",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/occurrences": {
+            "get": {
+                "description": "Lists the due dates of the occurrences that follow a recurring todo, in the order completing\neach in turn would create them, together with the rule of its series. Fewer are listed when\nthe series ends sooner. Due dates keep their time of day in the time zone of the series across\ndaylight saving changes, and are given with that zone's offset.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/yaml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Preview the occurrences of a recurring todo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of occurrences (default 10, max 100)",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upcoming occurrences",
+                        "schema": {
+                            "$ref": "#/definitions/v1.OccurrencesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or count parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found or not recurring",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted media types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/restore": {
+            "post": {
+                "description": "Takes a todo item out of the trash, together with the subtasks that were deleted along with it.\nA subtask cannot be restored while its parent is still in the trash.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Restore a todo item from the trash",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully restored todo",
+                        "schema": {
+                            "$ref": "#/definitions/v1.TodoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found in trash",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Parent todo is still in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos:batch": {
+            "post": {
+                "description": "Applies a list of operations and returns one result per operation, in order. create takes a\nCreateTodoRequest as todo, update a PatchTodoRequest and an id, and delete an id.\nBy default every operation is attempted on its own and the response is 200 whatever the\noutcome of each operation. With atomic=true the operations run in one transaction and stop at\nthe first failure: the response then carries the status of the failed operation, and every\nother operation reports 424 BATCH_ABORTED.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Create, update and delete many todo items at once",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Apply all operations or none",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Operations to apply",
+                        "name": "operations",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/v1.BatchOperationRequest"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result of every operation",
+                        "schema": {
+                            "$ref": "#/definitions/v1.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed request or, when atomic, an invalid operation",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Too many operations or body too large",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash": {
+            "get": {
+                "description": "Retrieves every todo item in the trash, most recently deleted first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "List the trash",
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved trash",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/v1.TodoResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/{id}": {
+            "delete": {
+                "description": "Permanently deletes a todo item in the trash together with its subtasks. This cannot be undone.",
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Permanently delete a todo item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully deleted todo"
+                    },
+                    "400": {
+                        "description": "Invalid ID parameter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo not found in trash",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "v1.AddDependencyRequest": {
+            "type": "object",
+            "required": [
+                "blocked_by"
+            ],
+            "properties": {
+                "blocked_by": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "v1.AttachmentResponse": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "application/pdf"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "filename": {
+                    "type": "string",
+                    "example": "floor-plan.pdf"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 48213
+                },
+                "todo_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "v1.BatchOperationRequest": {
+            "type": "object",
+            "required": [
+                "op"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "if_match": {
+                    "type": "string",
+                    "example": "\"3\""
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ],
+                    "example": "update"
+                },
+                "todo": {
+                    "type": "object"
+                }
+            }
+        },
+        "v1.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.BatchResultResponse"
+                    }
+                }
+            }
+        },
+        "v1.BatchResultResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "TODO_NOT_FOUND"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "error": {
+                    "type": "string",
+                    "example": "todo not found"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "todo": {
+                    "$ref": "#/definitions/v1.TodoResponse"
+                }
+            }
+        },
+        "v1.CommentListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.CommentResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJpZCI6MjB9"
+                },
+                "prev_cursor": {
+                    "type": "string",
+                    "example": "eyJpZCI6MX0"
+                }
+            }
+        },
+        "v1.CommentResponse": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Alice"
+                },
+                "body": {
+                    "type": "string",
+                    "example": "Should we order the tiles this week?"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "todo_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:30:00Z"
+                }
+            }
+        },
+        "v1.CreateCommentRequest": {
+            "type": "object",
+            "required": [
+                "author",
+                "body"
+            ],
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1,
+                    "example": "Alice"
+                },
+                "body": {
+                    "type": "string",
+                    "maxLength": 10000,
+                    "minLength": 1,
+                    "example": "Should we order the tiles this week?"
+                }
+            }
+        },
+        "v1.CreateProjectRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "Everything for the new kitchen"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1,
+                    "example": "Home renovation"
+                }
+            }
+        },
+        "v1.CreateTodoRequest": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 10000,
+                    "example": "Whole milk and **rye** bread"
+                },
+                "due_at": {
+                    "type": "string",
+                    "example": "2023-01-02T17:00:00Z"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "medium",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "medium"
+                },
+                "project_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "recurrence": {
+                    "description": "Recurrence makes the todo the first occurrence of a series. It needs\na due date, which the series starts at.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/v1.RecurrenceRequest"
+                        }
+                    ]
+                },
+                "remind_at": {
+                    "type": "string",
+                    "example": "2023-01-02T16:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "backlog",
+                        "in_progress",
+                        "blocked",
+                        "done"
+                    ],
+                    "example": "backlog"
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "home",
+                        "errands"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1,
+                    "example": "Buy groceries"
+                }
+            }
+        },
+        "v1.DependencyGraphResponse": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.DependencyResponse"
+                    }
+                },
+                "todo_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "todos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.TodoResponse"
+                    }
+                },
+                "truncated": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "v1.DependencyResponse": {
+            "type": "object",
+            "properties": {
+                "blocked_by_id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "todo_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "v1.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "trace_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.ImportLineError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "VALIDATION_ERROR"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "error": {
+                    "type": "string",
+                    "example": "validation failed"
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "v1.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.ImportLineError"
+                    }
+                },
+                "imported": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "v1.MoveTodoRequest": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "integer"
+                },
+                "before": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "v1.OccurrenceResponse": {
+            "type": "object",
+            "properties": {
+                "due_at": {
+                    "type": "string",
+                    "example": "2023-02-01T09:00:00+01:00"
+                }
+            }
+        },
+        "v1.OccurrencesResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.OccurrenceResponse"
+                    }
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "FREQ=MONTHLY;BYMONTHDAY=1"
+                },
+                "series_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "time_zone": {
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                }
+            }
+        },
+        "v1.PatchTodoRequest": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 10000,
+                    "example": "Whole milk and **rye** bread"
+                },
+                "due_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2023-01-02T17:00:00Z"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "medium",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "urgent"
+                },
+                "project_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "remind_at": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2023-01-02T16:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "backlog",
+                        "in_progress",
+                        "blocked",
+                        "done"
+                    ],
+                    "example": "in_progress"
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1,
+                    "example": "Buy groceries"
+                }
+            }
+        },
+        "v1.ProjectResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Everything for the new kitchen"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Home renovation"
+                },
+                "todo_count": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "v1.RecurrenceRequest": {
+            "type": "object",
+            "required": [
+                "rule"
+            ],
+            "properties": {
+                "rule": {
+                    "description": "Rule is an RFC 5545 RRULE, with or without the \"RRULE:\" prefix",
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "FREQ=MONTHLY;BYMONTHDAY=1"
+                },
+                "time_zone": {
+                    "description": "TimeZone is the IANA time zone the rule is evaluated in (default UTC)",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "Europe/Berlin"
+                }
+            }
+        },
+        "v1.SearchResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.SearchResultResponse"
+                    }
+                }
+            }
+        },
+        "v1.SearchResultResponse": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2023-01-03T09:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Whole milk and **rye** bread"
+                },
+                "description_html": {
+                    "type": "string",
+                    "example": "\u003cp\u003eWhole milk and \u003cstrong\u003erye\u003c/strong\u003e bread\u003c/p\u003e"
+                },
+                "due_at": {
+                    "type": "string",
+                    "example": "2023-01-02T17:00:00Z"
+                },
+                "etag": {
+                    "type": "string",
+                    "example": "\"3\""
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "priority": {
+                    "type": "string",
+                    "example": "medium"
+                },
+                "project_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "remind_at": {
+                    "type": "string",
+                    "example": "2023-01-02T16:00:00Z"
+                },
+                "score": {
+                    "type": "number",
+                    "example": 0.1
+                },
+                "series_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "snippet": {
+                    "type": "string",
+                    "example": "Buy \u003cmark\u003egroceries\u003c/mark\u003e"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "in_progress"
+                },
+                "subtasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.TodoResponse"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "home",
+                        "errands"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Buy groceries"
+                }
+            }
+        },
+        "v1.TagResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
+                    "type": "string",
+                    "example": "work"
+                }
+            }
+        },
+        "v1.TodoListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.TodoResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJpZCI6MjB9"
+                },
+                "prev_cursor": {
+                    "type": "string",
+                    "example": "eyJpZCI6MX0"
+                }
+            }
+        },
+        "v1.TodoResponse": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2023-01-03T09:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Whole milk and **rye** bread"
+                },
+                "description_html": {
+                    "type": "string",
+                    "example": "\u003cp\u003eWhole milk and \u003cstrong\u003erye\u003c/strong\u003e bread\u003c/p\u003e"
+                },
+                "due_at": {
+                    "type": "string",
+                    "example": "2023-01-02T17:00:00Z"
+                },
+                "etag": {
+                    "type": "string",
+                    "example": "\"3\""
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "priority": {
+                    "type": "string",
+                    "example": "medium"
+                },
+                "project_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "remind_at": {
+                    "type": "string",
+                    "example": "2023-01-02T16:00:00Z"
+                },
+                "series_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "status": {
+                    "type": "string",
+                    "example": "in_progress"
+                },
+                "subtasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.TodoResponse"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "home",
+                        "errands"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Buy groceries"
+                }
+            }
+        },
+        "v1.UpdateCommentRequest": {
+            "type": "object",
+            "required": [
+                "body"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "maxLength": 10000,
+                    "minLength": 1,
+                    "example": "Tiles are ordered for Monday."
+                }
+            }
+        },
+        "v1.UpdateProjectRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "Everything for the new kitchen"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1,
+                    "example": "Home renovation"
+                }
+            }
+        },
+        "v1.UpdateTodoRequest": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "completed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 10000,
+                    "example": "Whole milk and **rye** bread"
+                },
+                "due_at": {
+                    "type": "string",
+                    "example": "2023-01-02T17:00:00Z"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "medium",
+                        "high",
+                        "urgent"
+                    ],
+                    "example": "high"
+                },
+                "project_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "remind_at": {
+                    "type": "string",
+                    "example": "2023-01-02T16:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "backlog",
+                        "in_progress",
+                        "blocked",
+                        "done"
+                    ],
+                    "example": "done"
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "home",
+                        "errands"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1,
                     "example": "Buy groceries"
                 }
             }
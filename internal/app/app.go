@@ -5,14 +5,26 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 
 	"github.com/NoroSaroyan/go-rest-api-example/internal/config"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/blobstore"
 	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
 	"github.com/NoroSaroyan/go-rest-api-example/internal/repository"
 	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/middleware"
+)
+
+const (
+	// idempotencySweepInterval is how often expired idempotency keys are deleted.
+	idempotencySweepInterval = time.Hour
+	// attachmentSweepInterval is how often the files of deleted attachments
+	// are deleted.
+	attachmentSweepInterval = time.Hour
 )
 
 // App encapsulates the whole application state.
@@ -21,6 +33,14 @@ type App struct {
 	server *http.Server
 	db     *pgxpool.Pool
 	logger logger.Logger
+	purger *service.TrashPurger
+
+	idempotency middleware.IdempotencyStore
+	attachments service.AttachmentService
+
+	// stopWorkers cancels the background workers and workers waits for them.
+	stopWorkers context.CancelFunc
+	workers     sync.WaitGroup
 }
 
 func New() (*App, error) {
@@ -72,10 +92,44 @@ func New() (*App, error) {
 
 	// Initialize repository & service
 	todoRepo := repository.NewTodoRepository(dbpool)
-	todoService := service.NewTodoService(todoRepo)
+	serviceOpts := []service.Option{
+		service.WithMaxSubtaskDepth(cfg.Todo.MaxSubtaskDepth),
+		service.WithAutoCompleteParents(cfg.Todo.AutoCompleteParents),
+	}
+	if cfg.Todo.Workflow != nil {
+		serviceOpts = append(serviceOpts, service.WithWorkflow(cfg.Todo.Workflow))
+	}
+	todoService := service.NewTodoService(todoRepo, serviceOpts...)
+
+	purger := service.NewTrashPurger(todoService, cfg.Todo.TrashRetention, cfg.Todo.TrashPurgeInterval, log)
+
+	projectRepo := repository.NewProjectRepository(dbpool)
+	projectService := service.NewProjectService(projectRepo)
+
+	commentRepo := repository.NewCommentRepository(dbpool)
+	commentService := service.NewCommentService(commentRepo, todoRepo)
+
+	blobs, err := newBlobStore(cfg.Attachment)
+	if err != nil {
+		log.Error("failed to set up attachment store", zap.Error(err))
+		dbpool.Close()
+		return nil, fmt.Errorf("failed to set up attachment store: %w", err)
+	}
+	attachmentRepo := repository.NewAttachmentRepository(dbpool)
+	attachmentService := service.NewAttachmentService(attachmentRepo, blobs, todoRepo,
+		service.WithMaxAttachmentSize(cfg.Attachment.MaxSize),
+		service.WithAttachmentTypes(cfg.Attachment.AllowedTypes),
+	)
+
+	var idempotency middleware.IdempotencyStore
+	if cfg.Idempotency.Store == config.IdempotencyStoreMemory {
+		idempotency = middleware.NewMemoryIdempotencyStore()
+	} else {
+		idempotency = repository.NewIdempotencyStore(dbpool)
+	}
 
 	// Build router
-	router := NewRouter(todoService, log)
+	router := NewRouter(cfg, todoService, projectService, commentService, attachmentService, idempotency, log)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.App.Port,
@@ -85,12 +139,119 @@ func New() (*App, error) {
 		IdleTimeout:  cfg.App.IdleTimeout,
 	}
 
-	return &App{
+	a := &App{
 		cfg:    cfg,
 		server: srv,
 		db:     dbpool,
 		logger: log,
-	}, nil
+		purger: purger,
+
+		idempotency: idempotency,
+		attachments: attachmentService,
+	}
+	a.startWorkers()
+
+	return a, nil
+}
+
+// startWorkers runs the background workers until Shutdown is called.
+func (a *App) startWorkers() {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopWorkers = cancel
+
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		a.purger.Run(ctx)
+	}()
+
+	a.logger.Info("trash purger started",
+		zap.Duration("retention", a.cfg.Todo.TrashRetention),
+		zap.Duration("interval", a.cfg.Todo.TrashPurgeInterval),
+	)
+
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		a.sweepIdempotencyKeys(ctx)
+	}()
+
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		a.sweepAttachments(ctx)
+	}()
+}
+
+// newBlobStore returns the store attachments are kept in.
+func newBlobStore(cfg config.AttachmentConfig) (service.BlobStore, error) {
+	if cfg.Store == config.AttachmentStoreS3 {
+		return blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+		}), nil
+	}
+	return blobstore.NewLocalStore(cfg.Dir)
+}
+
+// sweepIdempotencyKeys deletes expired idempotency keys every
+// idempotencySweepInterval until ctx is done.
+func (a *App) sweepIdempotencyKeys(ctx context.Context) {
+	ctx = logger.Inject(ctx, a.logger)
+
+	ticker := time.NewTicker(idempotencySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := a.idempotency.DeleteExpired(ctx)
+			if err != nil {
+				a.logger.Error("failed to delete expired idempotency keys", zap.Error(err))
+				continue
+			}
+			if n > 0 {
+				a.logger.Info("expired idempotency keys deleted", zap.Int("count", n))
+			}
+		}
+	}
+}
+
+// sweepAttachments deletes the files of attachments that were deleted, or
+// whose todo was, every attachmentSweepInterval until ctx is done. Each
+// sweep goes on batch after batch until one deletes nothing.
+func (a *App) sweepAttachments(ctx context.Context) {
+	ctx = logger.Inject(ctx, a.logger)
+
+	ticker := time.NewTicker(attachmentSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			total := 0
+			for ctx.Err() == nil {
+				n, err := a.attachments.DeleteOrphans(ctx)
+				if err != nil {
+					a.logger.Error("failed to delete orphaned attachments", zap.Error(err))
+				}
+				if n == 0 {
+					break
+				}
+				total += n
+			}
+			if total > 0 {
+				a.logger.Info("orphaned attachments deleted", zap.Int("count", total))
+			}
+		}
+	}
 }
 
 // Run starts the HTTP server.
@@ -99,9 +260,11 @@ func (a *App) Run() error {
 	return a.server.ListenAndServe()
 }
 
-// Shutdown gracefully stops the server.
+// Shutdown gracefully stops the background workers and the server.
 func (a *App) Shutdown(ctx context.Context) error {
 	a.logger.Info("shutting down server")
+	a.stopWorkers()
+	a.workers.Wait()
 	a.db.Close()
 	return a.server.Shutdown(ctx)
 }
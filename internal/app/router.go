@@ -7,26 +7,56 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 	"go.uber.org/zap"
 
+	"github.com/NoroSaroyan/go-rest-api-example/internal/config"
 	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
 	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/codec"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/problem"
 	v1 "github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/v1"
 	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/middleware"
 )
 
-// NewRouter configures all HTTP routes and middleware.
-func NewRouter(todoService service.TodoService, log logger.Logger) http.Handler {
+// NewRouter configures all HTTP routes and middleware. POST requests to the
+// API made with an Idempotency-Key are remembered in idempotency.
+func NewRouter(
+	cfg *config.Config,
+	todoService service.TodoService,
+	projectService service.ProjectService,
+	commentService service.CommentService,
+	attachmentService service.AttachmentService,
+	idempotency middleware.IdempotencyStore,
+	log logger.Logger,
+) http.Handler {
 	r := mux.NewRouter()
 
 	// Middlewares
 	r.Use(middleware.RequestID)
 	r.Use(middleware.Logging(log))
+	r.Use(middleware.ProblemDetails(problem.Format(cfg.App.ErrorFormat)))
 
 	// API v1
 	v1Router := r.PathPrefix("/api/v1").Subrouter()
+	v1Router.Use(middleware.ContentNegotiation(codec.Default))
+	v1Router.Use(middleware.Idempotency(idempotency, cfg.Idempotency.TTL, v1.MaxBodySize(cfg.Attachment.MaxSize)))
 
 	todoHandler := v1.NewTodoHandler(todoService)
 	todoHandler.RegisterRoutes(v1Router)
 
+	projectHandler := v1.NewProjectHandler(projectService, todoHandler)
+	projectHandler.RegisterRoutes(v1Router)
+
+	commentHandler := v1.NewCommentHandler(commentService)
+	commentHandler.RegisterRoutes(v1Router)
+
+	attachmentHandler := v1.NewAttachmentHandler(attachmentService, cfg.Attachment.MaxSize)
+	attachmentHandler.RegisterRoutes(v1Router)
+
+	// Attached files are served as they were uploaded, whatever their type,
+	// so their route is left out of content negotiation. Routes are matched
+	// in order, so this only sees what v1Router does not match.
+	contentRouter := r.PathPrefix("/api/v1").Subrouter()
+	attachmentHandler.RegisterContentRoutes(contentRouter)
+
 	// Simple healthcheck
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -10,19 +10,35 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
 )
 
 type Config struct {
-	App AppConfig
-	DB  DBConfig
-	Log LogConfig
+	App  AppConfig
+	DB   DBConfig
+	Log  LogConfig
+	Todo TodoConfig
+
+	Idempotency IdempotencyConfig
+	Attachment  AttachmentConfig
 }
 
+// Error formats supported by AppConfig.ErrorFormat.
+const (
+	ErrorFormatLegacy  = "legacy"
+	ErrorFormatProblem = "problem"
+)
+
 type AppConfig struct {
 	Port         string
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// ErrorFormat is the format of error responses for clients that do not
+	// ask for one: ErrorFormatLegacy or ErrorFormatProblem (RFC 9457
+	// problem details).
+	ErrorFormat string
 }
 
 type DBConfig struct {
@@ -41,6 +57,69 @@ type LogConfig struct {
 	Level string
 }
 
+// TodoConfig holds the settings of the todo domain.
+type TodoConfig struct {
+	// Workflow restricts status transitions. Nil means domain.DefaultWorkflow.
+	Workflow domain.Workflow
+	// MaxSubtaskDepth limits how many levels of subtasks a todo may have.
+	MaxSubtaskDepth int
+	// AutoCompleteParents completes a todo once all of its subtasks are done.
+	AutoCompleteParents bool
+	// TrashRetention is how long deleted todos stay in the trash before
+	// they are purged.
+	TrashRetention time.Duration
+	// TrashPurgeInterval is how often the trash is checked for todos to purge.
+	TrashPurgeInterval time.Duration
+}
+
+// Idempotency stores supported by IdempotencyConfig.Store.
+const (
+	IdempotencyStorePostgres = "postgres"
+	IdempotencyStoreMemory   = "memory"
+)
+
+// IdempotencyConfig holds the settings of the Idempotency-Key support.
+type IdempotencyConfig struct {
+	// Store selects where responses are kept: IdempotencyStorePostgres or
+	// IdempotencyStoreMemory.
+	Store string
+	// TTL is how long a key is remembered after its first use.
+	TTL time.Duration
+}
+
+// Attachment stores supported by AttachmentConfig.Store.
+const (
+	AttachmentStoreLocal = "local"
+	AttachmentStoreS3    = "s3"
+)
+
+// AttachmentConfig holds the settings of file attachments.
+type AttachmentConfig struct {
+	// Store selects where files are kept: AttachmentStoreLocal or
+	// AttachmentStoreS3.
+	Store string
+	// Dir is the directory files are kept in by the local store.
+	Dir string
+	// MaxSize is the largest file that may be uploaded, in bytes.
+	MaxSize int64
+	// AllowedTypes lists the media types files may have, as sniffed from
+	// their contents.
+	AllowedTypes []string
+	// S3 configures the S3 store.
+	S3 S3Config
+}
+
+// S3Config holds the settings of an S3-compatible object store.
+type S3Config struct {
+	// Endpoint is the base URL of the store, such as
+	// https://s3.eu-central-1.amazonaws.com or http://localhost:9000.
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
 // Load reads configuration from environment variables and validates them.
 func Load() (*Config, error) {
 	// Load .env file if it exists (silently ignore if it doesn't)
@@ -58,6 +137,18 @@ func Load() (*Config, error) {
 
 	cfg.loadLogConfig()
 
+	if err := cfg.loadTodoConfig(); err != nil {
+		return nil, fmt.Errorf("failed to load todo config: %w", err)
+	}
+
+	if err := cfg.loadIdempotencyConfig(); err != nil {
+		return nil, fmt.Errorf("failed to load idempotency config: %w", err)
+	}
+
+	if err := cfg.loadAttachmentConfig(); err != nil {
+		return nil, fmt.Errorf("failed to load attachment config: %w", err)
+	}
+
 	if err := cfg.validate(); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
@@ -82,6 +173,13 @@ func (c *Config) loadAppConfig() error {
 		return err
 	}
 
+	c.App.ErrorFormat = strings.ToLower(getEnv("APP_ERROR_FORMAT", ErrorFormatLegacy))
+	switch c.App.ErrorFormat {
+	case ErrorFormatLegacy, ErrorFormatProblem:
+	default:
+		return fmt.Errorf("invalid APP_ERROR_FORMAT: must be one of legacy, problem")
+	}
+
 	return nil
 }
 
@@ -120,6 +218,108 @@ func (c *Config) loadLogConfig() {
 	c.Log.Level = getEnv("LOG_LEVEL", "info")
 }
 
+func (c *Config) loadTodoConfig() error {
+	var err error
+
+	if spec := getEnv("TODO_WORKFLOW", ""); spec != "" {
+		workflow, err := domain.ParseWorkflow(spec)
+		if err != nil {
+			return fmt.Errorf("invalid TODO_WORKFLOW: %w", err)
+		}
+		c.Todo.Workflow = workflow
+	}
+
+	if c.Todo.MaxSubtaskDepth, err = parseInt("TODO_MAX_SUBTASK_DEPTH", "3"); err != nil {
+		return err
+	}
+	if c.Todo.MaxSubtaskDepth < 1 {
+		return fmt.Errorf("invalid TODO_MAX_SUBTASK_DEPTH: must be at least 1")
+	}
+
+	if c.Todo.AutoCompleteParents, err = parseBool("TODO_AUTO_COMPLETE_PARENTS", "false"); err != nil {
+		return err
+	}
+
+	if c.Todo.TrashRetention, err = parseDuration("TODO_TRASH_RETENTION", "720h"); err != nil {
+		return err
+	}
+	if c.Todo.TrashRetention <= 0 {
+		return fmt.Errorf("invalid TODO_TRASH_RETENTION: must be positive")
+	}
+
+	if c.Todo.TrashPurgeInterval, err = parseDuration("TODO_TRASH_PURGE_INTERVAL", "1h"); err != nil {
+		return err
+	}
+	if c.Todo.TrashPurgeInterval <= 0 {
+		return fmt.Errorf("invalid TODO_TRASH_PURGE_INTERVAL: must be positive")
+	}
+
+	return nil
+}
+
+func (c *Config) loadIdempotencyConfig() error {
+	var err error
+
+	c.Idempotency.Store = strings.ToLower(getEnv("IDEMPOTENCY_STORE", IdempotencyStorePostgres))
+	switch c.Idempotency.Store {
+	case IdempotencyStorePostgres, IdempotencyStoreMemory:
+	default:
+		return fmt.Errorf("invalid IDEMPOTENCY_STORE: must be one of postgres, memory")
+	}
+
+	if c.Idempotency.TTL, err = parseDuration("IDEMPOTENCY_TTL", "24h"); err != nil {
+		return err
+	}
+	if c.Idempotency.TTL <= 0 {
+		return fmt.Errorf("invalid IDEMPOTENCY_TTL: must be positive")
+	}
+
+	return nil
+}
+
+func (c *Config) loadAttachmentConfig() error {
+	c.Attachment.Store = strings.ToLower(getEnv("ATTACHMENT_STORE", AttachmentStoreLocal))
+	switch c.Attachment.Store {
+	case AttachmentStoreLocal, AttachmentStoreS3:
+	default:
+		return fmt.Errorf("invalid ATTACHMENT_STORE: must be one of local, s3")
+	}
+
+	c.Attachment.Dir = getEnv("ATTACHMENT_DIR", "data/attachments")
+
+	maxSize, err := parseInt("ATTACHMENT_MAX_SIZE", "10485760")
+	if err != nil {
+		return err
+	}
+	if maxSize <= 0 {
+		return fmt.Errorf("invalid ATTACHMENT_MAX_SIZE: must be positive")
+	}
+	c.Attachment.MaxSize = int64(maxSize)
+
+	types := getEnv("ATTACHMENT_ALLOWED_TYPES", "image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain")
+	for _, t := range strings.Split(types, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			c.Attachment.AllowedTypes = append(c.Attachment.AllowedTypes, t)
+		}
+	}
+	if len(c.Attachment.AllowedTypes) == 0 {
+		return fmt.Errorf("invalid ATTACHMENT_ALLOWED_TYPES: must list at least one media type")
+	}
+
+	c.Attachment.S3 = S3Config{
+		Endpoint:        getEnv("S3_ENDPOINT", ""),
+		Region:          getEnv("S3_REGION", "us-east-1"),
+		Bucket:          getEnv("S3_BUCKET", ""),
+		AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+		SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+	}
+	if c.Attachment.Store == AttachmentStoreS3 && (c.Attachment.S3.Endpoint == "" || c.Attachment.S3.Bucket == "") {
+		return fmt.Errorf("S3_ENDPOINT and S3_BUCKET are required when ATTACHMENT_STORE is s3")
+	}
+
+	return nil
+}
+
 func (c *Config) validate() error {
 	// Validate app port
 	if port, err := strconv.Atoi(c.App.Port); err != nil || port < 1 || port > 65535 {
@@ -187,6 +387,15 @@ func parseInt(key, defaultValue string) (int, error) {
 	return result, nil
 }
 
+func parseBool(key, defaultValue string) (bool, error) {
+	val := getEnv(key, defaultValue)
+	result, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return result, nil
+}
+
 func parseDuration(key, defaultValue string) (time.Duration, error) {
 	val := getEnv(key, defaultValue)
 	result, err := time.ParseDuration(val)
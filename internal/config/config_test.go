@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -21,7 +22,17 @@ func TestLoad(t *testing.T) {
 				return c.App.Port == "8080" &&
 					c.DB.Host == "localhost" &&
 					c.DB.Port == 5432 &&
-					c.Log.Level == "info"
+					c.Log.Level == "info" &&
+					c.Todo.MaxSubtaskDepth == 3 &&
+					!c.Todo.AutoCompleteParents &&
+					c.Todo.TrashRetention == 30*24*time.Hour &&
+					c.Todo.TrashPurgeInterval == time.Hour &&
+					c.Idempotency.Store == IdempotencyStorePostgres &&
+					c.Idempotency.TTL == 24*time.Hour &&
+					c.App.ErrorFormat == ErrorFormatLegacy &&
+					c.Attachment.Store == AttachmentStoreLocal &&
+					c.Attachment.MaxSize == 10<<20 &&
+					len(c.Attachment.AllowedTypes) == 6
 			},
 			description: "should load with default values when no env vars set",
 		},
@@ -65,6 +76,143 @@ func TestLoad(t *testing.T) {
 			wantErr:     true,
 			description: "should fail validation with invalid log level",
 		},
+		{
+			name: "custom workflow",
+			env: map[string]string{
+				"TODO_WORKFLOW": "backlog:in_progress;in_progress:done",
+			},
+			validate: func(c *Config) bool {
+				return c.Todo.Workflow.Allows("backlog", "in_progress") &&
+					!c.Todo.Workflow.Allows("backlog", "done")
+			},
+			description: "should parse the status workflow",
+		},
+		{
+			name: "invalid workflow",
+			env: map[string]string{
+				"TODO_WORKFLOW": "backlog:archived",
+			},
+			wantErr:     true,
+			description: "should fail with an unknown status in the workflow",
+		},
+		{
+			name: "subtask settings",
+			env: map[string]string{
+				"TODO_MAX_SUBTASK_DEPTH":     "5",
+				"TODO_AUTO_COMPLETE_PARENTS": "true",
+			},
+			validate: func(c *Config) bool {
+				return c.Todo.MaxSubtaskDepth == 5 && c.Todo.AutoCompleteParents
+			},
+			description: "should load the subtask settings",
+		},
+		{
+			name: "invalid subtask depth",
+			env: map[string]string{
+				"TODO_MAX_SUBTASK_DEPTH": "0",
+			},
+			wantErr:     true,
+			description: "should fail when subtasks are not allowed to nest at all",
+		},
+		{
+			name: "trash settings",
+			env: map[string]string{
+				"TODO_TRASH_RETENTION":      "168h",
+				"TODO_TRASH_PURGE_INTERVAL": "10m",
+			},
+			validate: func(c *Config) bool {
+				return c.Todo.TrashRetention == 7*24*time.Hour && c.Todo.TrashPurgeInterval == 10*time.Minute
+			},
+			description: "should load the trash settings",
+		},
+		{
+			name: "invalid trash retention",
+			env: map[string]string{
+				"TODO_TRASH_RETENTION": "-1h",
+			},
+			wantErr:     true,
+			description: "should fail with a negative trash retention",
+		},
+		{
+			name: "problem details",
+			env: map[string]string{
+				"APP_ERROR_FORMAT": "Problem",
+			},
+			validate: func(c *Config) bool {
+				return c.App.ErrorFormat == ErrorFormatProblem
+			},
+			description: "should load the error format case-insensitively",
+		},
+		{
+			name: "invalid error format",
+			env: map[string]string{
+				"APP_ERROR_FORMAT": "xml",
+			},
+			wantErr:     true,
+			description: "should fail with an unknown error format",
+		},
+		{
+			name: "idempotency settings",
+			env: map[string]string{
+				"IDEMPOTENCY_STORE": "memory",
+				"IDEMPOTENCY_TTL":   "1h",
+			},
+			validate: func(c *Config) bool {
+				return c.Idempotency.Store == IdempotencyStoreMemory && c.Idempotency.TTL == time.Hour
+			},
+			description: "should load the idempotency settings",
+		},
+		{
+			name: "invalid idempotency store",
+			env: map[string]string{
+				"IDEMPOTENCY_STORE": "redis",
+			},
+			wantErr:     true,
+			description: "should fail with an unknown idempotency store",
+		},
+		{
+			name: "invalid idempotency ttl",
+			env: map[string]string{
+				"IDEMPOTENCY_TTL": "0s",
+			},
+			wantErr:     true,
+			description: "should fail when keys would expire immediately",
+		},
+		{
+			name: "attachment settings",
+			env: map[string]string{
+				"ATTACHMENT_STORE":         "S3",
+				"ATTACHMENT_MAX_SIZE":      "1024",
+				"ATTACHMENT_ALLOWED_TYPES": " image/png, Application/PDF ,",
+				"S3_ENDPOINT":              "http://localhost:9000",
+				"S3_BUCKET":                "attachments",
+			},
+			validate: func(c *Config) bool {
+				return c.Attachment.Store == AttachmentStoreS3 &&
+					c.Attachment.MaxSize == 1024 &&
+					reflect.DeepEqual(c.Attachment.AllowedTypes, []string{"image/png", "application/pdf"}) &&
+					c.Attachment.S3.Bucket == "attachments" &&
+					c.Attachment.S3.Region == "us-east-1"
+			},
+			description: "should load the attachment settings",
+		},
+		{
+			name: "s3 store without bucket",
+			env: map[string]string{
+				"ATTACHMENT_STORE": "s3",
+				"S3_ENDPOINT":      "http://localhost:9000",
+			},
+			wantErr:     true,
+			description: "should fail when the S3 store has no bucket",
+		},
+		{
+			name: "invalid attachment size",
+			env: map[string]string{
+				"ATTACHMENT_MAX_SIZE": "0",
+			},
+			wantErr:     true,
+			description: "should fail when no file could be uploaded",
+		},
 	}
 
 	for _, tt := range tests {
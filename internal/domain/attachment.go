@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Attachment is a file uploaded to a todo. Its contents live in a blob
+// store under Key; only what is needed to find and serve them is kept with
+// the todo. Attachments stay with their todo while it is in the trash, and
+// are deleted along with it once it is deleted for good.
+type Attachment struct {
+	ID       int    `db:"id"`
+	TodoID   int    `db:"todo_id"`
+	Filename string `db:"filename"`
+	// ContentType is sniffed from the contents on upload; whatever the
+	// client claimed is ignored.
+	ContentType string    `db:"content_type"`
+	Size        int64     `db:"size"`
+	Key         string    `db:"storage_key"`
+	CreatedAt   time.Time `db:"created_at"`
+}
@@ -0,0 +1,35 @@
+package domain
+
+// BatchAction is the kind of change a batch operation makes.
+type BatchAction string
+
+const (
+	BatchCreate BatchAction = "create"
+	BatchUpdate BatchAction = "update"
+	BatchDelete BatchAction = "delete"
+)
+
+// BatchOperation is a single change within a batch of changes to todos.
+type BatchOperation struct {
+	Action BatchAction
+
+	// Todo is the todo to create.
+	Todo Todo
+
+	// ID identifies the todo to update or delete.
+	ID int
+	// Update holds the changes an update makes, including its version check.
+	Update TodoUpdate
+	// IfVersion restricts a delete to todos at one of these versions.
+	IfVersion VersionMatch
+}
+
+// BatchResult is the outcome of a single batch operation.
+type BatchResult struct {
+	// ID is the ID of the todo that was created, updated or deleted.
+	ID int
+	// Todo is the updated todo.
+	Todo *Todo
+	// Err is why the operation failed, or nil if it succeeded.
+	Err error
+}
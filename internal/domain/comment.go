@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// Comment is a message left on a todo, so that the todo can hold the
+// discussion around it. Comments stay with their todo while it is in the
+// trash, and are deleted along with it once it is deleted for good.
+type Comment struct {
+	ID        int        `db:"id"`
+	TodoID    int        `db:"todo_id"`
+	Author    string     `db:"author"`
+	Body      string     `db:"body"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt *time.Time `db:"updated_at"`
+}
+
+// Validate checks the business rules that apply to every comment.
+func (c *Comment) Validate() error {
+	if c.Author == "" {
+		return ErrInvalidCommentAuthor
+	}
+	if c.Body == "" {
+		return ErrInvalidCommentBody
+	}
+	return nil
+}
+
+// CommentPageQuery describes a single keyset page of the comments on a
+// todo, oldest first. At most one of After and Before is set; both bounds
+// are exclusive.
+type CommentPageQuery struct {
+	TodoID int
+	Limit  int
+	After  *Cursor
+	Before *Cursor
+}
+
+// CommentPage is one page of comments along with opaque cursors for the
+// neighbouring pages. Empty cursors mean there is no such page.
+type CommentPage struct {
+	Items      []Comment
+	NextCursor string
+	PrevCursor string
+}
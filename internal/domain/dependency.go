@@ -0,0 +1,21 @@
+package domain
+
+// Dependency records that a todo cannot be completed before another todo,
+// its blocker, is done.
+type Dependency struct {
+	TodoID      int `db:"todo_id"`
+	BlockedByID int `db:"blocked_by_id"`
+}
+
+// DependencyGraph is the part of the dependency graph a todo belongs to:
+// the todos it waits on, directly or through others, and the todos waiting
+// on it. Todos in the trash are left out.
+type DependencyGraph struct {
+	TodoID int
+	// Todos holds the todo itself and every todo connected to it, ordered
+	// by ID.
+	Todos        []Todo
+	Dependencies []Dependency
+	// Truncated is set when the graph was cut short at its size limit
+	Truncated bool
+}
@@ -5,6 +5,55 @@ import "errors"
 // Domain-level errors returned by repositories and services,
 // enabling transport layer to map them to proper HTTP responses.
 var (
-	ErrTodoNotFound = errors.New("todo not found")
-	ErrInvalidTitle = errors.New("title cannot be empty")
+	ErrTodoNotFound      = errors.New("todo not found")
+	ErrInvalidTitle      = errors.New("title cannot be empty")
+	ErrInvalidCursor     = errors.New("invalid pagination cursor")
+	ErrInvalidReminder   = errors.New("reminder cannot be after the due date")
+	ErrInvalidStatus     = errors.New("invalid status")
+	ErrInvalidPriority   = errors.New("invalid priority")
+	ErrInvalidTransition = errors.New("status transition not allowed")
+	ErrInvalidTag        = errors.New("invalid tag")
+	ErrVersionMismatch   = errors.New("todo has been modified since it was last read")
+	ErrConcurrentUpdate  = errors.New("todo kept being modified by concurrent changes")
+
+	ErrParentNotFound = errors.New("parent todo not found")
+	ErrSubtaskCycle   = errors.New("a todo cannot be a subtask of itself or of its own subtasks")
+	ErrSubtaskTooDeep = errors.New("subtasks are nested too deeply")
+	ErrParentDeleted  = errors.New("parent todo is in the trash")
+
+	ErrProjectNotFound    = errors.New("project not found")
+	ErrInvalidProjectName = errors.New("project name cannot be empty")
+	ErrProjectNotEmpty    = errors.New("project still has todos")
+
+	ErrBatchTooLarge = errors.New("batch has too many operations")
+	ErrBatchAborted  = errors.New("operation rolled back because another operation in the batch failed")
+
+	ErrImportTooLarge = errors.New("import has too many todos")
+
+	ErrPatchTooLarge = errors.New("patch document is too large")
+
+	ErrInvalidSearch = errors.New("invalid search query")
+
+	ErrInvalidRecurrence    = errors.New("invalid recurrence")
+	ErrRecurrenceWithoutDue = errors.New("a recurring todo needs a due date")
+	ErrNotRecurring         = errors.New("todo does not recur")
+	ErrOccurrenceExists     = errors.New("another occurrence of the series is due at the same time")
+
+	ErrBlockerNotFound    = errors.New("blocking todo not found")
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrDependencyCycle    = errors.New("a todo cannot wait on itself or on a todo that waits on it")
+	ErrBlocked            = errors.New("todo is blocked by todos that are not done")
+
+	ErrInvalidMove    = errors.New("a todo cannot be moved next to itself")
+	ErrAnchorNotFound = errors.New("anchor todo not found")
+	ErrPositionTaken  = errors.New("position was taken by a concurrent move")
+
+	ErrCommentNotFound      = errors.New("comment not found")
+	ErrInvalidCommentAuthor = errors.New("comment author cannot be empty")
+	ErrInvalidCommentBody   = errors.New("comment body cannot be empty")
+
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	ErrAttachmentEmpty    = errors.New("attachment is empty")
+	ErrAttachmentType     = errors.New("attachment type is not allowed")
 )
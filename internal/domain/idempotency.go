@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord is what is remembered about the first request made with
+// an idempotency key, so that retries of it can be answered with the same
+// response instead of being carried out again.
+type IdempotencyRecord struct {
+	Key string
+	// RequestHash identifies the request the key was first used with.
+	RequestHash string
+
+	// StatusCode is 0 while the first request is still being processed.
+	StatusCode int
+	Header     map[string]string
+	Body       []byte
+
+	ExpiresAt time.Time
+}
+
+// Completed reports whether the response to the request has been stored.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// Cursor identifies a position in an ordered list of todos, or of the
+// comments on a todo. It holds the keyset values of the row the position
+// refers to, along with the sort order it was issued for.
+type Cursor struct {
+	ID        int       `json:"id"`
+	Title     string    `json:"title,omitempty"`
+	Completed bool      `json:"completed,omitempty"`
+	Status    Status    `json:"status,omitempty"`
+	Priority  Priority  `json:"priority,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Position  float64   `json:"position,omitempty"`
+	Sort      string    `json:"sort,omitempty"`
+}
+
+// CursorFor returns the cursor pointing at t within a list sorted by sort.
+func CursorFor(t Todo, sort string) Cursor {
+	return Cursor{
+		ID:        t.ID,
+		Title:     t.Title,
+		Completed: t.IsCompleted(),
+		Status:    t.Status,
+		Priority:  t.Priority,
+		CreatedAt: t.CreatedAt,
+		Position:  t.Position,
+		Sort:      sort,
+	}
+}
+
+// PageRequest is a client's request for a page of todos.
+// After and Before are opaque cursors; at most one of them may be set.
+type PageRequest struct {
+	Limit  int
+	After  string
+	Before string
+}
+
+// PageQuery describes a single keyset page requested from a repository.
+// At most one of After and Before is set; both bounds are exclusive.
+type PageQuery struct {
+	TodoQuery
+	Limit  int
+	After  *Cursor
+	Before *Cursor
+}
+
+// TodoPage is one page of todos along with opaque cursors for
+// the neighbouring pages. Empty cursors mean there is no such page.
+type TodoPage struct {
+	Items      []Todo
+	NextCursor string
+	PrevCursor string
+}
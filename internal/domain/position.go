@@ -0,0 +1,27 @@
+package domain
+
+// MoveAnchor names the todo another todo is moved next to: directly after
+// it, or directly before it.
+type MoveAnchor struct {
+	TodoID int
+	After  bool
+}
+
+// PositionGap is the gap in list order a moved todo is placed into,
+// bounded by the positions of the todos that end up directly before and
+// after it. A nil bound stands for the start or end of the list.
+type PositionGap struct {
+	After  *float64
+	Before *float64
+}
+
+// Contains reports whether position lies strictly inside the gap.
+func (g PositionGap) Contains(position float64) bool {
+	return (g.After == nil || *g.After < position) && (g.Before == nil || position < *g.Before)
+}
+
+// TodoPosition is the position of a single todo.
+type TodoPosition struct {
+	TodoID   int
+	Position float64
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Project groups related todos. Todos that belong to no project live in
+// the global list only.
+type Project struct {
+	ID          int       `db:"id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	CreatedAt   time.Time `db:"created_at"`
+
+	// TodoCount is the number of todos in the project. It is computed by
+	// the database and ignored on writes.
+	TodoCount int `db:"todo_count"`
+}
+
+// Validate checks the business rules that apply to every project.
+func (p *Project) Validate() error {
+	if p.Name == "" {
+		return ErrInvalidProjectName
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// TodoFilter narrows down which todos are listed.
+// Zero-valued fields do not filter anything.
+type TodoFilter struct {
+	Completed     *bool
+	Statuses      []Status
+	Priorities    []Priority
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	TitleContains string
+	ProjectID     *int
+
+	// Tags keeps todos carrying the given tags, matched according to
+	// TagMatch (any by default).
+	Tags     []string
+	TagMatch TagMatch
+
+	// DueFrom (inclusive) and DueUntil (exclusive) bound the due date.
+	DueFrom  *time.Time
+	DueUntil *time.Time
+
+	// Relative due date filters. They depend on the current time and are
+	// resolved into DueFrom and DueUntil by the service; Location decides
+	// where "today" starts and ends.
+	Overdue   bool
+	DueToday  bool
+	DueWithin time.Duration
+	Location  *time.Location
+}
+
+// SortField names a todo attribute lists can be ordered by.
+type SortField string
+
+// Sortable todo attributes.
+const (
+	SortByID        SortField = "id"
+	SortByTitle     SortField = "title"
+	SortByCompleted SortField = "completed"
+	SortByStatus    SortField = "status"
+	SortByPriority  SortField = "priority"
+	SortByCreatedAt SortField = "created_at"
+	SortByPosition  SortField = "position"
+)
+
+// SortKey orders a list by a single field.
+type SortKey struct {
+	Field SortField
+	Desc  bool
+}
+
+// String renders the key in the "-field" notation used by the API.
+func (k SortKey) String() string {
+	if k.Desc {
+		return "-" + string(k.Field)
+	}
+	return string(k.Field)
+}
+
+// TodoQuery combines filtering and ordering of a todo list.
+// When Sort is empty todos are ordered by ID.
+type TodoQuery struct {
+	Filter TodoFilter
+	Sort   []SortKey
+}
+
+// SortSpec renders the sort order in the "-field,field" notation used by the API.
+func (q TodoQuery) SortSpec() string {
+	parts := make([]string, 0, len(q.Sort))
+	for _, key := range q.Sort {
+		parts = append(parts, key.String())
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Recurrence makes a todo recur: completing one occurrence creates the
+// next, due at the next time its rule gives.
+type Recurrence struct {
+	// Rule is an RFC 5545 recurrence rule, such as "FREQ=MONTHLY;BYMONTHDAY=1"
+	Rule string `db:"rrule"`
+	// TimeZone is the IANA time zone the rule is evaluated in, so that
+	// occurrences keep their time of day across daylight saving changes
+	TimeZone string `db:"time_zone"`
+}
+
+// Series links the occurrences of a recurring todo.
+type Series struct {
+	ID int `db:"id"`
+	Recurrence
+	// Start is the due date of the first occurrence, which the rule is
+	// evaluated against.
+	Start     time.Time `db:"starts_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,38 @@
+package domain
+
+// Markers around the matched words of a search result snippet. They are
+// control characters, which todos do not otherwise contain, so that the
+// transport can replace them with whatever highlighting suits its format.
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// SearchTerm is a word or phrase a full-text search looks for.
+type SearchTerm struct {
+	// Words are matched next to each other, in this order. They hold
+	// only letters and digits.
+	Words []string
+	// Exclude keeps out the todos that match the term
+	Exclude bool
+	// Prefix matches words starting with the last word
+	Prefix bool
+}
+
+// SearchQuery is a full-text search for todos. A todo matches if it
+// matches every term of any of the alternatives in Any.
+type SearchQuery struct {
+	Any    [][]SearchTerm
+	Filter TodoFilter
+	Limit  int
+}
+
+// SearchResult is a todo matching a search.
+type SearchResult struct {
+	Todo Todo
+	// Score tells how well the todo matches; higher is better
+	Score float64
+	// Snippet is the matching title, or passages of the description, with
+	// every matched word between HighlightStart and HighlightEnd
+	Snippet string
+}
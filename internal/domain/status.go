@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Status is the stage of the workflow a todo is in.
+type Status string
+
+// Todo statuses. A todo counts as completed once it is done.
+const (
+	StatusBacklog    Status = "backlog"
+	StatusInProgress Status = "in_progress"
+	StatusBlocked    Status = "blocked"
+	StatusDone       Status = "done"
+)
+
+// Statuses lists every status in workflow order.
+var Statuses = []Status{StatusBacklog, StatusInProgress, StatusBlocked, StatusDone}
+
+// Valid reports whether s is a known status.
+func (s Status) Valid() bool {
+	switch s {
+	case StatusBacklog, StatusInProgress, StatusBlocked, StatusDone:
+		return true
+	default:
+		return false
+	}
+}
+
+// Priority expresses how urgent a todo is.
+type Priority string
+
+// Todo priorities, from least to most urgent.
+const (
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// Priorities lists every priority from least to most urgent.
+var Priorities = []Priority{PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+// Valid reports whether p is a known priority.
+func (p Priority) Valid() bool {
+	switch p {
+	case PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent:
+		return true
+	default:
+		return false
+	}
+}
+
+// Workflow maps each status to the statuses a todo may move to from it.
+// Staying in the same status is always allowed.
+type Workflow map[Status][]Status
+
+// DefaultWorkflow allows work to start, stall and finish, and lets finished
+// todos be reopened.
+var DefaultWorkflow = Workflow{
+	StatusBacklog:    {StatusInProgress, StatusBlocked, StatusDone},
+	StatusInProgress: {StatusBacklog, StatusBlocked, StatusDone},
+	StatusBlocked:    {StatusBacklog, StatusInProgress},
+	StatusDone:       {StatusBacklog, StatusInProgress},
+}
+
+// Allows reports whether a todo may move from one status to another.
+func (w Workflow) Allows(from, to Status) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range w[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseWorkflow parses a workflow definition such as
+//
+//	backlog:in_progress,done;in_progress:blocked,done;blocked:in_progress;done:in_progress
+//
+// where each ";"-separated rule lists the statuses reachable from the
+// status before the colon.
+func ParseWorkflow(spec string) (Workflow, error) {
+	w := make(Workflow)
+
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		from, targets, ok := strings.Cut(rule, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid workflow rule %q: expected from:to,...", rule)
+		}
+
+		fromStatus := Status(strings.TrimSpace(from))
+		if !fromStatus.Valid() {
+			return nil, fmt.Errorf("invalid workflow rule %q: unknown status %q", rule, fromStatus)
+		}
+
+		for _, target := range strings.Split(targets, ",") {
+			toStatus := Status(strings.TrimSpace(target))
+			if !toStatus.Valid() {
+				return nil, fmt.Errorf("invalid workflow rule %q: unknown status %q", rule, toStatus)
+			}
+			w[fromStatus] = append(w[fromStatus], toStatus)
+		}
+	}
+
+	if len(w) == 0 {
+		return nil, fmt.Errorf("workflow %q defines no transitions", spec)
+	}
+	return w, nil
+}
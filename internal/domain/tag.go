@@ -0,0 +1,24 @@
+package domain
+
+// Limits on the tags a todo may carry.
+const (
+	MaxTagLength   = 32
+	MaxTagsPerTodo = 20
+)
+
+// TagMatch decides how a list filtered by several tags is matched.
+type TagMatch string
+
+// Tag match modes.
+const (
+	// TagMatchAny keeps todos carrying at least one of the tags.
+	TagMatchAny TagMatch = "any"
+	// TagMatchAll keeps todos carrying every one of the tags.
+	TagMatchAll TagMatch = "all"
+)
+
+// TagCount is a tag together with the number of todos carrying it.
+type TagCount struct {
+	Name  string
+	Count int
+}
@@ -5,10 +5,163 @@ package domain
 
 import "time"
 
-// Todo represents a single task item in the application.
+// Todo represents a single task item in the application. Its description
+// holds free-form notes in Markdown.
 type Todo struct {
-	ID        int       `db:"id"`
-	Title     string    `db:"title"`
-	Completed bool      `db:"completed"`
-	CreatedAt time.Time `db:"created_at"`
+	ID          int        `db:"id"`
+	Title       string     `db:"title"`
+	Description string     `db:"description"`
+	Status      Status     `db:"status"`
+	Priority    Priority   `db:"priority"`
+	CreatedAt   time.Time  `db:"created_at"`
+	DueAt       *time.Time `db:"due_at"`
+	RemindAt    *time.Time `db:"remind_at"`
+	ProjectID   *int       `db:"project_id"`
+	ParentID    *int       `db:"parent_id"`
+	SeriesID    *int       `db:"series_id"`
+	DeletedAt   *time.Time `db:"deleted_at"`
+
+	// Version starts at 1 and is incremented by every change to the todo.
+	Version int `db:"version"`
+
+	// Position orders the todo when listing by position. It is assigned
+	// when the todo is created and changes when the todo is moved, or when
+	// the todos around it are spread out to make room.
+	Position float64 `db:"position"`
+
+	// Tags are normalized tag names, sorted alphabetically.
+	Tags []string
+
+	// Recurrence makes a new todo the first occurrence of a series. It is
+	// only read when the todo is created; SeriesID links the todo to its
+	// series afterwards.
+	Recurrence *Recurrence
+
+	// Subtasks holds the direct subtasks of the todo, each with their own
+	// subtasks, when the tree has been requested. It is nil otherwise.
+	Subtasks []Todo
+}
+
+// IsDeleted reports whether the todo is in the trash.
+func (t *Todo) IsDeleted() bool {
+	return t.DeletedAt != nil
+}
+
+// IsRecurring reports whether the todo is an occurrence of a series.
+func (t *Todo) IsRecurring() bool {
+	return t.SeriesID != nil
+}
+
+// IsCompleted reports whether the todo has reached the end of its workflow.
+func (t *Todo) IsCompleted() bool {
+	return t.Status == StatusDone
+}
+
+// Validate checks the business rules that apply to every todo,
+// whether it is being created or updated.
+func (t *Todo) Validate() error {
+	if t.Title == "" {
+		return ErrInvalidTitle
+	}
+	if !t.Status.Valid() {
+		return ErrInvalidStatus
+	}
+	if !t.Priority.Valid() {
+		return ErrInvalidPriority
+	}
+	if t.RemindAt != nil && t.DueAt != nil && t.RemindAt.After(*t.DueAt) {
+		return ErrInvalidReminder
+	}
+	if (t.Recurrence != nil || t.SeriesID != nil) && t.DueAt == nil {
+		return ErrRecurrenceWithoutDue
+	}
+	return nil
+}
+
+// TodoUpdate describes a change to an existing todo.
+// Nil fields are left untouched, so the same type serves both
+// full replacements and partial updates.
+//
+// Completed is kept for clients that predate the status workflow; the
+// service translates it into a status change before applying the update.
+type TodoUpdate struct {
+	Title       *string
+	Description *string
+	Completed   *bool
+	Status      *Status
+	Priority    *Priority
+	DueAt       Nullable[time.Time]
+	RemindAt    Nullable[time.Time]
+	ProjectID   Nullable[int]
+	ParentID    Nullable[int]
+
+	// Tags replaces every tag of the todo when non-nil.
+	Tags *[]string
+
+	// IfVersion makes the update conditional on the version of the todo.
+	IfVersion VersionMatch
+}
+
+// Apply returns a copy of t with the update applied. Completed is ignored;
+// see TodoUpdate.
+func (u TodoUpdate) Apply(t Todo) Todo {
+	if u.Title != nil {
+		t.Title = *u.Title
+	}
+	if u.Description != nil {
+		t.Description = *u.Description
+	}
+	if u.Status != nil {
+		t.Status = *u.Status
+	}
+	if u.Priority != nil {
+		t.Priority = *u.Priority
+	}
+	if u.DueAt.Set {
+		t.DueAt = u.DueAt.Value
+	}
+	if u.RemindAt.Set {
+		t.RemindAt = u.RemindAt.Value
+	}
+	if u.ProjectID.Set {
+		t.ProjectID = u.ProjectID.Value
+	}
+	if u.ParentID.Set {
+		t.ParentID = u.ParentID.Value
+	}
+	if u.Tags != nil {
+		t.Tags = *u.Tags
+	}
+	return t
+}
+
+// VersionMatch restricts a change to a todo that is still at one of the
+// listed versions. A nil VersionMatch matches any version, while an empty
+// one matches none.
+type VersionMatch []int
+
+// Matches reports whether a todo at the given version may be changed.
+func (m VersionMatch) Matches(version int) bool {
+	if m == nil {
+		return true
+	}
+	for _, v := range m {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Nullable is an update to an optional attribute. When Set is false the
+// attribute is left untouched; otherwise it is replaced by Value, with a
+// nil Value clearing it.
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// SetTo returns a Nullable that replaces the attribute with v (nil clears it).
+func SetTo[T any](v *T) Nullable[T] {
+	return Nullable[T]{Set: true, Value: v}
 }
@@ -0,0 +1,16 @@
+// Package blobstore keeps opaque blobs, such as uploaded files, under
+// slash-separated keys.
+//
+// LocalStore keeps blobs as files below a directory. Each blob is written
+// to a temporary file first and renamed into place, so a blob that is
+// read is never half written. S3Store keeps blobs in a bucket of any
+// service that speaks the S3 REST API, such as Amazon S3 or MinIO. It
+// signs requests with AWS Signature Version 4 and talks plain HTTP, with
+// no SDK involved, and addresses buckets by path, which every
+// S3-compatible service supports.
+//
+// Both open blobs as an io.ReadSeekCloser, which is what
+// http.ServeContent needs to answer Range requests. S3Store fetches only
+// the bytes that are read, starting at the current offset. Opening a key
+// that holds no blob returns an error matching fs.ErrNotExist.
+package blobstore
@@ -0,0 +1,98 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore keeps blobs as files below a directory.
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore returns a store keeping blobs below dir, creating dir if
+// it does not exist.
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("blobstore: %w", err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// path returns the file a key is kept in. Keys that would escape the
+// directory, such as ones containing "..", are rejected.
+func (s *LocalStore) path(key string) (string, error) {
+	local, err := filepath.Localize(key)
+	if err != nil {
+		return "", fmt.Errorf("blobstore: invalid key %q", key)
+	}
+	return filepath.Join(s.dir, local), nil
+}
+
+// Put writes the first size bytes read from r to the file of key. The file
+// only appears once it is complete.
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (err error) {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return fmt.Errorf("blobstore: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("blobstore: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	n, err := io.Copy(tmp, io.LimitReader(r, size))
+	if err != nil {
+		return fmt.Errorf("blobstore: writing %s: %w", key, err)
+	}
+	if n != size {
+		return fmt.Errorf("blobstore: writing %s: got %d bytes, want %d", key, n, size)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("blobstore: writing %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("blobstore: %w", err)
+	}
+	return nil
+}
+
+// Open opens the file of key for reading.
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name) //#nosec G304 -- name is kept below the directory by path
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: %w", err)
+	}
+	return f, nil
+}
+
+// Delete removes the file of key, if there is one.
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("blobstore: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+// Package ical writes and reads iCalendar streams (RFC 5545) holding
+// to-dos, the VTODO components calendar applications show as tasks.
+//
+// A Calendar is encoded with Encode, which takes care of escaping text,
+// writing times in UTC and folding long lines, and read back with Parse.
+// Parse accepts what calendar applications commonly export: both CRLF and
+// bare LF line endings, times with a TZID, plain dates and components
+// other than VTODO, such as events, time zones and alarms, which are
+// skipped. Only the properties that Todo models are kept.
+//
+// The package knows nothing about the todos of this API; mapping between
+// the two is left to the caller.
+package ical
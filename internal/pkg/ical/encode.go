@@ -0,0 +1,127 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxLineOctets is how long a line may be before it is folded,
+	// excluding the line break
+	maxLineOctets = 75
+	// dateTimeUTC is the layout of times written in UTC
+	dateTimeUTC = "20060102T150405Z"
+)
+
+// textEscaper escapes TEXT values.
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// Encode writes cal to w as an iCalendar stream. Times are written in UTC.
+func Encode(w io.Writer, cal *Calendar) error {
+	e := encoder{w: bufio.NewWriter(w)}
+
+	prodID := cal.ProdID
+	if prodID == "" {
+		prodID = DefaultProdID
+	}
+
+	e.property("BEGIN", "VCALENDAR")
+	e.property("VERSION", "2.0")
+	e.text("PRODID", prodID)
+	e.property("CALSCALE", "GREGORIAN")
+	e.text("X-WR-CALNAME", cal.Name)
+	for i := range cal.Todos {
+		e.todo(&cal.Todos[i])
+	}
+	e.property("END", "VCALENDAR")
+
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// encoder writes content lines, keeping the first error.
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *encoder) todo(t *Todo) {
+	e.property("BEGIN", "VTODO")
+	e.text("UID", t.UID)
+	e.time("DTSTAMP", &t.Stamp)
+	e.time("CREATED", t.Created)
+	e.text("SUMMARY", t.Summary)
+	e.text("DESCRIPTION", t.Description)
+	e.property("STATUS", string(t.Status))
+	if t.Priority > 0 {
+		e.property("PRIORITY", strconv.Itoa(t.Priority))
+	}
+	e.time("DUE", t.Due)
+	e.property("RRULE", t.RRule)
+	e.time("COMPLETED", t.Completed)
+	if len(t.Categories) > 0 {
+		categories := make([]string, len(t.Categories))
+		for i, c := range t.Categories {
+			categories[i] = textEscaper.Replace(c)
+		}
+		e.property("CATEGORIES", strings.Join(categories, ","))
+	}
+	e.text("RELATED-TO", t.Parent)
+	if t.Sequence > 0 {
+		e.property("SEQUENCE", strconv.Itoa(t.Sequence))
+	}
+	e.property("END", "VTODO")
+}
+
+// text writes a TEXT property unless value is empty.
+func (e *encoder) text(name, value string) {
+	e.property(name, textEscaper.Replace(value))
+}
+
+// time writes a DATE-TIME property in UTC unless t is nil.
+func (e *encoder) time(name string, t *time.Time) {
+	if t != nil {
+		e.property(name, t.UTC().Format(dateTimeUTC))
+	}
+}
+
+// property writes a content line unless value is empty, folding it into
+// lines of at most maxLineOctets octets. Lines are only folded between
+// characters, so that no UTF-8 sequence is split.
+func (e *encoder) property(name, value string) {
+	if e.err != nil || value == "" {
+		return
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range name + ":" + value {
+		size := utf8.RuneLen(r)
+		if size < 0 {
+			size = utf8.RuneLen(utf8.RuneError)
+		}
+		if n+size > maxLineOctets {
+			// The space starting a continuation line counts towards it
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	b.WriteString("\r\n")
+
+	_, e.err = e.w.WriteString(b.String())
+}
@@ -0,0 +1,77 @@
+package ical
+
+import (
+	"fmt"
+	"time"
+)
+
+// MediaType is the media type of iCalendar streams.
+const MediaType = "text/calendar"
+
+// DefaultProdID identifies the product that wrote a calendar when the
+// calendar does not name one.
+const DefaultProdID = "-//go-rest-api-example//todos//EN"
+
+// Status is the status of a to-do.
+type Status string
+
+const (
+	StatusNeedsAction Status = "NEEDS-ACTION"
+	StatusInProcess   Status = "IN-PROCESS"
+	StatusCompleted   Status = "COMPLETED"
+	StatusCancelled   Status = "CANCELLED"
+)
+
+// Calendar is an iCalendar object holding to-dos.
+type Calendar struct {
+	// ProdID identifies the product that wrote the calendar
+	ProdID string
+	// Name is the display name of the calendar (X-WR-CALNAME)
+	Name  string
+	Todos []Todo
+}
+
+// Todo is a VTODO component. Zero values stand for absent properties,
+// except for UID and Stamp, which every to-do has.
+type Todo struct {
+	UID string
+	// Stamp is when the to-do was last revised (DTSTAMP)
+	Stamp       time.Time
+	Created     *time.Time
+	Summary     string
+	Description string
+	Status      Status
+	// Priority runs from 1, the highest, to 9, the lowest
+	Priority int
+	Due      *time.Time
+	// DueTimeZone is the TZID the due time was given in, if any. It is not
+	// encoded: due times are written in UTC.
+	DueTimeZone string
+	Completed   *time.Time
+	Categories  []string
+	// Parent is the UID of the to-do this one belongs to (RELATED-TO)
+	Parent string
+	// RRule is the recurrence rule of a to-do that repeats, without the
+	// "RRULE:" prefix
+	RRule string
+	// Sequence counts the revisions of the to-do
+	Sequence int
+
+	// Line is the line the to-do starts on when it was parsed, counting
+	// from 1. It is not encoded.
+	Line int
+}
+
+// ParseError reports a line of a stream that cannot be parsed.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,269 @@
+package ical
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func utc(s string) *time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	t = t.UTC()
+	return &t
+}
+
+// encode encodes cal, failing the test on error.
+func encode(t *testing.T, cal *Calendar) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, cal); err != nil {
+		t.Fatalf("Encode() unexpected error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestEncode(t *testing.T) {
+	cal := &Calendar{
+		Name: "Todos",
+		Todos: []Todo{{
+			UID:        "todo-1@example.com",
+			Stamp:      *utc("2024-01-01T09:00:00Z"),
+			Created:    utc("2024-01-01T09:00:00Z"),
+			Summary:    "Buy milk, eggs; bread",
+			Status:     StatusCompleted,
+			Priority:   1,
+			Due:        utc("2024-01-05T18:00:00+01:00"),
+			Categories: []string{"home", "a,b"},
+			Sequence:   2,
+		}},
+	}
+
+	want := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:" + DefaultProdID + "\r\n" +
+		"CALSCALE:GREGORIAN\r\n" +
+		"X-WR-CALNAME:Todos\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:todo-1@example.com\r\n" +
+		"DTSTAMP:20240101T090000Z\r\n" +
+		"CREATED:20240101T090000Z\r\n" +
+		"SUMMARY:Buy milk\\, eggs\\; bread\r\n" +
+		"STATUS:COMPLETED\r\n" +
+		"PRIORITY:1\r\n" +
+		"DUE:20240105T170000Z\r\n" +
+		"CATEGORIES:home,a\\,b\r\n" +
+		"SEQUENCE:2\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	if got := encode(t, cal); got != want {
+		t.Errorf("Encode() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEncode_Folding(t *testing.T) {
+	summary := strings.Repeat("Überweisung prüfen ", 12)
+	got := encode(t, &Calendar{Todos: []Todo{{UID: "1", Summary: summary}}})
+
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line of %d octets, want at most %d: %q", len(line), maxLineOctets, line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a UTF-8 sequence: %q", line)
+		}
+	}
+	if !strings.Contains(got, "\r\n ") {
+		t.Errorf("Encode() did not fold a long line:\n%s", got)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	cal := &Calendar{
+		ProdID: "-//Example Corp.//Tasks//EN",
+		Name:   "Work; and home",
+		Todos: []Todo{
+			{
+				UID:         "todo-1@example.com",
+				Stamp:       *utc("2024-01-01T09:00:00Z"),
+				Created:     utc("2023-12-31T23:59:59Z"),
+				Summary:     strings.Repeat("Write the quarterly report, with charts; ", 4),
+				Description: "Line one\nLine two with a backslash \\ and a comma,",
+				Status:      StatusInProcess,
+				Priority:    5,
+				Due:         utc("2024-03-31T17:00:00Z"),
+				RRule:       "FREQ=MONTHLY;BYMONTHDAY=-1",
+				Categories:  []string{"work", "q1,2024", "naïve"},
+				Parent:      "todo-0@example.com",
+				Sequence:    3,
+			},
+			{
+				UID:       "todo-2@example.com",
+				Stamp:     *utc("2024-01-02T10:30:00Z"),
+				Summary:   "Water plants 🌱",
+				Status:    StatusCompleted,
+				Completed: utc("2024-01-03T08:00:00Z"),
+			},
+			{UID: "todo-3@example.com", Stamp: *utc("2024-01-02T10:30:00Z"), Summary: "Minimal"},
+		},
+	}
+
+	parsed, err := Parse(strings.NewReader(encode(t, cal)))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+
+	for i := range parsed.Todos {
+		if parsed.Todos[i].Line == 0 {
+			t.Errorf("todo %d has no line", i)
+		}
+		parsed.Todos[i].Line = 0
+	}
+	if !reflect.DeepEqual(parsed, cal) {
+		t.Errorf("Parse(Encode()) =\n%+v\nwant\n%+v", parsed, cal)
+	}
+
+	// A second round trip leaves the stream unchanged
+	if first, second := encode(t, cal), encode(t, parsed); first != second {
+		t.Errorf("re-encoded calendar differs:\n%s\nwant\n%s", second, first)
+	}
+}
+
+func TestParse(t *testing.T) {
+	// Written the way calendar applications write them: bare LF line
+	// endings, lower-case names, folding with a tab, time zones and
+	// components that are not to-dos.
+	input := "\ufeffBEGIN:VCALENDAR\n" +
+		"VERSION:2.0\n" +
+		"PRODID:-//ABC Corporation//NONSGML My Product//EN\n" +
+		"BEGIN:VTIMEZONE\n" +
+		"TZID:Europe/Berlin\n" +
+		"END:VTIMEZONE\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:event-1\n" +
+		"SUMMARY:Not a todo\n" +
+		"END:VEVENT\n" +
+		"\n" +
+		"BEGIN:VTODO\n" +
+		"UID:20070313T123432Z-456553@example.com\n" +
+		"dtstamp:20070313T123432Z\n" +
+		"DUE;VALUE=DATE:20070501\n" +
+		"SUMMARY;LANGUAGE=en-US:Submit Quebec Income Tax Return for 2006\n" +
+		"CLASS:CONFIDENTIAL\n" +
+		"CATEGORIES:FAMILY,FINANCE\n" +
+		"CATEGORIES:TAXES\n" +
+		"STATUS:needs-action\n" +
+		"BEGIN:VALARM\n" +
+		"ACTION:DISPLAY\n" +
+		"SUMMARY:Not the summary of the todo\n" +
+		"TRIGGER;RELATED=END:-P1D\n" +
+		"END:VALARM\n" +
+		"END:VTODO\n" +
+		"BEGIN:VTODO\n" +
+		"UID:todo-2\n" +
+		"DTSTAMP;TZID=Europe/Berlin:20240701T120000\n" +
+		"DUE;TZID=\"Europe/Berlin\":20240105T090000\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=FR\n" +
+		"SUMMARY:Call the \n" +
+		"\tbank\\nabout the card\n" +
+		"RELATED-TO;RELTYPE=SIBLING:todo-1\n" +
+		"PRIORITY:0\n" +
+		"END:VTODO\n" +
+		"END:VCALENDAR\n"
+
+	cal, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+
+	want := &Calendar{
+		ProdID: "-//ABC Corporation//NONSGML My Product//EN",
+		Todos: []Todo{
+			{
+				UID:        "20070313T123432Z-456553@example.com",
+				Stamp:      *utc("2007-03-13T12:34:32Z"),
+				Summary:    "Submit Quebec Income Tax Return for 2006",
+				Status:     StatusNeedsAction,
+				Due:        utc("2007-05-01T00:00:00Z"),
+				Categories: []string{"FAMILY", "FINANCE", "TAXES"},
+				Line:       12,
+			},
+			{
+				UID:         "todo-2",
+				Stamp:       *utc("2024-07-01T10:00:00Z"),
+				Summary:     "Call the bank\nabout the card",
+				Due:         utc("2024-01-05T08:00:00Z"),
+				DueTimeZone: "Europe/Berlin",
+				RRule:       "FREQ=WEEKLY;BYDAY=FR",
+				Line:        27,
+			},
+		},
+	}
+	if !reflect.DeepEqual(cal, want) {
+		t.Errorf("Parse() =\n%+v\nwant\n%+v", cal, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantLine int
+	}{
+		{name: "empty", input: "", wantLine: 0},
+		{name: "not a calendar", input: "title,status\nBuy milk,done\n", wantLine: 1},
+		{name: "property outside calendar", input: "VERSION:2.0\n", wantLine: 1},
+		{
+			name:     "unterminated",
+			input:    "BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:1\n",
+			wantLine: 3,
+		},
+		{
+			name:     "mismatched end",
+			input:    "BEGIN:VCALENDAR\nBEGIN:VTODO\nEND:VEVENT\nEND:VCALENDAR\n",
+			wantLine: 3,
+		},
+		{
+			name:     "invalid due date",
+			input:    "BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:1\nDUE:tomorrow\nEND:VTODO\nEND:VCALENDAR\n",
+			wantLine: 4,
+		},
+		{
+			name:     "unknown time zone",
+			input:    "BEGIN:VCALENDAR\nBEGIN:VTODO\nDUE;TZID=Mars/Olympus:20240105T090000\nEND:VTODO\nEND:VCALENDAR\n",
+			wantLine: 3,
+		},
+		{
+			name:     "priority out of range",
+			input:    "BEGIN:VCALENDAR\nBEGIN:VTODO\nPRIORITY:10\nEND:VTODO\nEND:VCALENDAR\n",
+			wantLine: 3,
+		},
+		{
+			name:     "unterminated quoted parameter",
+			input:    "BEGIN:VCALENDAR\nBEGIN:VTODO\nDUE;TZID=\"Europe/Berlin:20240105T090000\nEND:VTODO\nEND:VCALENDAR\n",
+			wantLine: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse() error = %v, want a *ParseError", err)
+			}
+			if parseErr.Line != tt.wantLine {
+				t.Errorf("Parse() error on line %d, want %d: %v", parseErr.Line, tt.wantLine, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// dateTimeLocal is the layout of times without a time zone, which are
+	// either floating or qualified by a TZID parameter
+	dateTimeLocal = "20060102T150405"
+	// dateOnly is the layout of DATE values
+	dateOnly = "20060102"
+)
+
+// contentLine is a single, unfolded property.
+type contentLine struct {
+	line   int
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the to-dos of every calendar in r. Times are returned in
+// UTC; floating times and dates, which have no time zone, are taken to be
+// in UTC. Input that is not a well-formed iCalendar stream, or a property
+// of a to-do whose value cannot be read, is reported with a *ParseError.
+func Parse(r io.Reader) (*Calendar, error) {
+	p := parser{cal: &Calendar{}}
+
+	err := unfold(r, func(line int, text string) error {
+		cl, err := parseContentLine(text)
+		if err != nil {
+			return &ParseError{Line: line, Err: err}
+		}
+		cl.line = line
+		if err := p.handle(cl); err != nil {
+			return &ParseError{Line: line, Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.stack) > 0 {
+		return nil, &ParseError{Line: p.lines, Err: fmt.Errorf("%s is not ended", p.stack[len(p.stack)-1])}
+	}
+	if !p.seen {
+		return nil, &ParseError{Line: p.lines, Err: errors.New("no VCALENDAR found")}
+	}
+	return p.cal, nil
+}
+
+// unfold calls fn for every logical line of r, with the line it starts
+// on. Lines starting with a space or a tab continue the previous one.
+func unfold(r io.Reader, fn func(line int, text string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+
+	var (
+		current strings.Builder
+		start   int
+	)
+	flush := func() error {
+		if current.Len() == 0 {
+			return nil
+		}
+		text := current.String()
+		current.Reset()
+		return fn(start, text)
+	}
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			current.WriteString(text[1:])
+			continue
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		if text != "" {
+			current.WriteString(text)
+			start = line
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return &ParseError{Line: line + 1, Err: errors.New("line is too long")}
+		}
+		return err
+	}
+	return flush()
+}
+
+// parseContentLine splits a content line into its name, parameters and
+// value. Parameter names are upper-cased; quoted parameter values may
+// hold the characters that otherwise separate them.
+func parseContentLine(text string) (contentLine, error) {
+	cl := contentLine{params: map[string]string{}}
+
+	i := strings.IndexAny(text, ";:")
+	if i <= 0 {
+		return cl, fmt.Errorf("malformed content line %q", text)
+	}
+	cl.name = strings.ToUpper(text[:i])
+	rest := text[i:]
+
+	for rest[0] == ';' {
+		rest = rest[1:]
+
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return cl, fmt.Errorf("malformed parameter in %s", cl.name)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return cl, fmt.Errorf("unterminated quoted parameter in %s", cl.name)
+			}
+			value, rest = rest[1:end+1], rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = rest[:end], rest[end:]
+		}
+
+		if rest == "" || (rest[0] != ';' && rest[0] != ':') {
+			return cl, fmt.Errorf("malformed parameter in %s", cl.name)
+		}
+		cl.params[name] = value
+	}
+
+	cl.value = rest[1:]
+	return cl, nil
+}
+
+// parser builds a calendar from content lines.
+type parser struct {
+	cal   *Calendar
+	stack []string
+	todo  *Todo
+	seen  bool
+	// lines is the line of the last content line handled
+	lines int
+}
+
+func (p *parser) handle(cl contentLine) error {
+	p.lines = cl.line
+
+	switch cl.name {
+	case "BEGIN":
+		return p.begin(strings.ToUpper(cl.value), cl.line)
+	case "END":
+		return p.end(strings.ToUpper(cl.value))
+	}
+
+	if len(p.stack) == 0 {
+		return fmt.Errorf("%s outside of a VCALENDAR", cl.name)
+	}
+
+	switch top := p.stack[len(p.stack)-1]; {
+	case top == "VCALENDAR":
+		p.calendarProperty(cl)
+		return nil
+	case top == "VTODO" && p.todo != nil:
+		if err := p.todoProperty(cl); err != nil {
+			return fmt.Errorf("%s: %w", cl.name, err)
+		}
+	}
+	return nil
+}
+
+func (p *parser) begin(component string, line int) error {
+	switch {
+	case len(p.stack) == 0 && component != "VCALENDAR":
+		return fmt.Errorf("%s outside of a VCALENDAR", component)
+	case len(p.stack) == 0:
+		p.seen = true
+	case component == "VCALENDAR":
+		return errors.New("VCALENDAR cannot be nested")
+	case component == "VTODO" && len(p.stack) == 1:
+		p.todo = &Todo{Line: line}
+	}
+
+	p.stack = append(p.stack, component)
+	return nil
+}
+
+func (p *parser) end(component string) error {
+	if len(p.stack) == 0 || p.stack[len(p.stack)-1] != component {
+		return fmt.Errorf("unexpected END:%s", component)
+	}
+	p.stack = p.stack[:len(p.stack)-1]
+
+	if component == "VTODO" && len(p.stack) == 1 && p.todo != nil {
+		p.cal.Todos = append(p.cal.Todos, *p.todo)
+		p.todo = nil
+	}
+	return nil
+}
+
+func (p *parser) calendarProperty(cl contentLine) {
+	switch cl.name {
+	case "PRODID":
+		p.cal.ProdID = unescapeText(cl.value)
+	case "X-WR-CALNAME":
+		p.cal.Name = unescapeText(cl.value)
+	}
+}
+
+func (p *parser) todoProperty(cl contentLine) error {
+	t := p.todo
+
+	var err error
+	switch cl.name {
+	case "UID":
+		t.UID = unescapeText(cl.value)
+	case "SUMMARY":
+		t.Summary = unescapeText(cl.value)
+	case "DESCRIPTION":
+		t.Description = unescapeText(cl.value)
+	case "STATUS":
+		t.Status = Status(strings.ToUpper(cl.value))
+	case "CATEGORIES":
+		t.Categories = append(t.Categories, splitText(cl.value)...)
+	case "RELATED-TO":
+		if reltype := cl.params["RELTYPE"]; reltype == "" || strings.EqualFold(reltype, "PARENT") {
+			t.Parent = unescapeText(cl.value)
+		}
+	case "PRIORITY":
+		t.Priority, err = parseInt(cl.value, 0, 9)
+	case "SEQUENCE":
+		t.Sequence, err = parseInt(cl.value, 0, math.MaxInt32)
+	case "RRULE":
+		t.RRule = cl.value
+	default:
+		return p.todoTime(cl)
+	}
+	return err
+}
+
+// todoTime reads the time properties of a to-do; other properties are
+// ignored.
+func (p *parser) todoTime(cl contentLine) error {
+	var target **time.Time
+	switch cl.name {
+	case "DTSTAMP":
+		ts, err := parseTime(cl)
+		if err != nil {
+			return err
+		}
+		p.todo.Stamp = ts
+		return nil
+	case "CREATED":
+		target = &p.todo.Created
+	case "DUE":
+		target = &p.todo.Due
+		p.todo.DueTimeZone = strings.TrimPrefix(cl.params["TZID"], "/")
+	case "COMPLETED":
+		target = &p.todo.Completed
+	default:
+		return nil
+	}
+
+	ts, err := parseTime(cl)
+	if err != nil {
+		return err
+	}
+	*target = &ts
+	return nil
+}
+
+// parseTime reads a DATE-TIME or DATE value and returns it in UTC.
+func parseTime(cl contentLine) (time.Time, error) {
+	value := cl.value
+
+	if strings.EqualFold(cl.params["VALUE"], "DATE") || len(value) == len(dateOnly) {
+		t, err := time.Parse(dateOnly, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", value)
+		}
+		return t, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeUTC, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q", value)
+		}
+		return t, nil
+	}
+
+	loc := time.UTC
+	if tzid := cl.params["TZID"]; tzid != "" {
+		// Some writers prefix the TZID with a slash to mark it as globally unique
+		var err error
+		if loc, err = time.LoadLocation(strings.TrimPrefix(tzid, "/")); err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q", tzid)
+		}
+	}
+
+	t, err := time.ParseInLocation(dateTimeLocal, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", value)
+	}
+	return t.UTC(), nil
+}
+
+// parseInt reads an integer between lo and hi.
+func parseInt(value string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return n, nil
+}
+
+// unescapeText undoes the escaping of a TEXT value.
+func unescapeText(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' || i == len(value)-1 {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// splitText splits a list of TEXT values at the commas that are not
+// escaped, and unescapes each value.
+func splitText(value string) []string {
+	var (
+		values []string
+		start  int
+	)
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, unescapeText(value[start:i]))
+			start = i + 1
+		}
+	}
+	return append(values, unescapeText(value[start:]))
+}
@@ -0,0 +1,155 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// csvColumns are the columns read from CSV files. Others, such as the id
+// or etag of a file exported from this API, are ignored.
+var csvColumns = map[string]bool{
+	"title":       true,
+	"description": true,
+	"status":      true,
+	"priority":    true,
+	"completed":   true,
+	"created_at":  true,
+	"due_at":      true,
+	"remind_at":   true,
+	"tags":        true,
+	"project_id":  true,
+	"parent_id":   true,
+}
+
+// ParseCSV reads todos from CSV with a header row. Column names are matched
+// case-insensitively and a title column is required. Tags are
+// comma-separated, and completed=true stands for the done status when no
+// status is given.
+func ParseCSV(r io.Reader) ([]Record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: missing header row", ErrMalformed)
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheets like to start files with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if csvColumns[name] {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("%w: missing title column", ErrMalformed)
+	}
+
+	var records []Record
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		if len(row) != len(header) {
+			records = append(records, Record{
+				Line: line,
+				Err:  fmt.Errorf("row has %d fields, header has %d", len(row), len(header)),
+			})
+			continue
+		}
+
+		fields := make(map[string]string, len(columns))
+		for name, i := range columns {
+			fields[name] = strings.TrimSpace(row[i])
+		}
+
+		todo, err := csvTodo(fields)
+		records = append(records, Record{Line: line, Todo: todo, Err: err})
+	}
+}
+
+// csvTodo maps the fields of a CSV row onto a todo. Empty fields are left
+// unset.
+func csvTodo(fields map[string]string) (domain.Todo, error) {
+	todo := domain.Todo{
+		Title:       fields["title"],
+		Description: fields["description"],
+		Status:      domain.Status(fields["status"]),
+		Priority:    domain.Priority(fields["priority"]),
+		Tags:        splitTags(fields["tags"]),
+	}
+
+	if v := fields["completed"]; v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			return todo, fieldError("completed", "must be true or false")
+		}
+		if completed && todo.Status == "" {
+			todo.Status = domain.StatusDone
+		}
+	}
+
+	var err error
+	if v := fields["project_id"]; v != "" {
+		if todo.ProjectID, err = parseID("project_id", v); err != nil {
+			return todo, err
+		}
+	}
+	if v := fields["parent_id"]; v != "" {
+		if todo.ParentID, err = parseID("parent_id", v); err != nil {
+			return todo, err
+		}
+	}
+
+	return todo, csvTimes(&todo, fields)
+}
+
+// csvTimes reads the timestamps of a CSV row into todo.
+func csvTimes(todo *domain.Todo, fields map[string]string) error {
+	var err error
+	if v := fields["created_at"]; v != "" {
+		createdAt, err := parseTime("created_at", v)
+		if err != nil {
+			return err
+		}
+		todo.CreatedAt = *createdAt
+	}
+	if v := fields["due_at"]; v != "" {
+		if todo.DueAt, err = parseTime("due_at", v); err != nil {
+			return err
+		}
+	}
+	if v := fields["remind_at"]; v != "" {
+		if todo.RemindAt, err = parseTime("remind_at", v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csvError reports CSV syntax errors as malformed input. Errors reading r
+// are returned as is.
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return err
+}
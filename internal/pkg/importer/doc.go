@@ -0,0 +1,16 @@
+// Package importer reads todos exported from other tools, in one of four
+// formats:
+//
+//	csv      - a header row naming the columns, then one todo per row
+//	json     - an array of todo objects, shaped like a create request
+//	todotxt  - one todo per line in the todo.txt format (todotxt.org)
+//	ical     - the to-dos of an iCalendar stream (RFC 5545)
+//
+// Every todo is returned as a Record carrying the line it starts on, so
+// that problems can be reported line by line. A record whose fields cannot
+// be read, such as a malformed date, carries the error instead of failing
+// the whole import; only input that cannot be read at all, such as a CSV
+// file without a header, is rejected with ErrMalformed. Records are not
+// validated beyond their syntax: that is left to the caller, as it is for
+// todos created one at a time.
+package importer
@@ -0,0 +1,86 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/ical"
+)
+
+// ParseICal reads the to-dos (VTODO components) of an iCalendar stream,
+// as exported by calendar applications; events and other components are
+// skipped. SUMMARY becomes the title and CATEGORIES the tags. Completed
+// and cancelled to-dos are done, and priorities 1 and 2 are urgent, 3 and
+// 4 high, 5 medium and 6 to 9 low. An RRULE makes the to-do recur, in the
+// time zone its due time was given in.
+//
+// Calendars are written by programs rather than by hand, so a property
+// whose value cannot be read rejects the whole stream with ErrMalformed.
+func ParseICal(r io.Reader) ([]Record, error) {
+	cal, err := ical.Parse(r)
+	var parseErr *ical.ParseError
+	if errors.As(err, &parseErr) {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(cal.Todos))
+	for _, t := range cal.Todos {
+		todo, err := icalTodo(t)
+		records = append(records, Record{Line: t.Line, Todo: todo, Err: err})
+	}
+	return records, nil
+}
+
+// icalTodo maps a VTODO component onto a todo.
+func icalTodo(t ical.Todo) (domain.Todo, error) {
+	todo := domain.Todo{
+		Title:       t.Summary,
+		Description: t.Description,
+		Priority:    icalPriority(t.Priority),
+		DueAt:       t.Due,
+		Tags:        t.Categories,
+	}
+	if t.Created != nil {
+		todo.CreatedAt = *t.Created
+	}
+	if t.RRule != "" {
+		todo.Recurrence = &domain.Recurrence{Rule: t.RRule, TimeZone: t.DueTimeZone}
+	}
+
+	switch t.Status {
+	case ical.StatusCompleted, ical.StatusCancelled:
+		todo.Status = domain.StatusDone
+	case ical.StatusInProcess:
+		todo.Status = domain.StatusInProgress
+	case ical.StatusNeedsAction, "":
+		if t.Completed != nil {
+			todo.Status = domain.StatusDone
+		}
+	default:
+		return todo, fieldError("STATUS", fmt.Sprintf("%s is not a to-do status", t.Status))
+	}
+
+	return todo, nil
+}
+
+// icalPriority maps an iCalendar priority, from 1 for the highest to 9
+// for the lowest, onto ours. 0 leaves the priority undefined.
+func icalPriority(p int) domain.Priority {
+	switch {
+	case p == 0:
+		return ""
+	case p <= 2:
+		return domain.PriorityUrgent
+	case p <= 4:
+		return domain.PriorityHigh
+	case p == 5:
+		return domain.PriorityMedium
+	default:
+		return domain.PriorityLow
+	}
+}
@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/ical"
+)
+
+// Format is an import format.
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatJSON    Format = "json"
+	FormatTodoTxt Format = "todotxt"
+	FormatICal    Format = "ical"
+)
+
+// ErrMalformed is returned for input that cannot be read at all.
+var ErrMalformed = errors.New("malformed import")
+
+// Record is a single todo read from an import. Err is set, and Todo may be
+// incomplete, if the fields of the todo could not be read.
+type Record struct {
+	// Line is the line the todo starts on, counting from 1.
+	Line int
+	Todo domain.Todo
+	Err  error
+}
+
+// ParseFormat parses the name of an import format.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatCSV, FormatJSON, FormatTodoTxt, FormatICal:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown import format %q", s)
+	}
+}
+
+// FormatForMediaType returns the format of bodies of the given media type:
+// text/csv, application/json, text/plain for todo.txt or text/calendar.
+func FormatForMediaType(mediaType string) (Format, bool) {
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, true
+	case "application/json":
+		return FormatJSON, true
+	case "text/plain":
+		return FormatTodoTxt, true
+	case ical.MediaType:
+		return FormatICal, true
+	default:
+		return "", false
+	}
+}
+
+// Parse reads every todo in r.
+func Parse(r io.Reader, format Format) ([]Record, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatJSON:
+		return ParseJSON(r)
+	case FormatTodoTxt:
+		return ParseTodoTxt(r)
+	case FormatICal:
+		return ParseICal(r)
+	default:
+		return nil, fmt.Errorf("unknown import format %q", format)
+	}
+}
+
+// fieldError describes a field whose value cannot be read.
+func fieldError(field, message string) error {
+	return fmt.Errorf("%s %s", field, message)
+}
+
+// parseTime reads an RFC 3339 timestamp or a plain date, which stands for
+// midnight UTC.
+func parseTime(field, value string) (*time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return &t, nil
+	}
+	return nil, fieldError(field, "must be an RFC 3339 time or a date")
+}
+
+// parseID reads the ID of a related project or todo.
+func parseID(field, value string) (*int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fieldError(field, "must be an integer")
+	}
+	return &id, nil
+}
+
+// splitTags splits a comma-separated list of tags.
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
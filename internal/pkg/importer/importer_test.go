@@ -0,0 +1,265 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+func date(s string) *time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func intp(i int) *int {
+	return &i
+}
+
+// checkRecords compares records, only checking that an error is present
+// where one is expected.
+func checkRecords(t *testing.T, got, want []Record) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if (got[i].Err != nil) != (want[i].Err != nil) {
+			t.Errorf("record %d error = %v, want %v", i, got[i].Err, want[i].Err)
+			continue
+		}
+		if got[i].Line != want[i].Line {
+			t.Errorf("record %d line = %d, want %d", i, got[i].Line, want[i].Line)
+		}
+		if want[i].Err == nil && !reflect.DeepEqual(got[i].Todo, want[i].Todo) {
+			t.Errorf("record %d todo = %+v, want %+v", i, got[i].Todo, want[i].Todo)
+		}
+	}
+}
+
+var errAny = errors.New("any error")
+
+func TestParseCSV(t *testing.T) {
+	input := "\ufeffid,Title,description,status,priority,completed,created_at,due_at,tags,project_id,etag\n" +
+		"1,Buy milk,\"Whole **milk**\n- [ ] oat\",in_progress,high,false,2024-01-01T09:00:00Z,2024-01-05," +
+		"\"home,errands\",2,\"\"\"1\"\"\"\n" +
+		"2,\"Multi\nline\",,,,true,,,,,\n" +
+		"3,Bad date,,,,,,tomorrow,,,\n" +
+		"4,Too short\n" +
+		"5,Bad project,,,,,,,,two,\n"
+
+	records, err := ParseCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseCSV() unexpected error = %v", err)
+	}
+
+	checkRecords(t, records, []Record{
+		{Line: 2, Todo: domain.Todo{
+			Title:       "Buy milk",
+			Description: "Whole **milk**\n- [ ] oat",
+			Status:      domain.StatusInProgress,
+			Priority:    domain.PriorityHigh,
+			CreatedAt:   *date("2024-01-01T09:00:00Z"),
+			DueAt:       date("2024-01-05T00:00:00Z"),
+			Tags:        []string{"home", "errands"},
+			ProjectID:   intp(2),
+		}},
+		{Line: 4, Todo: domain.Todo{Title: "Multi\nline", Status: domain.StatusDone}},
+		{Line: 6, Err: errAny},
+		{Line: 7, Err: errAny},
+		{Line: 8, Err: errAny},
+	})
+}
+
+func TestParseCSV_Malformed(t *testing.T) {
+	for _, input := range []string{"", "name,status\nBuy milk,done\n", "title\n\"unterminated\n"} {
+		if _, err := ParseCSV(strings.NewReader(input)); !errors.Is(err, ErrMalformed) {
+			t.Errorf("ParseCSV(%q) error = %v, want %v", input, err, ErrMalformed)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	input := `[
+  {"id": 7, "title": "Buy milk", "description": "Whole **milk**", "priority": "urgent",
+   "due_at": "2024-01-05T17:00:00Z", "tags": ["home"]},
+  {"title": "Done already", "completed": true, "parent_id": 3},
+  {"title": 42},
+  "not a todo",
+  {
+    "title": "Spread out"
+  }
+]`
+
+	records, err := ParseJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseJSON() unexpected error = %v", err)
+	}
+
+	checkRecords(t, records, []Record{
+		{Line: 2, Todo: domain.Todo{
+			Title:       "Buy milk",
+			Description: "Whole **milk**",
+			Priority:    domain.PriorityUrgent,
+			DueAt:       date("2024-01-05T17:00:00Z"),
+			Tags:        []string{"home"},
+		}},
+		{Line: 4, Todo: domain.Todo{Title: "Done already", Status: domain.StatusDone, ParentID: intp(3)}},
+		{Line: 5, Err: errAny},
+		{Line: 6, Err: errAny},
+		{Line: 7, Todo: domain.Todo{Title: "Spread out"}},
+	})
+
+	if records[2].Err.Error() != "title cannot be a number" {
+		t.Errorf("type error = %q, want %q", records[2].Err, "title cannot be a number")
+	}
+}
+
+func TestParseJSON_Malformed(t *testing.T) {
+	for _, input := range []string{``, `{"title": "Buy milk"}`, `[{"title": "Buy milk"},`, `[{"title": }]`} {
+		if _, err := ParseJSON(strings.NewReader(input)); !errors.Is(err, ErrMalformed) {
+			t.Errorf("ParseJSON(%q) error = %v, want %v", input, err, ErrMalformed)
+		}
+	}
+}
+
+func TestParseTodoTxt(t *testing.T) {
+	input := "(A) 2024-01-01 Call mom +Family @phone due:2024-01-05\n" +
+		"\n" +
+		"x 2024-01-03 2024-01-02 Pay rent pri:B\n" +
+		"x 2024-01-03 Water plants\n" +
+		"(D) Read (a) book https://example.com/book\n" +
+		"Renew passport due:soon\n" +
+		"+errands\n"
+
+	records, err := ParseTodoTxt(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseTodoTxt() unexpected error = %v", err)
+	}
+
+	checkRecords(t, records, []Record{
+		{Line: 1, Todo: domain.Todo{
+			Title:     "Call mom",
+			Priority:  domain.PriorityUrgent,
+			CreatedAt: *date("2024-01-01T00:00:00Z"),
+			DueAt:     date("2024-01-05T00:00:00Z"),
+			Tags:      []string{"Family", "phone"},
+		}},
+		{Line: 3, Todo: domain.Todo{
+			Title:     "Pay rent",
+			Status:    domain.StatusDone,
+			Priority:  domain.PriorityHigh,
+			CreatedAt: *date("2024-01-02T00:00:00Z"),
+		}},
+		{Line: 4, Todo: domain.Todo{Title: "Water plants", Status: domain.StatusDone}},
+		{Line: 5, Todo: domain.Todo{Title: "Read (a) book https://example.com/book", Priority: domain.PriorityLow}},
+		{Line: 6, Err: errAny},
+		{Line: 7, Todo: domain.Todo{Tags: []string{"errands"}}},
+	})
+}
+
+func TestParseICal(t *testing.T) {
+	input := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//Example//Tasks//EN\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Dentist\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:1\r\n" +
+		"CREATED:20240101T090000Z\r\n" +
+		"SUMMARY:Buy milk\\, eggs\r\n" +
+		"PRIORITY:2\r\n" +
+		"DUE;VALUE=DATE:20240105\r\n" +
+		"RRULE:FREQ=WEEKLY\r\n" +
+		"CATEGORIES:home,errands\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:2\r\n" +
+		"SUMMARY:Water plants\r\n" +
+		"COMPLETED:20240103T080000Z\r\n" +
+		"PRIORITY:7\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:3\r\n" +
+		"SUMMARY:Write report\r\n" +
+		"DESCRIPTION:Cover **Q3**\\nand Q4\r\n" +
+		"STATUS:IN-PROCESS\r\n" +
+		"DUE;TZID=Europe/Berlin:20240401T090000\r\n" +
+		"RRULE:FREQ=MONTHLY\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:4\r\n" +
+		"SUMMARY:Odd one\r\n" +
+		"STATUS:TENTATIVE\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	records, err := ParseICal(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseICal() unexpected error = %v", err)
+	}
+
+	checkRecords(t, records, []Record{
+		{Line: 7, Todo: domain.Todo{
+			Title:      "Buy milk, eggs",
+			Priority:   domain.PriorityUrgent,
+			CreatedAt:  *date("2024-01-01T09:00:00Z"),
+			DueAt:      date("2024-01-05T00:00:00Z"),
+			Tags:       []string{"home", "errands"},
+			Recurrence: &domain.Recurrence{Rule: "FREQ=WEEKLY"},
+		}},
+		{Line: 16, Todo: domain.Todo{Title: "Water plants", Status: domain.StatusDone, Priority: domain.PriorityLow}},
+		{Line: 22, Todo: domain.Todo{
+			Title:       "Write report",
+			Description: "Cover **Q3**\nand Q4",
+			Status:      domain.StatusInProgress,
+			DueAt:       date("2024-04-01T07:00:00Z"),
+			Recurrence:  &domain.Recurrence{Rule: "FREQ=MONTHLY", TimeZone: "Europe/Berlin"},
+		}},
+		{Line: 30, Err: errAny},
+	})
+}
+
+func TestParseICal_Malformed(t *testing.T) {
+	input := "BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:Buy milk\nDUE:soon\nEND:VTODO\nEND:VCALENDAR\n"
+	if _, err := ParseICal(strings.NewReader(input)); !errors.Is(err, ErrMalformed) {
+		t.Errorf("ParseICal() error = %v, want %v", err, ErrMalformed)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat(" TodoTxt "); err != nil || f != FormatTodoTxt {
+		t.Errorf("ParseFormat() = %q, %v, want %q", f, err, FormatTodoTxt)
+	}
+	if _, err := ParseFormat("xlsx"); err == nil {
+		t.Errorf("ParseFormat(xlsx) expected error")
+	}
+
+	if f, ok := FormatForMediaType("text/plain"); !ok || f != FormatTodoTxt {
+		t.Errorf("FormatForMediaType(text/plain) = %q, %t, want %q", f, ok, FormatTodoTxt)
+	}
+	if _, ok := FormatForMediaType("application/xml"); ok {
+		t.Errorf("FormatForMediaType(application/xml) ok, want none")
+	}
+}
+
+func TestParse_ReadError(t *testing.T) {
+	errRead := errors.New("connection reset")
+
+	for _, format := range []Format{FormatCSV, FormatJSON, FormatTodoTxt, FormatICal} {
+		r := io.MultiReader(strings.NewReader("title\n"), iotest.ErrReader(errRead))
+		if _, err := Parse(r, format); !errors.Is(err, errRead) || errors.Is(err, ErrMalformed) {
+			t.Errorf("Parse(%s) error = %v, want %v", format, err, errRead)
+		}
+	}
+}
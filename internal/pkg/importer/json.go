@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// jsonTodo is a todo in a JSON import, with the fields of a create
+// request. Other fields, such as the id of a todo exported from this API,
+// are ignored.
+type jsonTodo struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	Completed   *bool      `json:"completed"`
+	CreatedAt   *time.Time `json:"created_at"`
+	DueAt       *time.Time `json:"due_at"`
+	RemindAt    *time.Time `json:"remind_at"`
+	Tags        []string   `json:"tags"`
+	ProjectID   *int       `json:"project_id"`
+	ParentID    *int       `json:"parent_id"`
+}
+
+// ParseJSON reads todos from a JSON array of objects. Elements with fields
+// of the wrong type are reported on their own; input that is not a JSON
+// array is rejected as a whole.
+func ParseJSON(r io.Reader) ([]Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, fmt.Errorf("%w: expected a JSON array", ErrMalformed)
+	}
+
+	var records []Record
+	for dec.More() {
+		line := lineAt(data, dec.InputOffset())
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrMalformed, line, err)
+		}
+
+		todo, err := decodeJSONTodo(raw)
+		records = append(records, Record{Line: line, Todo: todo, Err: err})
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return records, nil
+}
+
+// decodeJSONTodo maps a single element of the array onto a todo.
+func decodeJSONTodo(raw json.RawMessage) (domain.Todo, error) {
+	var jt jsonTodo
+	if err := json.Unmarshal(raw, &jt); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			if typeErr.Field == "" {
+				return domain.Todo{}, errors.New("todo must be an object")
+			}
+			return domain.Todo{}, fieldError(typeErr.Field, "cannot be a "+typeErr.Value)
+		}
+		return domain.Todo{}, fmt.Errorf("invalid todo: %v", err)
+	}
+
+	todo := domain.Todo{
+		Title:       jt.Title,
+		Description: jt.Description,
+		Status:      domain.Status(jt.Status),
+		Priority:    domain.Priority(jt.Priority),
+		DueAt:       jt.DueAt,
+		RemindAt:    jt.RemindAt,
+		Tags:        jt.Tags,
+		ProjectID:   jt.ProjectID,
+		ParentID:    jt.ParentID,
+	}
+	if jt.CreatedAt != nil {
+		todo.CreatedAt = *jt.CreatedAt
+	}
+	if jt.Completed != nil && *jt.Completed && todo.Status == "" {
+		todo.Status = domain.StatusDone
+	}
+	return todo, nil
+}
+
+// lineAt returns the line of the first value at or after offset in data,
+// skipping whitespace and the comma separating array elements.
+func lineAt(data []byte, offset int64) int {
+	i := int(offset)
+	for i < len(data) && bytes.IndexByte([]byte(" \t\r\n,"), data[i]) >= 0 {
+		i++
+	}
+	return bytes.Count(data[:i], []byte("\n")) + 1
+}
@@ -0,0 +1,122 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// todoTxtPriorities maps todo.txt priorities onto ours: (A) is urgent, (B)
+// high, (C) medium and anything lower low.
+var todoTxtPriorities = map[byte]domain.Priority{
+	'A': domain.PriorityUrgent,
+	'B': domain.PriorityHigh,
+	'C': domain.PriorityMedium,
+}
+
+// ParseTodoTxt reads todos in the todo.txt format, one per line:
+//
+//	x 2024-01-03 2024-01-01 (A) Call mom +family @phone due:2024-01-05
+//
+// A leading x marks the todo as done and is followed by an optional
+// completion date, which is dropped. The creation date, priority and
+// due: tag are mapped onto their fields, +project and @context words become
+// tags, and the rest of the line is the title. Blank lines are skipped.
+func ParseTodoTxt(r io.Reader) ([]Record, error) {
+	scanner := bufio.NewScanner(r)
+
+	var records []Record
+	line := 1
+	for ; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		todo, err := todoTxtTodo(strings.Fields(text))
+		records = append(records, Record{Line: line, Todo: todo, Err: err})
+	}
+
+	err := scanner.Err()
+	if errors.Is(err, bufio.ErrTooLong) {
+		return nil, fmt.Errorf("%w: line %d is too long", ErrMalformed, line)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// todoTxtTodo maps the words of a todo.txt line onto a todo.
+func todoTxtTodo(words []string) (domain.Todo, error) {
+	todo, words := todoTxtPrefix(words)
+
+	title := make([]string, 0, len(words))
+	for _, word := range words {
+		switch {
+		case len(word) > 1 && (word[0] == '+' || word[0] == '@'):
+			todo.Tags = append(todo.Tags, word[1:])
+		case strings.HasPrefix(word, "due:"):
+			due, err := parseTime("due", strings.TrimPrefix(word, "due:"))
+			if err != nil {
+				return todo, err
+			}
+			todo.DueAt = due
+		case strings.HasPrefix(word, "pri:") && len(word) == len("pri:A") && isPriority(word[4]):
+			// Done todos keep their priority as a tag
+			todo.Priority = todoTxtPriority(word[4])
+		default:
+			title = append(title, word)
+		}
+	}
+	todo.Title = strings.Join(title, " ")
+
+	return todo, nil
+}
+
+// todoTxtPrefix reads the completion mark, priority and dates that may
+// start a todo.txt line, and returns the words that follow them.
+func todoTxtPrefix(words []string) (domain.Todo, []string) {
+	var todo domain.Todo
+
+	switch {
+	case words[0] == "x":
+		todo.Status = domain.StatusDone
+		words = words[1:]
+		// The completion date comes first, and only then the creation date
+		if len(words) > 0 && isDate(words[0]) {
+			words = words[1:]
+		}
+	case len(words[0]) == len("(A)") && words[0][0] == '(' && words[0][2] == ')' && isPriority(words[0][1]):
+		todo.Priority = todoTxtPriority(words[0][1])
+		words = words[1:]
+	}
+
+	if len(words) > 0 && isDate(words[0]) {
+		todo.CreatedAt, _ = time.Parse(time.DateOnly, words[0])
+		words = words[1:]
+	}
+
+	return todo, words
+}
+
+func isDate(word string) bool {
+	_, err := time.Parse(time.DateOnly, word)
+	return err == nil
+}
+
+func isPriority(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func todoTxtPriority(c byte) domain.Priority {
+	if p, ok := todoTxtPriorities[c]; ok {
+		return p
+	}
+	return domain.PriorityLow
+}
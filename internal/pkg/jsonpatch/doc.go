@@ -0,0 +1,12 @@
+// Package jsonpatch applies partial updates to JSON documents using either
+// JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902).
+//
+// Both formats operate on raw JSON and return the patched document as raw
+// JSON, leaving it to the caller to decode and validate the result. Errors
+// are classified with sentinel values so that transports can map them to
+// the appropriate response status:
+//
+//	ErrInvalidPatch  - the patch document itself is malformed
+//	ErrTestFailed    - a "test" operation did not match the document
+//	ErrPathNotFound  - an operation referenced a location that does not exist
+package jsonpatch
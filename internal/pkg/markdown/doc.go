@@ -0,0 +1,12 @@
+// Package markdown renders Markdown as HTML that is safe to embed in a
+// web page.
+//
+// Markdown follows CommonMark with the GitHub extensions: tables,
+// strikethrough, task lists and bare URLs turned into links. Rendering
+// happens in two steps, each of which would keep scripts out on its own:
+// the renderer leaves raw HTML out of its output and drops links to
+// dangerous URLs, and the HTML it produces is then passed through an
+// allowlist sanitizer, which keeps only the elements and attributes
+// Markdown produces, links only to http, https and mailto URLs and strips
+// every event handler, style and script.
+package markdown
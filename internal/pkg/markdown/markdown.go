@@ -0,0 +1,42 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// renderer turns Markdown into HTML. Raw HTML in the source is omitted, as
+// it is unless goldmark is told to render it unsafely.
+var renderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// policy is the allowlist the rendered HTML is sanitized with.
+var policy = newPolicy()
+
+// newPolicy allows what Markdown renders to, including the disabled
+// checkboxes of task lists, and nothing else.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// Render returns the sanitized HTML rendering of the Markdown source.
+func Render(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return Sanitize(buf.String()), nil
+}
+
+// Sanitize strips from an HTML fragment everything that Render would not
+// produce, keeping the text of elements that are removed.
+func Sanitize(fragment string) string {
+	return policy.Sanitize(fragment)
+}
@@ -0,0 +1,282 @@
+package markdown
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// render renders source, failing the test on error.
+func render(t *testing.T, source string) string {
+	t.Helper()
+
+	out, err := Render(source)
+	if err != nil {
+		t.Fatalf("Render(%q) unexpected error = %v", source, err)
+	}
+	return out
+}
+
+// unsafeElements are elements that must never survive sanitizing, as they
+// run scripts, load other documents or change how the page is read.
+var unsafeElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Frame: true, atom.Frameset: true,
+	atom.Object: true, atom.Embed: true, atom.Applet: true, atom.Svg: true, atom.Math: true,
+	atom.Form: true, atom.Button: true, atom.Textarea: true, atom.Select: true, atom.Link: true,
+	atom.Meta: true, atom.Base: true, atom.Template: true, atom.Noscript: true, atom.Details: true,
+}
+
+// urlAttributes are attributes whose value is loaded or navigated to.
+var urlAttributes = map[string]bool{
+	"href": true, "src": true, "action": true, "formaction": true, "xlink:href": true, "background": true,
+	"poster": true, "cite": true, "srcset": true,
+}
+
+// checkSafe parses an HTML fragment the way a browser would and fails the
+// test if it holds anything that could run a script.
+func checkSafe(t *testing.T, fragment string) {
+	t.Helper()
+
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{
+		Type: html.ElementNode, Data: "div", DataAtom: atom.Div,
+	})
+	if err != nil {
+		t.Fatalf("output %q is not HTML: %v", fragment, err)
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			checkElement(t, fragment, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+}
+
+func checkElement(t *testing.T, fragment string, n *html.Node) {
+	t.Helper()
+
+	if unsafeElements[n.DataAtom] {
+		t.Errorf("output %q holds a <%s> element", fragment, n.Data)
+	}
+	if n.DataAtom == atom.Input {
+		for _, attr := range n.Attr {
+			if attr.Key == "type" && attr.Val != "checkbox" {
+				t.Errorf("output %q holds an input of type %q", fragment, attr.Val)
+			}
+		}
+	}
+
+	for _, attr := range n.Attr {
+		key := strings.ToLower(attr.Key)
+		if attr.Namespace != "" {
+			key = attr.Namespace + ":" + key
+		}
+
+		switch {
+		case strings.HasPrefix(key, "on"):
+			t.Errorf("output %q holds the event handler %s", fragment, key)
+		case key == "style":
+			t.Errorf("output %q holds a style attribute", fragment)
+		case urlAttributes[key]:
+			checkURL(t, fragment, key, attr.Val)
+		}
+	}
+}
+
+func checkURL(t *testing.T, fragment, key, value string) {
+	t.Helper()
+
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		t.Errorf("output %q holds the unparseable %s %q", fragment, key, value)
+		return
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+	default:
+		t.Errorf("output %q holds the %s %q", fragment, key, value)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "inline formatting",
+			source: "**bold** _italic_ ~~struck~~ `code`",
+			want:   "<p><strong>bold</strong> <em>italic</em> <del>struck</del> <code>code</code></p>\n",
+		},
+		{
+			name:   "heading and list",
+			source: "# Plan\n\n1. Pack\n2. Move\n",
+			want:   "<h1>Plan</h1>\n<ol>\n<li>Pack</li>\n<li>Move</li>\n</ol>\n",
+		},
+		{
+			name:   "task list",
+			source: "- [x] Pack\n- [ ] Move\n",
+			want: "<ul>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> Pack</li>\n" +
+				"<li><input disabled=\"\" type=\"checkbox\"> Move</li>\n</ul>\n",
+		},
+		{
+			name:   "external link",
+			source: "[Docs](https://example.com/docs)",
+			want: "<p><a href=\"https://example.com/docs\" rel=\"nofollow noopener\" target=\"_blank\">" +
+				"Docs</a></p>\n",
+		},
+		{
+			name:   "bare url",
+			source: "See https://example.com",
+			want: "<p>See <a href=\"https://example.com\" rel=\"nofollow noopener\" target=\"_blank\">" +
+				"https://example.com</a></p>\n",
+		},
+		{
+			name:   "relative and mail links",
+			source: "[Todo](/api/v1/todos/1) [Mail](mailto:me@example.com)",
+			want: "<p><a href=\"/api/v1/todos/1\" rel=\"nofollow\">Todo</a> " +
+				"<a href=\"mailto:me@example.com\" rel=\"nofollow\">Mail</a></p>\n",
+		},
+		{
+			name:   "image",
+			source: "![Plan](https://example.com/plan.png)",
+			want:   "<p><img src=\"https://example.com/plan.png\" alt=\"Plan\"></p>\n",
+		},
+		{
+			name:   "table",
+			source: "| a | b |\n|---|---|\n| 1 | 2 |\n",
+			want: "<table>\n<thead>\n<tr>\n<th>a</th>\n<th>b</th>\n</tr>\n</thead>\n" +
+				"<tbody>\n<tr>\n<td>1</td>\n<td>2</td>\n</tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name:   "html in code is escaped",
+			source: "```\n<script>alert(1)</script>\n```\n\n`<b>`",
+			want:   "<pre><code>&lt;script&gt;alert(1)&lt;/script&gt;\n</code></pre>\n<p><code>&lt;b&gt;</code></p>\n",
+		},
+		{
+			name:   "entities stay escaped",
+			source: "&lt;script&gt; &#60;b&#62; 1 < 2 & 3",
+			want:   "<p>&lt;script&gt; &lt;b&gt; 1 &lt; 2 &amp; 3</p>\n",
+		},
+		{
+			name:   "empty",
+			source: "",
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.source); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_XSS(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		// keep is text of the source that must still be shown
+		keep string
+	}{
+		{name: "script element", source: "<script>alert(1)</script>\n\nafter", keep: "after"},
+		{name: "inline script", source: "before <script>alert(1)</script>", keep: "before"},
+		{name: "event handler", source: "<img src=x onerror=alert(1)>"},
+		{name: "raw link", source: `<a href="javascript:alert(1)">click</a>`},
+		{name: "raw link with handler", source: `<a href="https://example.com" onclick="alert(1)">click</a>`},
+		{name: "javascript link", source: "[click](javascript:alert(1))", keep: "click"},
+		{name: "mixed case scheme", source: "[click](JaVaScRiPt:alert(1))", keep: "click"},
+		{name: "bracketed destination", source: "[click](<javascript:alert(1)>)", keep: "click"},
+		{name: "tab in scheme", source: "[click](java&#x09;script:alert(1))", keep: "click"},
+		{name: "entity in scheme", source: "[click](&#106;avascript:alert(1))", keep: "click"},
+		{name: "newline entity in scheme", source: "[click](java&#10;script:alert(1))", keep: "click"},
+		{name: "percent encoded scheme", source: "[click](%6Aavascript:alert(1))", keep: "click"},
+		{name: "vbscript link", source: "[click](vbscript:msgbox(1))", keep: "click"},
+		{name: "html data link", source: "[click](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)"},
+		{name: "svg data image", source: "![x](data:image/svg+xml;base64,PHN2ZyBvbmxvYWQ9YWxlcnQoMSk+)"},
+		{name: "javascript image", source: "![x](javascript:alert(1))"},
+		{name: "javascript autolink", source: "<javascript:alert(1)>"},
+		{name: "reference link", source: "[click][1]\n\n[1]: javascript:alert(1)", keep: "click"},
+		{name: "title breaking out", source: `[click](https://example.com "\" onmouseover=alert(1) x=\"")`},
+		{name: "alt breaking out", source: `![a" onerror="alert(1)](https://example.com/x.png)`},
+		{name: "bare url breaking out", source: `https://example.com/"onmouseover="alert(1)`},
+		{name: "svg", source: "<svg/onload=alert(1)>"},
+		{name: "iframe", source: `<iframe src="https://evil.example"></iframe>`},
+		{name: "object", source: `<object data="https://evil.example/x.swf"></object>`},
+		{name: "style element", source: "<style>*{display:none}</style>"},
+		{name: "style attribute", source: `<div style="background:url(javascript:alert(1))">x</div>`},
+		{name: "details toggle", source: "<details open ontoggle=alert(1)>"},
+		{name: "form", source: `<form action="javascript:alert(1)"><button>x</button></form>`},
+		{name: "meta refresh", source: `<meta http-equiv="refresh" content="0;url=javascript:alert(1)">`},
+		{name: "html in table cell", source: "| a |\n|---|\n| <img src=x onerror=alert(1)> |"},
+		{name: "html in link text", source: "[<img src=x onerror=alert(1)>](https://example.com)"},
+		{name: "comment", source: "<!-- --><script>alert(1)</script>"},
+		{name: "nested tags", source: "<scr<script>ipt>alert(1)</script>"},
+		{name: "mutation", source: "<math><mtext><table><mglyph><style><img src=x onerror=alert(1)>"},
+		{name: "unclosed tag", source: "<img src=x onerror=alert(1)//"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := render(t, tt.source)
+			checkSafe(t, got)
+			if !strings.Contains(got, tt.keep) {
+				t.Errorf("Render(%q) = %q, want it to keep %q", tt.source, got, tt.keep)
+			}
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		want     string
+	}{
+		{
+			name:     "markdown output is kept",
+			fragment: `<p><strong>a</strong> <a href="https://example.com">b</a></p>`,
+			want:     `<p><strong>a</strong> <a href="https://example.com" rel="nofollow noopener" target="_blank">b</a></p>`,
+		},
+		{name: "script", fragment: `<p>a<script>alert(1)</script></p>`, want: `<p>a</p>`},
+		{name: "event handler", fragment: `<p onclick="alert(1)">a</p>`, want: `<p>a</p>`},
+		{name: "javascript link", fragment: `<a href="javascript:alert(1)">a</a>`, want: `a`},
+		{name: "entity encoded link", fragment: `<a href="&#106;avascript:alert(1)">a</a>`, want: `a`},
+		{name: "style", fragment: `<p style="color:red">a</p>`, want: `<p>a</p>`},
+		{name: "svg", fragment: `<svg onload="alert(1)"><circle/></svg>a`, want: `a`},
+		{name: "iframe", fragment: `<iframe src="https://evil.example"></iframe>a`, want: `a`},
+		{name: "text input", fragment: `<input type="text" value="x">`, want: ``},
+		{
+			name:     "checkbox",
+			fragment: `<input type="checkbox" checked="" disabled="">`,
+			want:     `<input type="checkbox" checked="" disabled="">`,
+		},
+		{
+			name:     "checked with a value",
+			fragment: `<input type="checkbox" checked="javascript:alert(1)">`,
+			want:     `<input type="checkbox">`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sanitize(tt.fragment)
+			if got != tt.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.fragment, got, tt.want)
+			}
+			checkSafe(t, got)
+		})
+	}
+}
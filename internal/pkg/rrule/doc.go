@@ -0,0 +1,16 @@
+// Package rrule parses and evaluates recurrence rules, the RRULE property
+// of iCalendar (RFC 5545).
+//
+// A Rule is read with Parse and written back with String. Rules recur
+// daily, weekly, monthly or yearly; the parts that narrow a rule down to
+// hours, minutes, seconds, week numbers or days of the year are not
+// supported.
+//
+// A rule is evaluated against a start, the DTSTART of iCalendar, which is
+// always its first occurrence. Every occurrence falls on the wall-clock
+// time of the start, in the start's location, so a todo due at 09:00 stays
+// due at 09:00 across daylight saving changes. Dates that do not exist, such as the 31st of a short
+// month, are skipped. A time that a daylight saving jump skips resolves
+// to the offset in force before the jump, and a time that happens twice
+// when clocks fall back resolves to its first instance.
+package rrule
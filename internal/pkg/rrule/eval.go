@@ -0,0 +1,318 @@
+package rrule
+
+import (
+	"slices"
+	"time"
+)
+
+// horizon is how many years past its last occurrence, or its start, a rule
+// is searched before it is taken to have no occurrences left. The calendar
+// repeats every 400 years, so a rule such as the 30th of February, which
+// never matches, ends there rather than being searched forever.
+const horizon = 400
+
+// Next returns the first occurrence of the rule, evaluated against start,
+// that falls after t. It returns false when the rule has ended.
+func (r *Rule) Next(start, t time.Time) (time.Time, bool) {
+	next := r.Occurrences(start, t, 1)
+	if len(next) == 0 {
+		return time.Time{}, false
+	}
+	return next[0], true
+}
+
+// Occurrences returns the first n occurrences of the rule, evaluated
+// against start, that fall after t. As in iCalendar, the start is always
+// the first occurrence, whether or not it matches the rule.
+func (r *Rule) Occurrences(start, t time.Time, n int) []time.Time {
+	var list []time.Time
+	if n <= 0 {
+		return list
+	}
+	r.each(start, func(o time.Time) bool {
+		if o.After(t) {
+			list = append(list, o)
+		}
+		return len(list) < n
+	})
+	return list
+}
+
+// each calls fn with the occurrences of the rule from start on, in order,
+// until fn returns false or the rule ends.
+func (r *Rule) each(start time.Time, fn func(time.Time) bool) {
+	if !fn(start) {
+		return
+	}
+	first := dateOf(start)
+	hour, min, sec := start.Clock()
+	last, count := first.year, 1
+	interval := max(r.Interval, 1)
+	for n := 0; ; n += interval {
+		anchor, days := r.period(first, n)
+		if anchor.year > last+horizon || anchor.year > 9999 {
+			return
+		}
+		for _, d := range r.setPos(days) {
+			if d.before(first) {
+				continue
+			}
+			if r.UntilDate && dateOf(r.Until.UTC()).before(d) {
+				return
+			}
+			o := wallClock(d, hour, min, sec, start.Nanosecond(), start.Location())
+			if !o.After(start) {
+				continue
+			}
+			if !r.UntilDate && !r.Until.IsZero() && o.After(r.Until) {
+				return
+			}
+			if count++; r.Count > 0 && count > r.Count {
+				return
+			}
+			last = d.year
+			if !fn(o) {
+				return
+			}
+		}
+	}
+}
+
+// period returns the first day of the nth period after the one holding
+// first, and the days of that period the rule picks, in order.
+func (r *Rule) period(first date, n int) (date, []date) {
+	switch r.Freq {
+	case Yearly:
+		y := first.year + n
+		return date{y, time.January, 1}, r.yearDays(y, first)
+	case Monthly:
+		m := int(first.month) - 1 + n
+		y, month := first.year+m/12, time.Month(m%12+1)
+		if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, month) {
+			return date{y, month, 1}, nil
+		}
+		return date{y, month, 1}, r.monthDays(y, month, first)
+	case Weekly:
+		offset := (int(first.weekday()) - int(r.WeekStart) + 7) % 7
+		week := first.add(7*n - offset)
+		return week, r.weekDays(week, first)
+	default:
+		d := first.add(n)
+		if !r.matches(d) {
+			return d, nil
+		}
+		return d, []date{d}
+	}
+}
+
+// yearDays returns the days of year y the rule picks.
+func (r *Rule) yearDays(y int, first date) []date {
+	if len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 {
+		if len(r.ByDay) > 0 {
+			jan1 := date{y, time.January, 1}
+			return r.weekdays(jan1, date{y + 1, time.January, 1}.sub(jan1))
+		}
+		return r.monthDays(y, first.month, first)
+	}
+	months := slices.Clone(r.ByMonth)
+	if len(months) == 0 {
+		for m := time.January; m <= time.December; m++ {
+			months = append(months, m)
+		}
+	}
+	slices.Sort(months)
+	var days []date
+	for _, m := range slices.Compact(months) {
+		days = append(days, r.monthDays(y, m, first)...)
+	}
+	return days
+}
+
+// monthDays returns the days of month m of year y the rule picks.
+func (r *Rule) monthDays(y int, m time.Month, first date) []date {
+	n := daysIn(y, m)
+	if len(r.ByMonthDay) == 0 {
+		if len(r.ByDay) > 0 {
+			return r.weekdays(date{y, m, 1}, n)
+		}
+		if first.day > n {
+			return nil
+		}
+		return []date{{y, m, first.day}}
+	}
+	var allowed []date
+	if len(r.ByDay) > 0 {
+		allowed = r.weekdays(date{y, m, 1}, n)
+	}
+	var days []date
+	for _, md := range r.ByMonthDay {
+		if md < 0 {
+			md += n + 1
+		}
+		d := date{y, m, md}
+		if md < 1 || md > n || allowed != nil && !slices.Contains(allowed, d) {
+			continue
+		}
+		days = append(days, d)
+	}
+	slices.SortFunc(days, date.compare)
+	return slices.Compact(days)
+}
+
+// weekDays returns the days of the week beginning on week the rule picks.
+func (r *Rule) weekDays(week, first date) []date {
+	var days []date
+	for i := 0; i < 7; i++ {
+		d := week.add(i)
+		if len(r.ByDay) == 0 && d.weekday() != first.weekday() {
+			continue
+		}
+		if r.matches(d) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// weekdays returns the days of the n days from from on whose weekday is in
+// BYDAY, numbered weekdays counting within those n days.
+func (r *Rule) weekdays(from date, n int) []date {
+	match := make([]bool, n)
+	for _, w := range r.ByDay {
+		first := (int(w.Day) - int(from.weekday()) + 7) % 7
+		switch {
+		case w.N == 0:
+			for i := first; i < n; i += 7 {
+				match[i] = true
+			}
+		case w.N > 0:
+			if i := first + 7*(w.N-1); i < n {
+				match[i] = true
+			}
+		default:
+			last := first + 7*((n-1-first)/7)
+			if i := last + 7*(w.N+1); i >= 0 {
+				match[i] = true
+			}
+		}
+	}
+	var days []date
+	for i, ok := range match {
+		if ok {
+			days = append(days, from.add(i))
+		}
+	}
+	return days
+}
+
+// matches reports whether a day passes the BYMONTH, BYMONTHDAY and BYDAY
+// parts of a daily or weekly rule, which only ever narrow it down.
+func (r *Rule) matches(d date) bool {
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, d.month) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 {
+		n := daysIn(d.year, d.month)
+		if !slices.ContainsFunc(r.ByMonthDay, func(md int) bool {
+			return md == d.day || md < 0 && md+n+1 == d.day
+		}) {
+			return false
+		}
+	}
+	return len(r.ByDay) == 0 || slices.ContainsFunc(r.ByDay, func(w Weekday) bool {
+		return w.Day == d.weekday()
+	})
+}
+
+// setPos keeps the days at the BYSETPOS positions.
+func (r *Rule) setPos(days []date) []date {
+	if len(r.BySetPos) == 0 {
+		return days
+	}
+	picked := make([]bool, len(days))
+	for _, p := range r.BySetPos {
+		i := p - 1
+		if p < 0 {
+			i = len(days) + p
+		}
+		if i >= 0 && i < len(days) {
+			picked[i] = true
+		}
+	}
+	var kept []date
+	for i, d := range days {
+		if picked[i] {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// date is a day of the calendar.
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) date {
+	y, m, d := t.Date()
+	return date{y, m, d}
+}
+
+func (d date) time() time.Time {
+	return time.Date(d.year, d.month, d.day, 0, 0, 0, 0, time.UTC)
+}
+
+func (d date) add(days int) date {
+	return dateOf(d.time().AddDate(0, 0, days))
+}
+
+// sub returns the number of days from e to d.
+func (d date) sub(e date) int {
+	return int(d.time().Sub(e.time()) / (24 * time.Hour))
+}
+
+func (d date) weekday() time.Weekday {
+	return d.time().Weekday()
+}
+
+func (d date) before(e date) bool {
+	return d.compare(e) < 0
+}
+
+func (d date) compare(e date) int {
+	return d.time().Compare(e.time())
+}
+
+func daysIn(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// wallClock returns the instant at which the clocks of loc read the given
+// time of day on d. Of the two instants a time that happens twice when
+// clocks fall back can stand for, it returns the first; a time that a jump
+// forward skips is read with the offset in force before the jump, as RFC
+// 5545 has it. time.Date leaves both cases unspecified.
+func wallClock(d date, hour, min, sec, nsec int, loc *time.Location) time.Time {
+	wall := time.Date(d.year, d.month, d.day, hour, min, sec, nsec, time.UTC)
+	var first time.Time
+	for _, probe := range []time.Time{wall.Add(-24 * time.Hour), wall, wall.Add(24 * time.Hour)} {
+		_, offset := probe.In(loc).Zone()
+		t := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if sameWall(t, wall) && (first.IsZero() || t.Before(first)) {
+			first = t
+		}
+	}
+	if !first.IsZero() {
+		return first
+	}
+	_, before := wall.Add(-24 * time.Hour).In(loc).Zone()
+	return wall.Add(-time.Duration(before) * time.Second).In(loc)
+}
+
+// sameWall reports whether t reads the same date and time of day as wall.
+func sameWall(t, wall time.Time) bool {
+	return dateOf(t) == dateOf(wall) && t.Hour() == wall.Hour() &&
+		t.Minute() == wall.Minute() && t.Second() == wall.Second()
+}
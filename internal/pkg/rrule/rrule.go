@@ -0,0 +1,298 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is how often a rule recurs.
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// Weekday is a day of the week in a BYDAY list.
+type Weekday struct {
+	Day time.Weekday
+	// N, when not 0, picks the Nth such day of the month or year, counting
+	// back from its end when negative
+	N int
+}
+
+// Rule is a recurrence rule.
+type Rule struct {
+	Freq Frequency
+	// Interval is the number of periods between occurrences: 2 with a
+	// weekly rule is every other week
+	Interval int
+	// Count, when not 0, is the number of occurrences, the first counted
+	// from the start the rule is evaluated against
+	Count int
+	// Until, when not zero, is the last moment an occurrence may fall on
+	Until time.Time
+	// UntilDate makes Until a date, given in UTC: occurrences may fall on
+	// it whatever their time of day
+	UntilDate  bool
+	ByMonth    []time.Month
+	ByMonthDay []int
+	ByDay      []Weekday
+	// BySetPos picks occurrences by their position among those of a period,
+	// counting back from its end when negative
+	BySetPos []int
+	// WeekStart is the first day of the week. Parse sets it to Monday
+	// unless the rule has a WKST part.
+	WeekStart time.Weekday
+}
+
+// ErrSyntax is wrapped by the errors Parse returns.
+var ErrSyntax = errors.New("invalid recurrence rule")
+
+const (
+	untilDate     = "20060102"
+	untilDateTime = "20060102T150405Z"
+)
+
+var dayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// unsupported lists the parts of RFC 5545 rules Parse rejects.
+var unsupported = map[string]bool{
+	"BYSECOND":  true,
+	"BYMINUTE":  true,
+	"BYHOUR":    true,
+	"BYYEARDAY": true,
+	"BYWEEKNO":  true,
+}
+
+// Parse reads a rule such as "FREQ=MONTHLY;BYDAY=-1FR". The "RRULE:"
+// prefix of the iCalendar property is allowed.
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+		s = s[6:]
+	}
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrSyntax, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s given twice", ErrSyntax, name)
+		}
+		seen[name] = true
+		if err := r.set(name, strings.ToUpper(strings.TrimSpace(value))); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrSyntax, name, err)
+		}
+	}
+	if err := r.check(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSyntax, err)
+	}
+	return r, nil
+}
+
+// set reads the value of one part of a rule.
+func (r *Rule) set(name, value string) error {
+	var err error
+	switch name {
+	case "FREQ":
+		r.Freq, err = parseFrequency(value)
+	case "INTERVAL":
+		r.Interval, err = parseInt(value, 1, 1000)
+	case "COUNT":
+		r.Count, err = parseInt(value, 1, 100000)
+	case "UNTIL":
+		err = r.setUntil(value)
+	case "BYMONTH":
+		r.ByMonth, err = parseList(value, func(v string) (time.Month, error) {
+			n, err := parseInt(v, 1, 12)
+			return time.Month(n), err
+		})
+	case "BYMONTHDAY":
+		r.ByMonthDay, err = parseList(value, func(v string) (int, error) {
+			return parseOrdinal(v, 31)
+		})
+	case "BYDAY":
+		r.ByDay, err = parseList(value, parseWeekday)
+	case "BYSETPOS":
+		r.BySetPos, err = parseList(value, func(v string) (int, error) {
+			return parseOrdinal(v, 366)
+		})
+	case "WKST":
+		var w Weekday
+		w, err = parseWeekday(value)
+		if err == nil && w.N != 0 {
+			err = fmt.Errorf("invalid weekday %q", value)
+		}
+		r.WeekStart = w.Day
+	default:
+		if unsupported[name] {
+			return errors.New("not supported")
+		}
+		return errors.New("unknown part")
+	}
+	return err
+}
+
+func (r *Rule) setUntil(value string) error {
+	if t, err := time.Parse(untilDate, value); err == nil {
+		r.Until, r.UntilDate = t, true
+		return nil
+	}
+	t, err := time.Parse(untilDateTime, value)
+	if err != nil {
+		return fmt.Errorf("invalid date or UTC time %q", value)
+	}
+	r.Until = t
+	return nil
+}
+
+// check reports parts of a rule that are missing or do not go together.
+func (r *Rule) check() error {
+	switch {
+	case r.Freq == "":
+		return errors.New("FREQ is required")
+	case r.Count != 0 && !r.Until.IsZero():
+		return errors.New("COUNT and UNTIL cannot both be given")
+	case r.Freq == Weekly && len(r.ByMonthDay) > 0:
+		return errors.New("BYMONTHDAY cannot be used with a weekly rule")
+	case len(r.BySetPos) > 0 && len(r.ByMonth)+len(r.ByMonthDay)+len(r.ByDay) == 0:
+		return errors.New("BYSETPOS needs another BY part")
+	}
+	if r.Freq != Monthly && r.Freq != Yearly {
+		for _, w := range r.ByDay {
+			if w.N != 0 {
+				return fmt.Errorf("BYDAY cannot number weekdays of a %s rule", strings.ToLower(string(r.Freq)))
+			}
+		}
+	}
+	return nil
+}
+
+func parseFrequency(value string) (Frequency, error) {
+	switch f := Frequency(value); f {
+	case Daily, Weekly, Monthly, Yearly:
+		return f, nil
+	case "HOURLY", "MINUTELY", "SECONDLY":
+		return "", fmt.Errorf("%s is not supported", value)
+	default:
+		return "", fmt.Errorf("invalid frequency %q", value)
+	}
+}
+
+// parseInt reads an integer between lo and hi.
+func parseInt(value string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return n, nil
+}
+
+// parseOrdinal reads a signed, non-zero integer of at most max.
+func parseOrdinal(value string, max int) (int, error) {
+	n, err := parseInt(value, -max, max)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return n, nil
+}
+
+// parseWeekday reads a weekday such as "FR", "1MO" or "-1SU".
+func parseWeekday(value string) (Weekday, error) {
+	if len(value) < 2 {
+		return Weekday{}, fmt.Errorf("invalid weekday %q", value)
+	}
+	prefix, name := value[:len(value)-2], value[len(value)-2:]
+	w := Weekday{Day: -1}
+	for d, n := range dayNames {
+		if n == name {
+			w.Day = time.Weekday(d)
+		}
+	}
+	if w.Day < 0 {
+		return Weekday{}, fmt.Errorf("invalid weekday %q", value)
+	}
+	if prefix != "" {
+		n, err := parseOrdinal(strings.TrimPrefix(prefix, "+"), 53)
+		if err != nil {
+			return Weekday{}, fmt.Errorf("invalid weekday %q", value)
+		}
+		w.N = n
+	}
+	return w, nil
+}
+
+// parseList reads a comma-separated list of values.
+func parseList[T any](value string, parse func(string) (T, error)) ([]T, error) {
+	var list []T
+	for _, v := range strings.Split(value, ",") {
+		item, err := parse(v)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// String returns the rule in the form Parse reads, without the "RRULE:"
+// prefix and with its parts in a fixed order.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		layout := untilDateTime
+		if r.UntilDate {
+			layout = untilDate
+		}
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(layout))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinList(r.ByMonth, func(m time.Month) string {
+			return strconv.Itoa(int(m))
+		}))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinList(r.ByMonthDay, strconv.Itoa))
+	}
+	if len(r.ByDay) > 0 {
+		parts = append(parts, "BYDAY="+joinList(r.ByDay, Weekday.String))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinList(r.BySetPos, strconv.Itoa))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+dayNames[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// String returns the weekday as it is written in a BYDAY list.
+func (w Weekday) String() string {
+	if w.N == 0 {
+		return dayNames[w.Day]
+	}
+	return strconv.Itoa(w.N) + dayNames[w.Day]
+}
+
+func joinList[T any](list []T, format func(T) string) string {
+	s := make([]string, len(list))
+	for i, v := range list {
+		s[i] = format(v)
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,311 @@
+package rrule
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func load(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return loc
+}
+
+// occurrences evaluates rule against start, a local time in loc, and
+// returns its first n occurrences formatted by format.
+func occurrences(t *testing.T, rule, start string, loc *time.Location, n int, format func(time.Time) string) []string {
+	t.Helper()
+	r, err := Parse(rule)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", rule, err)
+	}
+	dtstart, err := time.ParseInLocation("20060102T150405", start, loc)
+	if err != nil {
+		t.Fatalf("start %q: %v", start, err)
+	}
+	var got []string
+	for _, o := range r.Occurrences(dtstart, dtstart.Add(-time.Nanosecond), n) {
+		got = append(got, format(o))
+	}
+	return got
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:FREQ=WEEKLY;INTERVAL=1", "FREQ=WEEKLY"},
+		{"rrule:freq=weekly;byday=mo,we", "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{"FREQ=MONTHLY;BYDAY=-1FR", "FREQ=MONTHLY;BYDAY=-1FR"},
+		{"FREQ=MONTHLY;BYDAY=+2MO", "FREQ=MONTHLY;BYDAY=2MO"},
+		{"BYSETPOS=-1;BYDAY=MO,TU,WE,TH,FR;FREQ=MONTHLY", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1"},
+		{"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29;COUNT=3", "FREQ=YEARLY;COUNT=3;BYMONTH=2;BYMONTHDAY=29"},
+		{"FREQ=DAILY;UNTIL=20241231", "FREQ=DAILY;UNTIL=20241231"},
+		{"FREQ=DAILY;UNTIL=20241231T230000Z", "FREQ=DAILY;UNTIL=20241231T230000Z"},
+		{"FREQ=WEEKLY;INTERVAL=2;WKST=SU", "FREQ=WEEKLY;INTERVAL=2;WKST=SU"},
+		{"FREQ=WEEKLY;WKST=MO", "FREQ=WEEKLY"},
+		{" FREQ = MONTHLY ; BYMONTHDAY = 1,-1 ", "FREQ=MONTHLY;BYMONTHDAY=1,-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			r, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := r.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			again, err := Parse(r.String())
+			if err != nil || again.String() != tt.want {
+				t.Errorf("round trip = %v, %v", again, err)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "malformed part"},
+		{"INTERVAL=2", "FREQ is required"},
+		{"FREQ=FORTNIGHTLY", "invalid frequency"},
+		{"FREQ=HOURLY", "HOURLY is not supported"},
+		{"FREQ=DAILY;BYHOUR=9", "BYHOUR: not supported"},
+		{"FREQ=DAILY;FOO=1", "FOO: unknown part"},
+		{"FREQ=DAILY;FREQ=WEEKLY", "FREQ given twice"},
+		{"FREQ=DAILY;INTERVAL=0", "invalid value"},
+		{"FREQ=DAILY;COUNT=-1", "invalid value"},
+		{"FREQ=DAILY;COUNT=2;UNTIL=20240101", "COUNT and UNTIL"},
+		{"FREQ=DAILY;UNTIL=2024-01-01", "invalid date"},
+		{"FREQ=DAILY;UNTIL=20240101T090000", "invalid date or UTC time"},
+		{"FREQ=MONTHLY;BYMONTHDAY=0", "invalid value"},
+		{"FREQ=MONTHLY;BYMONTHDAY=32", "invalid value"},
+		{"FREQ=YEARLY;BYMONTH=13", "invalid value"},
+		{"FREQ=WEEKLY;BYDAY=XX", "invalid weekday"},
+		{"FREQ=WEEKLY;BYDAY=1MO", "cannot number weekdays of a weekly rule"},
+		{"FREQ=MONTHLY;BYDAY=0MO", "invalid weekday"},
+		{"FREQ=WEEKLY;BYMONTHDAY=1", "BYMONTHDAY cannot be used"},
+		{"FREQ=MONTHLY;BYSETPOS=1", "BYSETPOS needs another BY part"},
+		{"FREQ=WEEKLY;WKST=1MO", "invalid weekday"},
+		{"FREQ=DAILY;", "malformed part"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, err := Parse(tt.in)
+			if !errors.Is(err, ErrSyntax) {
+				t.Fatalf("Parse error = %v, want ErrSyntax", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestOccurrences follows the examples of RFC 5545, section 3.8.5.3, which
+// start in America/New_York.
+func TestOccurrences(t *testing.T) {
+	ny := load(t, "America/New_York")
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		n     int
+		want  []string
+	}{
+		{
+			name: "daily for 10 occurrences", rule: "FREQ=DAILY;COUNT=10", start: "19970902T090000", n: 20,
+			want: []string{"19970902", "19970903", "19970904", "19970905", "19970906",
+				"19970907", "19970908", "19970909", "19970910", "19970911"},
+		},
+		{
+			name: "daily until a UTC time", rule: "FREQ=DAILY;UNTIL=19971224T000000Z", start: "19971220T090000", n: 10,
+			want: []string{"19971220", "19971221", "19971222", "19971223"},
+		},
+		{
+			name: "daily until a date", rule: "FREQ=DAILY;UNTIL=19971224", start: "19971220T090000", n: 10,
+			want: []string{"19971220", "19971221", "19971222", "19971223", "19971224"},
+		},
+		{
+			name: "every other week on Tuesday and Thursday", start: "19970902T090000", n: 10,
+			rule: "FREQ=WEEKLY;INTERVAL=2;COUNT=8;WKST=SU;BYDAY=TU,TH",
+			want: []string{"19970902", "19970904", "19970916", "19970918", "19970930",
+				"19971002", "19971014", "19971016"},
+		},
+		{
+			name: "week starting on Monday", rule: "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO",
+			start: "19970805T090000", n: 10,
+			want: []string{"19970805", "19970810", "19970819", "19970824"},
+		},
+		{
+			name: "week starting on Sunday", rule: "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU",
+			start: "19970805T090000", n: 10,
+			want: []string{"19970805", "19970817", "19970819", "19970831"},
+		},
+		{
+			name: "first Friday of the month", rule: "FREQ=MONTHLY;COUNT=10;BYDAY=1FR", start: "19970905T090000", n: 20,
+			want: []string{"19970905", "19971003", "19971107", "19971205", "19980102",
+				"19980206", "19980306", "19980403", "19980501", "19980605"},
+		},
+		{
+			name: "third to last day of the month", rule: "FREQ=MONTHLY;BYMONTHDAY=-3", start: "19970928T090000", n: 6,
+			want: []string{"19970928", "19971029", "19971128", "19971229", "19980129", "19980226"},
+		},
+		{
+			name: "third Tuesday, Wednesday or Thursday", rule: "FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3",
+			start: "19970904T090000", n: 10,
+			want: []string{"19970904", "19971007", "19971106"},
+		},
+		{
+			name: "second to last weekday", rule: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-2",
+			start: "19970929T090000", n: 7,
+			want: []string{"19970929", "19971030", "19971127", "19971230", "19980129", "19980226", "19980330"},
+		},
+		{
+			name: "Friday the 13th", rule: "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", start: "19980213T090000", n: 5,
+			want: []string{"19980213", "19980313", "19981113", "19990813", "20001013"},
+		},
+		{
+			name: "20th Monday of the year", rule: "FREQ=YEARLY;BYDAY=20MO", start: "19970519T090000", n: 3,
+			want: []string{"19970519", "19980518", "19990517"},
+		},
+		{
+			name: "US presidential election day", start: "19961105T090000", n: 3,
+			rule: "FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8",
+			want: []string{"19961105", "20001107", "20041102"},
+		},
+		{
+			name: "15th and 30th", rule: "FREQ=MONTHLY;BYMONTHDAY=15,30;COUNT=5", start: "20070115T090000", n: 10,
+			want: []string{"20070115", "20070130", "20070215", "20070315", "20070330"},
+		},
+		{
+			name: "31st skips short months", rule: "FREQ=MONTHLY", start: "20240131T090000", n: 4,
+			want: []string{"20240131", "20240331", "20240531", "20240731"},
+		},
+		{
+			name: "last day of the month", rule: "FREQ=MONTHLY;BYMONTHDAY=-1", start: "20240131T090000", n: 4,
+			want: []string{"20240131", "20240229", "20240331", "20240430"},
+		},
+		{
+			name: "29th of February", rule: "FREQ=YEARLY", start: "20240229T090000", n: 3,
+			want: []string{"20240229", "20280229", "20320229"},
+		},
+		{
+			name: "leap day across a century", rule: "FREQ=YEARLY;INTERVAL=100", start: "20000229T090000", n: 2,
+			want: []string{"20000229", "24000229"},
+		},
+		{
+			name: "start outside the rule", rule: "FREQ=WEEKLY;BYDAY=MO;COUNT=3", start: "20240103T090000", n: 10,
+			want: []string{"20240103", "20240108", "20240115"},
+		},
+		{
+			name: "never again", rule: "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", start: "20240101T090000", n: 3,
+			want: []string{"20240101"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := occurrences(t, tt.rule, tt.start, ny, tt.n, func(o time.Time) string {
+				if o.Location() != ny || o.Hour() != 9 {
+					t.Errorf("occurrence %v is not at 09:00 in New York", o)
+				}
+				return o.Format("20060102")
+			})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("occurrences = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOccurrences_DST checks that occurrences keep their wall-clock time
+// across daylight saving changes, including the times the changes skip and
+// repeat.
+func TestOccurrences_DST(t *testing.T) {
+	berlin := load(t, "Europe/Berlin")
+	ny := load(t, "America/New_York")
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		loc   *time.Location
+		want  []string
+	}{
+		{
+			name: "spring forward", rule: "FREQ=DAILY", start: "20240330T090000", loc: berlin,
+			want: []string{"2024-03-30T08:00:00Z", "2024-03-31T07:00:00Z", "2024-04-01T07:00:00Z"},
+		},
+		{
+			name: "fall back", rule: "FREQ=DAILY", start: "20241026T090000", loc: berlin,
+			want: []string{"2024-10-26T07:00:00Z", "2024-10-27T08:00:00Z", "2024-10-28T08:00:00Z"},
+		},
+		{
+			name: "skipped time in Berlin", rule: "FREQ=DAILY", start: "20240330T023000", loc: berlin,
+			want: []string{"2024-03-30T01:30:00Z", "2024-03-31T01:30:00Z", "2024-04-01T00:30:00Z"},
+		},
+		{
+			name: "repeated time in Berlin", rule: "FREQ=DAILY", start: "20241026T023000", loc: berlin,
+			want: []string{"2024-10-26T00:30:00Z", "2024-10-27T00:30:00Z", "2024-10-28T01:30:00Z"},
+		},
+		{
+			name: "skipped time in New York", rule: "FREQ=DAILY", start: "20240309T023000", loc: ny,
+			want: []string{"2024-03-09T07:30:00Z", "2024-03-10T07:30:00Z", "2024-03-11T06:30:00Z"},
+		},
+		{
+			name: "repeated time in New York", rule: "FREQ=DAILY", start: "20241102T013000", loc: ny,
+			want: []string{"2024-11-02T05:30:00Z", "2024-11-03T05:30:00Z", "2024-11-04T06:30:00Z"},
+		},
+		{
+			name: "weekly across the change", rule: "FREQ=WEEKLY", start: "20241027T090000", loc: ny,
+			want: []string{"2024-10-27T13:00:00Z", "2024-11-03T14:00:00Z", "2024-11-10T14:00:00Z"},
+		},
+		{
+			name: "monthly across the change", rule: "FREQ=MONTHLY;BYDAY=-1SU", start: "20240225T023000", loc: berlin,
+			want: []string{"2024-02-25T01:30:00Z", "2024-03-31T01:30:00Z", "2024-04-28T00:30:00Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := occurrences(t, tt.rule, tt.start, tt.loc, len(tt.want)+1, func(o time.Time) string {
+				return o.UTC().Format(time.RFC3339)
+			})
+			if !slices.Equal(got[:len(tt.want)], tt.want) {
+				t.Errorf("occurrences = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	berlin := load(t, "Europe/Berlin")
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, berlin)
+	r, err := Parse("FREQ=MONTHLY;COUNT=3")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	next, ok := r.Next(start, start)
+	if want := time.Date(2024, time.February, 1, 9, 0, 0, 0, berlin); !ok || !next.Equal(want) {
+		t.Errorf("Next(start) = %v, %v, want %v", next, ok, want)
+	}
+	next, ok = r.Next(start, time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2024, time.March, 1, 9, 0, 0, 0, berlin); !ok || !next.Equal(want) {
+		t.Errorf("Next(mid February) = %v, %v, want %v", next, ok, want)
+	}
+	if next, ok = r.Next(start, next); ok {
+		t.Errorf("Next(last) = %v, want the rule to have ended", next)
+	}
+	if got := r.Occurrences(start, start, 0); len(got) != 0 {
+		t.Errorf("Occurrences(0) = %v, want none", got)
+	}
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// attachmentColumns lists the columns selected for an attachment, in the
+// order scanAttachment expects. Orphaned attachments have a TodoID of 0.
+const attachmentColumns = `id, COALESCE(todo_id, 0), filename, content_type, size, storage_key, created_at`
+
+// scanAttachment reads a single attachment row selected with
+// attachmentColumns.
+func scanAttachment(row pgx.Row) (domain.Attachment, error) {
+	var a domain.Attachment
+	err := row.Scan(
+		&a.ID,
+		&a.TodoID,
+		&a.Filename,
+		&a.ContentType,
+		&a.Size,
+		&a.Key,
+		&a.CreatedAt,
+	)
+	return a, err
+}
+
+type AttachmentRepositoryPg struct {
+	db *pgxpool.Pool
+}
+
+// NewAttachmentRepository creates a new attachment repository.
+func NewAttachmentRepository(db *pgxpool.Pool) *AttachmentRepositoryPg {
+	return &AttachmentRepositoryPg{db: db}
+}
+
+// Create inserts a new attachment and returns it as stored.
+// domain.ErrTodoNotFound is returned if the todo does not exist.
+func (r *AttachmentRepositoryPg) Create(ctx context.Context, a domain.Attachment) (*domain.Attachment, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		INSERT INTO todo_attachments (todo_id, filename, content_type, size, storage_key)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + attachmentColumns
+
+	created, err := scanAttachment(r.db.QueryRow(ctx, query, a.TodoID, a.Filename, a.ContentType, a.Size, a.Key))
+	if isForeignKeyViolation(err, "todo_attachments_todo_id_fkey") {
+		log.Warn("todo not found for attachment", zap.Int("todo_id", a.TodoID))
+		return nil, domain.ErrTodoNotFound
+	}
+	if err != nil {
+		log.Error("failed to insert attachment", zap.Error(err))
+		return nil, err
+	}
+
+	log.Info("attachment created", zap.Int("todo_id", a.TodoID), zap.Int("id", created.ID))
+	return &created, nil
+}
+
+// GetByID retrieves an attachment of todo todoID by its ID.
+func (r *AttachmentRepositoryPg) GetByID(ctx context.Context, todoID, id int) (*domain.Attachment, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		SELECT ` + attachmentColumns + `
+		FROM todo_attachments
+		WHERE id = $1 AND todo_id = $2
+	`
+
+	a, err := scanAttachment(r.db.QueryRow(ctx, query, id, todoID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Warn("attachment not found", zap.Int("todo_id", todoID), zap.Int("id", id))
+		return nil, domain.ErrAttachmentNotFound
+	}
+	if err != nil {
+		log.Error("failed to fetch attachment", zap.Error(err))
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+// List retrieves the attachments of a todo, oldest first.
+func (r *AttachmentRepositoryPg) List(ctx context.Context, todoID int) ([]domain.Attachment, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		SELECT ` + attachmentColumns + `
+		FROM todo_attachments
+		WHERE todo_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.Query(ctx, query, todoID)
+	if err != nil {
+		log.Error("failed to query attachments", zap.Error(err))
+		return nil, err
+	}
+
+	attachments, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Attachment, error) {
+		return scanAttachment(row)
+	})
+	if err != nil {
+		log.Error("failed to scan attachment rows", zap.Error(err))
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// Detach takes an attachment away from todo todoID, leaving it orphaned
+// until its file is deleted, and returns it.
+func (r *AttachmentRepositoryPg) Detach(ctx context.Context, todoID, id int) (*domain.Attachment, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		UPDATE todo_attachments
+		SET todo_id = NULL
+		WHERE id = $1 AND todo_id = $2
+		RETURNING ` + attachmentColumns
+
+	a, err := scanAttachment(r.db.QueryRow(ctx, query, id, todoID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Warn("attachment not found for detach", zap.Int("todo_id", todoID), zap.Int("id", id))
+		return nil, domain.ErrAttachmentNotFound
+	}
+	if err != nil {
+		log.Error("failed to detach attachment", zap.Error(err))
+		return nil, err
+	}
+
+	log.Info("attachment detached", zap.Int("todo_id", todoID), zap.Int("id", id))
+	return &a, nil
+}
+
+// Orphans retrieves up to limit attachments that belong to no todo.
+func (r *AttachmentRepositoryPg) Orphans(ctx context.Context, limit int) ([]domain.Attachment, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		SELECT ` + attachmentColumns + `
+		FROM todo_attachments
+		WHERE todo_id IS NULL
+		ORDER BY id
+		LIMIT $1
+	`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		log.Error("failed to query orphaned attachments", zap.Error(err))
+		return nil, err
+	}
+
+	attachments, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Attachment, error) {
+		return scanAttachment(row)
+	})
+	if err != nil {
+		log.Error("failed to scan attachment rows", zap.Error(err))
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// DeleteOrphan removes an attachment that belongs to no todo. Deleting one
+// that is already gone is not an error.
+func (r *AttachmentRepositoryPg) DeleteOrphan(ctx context.Context, id int) error {
+	log := logger.FromContext(ctx)
+
+	const query = `DELETE FROM todo_attachments WHERE id = $1 AND todo_id IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		log.Error("failed to delete orphaned attachment", zap.Error(err))
+		return err
+	}
+
+	log.Debug("orphaned attachment deleted", zap.Int("id", id))
+	return nil
+}
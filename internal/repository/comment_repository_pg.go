@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// commentColumns lists the columns selected for a comment, in the order
+// scanComment expects.
+const commentColumns = `id, todo_id, author, body, created_at, updated_at`
+
+// scanComment reads a single comment row selected with commentColumns.
+func scanComment(row pgx.Row) (domain.Comment, error) {
+	var c domain.Comment
+	err := row.Scan(
+		&c.ID,
+		&c.TodoID,
+		&c.Author,
+		&c.Body,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	return c, err
+}
+
+type CommentRepositoryPg struct {
+	db *pgxpool.Pool
+}
+
+// NewCommentRepository creates a new comment repository.
+func NewCommentRepository(db *pgxpool.Pool) *CommentRepositoryPg {
+	return &CommentRepositoryPg{db: db}
+}
+
+// Create inserts a new comment and returns it as stored.
+// domain.ErrTodoNotFound is returned if the todo does not exist.
+func (r *CommentRepositoryPg) Create(ctx context.Context, c domain.Comment) (*domain.Comment, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		INSERT INTO todo_comments (todo_id, author, body)
+		VALUES ($1, $2, $3)
+		RETURNING ` + commentColumns
+
+	created, err := scanComment(r.db.QueryRow(ctx, query, c.TodoID, c.Author, c.Body))
+	if isForeignKeyViolation(err, "todo_comments_todo_id_fkey") {
+		log.Warn("todo not found for comment", zap.Int("todo_id", c.TodoID))
+		return nil, domain.ErrTodoNotFound
+	}
+	if err != nil {
+		log.Error("failed to insert comment", zap.Error(err))
+		return nil, err
+	}
+
+	log.Info("comment created", zap.Int("todo_id", c.TodoID), zap.Int("id", created.ID))
+	return &created, nil
+}
+
+// GetByID retrieves a comment on todo todoID by its ID.
+func (r *CommentRepositoryPg) GetByID(ctx context.Context, todoID, id int) (*domain.Comment, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		SELECT ` + commentColumns + `
+		FROM todo_comments
+		WHERE id = $1 AND todo_id = $2
+	`
+
+	c, err := scanComment(r.db.QueryRow(ctx, query, id, todoID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Warn("comment not found", zap.Int("todo_id", todoID), zap.Int("id", id))
+		return nil, domain.ErrCommentNotFound
+	}
+	if err != nil {
+		log.Error("failed to fetch comment", zap.Error(err))
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// ListPage retrieves a single keyset page of the comments on a todo,
+// ordered by creation time with the ID breaking ties. Pages before a
+// cursor are read backwards and reversed, so the result is always oldest
+// first.
+func (r *CommentRepositoryPg) ListPage(ctx context.Context, q domain.CommentPageQuery) ([]domain.Comment, error) {
+	log := logger.FromContext(ctx)
+
+	query := `
+		SELECT ` + commentColumns + `
+		FROM todo_comments
+		WHERE todo_id = $1
+		ORDER BY created_at, id
+		LIMIT $2
+	`
+	args := []any{q.TodoID, q.Limit}
+
+	switch {
+	case q.After != nil:
+		query = `
+			SELECT ` + commentColumns + `
+			FROM todo_comments
+			WHERE todo_id = $1 AND (created_at, id) > ($3, $4)
+			ORDER BY created_at, id
+			LIMIT $2
+		`
+		args = append(args, q.After.CreatedAt, q.After.ID)
+	case q.Before != nil:
+		query = `
+			SELECT ` + commentColumns + `
+			FROM todo_comments
+			WHERE todo_id = $1 AND (created_at, id) < ($3, $4)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		`
+		args = append(args, q.Before.CreatedAt, q.Before.ID)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		log.Error("failed to query comments", zap.Error(err))
+		return nil, err
+	}
+
+	comments, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Comment, error) {
+		return scanComment(row)
+	})
+	if err != nil {
+		log.Error("failed to scan comment rows", zap.Error(err))
+		return nil, err
+	}
+
+	if q.Before != nil {
+		slices.Reverse(comments)
+	}
+	return comments, nil
+}
+
+// Update replaces the body of a comment, records when it was edited and
+// returns the updated row.
+func (r *CommentRepositoryPg) Update(ctx context.Context, c domain.Comment) (*domain.Comment, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		UPDATE todo_comments
+		SET body       = $3,
+		    updated_at = NOW()
+		WHERE id = $1 AND todo_id = $2
+		RETURNING ` + commentColumns
+
+	updated, err := scanComment(r.db.QueryRow(ctx, query, c.ID, c.TodoID, c.Body))
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Warn("comment not found for update", zap.Int("todo_id", c.TodoID), zap.Int("id", c.ID))
+		return nil, domain.ErrCommentNotFound
+	}
+	if err != nil {
+		log.Error("failed to update comment", zap.Error(err))
+		return nil, err
+	}
+
+	log.Info("comment updated", zap.Int("todo_id", c.TodoID), zap.Int("id", c.ID))
+	return &updated, nil
+}
+
+// Delete removes a comment on todo todoID by its ID.
+func (r *CommentRepositoryPg) Delete(ctx context.Context, todoID, id int) error {
+	log := logger.FromContext(ctx)
+
+	const query = `DELETE FROM todo_comments WHERE id = $1 AND todo_id = $2`
+
+	res, err := r.db.Exec(ctx, query, id, todoID)
+	if err != nil {
+		log.Error("failed to delete comment", zap.Error(err))
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		log.Warn("comment not found for delete", zap.Int("todo_id", todoID), zap.Int("id", id))
+		return domain.ErrCommentNotFound
+	}
+
+	log.Info("comment deleted", zap.Int("todo_id", todoID), zap.Int("id", id))
+	return nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// maxReserveAttempts bounds how often Reserve retries when the record
+// holding a key disappears between the insert and the select.
+const maxReserveAttempts = 3
+
+// IdempotencyStorePg stores idempotency records in the idempotency_keys
+// table, so that they are shared by every instance of the server.
+type IdempotencyStorePg struct {
+	db *pgxpool.Pool
+}
+
+// NewIdempotencyStore creates a new Postgres-backed idempotency store.
+func NewIdempotencyStore(db *pgxpool.Pool) *IdempotencyStorePg {
+	return &IdempotencyStorePg{db: db}
+}
+
+// Reserve claims key for a request, unless an unexpired record already
+// holds it, in which case that record is returned. Expired records are
+// taken over in the same statement, so concurrent requests with the same
+// key cannot both reserve it.
+func (s *IdempotencyStorePg) Reserve(
+	ctx context.Context, key, requestHash string, ttl time.Duration,
+) (*domain.IdempotencyRecord, error) {
+	log := logger.FromContext(ctx)
+
+	const reserve = `
+		INSERT INTO idempotency_keys (key, request_hash, expires_at)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3))
+		ON CONFLICT (key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		    status_code  = 0,
+		    header       = '{}',
+		    body         = NULL,
+		    created_at   = NOW(),
+		    expires_at   = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		RETURNING key
+	`
+
+	const existing = `
+		SELECT key, request_hash, status_code, header, body, expires_at
+		FROM idempotency_keys
+		WHERE key = $1 AND expires_at > NOW()
+	`
+
+	for range maxReserveAttempts {
+		var reserved string
+		err := s.db.QueryRow(ctx, reserve, key, requestHash, ttl.Seconds()).Scan(&reserved)
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Error("failed to reserve idempotency key", zap.Error(err))
+			return nil, err
+		}
+
+		var rec domain.IdempotencyRecord
+		err = s.db.QueryRow(ctx, existing, key).Scan(
+			&rec.Key,
+			&rec.RequestHash,
+			&rec.StatusCode,
+			&rec.Header,
+			&rec.Body,
+			&rec.ExpiresAt,
+		)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Released or expired in the meantime; try to reserve it again
+			continue
+		}
+		if err != nil {
+			log.Error("failed to fetch idempotency record", zap.Error(err))
+			return nil, err
+		}
+		return &rec, nil
+	}
+
+	log.Error("failed to reserve idempotency key", zap.String("key", key))
+	return nil, errors.New("idempotency key changed hands too often to be reserved")
+}
+
+// Complete stores the response to the request that reserved the key.
+func (s *IdempotencyStorePg) Complete(ctx context.Context, rec domain.IdempotencyRecord) error {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		UPDATE idempotency_keys
+		SET status_code = $3,
+		    header      = $4,
+		    body        = $5
+		WHERE key = $1 AND request_hash = $2 AND status_code = 0
+	`
+
+	header := rec.Header
+	if header == nil {
+		header = map[string]string{}
+	}
+
+	_, err := s.db.Exec(ctx, query, rec.Key, rec.RequestHash, rec.StatusCode, header, rec.Body)
+	if err != nil {
+		log.Error("failed to store idempotent response", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// Release drops the reservation of a key whose response was not stored.
+func (s *IdempotencyStorePg) Release(ctx context.Context, key string) error {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		DELETE FROM idempotency_keys
+		WHERE key = $1 AND status_code = 0
+	`
+
+	if _, err := s.db.Exec(ctx, query, key); err != nil {
+		log.Error("failed to release idempotency key", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExpired removes expired records and returns how many were removed.
+func (s *IdempotencyStorePg) DeleteExpired(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		DELETE FROM idempotency_keys
+		WHERE expires_at <= NOW()
+	`
+
+	res, err := s.db.Exec(ctx, query)
+	if err != nil {
+		log.Error("failed to delete expired idempotency keys", zap.Error(err))
+		return 0, err
+	}
+
+	return int(res.RowsAffected()), nil
+}
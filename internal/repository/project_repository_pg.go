@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// projectColumns lists the columns selected for a project, in the order
+// scanProject expects. The todo count is computed with a correlated
+// subquery on idx_todos_project_id, so todos are counted, never loaded.
+// Todos in the trash are not counted.
+const projectColumns = `id, name, description, created_at,
+	(SELECT COUNT(*) FROM todos t WHERE t.project_id = projects.id AND t.deleted_at IS NULL) AS todo_count`
+
+// scanProject reads a single project row selected with projectColumns.
+func scanProject(row pgx.Row) (domain.Project, error) {
+	var p domain.Project
+	err := row.Scan(
+		&p.ID,
+		&p.Name,
+		&p.Description,
+		&p.CreatedAt,
+		&p.TodoCount,
+	)
+	return p, err
+}
+
+type ProjectRepositoryPg struct {
+	db *pgxpool.Pool
+}
+
+// NewProjectRepository creates a new project repository.
+func NewProjectRepository(db *pgxpool.Pool) *ProjectRepositoryPg {
+	return &ProjectRepositoryPg{db: db}
+}
+
+// Create inserts a new project and returns its generated ID.
+func (r *ProjectRepositoryPg) Create(ctx context.Context, p domain.Project) (int, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		INSERT INTO projects (name, description)
+		VALUES ($1, $2)
+		RETURNING id
+	`
+
+	var id int
+	err := r.db.QueryRow(ctx, query, p.Name, p.Description).Scan(&id)
+	if err != nil {
+		log.Error("failed to insert project", zap.Error(err))
+		return 0, err
+	}
+
+	log.Info("project created", zap.Int("id", id))
+	return id, nil
+}
+
+// GetByID retrieves a project by its ID.
+func (r *ProjectRepositoryPg) GetByID(ctx context.Context, id int) (*domain.Project, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		SELECT ` + projectColumns + `
+		FROM projects
+		WHERE id = $1
+	`
+
+	p, err := scanProject(r.db.QueryRow(ctx, query, id))
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Warn("project not found", zap.Int("id", id))
+		return nil, domain.ErrProjectNotFound
+	}
+
+	if err != nil {
+		log.Error("failed to fetch project", zap.Error(err))
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// List retrieves all projects ordered by ID.
+func (r *ProjectRepositoryPg) List(ctx context.Context) ([]domain.Project, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		SELECT ` + projectColumns + `
+		FROM projects
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		log.Error("failed to query projects", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := make([]domain.Project, 0)
+
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			log.Error("failed to scan project row", zap.Error(err))
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+
+	if rows.Err() != nil {
+		log.Error("rows error", zap.Error(rows.Err()))
+		return nil, rows.Err()
+	}
+
+	return projects, nil
+}
+
+// Update replaces the name and description of a project and returns the
+// updated row.
+func (r *ProjectRepositoryPg) Update(ctx context.Context, p domain.Project) (*domain.Project, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		UPDATE projects
+		SET name        = $2,
+		    description = $3
+		WHERE id = $1
+		RETURNING ` + projectColumns
+
+	updated, err := scanProject(r.db.QueryRow(ctx, query, p.ID, p.Name, p.Description))
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Warn("project not found for update", zap.Int("id", p.ID))
+		return nil, domain.ErrProjectNotFound
+	}
+
+	if err != nil {
+		log.Error("failed to update project", zap.Error(err))
+		return nil, err
+	}
+
+	log.Info("project updated", zap.Int("id", p.ID))
+	return &updated, nil
+}
+
+// Delete removes a project by ID. Nothing is deleted for good: with
+// cascade the todos of the project are moved to the trash first, together
+// with their subtasks, as if each had been deleted. Todos of the project
+// that are in the trash are kept there, taken out of the project, so that
+// they can still be restored until they are purged. Unless cascade is set,
+// a project that still has todos outside the trash is left in place and
+// domain.ErrProjectNotEmpty is returned; the foreign key makes this safe
+// against concurrent inserts.
+func (r *ProjectRepositoryPg) Delete(ctx context.Context, id int, cascade bool) error {
+	log := logger.FromContext(ctx)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op once committed
+
+	if cascade {
+		// NOW() is the same for the whole transaction, so every todo trashed
+		// here is restored along with its parent
+		const trash = `
+			WITH RECURSIVE tree AS (
+				SELECT id
+				FROM todos
+				WHERE project_id = $1 AND deleted_at IS NULL
+				UNION
+				SELECT t.id
+				FROM todos t
+				JOIN tree ON t.parent_id = tree.id
+				WHERE t.deleted_at IS NULL
+			)
+			UPDATE todos
+			SET deleted_at = NOW(),
+			    version    = version + 1
+			WHERE id IN (SELECT id FROM tree)
+		`
+
+		res, err := tx.Exec(ctx, trash, id)
+		if err != nil {
+			log.Error("failed to move project todos to trash", zap.Error(err))
+			return err
+		}
+		if res.RowsAffected() > 0 {
+			log.Info("project todos moved to trash", zap.Int("id", id), zap.Int64("count", res.RowsAffected()))
+		}
+	}
+
+	const detach = `
+		UPDATE todos
+		SET project_id = NULL,
+		    version    = version + 1
+		WHERE project_id = $1 AND deleted_at IS NOT NULL
+	`
+
+	if _, err := tx.Exec(ctx, detach, id); err != nil {
+		log.Error("failed to detach trashed todos from project", zap.Error(err))
+		return err
+	}
+
+	res, err := tx.Exec(ctx, `DELETE FROM projects WHERE id = $1`, id)
+	if isForeignKeyViolation(err, "todos_project_id_fkey") {
+		log.Warn("project still has todos", zap.Int("id", id))
+		return domain.ErrProjectNotEmpty
+	}
+	if err != nil {
+		log.Error("failed to delete project", zap.Error(err))
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		log.Warn("project not found for delete", zap.Int("id", id))
+		return domain.ErrProjectNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit project delete", zap.Error(err))
+		return err
+	}
+
+	log.Info("project deleted", zap.Int("id", id))
+	return nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// ListByIDs retrieves the todos with the given IDs, ordered by ID. Todos
+// that do not exist or are in the trash are left out.
+func (r *TodoRepositoryPg) ListByIDs(ctx context.Context, ids []int) ([]domain.Todo, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE id = ANY($1) AND deleted_at IS NULL
+		ORDER BY id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, ids)
+	if err != nil {
+		log.Error("failed to query todos by id", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := make([]domain.Todo, 0, len(ids))
+
+	for rows.Next() {
+		t, err := scanTodo(rows)
+		if err != nil {
+			log.Error("failed to scan todo row", zap.Error(err))
+			return nil, err
+		}
+		todos = append(todos, t)
+	}
+
+	if rows.Err() != nil {
+		log.Error("rows error", zap.Error(rows.Err()))
+		return nil, rows.Err()
+	}
+
+	if err := loadTags(ctx, r.conn(ctx), todos); err != nil {
+		log.Error("failed to load todo tags", zap.Error(err))
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// AddDependency records that a todo is blocked by another. It reports
+// whether the dependency is new; adding one that exists changes nothing.
+func (r *TodoRepositoryPg) AddDependency(ctx context.Context, d domain.Dependency) (bool, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		INSERT INTO todo_dependencies (todo_id, blocked_by_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	tag, err := r.conn(ctx).Exec(ctx, query, d.TodoID, d.BlockedByID)
+	if isForeignKeyViolation(err, "todo_dependencies_todo_id_fkey") {
+		log.Warn("todo not found for dependency", zap.Int("id", d.TodoID))
+		return false, domain.ErrTodoNotFound
+	}
+	if isForeignKeyViolation(err, "todo_dependencies_blocked_by_id_fkey") {
+		log.Warn("blocker not found for dependency", zap.Int("blocked_by_id", d.BlockedByID))
+		return false, domain.ErrBlockerNotFound
+	}
+	if err != nil {
+		log.Error("failed to insert dependency", zap.Error(err))
+		return false, err
+	}
+
+	created := tag.RowsAffected() == 1
+	if created {
+		log.Info("dependency added", zap.Int("id", d.TodoID), zap.Int("blocked_by_id", d.BlockedByID))
+	}
+	return created, nil
+}
+
+// RemoveDependency deletes a dependency.
+func (r *TodoRepositoryPg) RemoveDependency(ctx context.Context, d domain.Dependency) error {
+	log := logger.FromContext(ctx)
+
+	const query = `DELETE FROM todo_dependencies WHERE todo_id = $1 AND blocked_by_id = $2`
+
+	tag, err := r.conn(ctx).Exec(ctx, query, d.TodoID, d.BlockedByID)
+	if err != nil {
+		log.Error("failed to delete dependency", zap.Error(err))
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		log.Warn("dependency not found", zap.Int("id", d.TodoID), zap.Int("blocked_by_id", d.BlockedByID))
+		return domain.ErrDependencyNotFound
+	}
+
+	log.Info("dependency removed", zap.Int("id", d.TodoID), zap.Int("blocked_by_id", d.BlockedByID))
+	return nil
+}
+
+// Dependencies returns the dependencies of the todos with the given IDs,
+// which name the todos blocking them. Blockers in the trash are included.
+func (r *TodoRepositoryPg) Dependencies(ctx context.Context, ids []int) ([]domain.Dependency, error) {
+	const query = `
+		SELECT todo_id, blocked_by_id
+		FROM todo_dependencies
+		WHERE todo_id = ANY($1)
+		ORDER BY todo_id, blocked_by_id
+	`
+	return r.queryDependencies(ctx, query, ids)
+}
+
+// Dependents returns the dependencies on the todos with the given IDs,
+// which name the todos they block. Blocked todos in the trash are included.
+func (r *TodoRepositoryPg) Dependents(ctx context.Context, ids []int) ([]domain.Dependency, error) {
+	const query = `
+		SELECT todo_id, blocked_by_id
+		FROM todo_dependencies
+		WHERE blocked_by_id = ANY($1)
+		ORDER BY blocked_by_id, todo_id
+	`
+	return r.queryDependencies(ctx, query, ids)
+}
+
+func (r *TodoRepositoryPg) queryDependencies(ctx context.Context, query string,
+	ids []int) ([]domain.Dependency, error) {
+	log := logger.FromContext(ctx)
+
+	rows, err := r.conn(ctx).Query(ctx, query, ids)
+	if err != nil {
+		log.Error("failed to query dependencies", zap.Error(err))
+		return nil, err
+	}
+
+	deps, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Dependency, error) {
+		var d domain.Dependency
+		err := row.Scan(&d.TodoID, &d.BlockedByID)
+		return d, err
+	})
+	if err != nil {
+		log.Error("failed to scan dependencies", zap.Error(err))
+		return nil, err
+	}
+
+	return deps, nil
+}
+
+// LockDependencies keeps other transactions from changing dependencies
+// until the transaction it is called in ends, so that a change can be
+// checked against the graph as it stands. It has no lasting effect outside
+// InTx.
+func (r *TodoRepositoryPg) LockDependencies(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+
+	if _, err := r.conn(ctx).Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('todo_dependencies'))`); err != nil {
+		log.Error("failed to lock dependencies", zap.Error(err))
+		return err
+	}
+	return nil
+}
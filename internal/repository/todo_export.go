@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// exportBatchSize is how many todos are fetched from the export cursor at
+// a time. Only one batch is held in memory.
+const exportBatchSize = 500
+
+// Export calls fn for every todo matching q, in list order. The todos are
+// read through a cursor in a read-only REPEATABLE READ transaction of its
+// own, so that they form one consistent snapshot however long the export
+// takes, and tags are loaded a batch at a time within that snapshot.
+// Canceling ctx aborts the export. An error returned by fn stops the export
+// and is returned as is.
+func (r *TodoRepositoryPg) Export(ctx context.Context, q domain.TodoQuery, fn func(domain.Todo) error) error {
+	log := logger.FromContext(ctx)
+
+	keys, err := orderKeys(q.Sort)
+	if err != nil {
+		log.Error("invalid todo sort order", zap.Error(err))
+		return err
+	}
+
+	var b todoQueryBuilder
+	b.filter(q.Filter)
+
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		log.Error("failed to begin export transaction", zap.Error(err))
+		return err
+	}
+	// Nothing is written, so the transaction is only ever rolled back
+	defer tx.Rollback(ctx) //nolint:errcheck // the snapshot is released either way
+
+	query := `
+		DECLARE todo_export NO SCROLL CURSOR FOR
+		SELECT ` + todoColumns + `
+		FROM todos
+		` + b.where() + `
+		` + orderBy(keys, false)
+
+	if _, err := tx.Exec(ctx, query, b.args...); err != nil {
+		log.Error("failed to declare export cursor", zap.Error(err))
+		return err
+	}
+
+	for {
+		todos, err := fetchExportBatch(ctx, tx)
+		if err != nil {
+			log.Error("failed to fetch todos to export", zap.Error(err))
+			return err
+		}
+
+		for _, t := range todos {
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
+
+		if len(todos) < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+// fetchExportBatch reads the next batch of todos from the export cursor
+// together with their tags.
+func fetchExportBatch(ctx context.Context, tx pgx.Tx) ([]domain.Todo, error) {
+	rows, err := tx.Query(ctx, "FETCH FORWARD "+strconv.Itoa(exportBatchSize)+" FROM todo_export")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := make([]domain.Todo, 0, exportBatchSize)
+	for rows.Next() {
+		t, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// The connection is busy until the rows are closed
+	rows.Close()
+
+	if err := loadTags(ctx, tx, todos); err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// Import inserts todos together with their tags in a single transaction.
+// The series of recurring todos must exist already. Todos are appended to
+// the manual order in the order given.
+//
+// Rows are loaded with COPY, which is far faster than one INSERT per todo.
+// COPY cannot cast text to the status and priority enums, so todos are
+// first copied into temporary staging tables with plain columns and moved
+// into place from there. IDs are taken from the todos sequence up front so
+// that tags can be staged against the todos they belong to, and positions
+// are taken the same way so that they follow the order of todos.
+func (r *TodoRepositoryPg) Import(ctx context.Context, todos []domain.Todo) error {
+	log := logger.FromContext(ctx)
+
+	if len(todos) == 0 {
+		return nil
+	}
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op once committed
+
+	ids, err := reserveTodoIDs(ctx, tx, len(todos))
+	if err != nil {
+		log.Error("failed to reserve todo ids", zap.Error(err))
+		return err
+	}
+
+	positions, err := reserveTodoPositions(ctx, tx, len(todos))
+	if err != nil {
+		log.Error("failed to reserve todo positions", zap.Error(err))
+		return err
+	}
+
+	if err := stageTodos(ctx, tx, ids, positions, todos); err != nil {
+		log.Error("failed to stage imported todos", zap.Error(err))
+		return err
+	}
+
+	const insertTodos = `
+		INSERT INTO todos (id, title, description, status, priority, created_at, due_at, remind_at, project_id,
+		                   parent_id, series_id, position)
+		SELECT id, title, description, status::todo_status, priority::todo_priority, COALESCE(created_at, NOW()),
+		       due_at, remind_at, project_id, parent_id, series_id, position
+		FROM todo_import
+		ORDER BY id
+	`
+
+	_, err = tx.Exec(ctx, insertTodos)
+	if isForeignKeyViolation(err, "todos_project_id_fkey") {
+		log.Warn("project not found for imported todo", zap.Error(err))
+		return domain.ErrProjectNotFound
+	}
+	if isForeignKeyViolation(err, "todos_parent_id_fkey") {
+		log.Warn("parent not found for imported todo", zap.Error(err))
+		return domain.ErrParentNotFound
+	}
+	if err != nil {
+		log.Error("failed to insert imported todos", zap.Error(err))
+		return err
+	}
+
+	if err := importTags(ctx, tx, ids, todos); err != nil {
+		log.Error("failed to tag imported todos", zap.Error(err))
+		return err
+	}
+
+	// The staging tables go away on commit, but only once the outermost
+	// transaction commits; drop them now so that another import in the same
+	// transaction can create them again.
+	if _, err := tx.Exec(ctx, `DROP TABLE todo_import, todo_import_tags`); err != nil {
+		log.Error("failed to drop import staging tables", zap.Error(err))
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit import", zap.Error(err))
+		return err
+	}
+
+	log.Info("todos imported", zap.Int("count", len(todos)))
+	return nil
+}
+
+// reserveTodoIDs takes n IDs from the todos sequence.
+func reserveTodoIDs(ctx context.Context, q querier, n int) ([]int, error) {
+	const query = `
+		SELECT nextval(pg_get_serial_sequence('todos', 'id'))
+		FROM generate_series(1, $1)
+	`
+
+	rows, err := q.Query(ctx, query, n)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// reserveTodoPositions takes n positions from the sequence new todos are
+// appended with.
+func reserveTodoPositions(ctx context.Context, q querier, n int) ([]float64, error) {
+	const query = `
+		SELECT nextval('todos_position_seq')::double precision
+		FROM generate_series(1, $1)
+	`
+
+	rows, err := q.Query(ctx, query, n)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[float64])
+}
+
+// stageTodos creates the staging tables and copies todos into them, with
+// the given IDs and positions.
+func stageTodos(ctx context.Context, tx pgx.Tx, ids []int, positions []float64, todos []domain.Todo) error {
+	const createTables = `
+		CREATE TEMP TABLE todo_import (
+			id          INT         NOT NULL,
+			title       TEXT        NOT NULL,
+			description TEXT        NOT NULL,
+			status      TEXT        NOT NULL,
+			priority    TEXT        NOT NULL,
+			created_at  TIMESTAMP,
+			due_at      TIMESTAMPTZ,
+			remind_at   TIMESTAMPTZ,
+			project_id  INT,
+			parent_id   INT,
+			series_id   INT,
+			position    DOUBLE PRECISION NOT NULL
+		) ON COMMIT DROP;
+
+		CREATE TEMP TABLE todo_import_tags (
+			todo_id INT  NOT NULL,
+			name    TEXT NOT NULL
+		) ON COMMIT DROP;
+	`
+
+	if _, err := tx.Exec(ctx, createTables); err != nil {
+		return err
+	}
+
+	columns := []string{"id", "title", "description", "status", "priority", "created_at", "due_at", "remind_at",
+		"project_id", "parent_id", "series_id", "position"}
+
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{"todo_import"}, columns,
+		pgx.CopyFromSlice(len(todos), func(i int) ([]any, error) {
+			t := todos[i]
+
+			// Todos without a creation time get the default one on insert
+			var createdAt any
+			if !t.CreatedAt.IsZero() {
+				createdAt = t.CreatedAt
+			}
+
+			return []any{ids[i], t.Title, t.Description, string(t.Status), string(t.Priority), createdAt, t.DueAt, t.RemindAt,
+				t.ProjectID, t.ParentID, t.SeriesID, positions[i]}, nil
+		}),
+	)
+	return err
+}
+
+// importTags copies the tags of todos into their staging table and tags
+// the inserted todos, creating tags that do not exist yet.
+func importTags(ctx context.Context, tx pgx.Tx, ids []int, todos []domain.Todo) error {
+	var rows [][]any
+	for i, t := range todos {
+		for _, name := range t.Tags {
+			rows = append(rows, []any{ids[i], name})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"todo_import_tags"}, []string{"todo_id", "name"},
+		pgx.CopyFromRows(rows)); err != nil {
+		return err
+	}
+
+	const insertTags = `
+		INSERT INTO tags (name)
+		SELECT DISTINCT name FROM todo_import_tags
+		ON CONFLICT (name) DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, insertTags); err != nil {
+		return err
+	}
+
+	const linkTags = `
+		INSERT INTO todo_tags (todo_id, tag_id)
+		SELECT s.todo_id, tg.id
+		FROM todo_import_tags s
+		JOIN tags tg ON tg.name = s.name
+	`
+	_, err := tx.Exec(ctx, linkTags)
+	return err
+}
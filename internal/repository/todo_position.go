@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// PositionGap returns the gap todo id would be moved into to end up next
+// to the anchor: between the anchor and the todo following it, or the
+// todo preceding it. Todo id itself is skipped, and todos in the trash are
+// not, since they keep their positions. domain.ErrAnchorNotFound is
+// returned if the anchor does not exist or is in the trash.
+func (r *TodoRepositoryPg) PositionGap(ctx context.Context, id int, anchor domain.MoveAnchor) (domain.PositionGap,
+	error) {
+	log := logger.FromContext(ctx)
+
+	query := `
+		SELECT a.position,
+		       (SELECT position FROM todos WHERE position > a.position AND id <> $2 ORDER BY position LIMIT 1)
+		FROM todos a
+		WHERE a.id = $1 AND a.deleted_at IS NULL
+	`
+	if !anchor.After {
+		query = `
+			SELECT (SELECT position FROM todos WHERE position < a.position AND id <> $2 ORDER BY position DESC LIMIT 1),
+			       a.position
+			FROM todos a
+			WHERE a.id = $1 AND a.deleted_at IS NULL
+		`
+	}
+
+	var gap domain.PositionGap
+	err := r.conn(ctx).QueryRow(ctx, query, anchor.TodoID, id).Scan(&gap.After, &gap.Before)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Warn("anchor todo not found", zap.Int("anchor_id", anchor.TodoID))
+		return gap, domain.ErrAnchorNotFound
+	}
+	if err != nil {
+		log.Error("failed to query position gap", zap.Error(err))
+		return gap, err
+	}
+
+	return gap, nil
+}
+
+// SetPosition moves todo id to the given position, or to the end of the
+// list when position is nil. The version of the todo is left alone: its
+// position is not part of what clients see of it. domain.ErrPositionTaken
+// is returned if another todo already holds the position.
+func (r *TodoRepositoryPg) SetPosition(ctx context.Context, id int, position *float64) error {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		UPDATE todos
+		SET position = COALESCE($2::double precision, nextval('todos_position_seq'))
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	res, err := r.conn(ctx).Exec(ctx, query, id, position)
+	if isUniqueViolation(err, "todos_position_key") {
+		log.Warn("position already taken", zap.Int("id", id), zap.Float64p("position", position))
+		return domain.ErrPositionTaken
+	}
+	if err != nil {
+		log.Error("failed to set todo position", zap.Error(err))
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		log.Warn("todo not found for move", zap.Int("id", id))
+		return domain.ErrTodoNotFound
+	}
+
+	log.Info("todo moved", zap.Int("id", id))
+	return nil
+}
+
+// PositionsAround returns the positions of up to n todos at or before
+// position and up to n todos after it, in list order. Todos in the trash
+// are included.
+func (r *TodoRepositoryPg) PositionsAround(ctx context.Context, position float64, n int) ([]domain.TodoPosition,
+	error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		(SELECT id, position FROM todos WHERE position <= $1 ORDER BY position DESC LIMIT $2)
+		UNION ALL
+		(SELECT id, position FROM todos WHERE position > $1 ORDER BY position LIMIT $2)
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, position, n)
+	if err != nil {
+		log.Error("failed to query positions", zap.Error(err))
+		return nil, err
+	}
+
+	positions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.TodoPosition, error) {
+		var p domain.TodoPosition
+		err := row.Scan(&p.TodoID, &p.Position)
+		return p, err
+	})
+	if err != nil {
+		log.Error("failed to scan positions", zap.Error(err))
+		return nil, err
+	}
+
+	slices.SortFunc(positions, func(a, b domain.TodoPosition) int { return cmp.Compare(a.Position, b.Position) })
+	return positions, nil
+}
+
+// SetPositions moves every todo in positions to its new position in a
+// single statement, so that todos may trade places with each other along
+// the way. Only the rows of those todos are locked.
+func (r *TodoRepositoryPg) SetPositions(ctx context.Context, positions []domain.TodoPosition) error {
+	log := logger.FromContext(ctx)
+
+	ids := make([]int, 0, len(positions))
+	values := make([]float64, 0, len(positions))
+	for _, p := range positions {
+		ids = append(ids, p.TodoID)
+		values = append(values, p.Position)
+	}
+
+	const query = `
+		UPDATE todos
+		SET position = v.position
+		FROM unnest($1::int[], $2::double precision[]) AS v(id, position)
+		WHERE todos.id = v.id
+	`
+
+	if _, err := r.conn(ctx).Exec(ctx, query, ids, values); err != nil {
+		log.Error("failed to set todo positions", zap.Error(err))
+		return err
+	}
+
+	log.Info("todo positions spread out", zap.Int("count", len(positions)))
+	return nil
+}
+
+// LockPositions keeps others from changing positions until the
+// transaction it is called in ends. Moves take the lock shared, so that
+// they only wait while a stretch of the list is spread out, which takes it
+// exclusively. The table itself is never locked. It has no lasting effect
+// outside InTx.
+func (r *TodoRepositoryPg) LockPositions(ctx context.Context, exclusive bool) error {
+	log := logger.FromContext(ctx)
+
+	query := `SELECT pg_advisory_xact_lock_shared(hashtext('todo_positions'))`
+	if exclusive {
+		query = `SELECT pg_advisory_xact_lock(hashtext('todo_positions'))`
+	}
+
+	if _, err := r.conn(ctx).Exec(ctx, query); err != nil {
+		log.Error("failed to lock positions", zap.Error(err))
+		return err
+	}
+	return nil
+}
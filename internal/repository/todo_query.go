@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// sortColumns whitelists the columns todos may be ordered by. Sort fields
+// never reach SQL except through this map.
+var sortColumns = map[domain.SortField]string{
+	domain.SortByID:        "id",
+	domain.SortByTitle:     "title",
+	domain.SortByCompleted: "completed",
+	domain.SortByStatus:    "status",
+	domain.SortByPriority:  "priority",
+	domain.SortByCreatedAt: "created_at",
+	domain.SortByPosition:  "position",
+}
+
+// likeEscaper escapes LIKE wildcards so user input is matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// todoQueryBuilder assembles the WHERE and ORDER BY clauses of a todo
+// listing, collecting positional arguments as it goes.
+type todoQueryBuilder struct {
+	conds []string
+	args  []any
+}
+
+// arg registers a query argument and returns its placeholder.
+func (b *todoQueryBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// where renders the collected conditions as a WHERE clause.
+func (b *todoQueryBuilder) where() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conds, " AND ")
+}
+
+// filter adds the conditions of f. Todos in the trash are always left
+// out. Filtering on completed is a plain equality on the generated column
+// so that it can use idx_todos_completed.
+func (b *todoQueryBuilder) filter(f domain.TodoFilter) {
+	b.conds = append(b.conds, "deleted_at IS NULL")
+	if f.Completed != nil {
+		b.conds = append(b.conds, "completed = "+b.arg(*f.Completed))
+	}
+	if len(f.Statuses) > 0 {
+		placeholders := make([]string, 0, len(f.Statuses))
+		for _, status := range f.Statuses {
+			placeholders = append(placeholders, b.arg(string(status)))
+		}
+		b.conds = append(b.conds, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if len(f.Priorities) > 0 {
+		placeholders := make([]string, 0, len(f.Priorities))
+		for _, priority := range f.Priorities {
+			placeholders = append(placeholders, b.arg(string(priority)))
+		}
+		b.conds = append(b.conds, "priority IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if f.CreatedAfter != nil {
+		b.conds = append(b.conds, "created_at > "+b.arg(f.CreatedAfter.UTC()))
+	}
+	if f.CreatedBefore != nil {
+		b.conds = append(b.conds, "created_at < "+b.arg(f.CreatedBefore.UTC()))
+	}
+	if f.TitleContains != "" {
+		b.conds = append(b.conds, "title ILIKE '%' || "+b.arg(likeEscaper.Replace(f.TitleContains))+" || '%'")
+	}
+	if f.ProjectID != nil {
+		b.conds = append(b.conds, "project_id = "+b.arg(*f.ProjectID))
+	}
+	if len(f.Tags) > 0 {
+		b.tags(f.Tags, f.TagMatch)
+	}
+	if f.DueFrom != nil {
+		b.conds = append(b.conds, "due_at >= "+b.arg(*f.DueFrom))
+	}
+	if f.DueUntil != nil {
+		b.conds = append(b.conds, "due_at < "+b.arg(*f.DueUntil))
+	}
+}
+
+// tags keeps todos carrying any or, with domain.TagMatchAll, every one of
+// the given tags. Tags are expected to be distinct.
+func (b *todoQueryBuilder) tags(names []string, match domain.TagMatch) {
+	sub := `SELECT tt.todo_id FROM todo_tags tt JOIN tags tg ON tg.id = tt.tag_id ` +
+		`WHERE tg.name = ANY(` + b.arg(names) + `)`
+	if match == domain.TagMatchAll {
+		sub += ` GROUP BY tt.todo_id HAVING COUNT(*) = ` + b.arg(len(names))
+	}
+	b.conds = append(b.conds, "id IN ("+sub+")")
+}
+
+// keyset restricts the rows to those strictly after (or, when forward is
+// false, strictly before) the cursor in the order given by keys.
+//
+// For keys k1..kn the condition expands to
+//
+//	k1 > v1 OR (k1 = v1 AND k2 > v2) OR ... OR (k1 = v1 AND ... AND kn > vn)
+//
+// with the comparison flipped for descending keys.
+func (b *todoQueryBuilder) keyset(keys []domain.SortKey, c *domain.Cursor, forward bool) error {
+	placeholders := make([]string, len(keys))
+	for i, key := range keys {
+		value, err := cursorValue(c, key.Field)
+		if err != nil {
+			return err
+		}
+		placeholders[i] = b.arg(value)
+	}
+
+	alternatives := make([]string, 0, len(keys))
+	for i, key := range keys {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, sortColumns[keys[j].Field]+" = "+placeholders[j])
+		}
+
+		op := ">"
+		if key.Desc == forward {
+			op = "<"
+		}
+		parts = append(parts, sortColumns[key.Field]+" "+op+" "+placeholders[i])
+
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	b.conds = append(b.conds, "("+strings.Join(alternatives, " OR ")+")")
+	return nil
+}
+
+// orderKeys validates the requested sort order and appends id as a final
+// tiebreaker, so that every row has a unique position for keyset paging.
+func orderKeys(sort []domain.SortKey) ([]domain.SortKey, error) {
+	keys := make([]domain.SortKey, 0, len(sort)+1)
+	hasID := false
+	for _, key := range sort {
+		if _, ok := sortColumns[key.Field]; !ok {
+			return nil, fmt.Errorf("unsupported sort field %q", key.Field)
+		}
+		if key.Field == domain.SortByID {
+			hasID = true
+		}
+		keys = append(keys, key)
+	}
+
+	if !hasID {
+		keys = append(keys, domain.SortKey{Field: domain.SortByID})
+	}
+	return keys, nil
+}
+
+// orderBy renders keys as an ORDER BY clause, optionally reversed.
+func orderBy(keys []domain.SortKey, reverse bool) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		dir := "ASC"
+		if key.Desc != reverse {
+			dir = "DESC"
+		}
+		parts = append(parts, sortColumns[key.Field]+" "+dir)
+	}
+	return "ORDER BY " + strings.Join(parts, ", ")
+}
+
+// cursorValue returns the value the cursor holds for field.
+func cursorValue(c *domain.Cursor, field domain.SortField) (any, error) {
+	switch field {
+	case domain.SortByID:
+		return c.ID, nil
+	case domain.SortByTitle:
+		return c.Title, nil
+	case domain.SortByCompleted:
+		return c.Completed, nil
+	case domain.SortByStatus:
+		return string(c.Status), nil
+	case domain.SortByPriority:
+		return string(c.Priority), nil
+	case domain.SortByCreatedAt:
+		return c.CreatedAt.UTC(), nil
+	case domain.SortByPosition:
+		return c.Position, nil
+	default:
+		return nil, fmt.Errorf("unsupported sort field %q", field)
+	}
+}
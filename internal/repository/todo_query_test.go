@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+func TestTodoQueryBuilder_Filter(t *testing.T) {
+	completed := false
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	var b todoQueryBuilder
+	b.filter(domain.TodoFilter{
+		Completed:     &completed,
+		CreatedAfter:  &after,
+		TitleContains: "50%_off",
+	})
+
+	wantWhere := `WHERE deleted_at IS NULL AND completed = $1 AND created_at > $2 AND title ILIKE '%' || $3 || '%'`
+	if got := b.where(); got != wantWhere {
+		t.Errorf("where() = %q, want %q", got, wantWhere)
+	}
+
+	wantArgs := []any{false, after.UTC(), `50\%\_off`}
+	if !reflect.DeepEqual(b.args, wantArgs) {
+		t.Errorf("args = %v, want %v", b.args, wantArgs)
+	}
+}
+
+func TestTodoQueryBuilder_Tags(t *testing.T) {
+	tests := []struct {
+		name      string
+		match     domain.TagMatch
+		wantWhere string
+		wantArgs  []any
+	}{
+		{
+			name: "any",
+			wantWhere: `WHERE deleted_at IS NULL AND ` +
+				`id IN (SELECT tt.todo_id FROM todo_tags tt JOIN tags tg ON tg.id = tt.tag_id ` +
+				`WHERE tg.name = ANY($1))`,
+			wantArgs: []any{[]string{"home", "work"}},
+		},
+		{
+			name:  "all",
+			match: domain.TagMatchAll,
+			wantWhere: `WHERE deleted_at IS NULL AND ` +
+				`id IN (SELECT tt.todo_id FROM todo_tags tt JOIN tags tg ON tg.id = tt.tag_id ` +
+				`WHERE tg.name = ANY($1) GROUP BY tt.todo_id HAVING COUNT(*) = $2)`,
+			wantArgs: []any{[]string{"home", "work"}, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b todoQueryBuilder
+			b.filter(domain.TodoFilter{Tags: []string{"home", "work"}, TagMatch: tt.match})
+
+			if got := b.where(); got != tt.wantWhere {
+				t.Errorf("where() = %q, want %q", got, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(b.args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", b.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestTodoQueryBuilder_Keyset(t *testing.T) {
+	keys, err := orderKeys([]domain.SortKey{{Field: domain.SortByCreatedAt, Desc: true}})
+	if err != nil {
+		t.Fatalf("orderKeys() unexpected error = %v", err)
+	}
+
+	cursor := &domain.Cursor{ID: 7, CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name      string
+		forward   bool
+		wantWhere string
+		wantOrder string
+	}{
+		{
+			name:      "after",
+			forward:   true,
+			wantWhere: `WHERE ((created_at < $1) OR (created_at = $1 AND id > $2))`,
+			wantOrder: "ORDER BY created_at DESC, id ASC",
+		},
+		{
+			name:      "before",
+			forward:   false,
+			wantWhere: `WHERE ((created_at > $1) OR (created_at = $1 AND id < $2))`,
+			wantOrder: "ORDER BY created_at ASC, id DESC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b todoQueryBuilder
+			if err := b.keyset(keys, cursor, tt.forward); err != nil {
+				t.Fatalf("keyset() unexpected error = %v", err)
+			}
+
+			if got := b.where(); got != tt.wantWhere {
+				t.Errorf("where() = %q, want %q", got, tt.wantWhere)
+			}
+
+			if got := orderBy(keys, !tt.forward); got != tt.wantOrder {
+				t.Errorf("orderBy() = %q, want %q", got, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestOrderKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		sort    []domain.SortKey
+		want    []domain.SortKey
+		wantErr bool
+	}{
+		{
+			name: "default order",
+			want: []domain.SortKey{{Field: domain.SortByID}},
+		},
+		{
+			name: "id tiebreaker appended",
+			sort: []domain.SortKey{{Field: domain.SortByTitle}},
+			want: []domain.SortKey{{Field: domain.SortByTitle}, {Field: domain.SortByID}},
+		},
+		{
+			name: "explicit id kept",
+			sort: []domain.SortKey{{Field: domain.SortByID, Desc: true}},
+			want: []domain.SortKey{{Field: domain.SortByID, Desc: true}},
+		},
+		{
+			name:    "unknown field",
+			sort:    []domain.SortKey{{Field: "title; DROP TABLE todos"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := orderKeys(tt.sort)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("orderKeys() expected error but got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("orderKeys() unexpected error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"slices"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -15,6 +16,34 @@ import (
 	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
 )
 
+// todoColumns lists the columns selected for a todo, in the order scanTodo expects.
+const todoColumns = "id, title, description, status, priority, created_at, due_at, remind_at, " +
+	"project_id, parent_id, series_id, deleted_at, version, position"
+
+// scanTodo reads a single todo row selected with todoColumns. Columns
+// selected after those are read into extra.
+func scanTodo(row pgx.Row, extra ...any) (domain.Todo, error) {
+	var t domain.Todo
+	dest := []any{
+		&t.ID,
+		&t.Title,
+		&t.Description,
+		&t.Status,
+		&t.Priority,
+		&t.CreatedAt,
+		&t.DueAt,
+		&t.RemindAt,
+		&t.ProjectID,
+		&t.ParentID,
+		&t.SeriesID,
+		&t.DeletedAt,
+		&t.Version,
+		&t.Position,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return t, err
+}
+
 type TodoRepositoryPg struct {
 	db *pgxpool.Pool
 }
@@ -24,44 +53,70 @@ func NewTodoRepository(db *pgxpool.Pool) *TodoRepositoryPg {
 	return &TodoRepositoryPg{db: db}
 }
 
-// Create inserts a new todo and returns its generated ID.
-func (r *TodoRepositoryPg) Create(ctx context.Context, title string) (int, error) {
+// Create inserts a new todo together with its tags and returns its
+// generated ID.
+func (r *TodoRepositoryPg) Create(ctx context.Context, t domain.Todo) (int, error) {
 	log := logger.FromContext(ctx)
 
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op once committed
+
 	const query = `
-		INSERT INTO todos (title)
-		VALUES ($1)
+		INSERT INTO todos (title, description, status, priority, due_at, remind_at, project_id, parent_id, series_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id
 	`
 
 	var id int
-	err := r.db.QueryRow(ctx, query, title).Scan(&id)
+	err = tx.QueryRow(ctx, query,
+		t.Title, t.Description, t.Status, t.Priority, t.DueAt, t.RemindAt, t.ProjectID, t.ParentID, t.SeriesID,
+	).Scan(&id)
+	if isForeignKeyViolation(err, "todos_project_id_fkey") {
+		log.Warn("project not found for todo", zap.Intp("project_id", t.ProjectID))
+		return 0, domain.ErrProjectNotFound
+	}
+	if isForeignKeyViolation(err, "todos_parent_id_fkey") {
+		log.Warn("parent not found for todo", zap.Intp("parent_id", t.ParentID))
+		return 0, domain.ErrParentNotFound
+	}
+	if isUniqueViolation(err, "idx_todos_series_due") {
+		log.Warn("occurrence already exists", zap.Intp("series_id", t.SeriesID), zap.Timep("due_at", t.DueAt))
+		return 0, domain.ErrOccurrenceExists
+	}
 	if err != nil {
 		log.Error("failed to insert todo", zap.Error(err))
 		return 0, err
 	}
 
+	if err := replaceTags(ctx, tx, id, t.Tags); err != nil {
+		log.Error("failed to tag todo", zap.Error(err))
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit todo", zap.Error(err))
+		return 0, err
+	}
+
 	log.Info("todo created", zap.Int("id", id))
 	return id, nil
 }
 
-// GetByID retrieves a todo by its ID.
+// GetByID retrieves a todo by its ID. Todos in the trash are not found.
 func (r *TodoRepositoryPg) GetByID(ctx context.Context, id int) (*domain.Todo, error) {
 	log := logger.FromContext(ctx)
 
 	const query = `
-		SELECT id, title, completed, created_at
+		SELECT ` + todoColumns + `
 		FROM todos
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	var t domain.Todo
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&t.ID,
-		&t.Title,
-		&t.Completed,
-		&t.CreatedAt,
-	)
+	t, err := scanTodo(r.conn(ctx).QueryRow(ctx, query, id))
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		log.Warn("todo not found", zap.Int("id", id))
@@ -73,20 +128,35 @@ func (r *TodoRepositoryPg) GetByID(ctx context.Context, id int) (*domain.Todo, e
 		return nil, err
 	}
 
-	return &t, nil
+	todos := []domain.Todo{t}
+	if err := loadTags(ctx, r.conn(ctx), todos); err != nil {
+		log.Error("failed to load todo tags", zap.Error(err))
+		return nil, err
+	}
+
+	return &todos[0], nil
 }
 
-// List retrieves all todos.
-func (r *TodoRepositoryPg) List(ctx context.Context) ([]domain.Todo, error) {
+// List retrieves all todos matching q.
+func (r *TodoRepositoryPg) List(ctx context.Context, q domain.TodoQuery) ([]domain.Todo, error) {
 	log := logger.FromContext(ctx)
 
-	const query = `
-		SELECT id, title, completed, created_at
+	keys, err := orderKeys(q.Sort)
+	if err != nil {
+		log.Error("invalid todo sort order", zap.Error(err))
+		return nil, err
+	}
+
+	var b todoQueryBuilder
+	b.filter(q.Filter)
+
+	query := `
+		SELECT ` + todoColumns + `
 		FROM todos
-		ORDER BY id
-	`
+		` + b.where() + `
+		` + orderBy(keys, false)
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.conn(ctx).Query(ctx, query, b.args...)
 	if err != nil {
 		log.Error("failed to query todos", zap.Error(err))
 		return nil, err
@@ -96,8 +166,72 @@ func (r *TodoRepositoryPg) List(ctx context.Context) ([]domain.Todo, error) {
 	todos := make([]domain.Todo, 0)
 
 	for rows.Next() {
-		var t domain.Todo
-		if err := rows.Scan(&t.ID, &t.Title, &t.Completed, &t.CreatedAt); err != nil {
+		t, err := scanTodo(rows)
+		if err != nil {
+			log.Error("failed to scan todo row", zap.Error(err))
+			return nil, err
+		}
+		todos = append(todos, t)
+	}
+
+	if rows.Err() != nil {
+		log.Error("rows error", zap.Error(rows.Err()))
+		return nil, rows.Err()
+	}
+
+	if err := loadTags(ctx, r.conn(ctx), todos); err != nil {
+		log.Error("failed to load todo tags", zap.Error(err))
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// ListPage retrieves a single keyset page of todos matching q.
+// Rows are always returned in list order, even when paging backwards.
+func (r *TodoRepositoryPg) ListPage(ctx context.Context, q domain.PageQuery) ([]domain.Todo, error) {
+	log := logger.FromContext(ctx)
+
+	keys, err := orderKeys(q.Sort)
+	if err != nil {
+		log.Error("invalid todo sort order", zap.Error(err))
+		return nil, err
+	}
+
+	var b todoQueryBuilder
+	b.filter(q.Filter)
+
+	backward := q.Before != nil
+	switch {
+	case q.After != nil:
+		err = b.keyset(keys, q.After, true)
+	case q.Before != nil:
+		err = b.keyset(keys, q.Before, false)
+	}
+	if err != nil {
+		log.Error("invalid todo cursor", zap.Error(err))
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + todoColumns + `
+		FROM todos
+		` + b.where() + `
+		` + orderBy(keys, backward) + `
+		LIMIT ` + b.arg(q.Limit)
+
+	rows, err := r.conn(ctx).Query(ctx, query, b.args...)
+	if err != nil {
+		log.Error("failed to query todo page", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := make([]domain.Todo, 0, q.Limit)
+
+	for rows.Next() {
+		t, err := scanTodo(rows)
+		if err != nil {
 			log.Error("failed to scan todo row", zap.Error(err))
 			return nil, err
 		}
@@ -109,29 +243,160 @@ func (r *TodoRepositoryPg) List(ctx context.Context) ([]domain.Todo, error) {
 		return nil, rows.Err()
 	}
 
+	if backward {
+		slices.Reverse(todos)
+	}
+
+	if err := loadTags(ctx, r.conn(ctx), todos); err != nil {
+		log.Error("failed to load todo tags", zap.Error(err))
+		return nil, err
+	}
+
 	return todos, nil
 }
 
-// Delete removes a todo by ID.
-func (r *TodoRepositoryPg) Delete(ctx context.Context, id int) error {
+// Update applies the non-nil fields of upd to the todo with the given ID
+// and returns the updated row. Todos in the trash cannot be updated. The
+// version is checked against upd.IfVersion in the same statement, so that
+// no concurrent change can slip in between.
+func (r *TodoRepositoryPg) Update(ctx context.Context, id int, upd domain.TodoUpdate) (*domain.Todo, error) {
+	log := logger.FromContext(ctx)
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op once committed
+
+	const query = `
+		UPDATE todos
+		SET title       = COALESCE($2, title),
+		    description = COALESCE($3, description),
+		    status      = COALESCE($4, status),
+		    priority    = COALESCE($5, priority),
+		    due_at      = CASE WHEN $6 THEN $7 ELSE due_at END,
+		    remind_at   = CASE WHEN $8 THEN $9 ELSE remind_at END,
+		    project_id  = CASE WHEN $10 THEN $11 ELSE project_id END,
+		    parent_id   = CASE WHEN $12 THEN $13 ELSE parent_id END,
+		    version     = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($14::int[] IS NULL OR version = ANY($14))
+		RETURNING ` + todoColumns
+
+	t, err := scanTodo(tx.QueryRow(ctx, query,
+		id,
+		upd.Title,
+		upd.Description,
+		upd.Status,
+		upd.Priority,
+		upd.DueAt.Set, upd.DueAt.Value,
+		upd.RemindAt.Set, upd.RemindAt.Value,
+		upd.ProjectID.Set, upd.ProjectID.Value,
+		upd.ParentID.Set, upd.ParentID.Value,
+		[]int(upd.IfVersion),
+	))
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = missingOrModified(ctx, tx, id)
+		log.Warn("todo not updated", zap.Int("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	if isForeignKeyViolation(err, "todos_project_id_fkey") {
+		log.Warn("project not found for todo", zap.Intp("project_id", upd.ProjectID.Value))
+		return nil, domain.ErrProjectNotFound
+	}
+
+	if isForeignKeyViolation(err, "todos_parent_id_fkey") {
+		log.Warn("parent not found for todo", zap.Intp("parent_id", upd.ParentID.Value))
+		return nil, domain.ErrParentNotFound
+	}
+
+	if isUniqueViolation(err, "idx_todos_series_due") {
+		log.Warn("occurrence already exists", zap.Int("id", id), zap.Timep("due_at", upd.DueAt.Value))
+		return nil, domain.ErrOccurrenceExists
+	}
+
+	if err != nil {
+		log.Error("failed to update todo", zap.Error(err))
+		return nil, err
+	}
+
+	if upd.Tags != nil {
+		if err := replaceTags(ctx, tx, id, *upd.Tags); err != nil {
+			log.Error("failed to tag todo", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	todos := []domain.Todo{t}
+	if err := loadTags(ctx, tx, todos); err != nil {
+		log.Error("failed to load todo tags", zap.Error(err))
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit todo update", zap.Error(err))
+		return nil, err
+	}
+
+	log.Info("todo updated", zap.Int("id", id))
+	return &todos[0], nil
+}
+
+// Delete moves a todo and all of its subtasks to the trash. They share
+// the same deleted_at, so that restoring the todo brings back exactly the
+// subtasks that were deleted with it. Like Update, the version of the todo
+// is checked against match in the same statement.
+func (r *TodoRepositoryPg) Delete(ctx context.Context, id int, match domain.VersionMatch) error {
 	log := logger.FromContext(ctx)
 
 	const query = `
-		DELETE FROM todos
-		WHERE id = $1
+		WITH RECURSIVE tree AS (
+			SELECT id
+			FROM todos
+			WHERE id = $1 AND deleted_at IS NULL
+			  AND ($2::int[] IS NULL OR version = ANY($2))
+			UNION
+			SELECT t.id
+			FROM todos t
+			JOIN tree ON t.parent_id = tree.id
+			WHERE t.deleted_at IS NULL
+		)
+		UPDATE todos
+		SET deleted_at = NOW(),
+		    version    = version + 1
+		WHERE id IN (SELECT id FROM tree)
 	`
 
-	res, err := r.db.Exec(ctx, query, id)
+	res, err := r.conn(ctx).Exec(ctx, query, id, []int(match))
 	if err != nil {
 		log.Error("failed to delete todo", zap.Error(err))
 		return err
 	}
 
 	if res.RowsAffected() == 0 {
-		log.Warn("todo not found for delete", zap.Int("id", id))
-		return domain.ErrTodoNotFound
+		err = missingOrModified(ctx, r.conn(ctx), id)
+		log.Warn("todo not deleted", zap.Int("id", id), zap.Error(err))
+		return err
 	}
 
-	log.Info("todo deleted", zap.Int("id", id))
+	log.Info("todo moved to trash", zap.Int("id", id), zap.Int64("count", res.RowsAffected()))
 	return nil
 }
+
+// missingOrModified explains why a conditional write to a todo matched no
+// rows: either the todo does not exist (or is in the trash), or it is no
+// longer at the expected version.
+func missingOrModified(ctx context.Context, q querier, id int) error {
+	var exists bool
+	err := q.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM todos WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrVersionMismatch
+	}
+	return domain.ErrTodoNotFound
+}
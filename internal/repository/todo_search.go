@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// highlightOptions mark every matched word of a snippet with the domain
+// highlight markers.
+const highlightOptions = "StartSel=" + domain.HighlightStart + ", StopSel=" + domain.HighlightEnd
+
+const (
+	// titleHeadline keeps the whole title in its snippet
+	titleHeadline = highlightOptions + ", HighlightAll=true"
+	// descriptionHeadline cuts the snippet of a description down to the
+	// passages around the matched words
+	descriptionHeadline = highlightOptions + `, MaxFragments=2, MinWords=8, MaxWords=20, FragmentDelimiter=" … "`
+)
+
+// tsqueryEscaper quotes a lexeme of a tsquery.
+var tsqueryEscaper = strings.NewReplacer(`'`, `''`, `\`, `\\`)
+
+// Search returns the todos matching q, best matches first, using the
+// search column and its GIN index. Snippets are cut from the title when
+// it matches on its own and from the description otherwise; the highlight
+// markers are stripped from both first so that they only ever surround
+// matched words.
+func (r *TodoRepositoryPg) Search(ctx context.Context, q domain.SearchQuery) ([]domain.SearchResult, error) {
+	log := logger.FromContext(ctx)
+
+	var b todoQueryBuilder
+	tsq := b.arg(tsquery(q.Any))
+	b.filter(q.Filter)
+	b.conds = append(b.conds, "search @@ query")
+	markers := b.arg(domain.HighlightStart + domain.HighlightEnd)
+
+	query := `
+		SELECT ` + todoColumns + `,
+		       ts_rank_cd(search, query)::float8 AS score,
+		       CASE WHEN to_tsvector('english', title) @@ query
+		            THEN ts_headline('english', translate(title, ` + markers + `, ''), query, ` + b.arg(titleHeadline) + `)
+		            ELSE ts_headline('english', translate(description, ` + markers + `, ''), query,
+		                             ` + b.arg(descriptionHeadline) + `)
+		       END
+		FROM todos, to_tsquery('english', ` + tsq + `) AS query
+		` + b.where() + `
+		ORDER BY score DESC, id
+		LIMIT ` + b.arg(q.Limit)
+
+	rows, err := r.conn(ctx).Query(ctx, query, b.args...)
+	if err != nil {
+		log.Error("failed to search todos", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]domain.SearchResult, 0)
+	for rows.Next() {
+		var res domain.SearchResult
+		if res.Todo, err = scanTodo(rows, &res.Score, &res.Snippet); err != nil {
+			log.Error("failed to scan search result", zap.Error(err))
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	if rows.Err() != nil {
+		log.Error("rows error", zap.Error(rows.Err()))
+		return nil, rows.Err()
+	}
+
+	todos := make([]domain.Todo, len(results))
+	for i := range results {
+		todos[i] = results[i].Todo
+	}
+	if err := loadTags(ctx, r.conn(ctx), todos); err != nil {
+		log.Error("failed to load todo tags", zap.Error(err))
+		return nil, err
+	}
+	for i := range results {
+		results[i].Todo = todos[i]
+	}
+
+	return results, nil
+}
+
+// tsquery renders the alternatives of a search as a tsquery. Every word is
+// quoted, so that nothing in it is read as an operator, and still goes
+// through the text search configuration like the words of a todo do.
+func tsquery(alternatives [][]domain.SearchTerm) string {
+	rendered := make([]string, len(alternatives))
+	for i, terms := range alternatives {
+		parts := make([]string, len(terms))
+		for j, term := range terms {
+			parts[j] = tsqueryTerm(term)
+		}
+		rendered[i] = "(" + strings.Join(parts, " & ") + ")"
+	}
+	return strings.Join(rendered, " | ")
+}
+
+// tsqueryTerm renders a single term, its words joined with the followed-by
+// operator.
+func tsqueryTerm(term domain.SearchTerm) string {
+	words := make([]string, len(term.Words))
+	for i, word := range term.Words {
+		words[i] = "'" + tsqueryEscaper.Replace(word) + "'"
+	}
+	if term.Prefix && len(words) > 0 {
+		words[len(words)-1] += ":*"
+	}
+
+	rendered := strings.Join(words, " <-> ")
+	if term.Exclude {
+		return "!(" + rendered + ")"
+	}
+	return rendered
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+func TestTsquery(t *testing.T) {
+	tests := []struct {
+		name         string
+		alternatives [][]domain.SearchTerm
+		want         string
+	}{
+		{
+			name:         "words",
+			alternatives: [][]domain.SearchTerm{{{Words: []string{"buy"}}, {Words: []string{"milk"}}}},
+			want:         "('buy' & 'milk')",
+		},
+		{
+			name:         "phrase with prefix",
+			alternatives: [][]domain.SearchTerm{{{Words: []string{"grocery", "sho"}, Prefix: true}}},
+			want:         "('grocery' <-> 'sho':*)",
+		},
+		{
+			name: "exclusion and alternatives",
+			alternatives: [][]domain.SearchTerm{
+				{{Words: []string{"milk"}}, {Words: []string{"oat", "milk"}, Exclude: true}},
+				{{Words: []string{"bread"}}},
+			},
+			want: "('milk' & !('oat' <-> 'milk')) | ('bread')",
+		},
+		{
+			name:         "quotes and backslashes are escaped",
+			alternatives: [][]domain.SearchTerm{{{Words: []string{`o'neil\`}}}},
+			want:         `('o''neil\\')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tsquery(tt.alternatives); got != tt.want {
+				t.Errorf("tsquery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
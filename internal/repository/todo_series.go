@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// CreateSeries inserts the series of a recurring todo and returns its
+// generated ID. The todos of the series are created separately.
+func (r *TodoRepositoryPg) CreateSeries(ctx context.Context, s domain.Series) (int, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		INSERT INTO todo_series (rrule, time_zone, starts_at)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+
+	var id int
+	err := r.conn(ctx).QueryRow(ctx, query, s.Rule, s.TimeZone, s.Start).Scan(&id)
+	if err != nil {
+		log.Error("failed to insert series", zap.Error(err))
+		return 0, err
+	}
+
+	log.Info("series created", zap.Int("id", id))
+	return id, nil
+}
+
+// GetSeries retrieves a series by its ID. A series that does not exist is
+// reported as domain.ErrNotRecurring.
+func (r *TodoRepositoryPg) GetSeries(ctx context.Context, id int) (*domain.Series, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		SELECT id, rrule, time_zone, starts_at, created_at
+		FROM todo_series
+		WHERE id = $1
+	`
+
+	var s domain.Series
+	err := r.conn(ctx).QueryRow(ctx, query, id).Scan(&s.ID, &s.Rule, &s.TimeZone, &s.Start, &s.CreatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Warn("series not found", zap.Int("id", id))
+		return nil, domain.ErrNotRecurring
+	}
+
+	if err != nil {
+		log.Error("failed to fetch series", zap.Error(err))
+		return nil, err
+	}
+
+	return &s, nil
+}
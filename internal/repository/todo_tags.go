@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// querier is implemented by both the connection pool and transactions, so
+// helpers can run either on their own or as part of a larger transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// loadTags fills in the tags of todos with a single query, however many
+// todos there are.
+func loadTags(ctx context.Context, q querier, todos []domain.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(todos))
+	index := make(map[int]int, len(todos))
+	for i := range todos {
+		ids[i] = todos[i].ID
+		index[todos[i].ID] = i
+		todos[i].Tags = []string{}
+	}
+
+	const query = `
+		SELECT tt.todo_id, tg.name
+		FROM todo_tags tt
+		JOIN tags tg ON tg.id = tt.tag_id
+		WHERE tt.todo_id = ANY($1)
+		ORDER BY tg.name
+	`
+
+	rows, err := q.Query(ctx, query, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			todoID int
+			name   string
+		)
+		if err := rows.Scan(&todoID, &name); err != nil {
+			return err
+		}
+		i := index[todoID]
+		todos[i].Tags = append(todos[i].Tags, name)
+	}
+
+	return rows.Err()
+}
+
+// replaceTags sets the tags of a todo to exactly names, creating tags that
+// do not exist yet.
+func replaceTags(ctx context.Context, q querier, todoID int, names []string) error {
+	if _, err := q.Exec(ctx, `DELETE FROM todo_tags WHERE todo_id = $1`, todoID); err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	const insertTags = `
+		INSERT INTO tags (name)
+		SELECT unnest($1::text[])
+		ON CONFLICT (name) DO NOTHING
+	`
+	if _, err := q.Exec(ctx, insertTags, names); err != nil {
+		return err
+	}
+
+	const linkTags = `
+		INSERT INTO todo_tags (todo_id, tag_id)
+		SELECT $1, id FROM tags WHERE name = ANY($2)
+	`
+	_, err := q.Exec(ctx, linkTags, todoID, names)
+	return err
+}
+
+// ListTags returns every tag in use along with the number of todos
+// carrying it, most used first. Todos in the trash are not counted.
+func (r *TodoRepositoryPg) ListTags(ctx context.Context) ([]domain.TagCount, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		SELECT tg.name, COUNT(*)
+		FROM tags tg
+		JOIN todo_tags tt ON tt.tag_id = tg.id
+		JOIN todos t ON t.id = tt.todo_id AND t.deleted_at IS NULL
+		GROUP BY tg.name
+		ORDER BY COUNT(*) DESC, tg.name
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query)
+	if err != nil {
+		log.Error("failed to query tags", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]domain.TagCount, 0)
+
+	for rows.Next() {
+		var tag domain.TagCount
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			log.Error("failed to scan tag row", zap.Error(err))
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	if rows.Err() != nil {
+		log.Error("rows error", zap.Error(rows.Err()))
+		return nil, rows.Err()
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// ListTrash retrieves every todo in the trash, most recently deleted first.
+func (r *TodoRepositoryPg) ListTrash(ctx context.Context) ([]domain.Todo, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query)
+	if err != nil {
+		log.Error("failed to query trash", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := make([]domain.Todo, 0)
+
+	for rows.Next() {
+		t, err := scanTodo(rows)
+		if err != nil {
+			log.Error("failed to scan todo row", zap.Error(err))
+			return nil, err
+		}
+		todos = append(todos, t)
+	}
+
+	if rows.Err() != nil {
+		log.Error("rows error", zap.Error(rows.Err()))
+		return nil, rows.Err()
+	}
+
+	if err := loadTags(ctx, r.conn(ctx), todos); err != nil {
+		log.Error("failed to load todo tags", zap.Error(err))
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// Restore takes a todo out of the trash together with the subtasks that
+// were deleted along with it, and returns the restored todo. A subtask
+// cannot be restored while its parent is still in the trash.
+func (r *TodoRepositoryPg) Restore(ctx context.Context, id int) (*domain.Todo, error) {
+	log := logger.FromContext(ctx)
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op once committed
+
+	const find = `
+		SELECT t.deleted_at, COALESCE(p.deleted_at IS NOT NULL, FALSE)
+		FROM todos t
+		LEFT JOIN todos p ON p.id = t.parent_id
+		WHERE t.id = $1 AND t.deleted_at IS NOT NULL
+		FOR UPDATE OF t
+	`
+
+	var (
+		deletedAt     time.Time
+		parentDeleted bool
+	)
+	err = tx.QueryRow(ctx, find, id).Scan(&deletedAt, &parentDeleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Warn("todo not found in trash", zap.Int("id", id))
+		return nil, domain.ErrTodoNotFound
+	}
+	if err != nil {
+		log.Error("failed to fetch deleted todo", zap.Error(err))
+		return nil, err
+	}
+	if parentDeleted {
+		log.Warn("parent of todo is in trash", zap.Int("id", id))
+		return nil, domain.ErrParentDeleted
+	}
+
+	const restore = `
+		WITH RECURSIVE tree AS (
+			SELECT id FROM todos WHERE id = $1
+			UNION
+			SELECT t.id
+			FROM todos t
+			JOIN tree ON t.parent_id = tree.id
+			WHERE t.deleted_at = $2
+		)
+		UPDATE todos
+		SET deleted_at = NULL,
+		    version    = version + 1
+		WHERE id IN (SELECT id FROM tree)
+	`
+
+	res, err := tx.Exec(ctx, restore, id, deletedAt)
+	if err != nil {
+		log.Error("failed to restore todo", zap.Error(err))
+		return nil, err
+	}
+
+	const query = `
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE id = $1
+	`
+
+	t, err := scanTodo(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		log.Error("failed to fetch restored todo", zap.Error(err))
+		return nil, err
+	}
+
+	todos := []domain.Todo{t}
+	if err := loadTags(ctx, tx, todos); err != nil {
+		log.Error("failed to load todo tags", zap.Error(err))
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit todo restore", zap.Error(err))
+		return nil, err
+	}
+
+	log.Info("todo restored", zap.Int("id", id), zap.Int64("count", res.RowsAffected()))
+	return &todos[0], nil
+}
+
+// DeletePermanently removes a todo that is in the trash for good. Its
+// subtasks are removed by the foreign key.
+func (r *TodoRepositoryPg) DeletePermanently(ctx context.Context, id int) error {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		DELETE FROM todos
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	res, err := r.conn(ctx).Exec(ctx, query, id)
+	if err != nil {
+		log.Error("failed to permanently delete todo", zap.Error(err))
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		log.Warn("todo not found in trash", zap.Int("id", id))
+		return domain.ErrTodoNotFound
+	}
+
+	log.Info("todo permanently deleted", zap.Int("id", id))
+	return nil
+}
+
+// Purge permanently removes every todo that was moved to the trash before
+// the given time and returns how many were removed.
+func (r *TodoRepositoryPg) Purge(ctx context.Context, before time.Time) (int, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		DELETE FROM todos
+		WHERE deleted_at < $1
+	`
+
+	res, err := r.conn(ctx).Exec(ctx, query, before)
+	if err != nil {
+		log.Error("failed to purge trash", zap.Error(err))
+		return 0, err
+	}
+
+	return int(res.RowsAffected()), nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// maxTreeWalk bounds the recursive queries over the subtask tree, so that
+// they terminate even if the tree was corrupted into a cycle.
+const maxTreeWalk = 64
+
+// qualifiedTodoColumns returns todoColumns prefixed with a table alias.
+func qualifiedTodoColumns(alias string) string {
+	columns := strings.Split(todoColumns, ", ")
+	for i, column := range columns {
+		columns[i] = alias + "." + column
+	}
+	return strings.Join(columns, ", ")
+}
+
+// Ancestors returns the ID of the todo followed by the IDs of its parent,
+// grandparent and so on up to the top-level todo. The result is empty if
+// the todo does not exist or is in the trash.
+func (r *TodoRepositoryPg) Ancestors(ctx context.Context, id int) ([]int, error) {
+	log := logger.FromContext(ctx)
+
+	const query = `
+		WITH RECURSIVE chain AS (
+			SELECT id, parent_id, 0 AS depth
+			FROM todos
+			WHERE id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT t.id, t.parent_id, c.depth + 1
+			FROM todos t
+			JOIN chain c ON t.id = c.parent_id
+			WHERE c.depth < $2
+		)
+		SELECT id FROM chain ORDER BY depth
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, id, maxTreeWalk)
+	if err != nil {
+		log.Error("failed to query todo ancestors", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+
+	for rows.Next() {
+		var ancestor int
+		if err := rows.Scan(&ancestor); err != nil {
+			log.Error("failed to scan todo ancestor", zap.Error(err))
+			return nil, err
+		}
+		ids = append(ids, ancestor)
+	}
+
+	if rows.Err() != nil {
+		log.Error("rows error", zap.Error(rows.Err()))
+		return nil, rows.Err()
+	}
+
+	return ids, nil
+}
+
+// Subtasks returns every todo below the given one in the subtask tree,
+// with their tags, as a flat list ordered by depth and then ID. Callers
+// rebuild the tree from ParentID. Subtasks in the trash are left out.
+func (r *TodoRepositoryPg) Subtasks(ctx context.Context, id int) ([]domain.Todo, error) {
+	log := logger.FromContext(ctx)
+
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT ` + todoColumns + `, 1 AS depth
+			FROM todos
+			WHERE parent_id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT ` + qualifiedTodoColumns("t") + `, tree.depth + 1
+			FROM todos t
+			JOIN tree ON t.parent_id = tree.id
+			WHERE tree.depth < $2 AND t.deleted_at IS NULL
+		)
+		SELECT ` + todoColumns + `
+		FROM tree
+		ORDER BY depth, id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, id, maxTreeWalk)
+	if err != nil {
+		log.Error("failed to query subtasks", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := make([]domain.Todo, 0)
+
+	for rows.Next() {
+		t, err := scanTodo(rows)
+		if err != nil {
+			log.Error("failed to scan subtask row", zap.Error(err))
+			return nil, err
+		}
+		todos = append(todos, t)
+	}
+
+	if rows.Err() != nil {
+		log.Error("rows error", zap.Error(rows.Err()))
+		return nil, rows.Err()
+	}
+
+	if err := loadTags(ctx, r.conn(ctx), todos); err != nil {
+		log.Error("failed to load subtask tags", zap.Error(err))
+		return nil, err
+	}
+
+	return todos, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// dbtx is a querier that can also start transactions. The pool starts
+// real transactions; a transaction starts savepoints, so that methods that
+// need a transaction of their own still work inside a larger one.
+type dbtx interface {
+	querier
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// txKey is the context key of the transaction started by InTx.
+type txKey struct{}
+
+// InTx runs fn in a single transaction. Every repository call fn makes with
+// the context it is given joins that transaction. The transaction is
+// committed if fn returns nil and rolled back otherwise. Calls to InTx
+// within fn start a savepoint.
+func (r *TodoRepositoryPg) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	log := logger.FromContext(ctx)
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		log.Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op once committed
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error("failed to commit transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// conn returns the transaction ctx is running in, if any, and the pool
+// otherwise.
+func (r *TodoRepositoryPg) conn(ctx context.Context) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return r.db
+}
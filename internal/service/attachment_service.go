@@ -0,0 +1,445 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+const (
+	// DefaultMaxAttachmentSize is the largest file that may be uploaded
+	// unless configured otherwise: 10 MiB.
+	DefaultMaxAttachmentSize = 10 << 20
+	// orphanBatchSize is how many attachments of deleted todos have their
+	// files deleted at a time
+	orphanBatchSize = 100
+	// sniffLength is how much of a file is looked at to tell its type,
+	// which is all http.DetectContentType considers
+	sniffLength = 512
+	// maxFilenameLength limits the length of stored file names
+	maxFilenameLength = 255
+	// blobKeyBytes is how many random bytes make up the key of a file
+	blobKeyBytes = 16
+)
+
+// DefaultAttachmentTypes are the media types files may have unless
+// configured otherwise.
+var DefaultAttachmentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf", "text/plain",
+}
+
+// BlobStore keeps the contents of attachments. Keys are slash-separated
+// paths made up by the service.
+type BlobStore interface {
+	// Put stores size bytes read from r under key, replacing anything
+	// stored there before.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the contents stored under key, or an error matching
+	// fs.ErrNotExist if there are none. The caller closes it.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes the contents stored under key. Deleting a key that
+	// holds nothing is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// AttachmentRepository is the persistence contract for the attachments of
+// todos. The consumer (the service) owns the interface.
+type AttachmentRepository interface {
+	// Create inserts an attachment and returns it as stored.
+	Create(ctx context.Context, attachment domain.Attachment) (*domain.Attachment, error)
+	// GetByID returns attachment id of todo todoID, or
+	// domain.ErrAttachmentNotFound.
+	GetByID(ctx context.Context, todoID, id int) (*domain.Attachment, error)
+	// List returns the attachments of a todo, oldest first.
+	List(ctx context.Context, todoID int) ([]domain.Attachment, error)
+	// Detach takes attachment id away from todo todoID and returns it, so
+	// that it is left for DeleteOrphan, or returns
+	// domain.ErrAttachmentNotFound.
+	Detach(ctx context.Context, todoID, id int) (*domain.Attachment, error)
+	// Orphans returns up to limit attachments that belong to no todo
+	// anymore, having been detached or had their todo deleted for good.
+	Orphans(ctx context.Context, limit int) ([]domain.Attachment, error)
+	// DeleteOrphan removes attachment id, which belongs to no todo.
+	DeleteOrphan(ctx context.Context, id int) error
+}
+
+// AttachmentService defines operations available on the attachments of
+// todos. Attachments of a todo in the trash cannot be reached until it is
+// restored.
+type AttachmentService interface {
+	// Upload stores the file read from r as an attachment of
+	// attachment.TodoID. The file name and size are taken from attachment;
+	// the content type is sniffed from the file itself.
+	Upload(ctx context.Context, attachment domain.Attachment, r io.Reader) (*domain.Attachment, error)
+	GetByID(ctx context.Context, todoID, id int) (*domain.Attachment, error)
+	List(ctx context.Context, todoID int) ([]domain.Attachment, error)
+	// Open returns an attachment along with its contents, which the caller
+	// closes.
+	Open(ctx context.Context, todoID, id int) (*domain.Attachment, io.ReadSeekCloser, error)
+	Delete(ctx context.Context, todoID, id int) error
+	// DeleteOrphans deletes the files of attachments that belong to no todo
+	// anymore, a batch at a time, and returns how many were deleted.
+	DeleteOrphans(ctx context.Context) (int, error)
+}
+
+type attachmentService struct {
+	repo  AttachmentRepository
+	blobs BlobStore
+	todos TodoRepository
+
+	maxSize      int64
+	allowedTypes []string
+}
+
+// AttachmentOption configures optional behaviour of an AttachmentService.
+type AttachmentOption func(*attachmentService)
+
+// WithMaxAttachmentSize limits how large uploaded files may be, in bytes.
+func WithMaxAttachmentSize(size int64) AttachmentOption {
+	return func(s *attachmentService) {
+		s.maxSize = size
+	}
+}
+
+// WithAttachmentTypes replaces the media types uploaded files may have.
+func WithAttachmentTypes(types []string) AttachmentOption {
+	return func(s *attachmentService) {
+		s.allowedTypes = types
+	}
+}
+
+// NewAttachmentService constructs a new AttachmentService keeping files in
+// blobs. Todos are looked up in todos, so that attachments are only
+// reached through todos outside the trash.
+func NewAttachmentService(repo AttachmentRepository, blobs BlobStore, todos TodoRepository,
+	opts ...AttachmentOption) AttachmentService {
+	s := &attachmentService{
+		repo:         repo,
+		blobs:        blobs,
+		todos:        todos,
+		maxSize:      DefaultMaxAttachmentSize,
+		allowedTypes: DefaultAttachmentTypes,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// checkTodo returns domain.ErrTodoNotFound unless todo id exists and is
+// not in the trash.
+func (s *attachmentService) checkTodo(ctx context.Context, id int) error {
+	log := logger.FromContext(ctx)
+
+	if id > 0 {
+		_, err := s.todos.GetByID(ctx, id)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, domain.ErrTodoNotFound) {
+			if log != nil {
+				log.Error("failed to get todo for attachments", zap.Error(err))
+			}
+			return err
+		}
+	}
+
+	if log != nil {
+		log.Warn("todo not found for attachments", zap.Int("todo_id", id))
+	}
+	return domain.ErrTodoNotFound
+}
+
+// Upload checks the size and type of a file, stores it and records it as
+// an attachment of its todo.
+func (s *attachmentService) Upload(ctx context.Context, attachment domain.Attachment,
+	r io.Reader) (*domain.Attachment, error) {
+	log := logger.FromContext(ctx)
+
+	if err := s.checkSize(attachment.Size); err != nil {
+		if log != nil {
+			log.Warn("attachment rejected", zap.Error(err), zap.Int64("size", attachment.Size))
+		}
+		return nil, err
+	}
+
+	if err := s.checkTodo(ctx, attachment.TodoID); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, min(sniffLength, attachment.Size))
+	if _, err := io.ReadFull(r, head); err != nil {
+		if log != nil {
+			log.Warn("failed to read attachment", zap.Error(err))
+		}
+		return nil, fmt.Errorf("%w: file is shorter than its size", domain.ErrAttachmentEmpty)
+	}
+
+	contentType, err := s.sniff(head)
+	if err != nil {
+		if log != nil {
+			log.Warn("attachment rejected", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	attachment.Filename = cleanFilename(attachment.Filename)
+	attachment.ContentType = contentType
+	created, err := s.store(ctx, attachment, io.MultiReader(bytes.NewReader(head), r))
+	if err != nil {
+		return nil, err
+	}
+
+	if log != nil {
+		log.Info("attachment uploaded successfully", zap.Int("todo_id", created.TodoID), zap.Int("id", created.ID),
+			zap.Int64("size", created.Size), zap.String("content_type", created.ContentType))
+	}
+	return created, nil
+}
+
+// store keeps the file read from r under a new key, then records it as an
+// attachment. Should that fail, the file is deleted again.
+func (s *attachmentService) store(ctx context.Context, attachment domain.Attachment,
+	r io.Reader) (*domain.Attachment, error) {
+	log := logger.FromContext(ctx)
+
+	var err error
+	if attachment.Key, err = newBlobKey(attachment.TodoID); err != nil {
+		if log != nil {
+			log.Error("failed to generate attachment key", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if err := s.blobs.Put(ctx, attachment.Key, r, attachment.Size, attachment.ContentType); err != nil {
+		if log != nil {
+			log.Error("failed to store attachment", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, attachment)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to create attachment", zap.Error(err))
+		}
+		if err := s.blobs.Delete(ctx, attachment.Key); err != nil && log != nil {
+			log.Error("failed to delete stored attachment", zap.Error(err), zap.String("key", attachment.Key))
+		}
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// checkSize rejects files that are empty or larger than allowed.
+func (s *attachmentService) checkSize(size int64) error {
+	if size <= 0 {
+		return domain.ErrAttachmentEmpty
+	}
+	if size > s.maxSize {
+		return fmt.Errorf("%w: files may be at most %d bytes", domain.ErrAttachmentTooLarge, s.maxSize)
+	}
+	return nil
+}
+
+// sniff returns the content type of a file starting with head, as
+// http.DetectContentType tells it, unless it is not allowed.
+func (s *attachmentService) sniff(head []byte) (string, error) {
+	contentType := http.DetectContentType(head)
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !slices.Contains(s.allowedTypes, mediaType) {
+		return "", fmt.Errorf("%w: %s", domain.ErrAttachmentType, contentType)
+	}
+	return contentType, nil
+}
+
+// cleanFilename reduces the name a file was uploaded with to its last
+// element, without control characters, so that it is safe to hand back in
+// a Content-Disposition header.
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > maxFilenameLength {
+		name = strings.ToValidUTF8(name[:maxFilenameLength], "")
+	}
+	return name
+}
+
+// newBlobKey returns a new, unguessable key for a file attached to todo
+// todoID.
+func newBlobKey(todoID int) (string, error) {
+	b := make([]byte, blobKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("todos/%d/%s", todoID, hex.EncodeToString(b)), nil
+}
+
+// GetByID retrieves a single attachment of a todo.
+func (s *attachmentService) GetByID(ctx context.Context, todoID, id int) (*domain.Attachment, error) {
+	log := logger.FromContext(ctx)
+
+	if err := s.checkTodo(ctx, todoID); err != nil {
+		return nil, err
+	}
+
+	a, err := s.repo.GetByID(ctx, todoID, id)
+	if errors.Is(err, domain.ErrAttachmentNotFound) {
+		if log != nil {
+			log.Warn("attachment not found", zap.Int("todo_id", todoID), zap.Int("id", id))
+		}
+		return nil, err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to get attachment", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// List retrieves the attachments of a todo, oldest first.
+func (s *attachmentService) List(ctx context.Context, todoID int) ([]domain.Attachment, error) {
+	log := logger.FromContext(ctx)
+
+	if err := s.checkTodo(ctx, todoID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.repo.List(ctx, todoID)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to list attachments", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// Open retrieves an attachment of a todo and opens its contents.
+func (s *attachmentService) Open(ctx context.Context, todoID, id int) (*domain.Attachment, io.ReadSeekCloser,
+	error) {
+	log := logger.FromContext(ctx)
+
+	a, err := s.GetByID(ctx, todoID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blob, err := s.blobs.Open(ctx, a.Key)
+	if errors.Is(err, fs.ErrNotExist) {
+		if log != nil {
+			log.Error("stored attachment is missing", zap.Int("id", id), zap.String("key", a.Key))
+		}
+		return nil, nil, domain.ErrAttachmentNotFound
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to open attachment", zap.Error(err))
+		}
+		return nil, nil, err
+	}
+
+	return a, blob, nil
+}
+
+// Delete removes an attachment from a todo and deletes its file. Should
+// that fail, the file is left for DeleteOrphans.
+func (s *attachmentService) Delete(ctx context.Context, todoID, id int) error {
+	log := logger.FromContext(ctx)
+
+	if err := s.checkTodo(ctx, todoID); err != nil {
+		return err
+	}
+
+	a, err := s.repo.Detach(ctx, todoID, id)
+	if errors.Is(err, domain.ErrAttachmentNotFound) {
+		if log != nil {
+			log.Warn("attachment not found for delete", zap.Int("todo_id", todoID), zap.Int("id", id))
+		}
+		return err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to delete attachment", zap.Error(err))
+		}
+		return err
+	}
+
+	if err := s.deleteOrphan(ctx, *a); err != nil && log != nil {
+		log.Warn("attachment file left for later", zap.Error(err), zap.Int("id", id))
+	}
+
+	if log != nil {
+		log.Info("attachment deleted successfully", zap.Int("todo_id", todoID), zap.Int("id", id))
+	}
+	return nil
+}
+
+// DeleteOrphans deletes the files of up to orphanBatchSize attachments
+// that belong to no todo anymore, then the attachments themselves. Files
+// that cannot be deleted are tried again on the next call.
+func (s *attachmentService) DeleteOrphans(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx)
+
+	orphans, err := s.repo.Orphans(ctx, orphanBatchSize)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to list orphaned attachments", zap.Error(err))
+		}
+		return 0, err
+	}
+
+	deleted := 0
+	for _, a := range orphans {
+		if err := s.deleteOrphan(ctx, a); err != nil {
+			if log != nil {
+				log.Error("failed to delete orphaned attachment", zap.Error(err), zap.Int("id", a.ID))
+			}
+			continue
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// deleteOrphan deletes the file of an attachment that belongs to no todo,
+// then the attachment.
+func (s *attachmentService) deleteOrphan(ctx context.Context, a domain.Attachment) error {
+	if err := s.blobs.Delete(ctx, a.Key); err != nil {
+		return err
+	}
+	return s.repo.DeleteOrphan(ctx, a.ID)
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// MockAttachmentRepository implements AttachmentRepository for testing.
+// Orphaned attachments have a TodoID of 0.
+type MockAttachmentRepository struct {
+	attachments map[int]domain.Attachment
+	nextID      int
+}
+
+func NewMockAttachmentRepository() *MockAttachmentRepository {
+	return &MockAttachmentRepository{attachments: make(map[int]domain.Attachment), nextID: 1}
+}
+
+func (m *MockAttachmentRepository) Create(ctx context.Context, a domain.Attachment) (*domain.Attachment, error) {
+	a.ID = m.nextID
+	a.CreatedAt = time.Date(2024, 1, 1, 0, 0, a.ID, 0, time.UTC)
+	m.nextID++
+	m.attachments[a.ID] = a
+	return &a, nil
+}
+
+func (m *MockAttachmentRepository) GetByID(ctx context.Context, todoID, id int) (*domain.Attachment, error) {
+	a, ok := m.attachments[id]
+	if !ok || a.TodoID != todoID {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	return &a, nil
+}
+
+func (m *MockAttachmentRepository) List(ctx context.Context, todoID int) ([]domain.Attachment, error) {
+	var attachments []domain.Attachment
+	for id := 1; id < m.nextID; id++ {
+		if a, ok := m.attachments[id]; ok && a.TodoID == todoID {
+			attachments = append(attachments, a)
+		}
+	}
+	return attachments, nil
+}
+
+func (m *MockAttachmentRepository) Detach(ctx context.Context, todoID, id int) (*domain.Attachment, error) {
+	a, err := m.GetByID(ctx, todoID, id)
+	if err != nil {
+		return nil, err
+	}
+	a.TodoID = 0
+	m.attachments[id] = *a
+	return a, nil
+}
+
+func (m *MockAttachmentRepository) Orphans(ctx context.Context, limit int) ([]domain.Attachment, error) {
+	orphans, _ := m.List(ctx, 0)
+	return orphans[:min(limit, len(orphans))], nil
+}
+
+func (m *MockAttachmentRepository) DeleteOrphan(ctx context.Context, id int) error {
+	if m.attachments[id].TodoID == 0 {
+		delete(m.attachments, id)
+	}
+	return nil
+}
+
+// memoryBlobStore implements BlobStore in memory. Deleting a key listed in
+// failDeletes fails.
+type memoryBlobStore struct {
+	blobs       map[string][]byte
+	failDeletes map[string]bool
+}
+
+func newMemoryBlobStore() *memoryBlobStore {
+	return &memoryBlobStore{blobs: make(map[string][]byte), failDeletes: make(map[string]bool)}
+}
+
+func (s *memoryBlobStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	b, err := io.ReadAll(io.LimitReader(r, size))
+	if err != nil {
+		return err
+	}
+	if int64(len(b)) != size {
+		return fmt.Errorf("got %d bytes, want %d", len(b), size)
+	}
+	s.blobs[key] = b
+	return nil
+}
+
+func (s *memoryBlobStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	b, ok := s.blobs[key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return struct {
+		io.ReadSeeker
+		io.Closer
+	}{bytes.NewReader(b), io.NopCloser(nil)}, nil
+}
+
+func (s *memoryBlobStore) Delete(ctx context.Context, key string) error {
+	if s.failDeletes[key] {
+		return errors.New("store unavailable")
+	}
+	delete(s.blobs, key)
+	return nil
+}
+
+const testPNG = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+func TestAttachmentService_Upload(t *testing.T) {
+	todos := NewMockTodoRepository()
+	todoID := createTodos(t, NewTodoService(todos), 1)[0]
+	blobs := newMemoryBlobStore()
+	service := NewAttachmentService(NewMockAttachmentRepository(), blobs, todos, WithMaxAttachmentSize(32))
+	ctx := context.Background()
+
+	a, err := service.Upload(ctx, domain.Attachment{TodoID: todoID, Filename: `C:\plans\floor.png`, Size: 16},
+		strings.NewReader(testPNG))
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if a.Filename != "floor.png" || a.ContentType != "image/png" || !strings.HasPrefix(a.Key, "todos/") {
+		t.Errorf("Upload() = %+v, want a PNG named floor.png", a)
+	}
+	if got := string(blobs.blobs[a.Key]); got != testPNG {
+		t.Errorf("stored %q, want %q", got, testPNG)
+	}
+
+	tests := []struct {
+		name string
+		todo int
+		body string
+		size int64
+		want error
+	}{
+		{"too large", todoID, strings.Repeat("a", 33), 33, domain.ErrAttachmentTooLarge},
+		{"empty", todoID, "", 0, domain.ErrAttachmentEmpty},
+		{"short", todoID, "abc", 10, domain.ErrAttachmentEmpty},
+		{"type not allowed", todoID, "<html><body>hi</body></html>", 28, domain.ErrAttachmentType},
+		{"todo not found", 999, testPNG, 16, domain.ErrTodoNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Upload(ctx, domain.Attachment{TodoID: tt.todo, Filename: "f", Size: tt.size},
+				strings.NewReader(tt.body))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Upload() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if len(blobs.blobs) != 1 {
+		t.Errorf("stored %d files, want only the accepted one", len(blobs.blobs))
+	}
+}
+
+func TestAttachmentService_OpenDelete(t *testing.T) {
+	todos := NewMockTodoRepository()
+	todoService := NewTodoService(todos)
+	ids := createTodos(t, todoService, 2)
+	repo := NewMockAttachmentRepository()
+	blobs := newMemoryBlobStore()
+	service := NewAttachmentService(repo, blobs, todos)
+	ctx := context.Background()
+
+	a, err := service.Upload(ctx, domain.Attachment{TodoID: ids[0], Filename: "notes.txt", Size: 5},
+		strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	got, blob, err := service.Open(ctx, ids[0], a.ID)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if b, _ := io.ReadAll(blob); string(b) != "hello" || got.ContentType != "text/plain; charset=utf-8" {
+		t.Errorf("Open() = %+v, %q, want the text file", got, b)
+	}
+	if _, _, err := service.Open(ctx, ids[1], a.ID); !errors.Is(err, domain.ErrAttachmentNotFound) {
+		t.Errorf("Open() from other todo error = %v, want %v", err, domain.ErrAttachmentNotFound)
+	}
+
+	// Attachments cannot be reached while their todo is in the trash
+	if err := todoService.Delete(ctx, ids[0], nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := service.List(ctx, ids[0]); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("List() error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+	if _, err := todoService.Restore(ctx, ids[0]); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	// A file that cannot be deleted right away is left for DeleteOrphans
+	blobs.failDeletes[a.Key] = true
+	if err := service.Delete(ctx, ids[0], a.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if attachments, err := service.List(ctx, ids[0]); err != nil || len(attachments) != 0 {
+		t.Errorf("List() after Delete() = %v, %v, want none", attachments, err)
+	}
+	if err := service.Delete(ctx, ids[0], a.ID); !errors.Is(err, domain.ErrAttachmentNotFound) {
+		t.Errorf("Delete() again error = %v, want %v", err, domain.ErrAttachmentNotFound)
+	}
+
+	if n, err := service.DeleteOrphans(ctx); err != nil || n != 0 {
+		t.Errorf("DeleteOrphans() = %d, %v, want nothing deleted while the store fails", n, err)
+	}
+	blobs.failDeletes[a.Key] = false
+	if n, err := service.DeleteOrphans(ctx); err != nil || n != 1 {
+		t.Errorf("DeleteOrphans() = %d, %v, want 1", n, err)
+	}
+	if len(blobs.blobs) != 0 || len(repo.attachments) != 0 {
+		t.Errorf("left %d files and %d attachments, want none", len(blobs.blobs), len(repo.attachments))
+	}
+}
+
+func TestCleanFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"report.pdf", "report.pdf"},
+		{"../../etc/passwd", "passwd"},
+		{`C:\Users\me\photo.jpg`, "photo.jpg"},
+		{"bad\r\nname.txt", "badname.txt"},
+		{"  ", "attachment"},
+		{"dir/", "dir"},
+		{"/", "attachment"},
+		{strings.Repeat("é", 200), strings.Repeat("é", 127)},
+	}
+	for _, tt := range tests {
+		if got := cleanFilename(tt.name); got != tt.want {
+			t.Errorf("cleanFilename(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
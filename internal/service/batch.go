@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// MaxBatchSize caps the number of operations in a single batch.
+const MaxBatchSize = 100
+
+// Batch applies a list of creates, updates and deletes, returning one
+// result per operation in the same order. Each operation goes through the
+// same checks as its single-todo counterpart.
+//
+// When atomic is true, the operations run in a single transaction and stop
+// at the first failure: the failed operation reports its error and every
+// other operation reports domain.ErrBatchAborted, as nothing was changed.
+// Otherwise every operation is attempted on its own and the failure of one
+// does not affect the others.
+func (s *todoService) Batch(ctx context.Context, ops []domain.BatchOperation, atomic bool) ([]domain.BatchResult, error) {
+	log := logger.FromContext(ctx)
+
+	if len(ops) > MaxBatchSize {
+		if log != nil {
+			log.Warn("batch too large", zap.Int("count", len(ops)))
+		}
+		return nil, fmt.Errorf("%w: at most %d operations are allowed", domain.ErrBatchTooLarge, MaxBatchSize)
+	}
+
+	results := make([]domain.BatchResult, len(ops))
+
+	if !atomic {
+		for i, op := range ops {
+			results[i] = s.applyBatchOperation(ctx, op)
+		}
+
+		if log != nil {
+			log.Info("batch applied", zap.Int("count", len(ops)))
+		}
+		return results, nil
+	}
+
+	failed := -1
+	err := s.repo.InTx(ctx, func(ctx context.Context) error {
+		for i, op := range ops {
+			results[i] = s.applyBatchOperation(ctx, op)
+			if results[i].Err != nil {
+				failed = i
+				return results[i].Err
+			}
+		}
+		return nil
+	})
+
+	if failed >= 0 {
+		for i := range results {
+			if i != failed {
+				results[i] = domain.BatchResult{Err: domain.ErrBatchAborted}
+			}
+		}
+
+		if log != nil {
+			log.Warn("atomic batch rolled back", zap.Int("failed", failed), zap.Error(results[failed].Err))
+		}
+		return results, nil
+	}
+
+	if err != nil {
+		if log != nil {
+			log.Error("failed to apply atomic batch", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if log != nil {
+		log.Info("atomic batch applied", zap.Int("count", len(ops)))
+	}
+	return results, nil
+}
+
+// applyBatchOperation carries out a single batch operation.
+func (s *todoService) applyBatchOperation(ctx context.Context, op domain.BatchOperation) domain.BatchResult {
+	switch op.Action {
+	case domain.BatchCreate:
+		id, err := s.Create(ctx, op.Todo)
+		return domain.BatchResult{ID: id, Err: err}
+	case domain.BatchUpdate:
+		t, err := s.Update(ctx, op.ID, op.Update)
+		return domain.BatchResult{ID: op.ID, Todo: t, Err: err}
+	case domain.BatchDelete:
+		err := s.Delete(ctx, op.ID, op.IfVersion)
+		return domain.BatchResult{ID: op.ID, Err: err}
+	default:
+		return domain.BatchResult{ID: op.ID, Err: fmt.Errorf("unknown batch action %q", op.Action)}
+	}
+}
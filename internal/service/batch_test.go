@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+func TestTodoService_Batch(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	existing, _ := service.Create(ctx, domain.Todo{Title: "Water plants"})
+	doomed, _ := service.Create(ctx, domain.Todo{Title: "Old receipts"})
+
+	done := domain.StatusDone
+	results, err := service.Batch(ctx, []domain.BatchOperation{
+		{Action: domain.BatchCreate, Todo: domain.Todo{Title: "Buy milk"}},
+		{Action: domain.BatchUpdate, ID: existing, Update: domain.TodoUpdate{Status: &done}},
+		{Action: domain.BatchDelete, ID: doomed},
+		{Action: domain.BatchCreate, Todo: domain.Todo{Title: "  "}},
+		{Action: domain.BatchDelete, ID: 999},
+	}, false)
+	if err != nil {
+		t.Fatalf("Batch() unexpected error = %v", err)
+	}
+
+	if len(results) != 5 {
+		t.Fatalf("Batch() returned %d results, want 5", len(results))
+	}
+	for i, want := range []error{nil, nil, nil, domain.ErrInvalidTitle, domain.ErrTodoNotFound} {
+		if !errors.Is(results[i].Err, want) {
+			t.Errorf("result %d error = %v, want %v", i, results[i].Err, want)
+		}
+	}
+
+	if _, err := service.GetByID(ctx, results[0].ID); err != nil {
+		t.Errorf("created todo %d not found: %v", results[0].ID, err)
+	}
+	if results[1].Todo == nil || results[1].Todo.Status != domain.StatusDone {
+		t.Errorf("updated todo = %+v, want status done", results[1].Todo)
+	}
+	if _, err := service.GetByID(ctx, doomed); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("deleted todo: GetByID() error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+}
+
+func TestTodoService_BatchAtomic(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	existing, _ := service.Create(ctx, domain.Todo{Title: "Water plants"})
+
+	title := "Water all plants"
+	results, err := service.Batch(ctx, []domain.BatchOperation{
+		{Action: domain.BatchCreate, Todo: domain.Todo{Title: "Buy milk"}},
+		{Action: domain.BatchUpdate, ID: existing, Update: domain.TodoUpdate{Title: &title}},
+		{Action: domain.BatchUpdate, ID: existing, Update: domain.TodoUpdate{IfVersion: domain.VersionMatch{1}}},
+		{Action: domain.BatchDelete, ID: existing},
+	}, true)
+	if err != nil {
+		t.Fatalf("Batch() unexpected error = %v", err)
+	}
+
+	// The version check fails because the previous operation bumped it
+	for i, want := range []error{domain.ErrBatchAborted, domain.ErrBatchAborted, domain.ErrVersionMismatch,
+		domain.ErrBatchAborted} {
+		if !errors.Is(results[i].Err, want) {
+			t.Errorf("result %d error = %v, want %v", i, results[i].Err, want)
+		}
+	}
+
+	todos, _ := service.List(ctx, domain.TodoQuery{})
+	if len(todos) != 1 || todos[0].Title != "Water plants" || todos[0].Version != 1 {
+		t.Errorf("todos after rolled back batch = %+v, want only the unchanged todo %d", todos, existing)
+	}
+
+	results, err = service.Batch(ctx, []domain.BatchOperation{
+		{Action: domain.BatchCreate, Todo: domain.Todo{Title: "Buy milk"}},
+		{Action: domain.BatchUpdate, ID: existing, Update: domain.TodoUpdate{Title: &title}},
+	}, true)
+	if err != nil {
+		t.Fatalf("Batch() unexpected error = %v", err)
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			t.Errorf("result %d unexpected error = %v", i, result.Err)
+		}
+	}
+	if todos, _ := service.List(ctx, domain.TodoQuery{}); len(todos) != 2 {
+		t.Errorf("List() returned %d todos after committed batch, want 2", len(todos))
+	}
+}
+
+func TestTodoService_BatchTooLarge(t *testing.T) {
+	service := NewTodoService(NewMockTodoRepository())
+
+	ops := make([]domain.BatchOperation, MaxBatchSize+1)
+	for i := range ops {
+		ops[i] = domain.BatchOperation{Action: domain.BatchCreate, Todo: domain.Todo{Title: "Todo"}}
+	}
+
+	for _, atomic := range []bool{false, true} {
+		if _, err := service.Batch(context.Background(), ops, atomic); !errors.Is(err, domain.ErrBatchTooLarge) {
+			t.Errorf("Batch(atomic=%t) error = %v, want %v", atomic, err, domain.ErrBatchTooLarge)
+		}
+	}
+}
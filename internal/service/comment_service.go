@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// commentCursorSort is the sort order comment cursors are issued for. It
+// keeps cursors of todo lists from being accepted for comments.
+const commentCursorSort = "comments"
+
+// CommentRepository is the persistence contract for the comments on todos.
+// The consumer (the service) owns the interface.
+type CommentRepository interface {
+	// Create inserts a comment and returns it as stored.
+	Create(ctx context.Context, comment domain.Comment) (*domain.Comment, error)
+	// GetByID returns comment id of todo todoID, or
+	// domain.ErrCommentNotFound.
+	GetByID(ctx context.Context, todoID, id int) (*domain.Comment, error)
+	// ListPage returns a single keyset page of the comments on a todo,
+	// oldest first.
+	ListPage(ctx context.Context, q domain.CommentPageQuery) ([]domain.Comment, error)
+	// Update replaces the body of a comment and returns it as stored.
+	Update(ctx context.Context, comment domain.Comment) (*domain.Comment, error)
+	// Delete removes comment id of todo todoID, or returns
+	// domain.ErrCommentNotFound.
+	Delete(ctx context.Context, todoID, id int) error
+}
+
+// CommentService defines operations available on the comments on todos.
+// Comments on a todo in the trash cannot be reached until it is restored.
+type CommentService interface {
+	Create(ctx context.Context, comment domain.Comment) (*domain.Comment, error)
+	GetByID(ctx context.Context, todoID, id int) (*domain.Comment, error)
+	ListPage(ctx context.Context, todoID int, p domain.PageRequest) (*domain.CommentPage, error)
+	Update(ctx context.Context, comment domain.Comment) (*domain.Comment, error)
+	Delete(ctx context.Context, todoID, id int) error
+}
+
+type commentService struct {
+	repo  CommentRepository
+	todos TodoRepository
+}
+
+// NewCommentService constructs a new CommentService. Todos are looked up
+// in todos, so that comments are only reached through todos outside the
+// trash.
+func NewCommentService(repo CommentRepository, todos TodoRepository) CommentService {
+	return &commentService{repo: repo, todos: todos}
+}
+
+// checkTodo returns domain.ErrTodoNotFound unless todo id exists and is
+// not in the trash.
+func (s *commentService) checkTodo(ctx context.Context, id int) error {
+	log := logger.FromContext(ctx)
+
+	if id > 0 {
+		_, err := s.todos.GetByID(ctx, id)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, domain.ErrTodoNotFound) {
+			if log != nil {
+				log.Error("failed to get todo for comments", zap.Error(err))
+			}
+			return err
+		}
+	}
+
+	if log != nil {
+		log.Warn("todo not found for comments", zap.Int("todo_id", id))
+	}
+	return domain.ErrTodoNotFound
+}
+
+// Create validates input and adds a comment to a todo.
+func (s *commentService) Create(ctx context.Context, comment domain.Comment) (*domain.Comment, error) {
+	log := logger.FromContext(ctx)
+
+	comment.Author = strings.TrimSpace(comment.Author)
+	comment.Body = strings.TrimSpace(comment.Body)
+	if err := comment.Validate(); err != nil {
+		if log != nil {
+			log.Warn("invalid comment", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if err := s.checkTodo(ctx, comment.TodoID); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, comment)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to create comment", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if log != nil {
+		log.Info("comment created successfully", zap.Int("todo_id", comment.TodoID), zap.Int("id", created.ID))
+	}
+	return created, nil
+}
+
+// GetByID retrieves a single comment on a todo.
+func (s *commentService) GetByID(ctx context.Context, todoID, id int) (*domain.Comment, error) {
+	log := logger.FromContext(ctx)
+
+	if err := s.checkTodo(ctx, todoID); err != nil {
+		return nil, err
+	}
+
+	c, err := s.repo.GetByID(ctx, todoID, id)
+	if errors.Is(err, domain.ErrCommentNotFound) {
+		if log != nil {
+			log.Warn("comment not found", zap.Int("todo_id", todoID), zap.Int("id", id))
+		}
+		return nil, err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to get comment", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// ListPage returns a page of the comments on a todo, oldest first, using
+// the same cursors and limits as todo pages.
+func (s *commentService) ListPage(ctx context.Context, todoID int, p domain.PageRequest) (*domain.CommentPage,
+	error) {
+	log := logger.FromContext(ctx)
+
+	limit := p.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+
+	if p.After != "" && p.Before != "" {
+		if log != nil {
+			log.Warn("both after and before cursors provided")
+		}
+		return nil, domain.ErrInvalidCursor
+	}
+
+	// Ask for one extra row to find out whether another page exists.
+	q := domain.CommentPageQuery{TodoID: todoID, Limit: limit + 1}
+	var err error
+	if p.After != "" {
+		if q.After, err = decodeCursor(p.After, commentCursorSort); err != nil {
+			if log != nil {
+				log.Warn("invalid after cursor", zap.String("cursor", p.After))
+			}
+			return nil, err
+		}
+	}
+	if p.Before != "" {
+		if q.Before, err = decodeCursor(p.Before, commentCursorSort); err != nil {
+			if log != nil {
+				log.Warn("invalid before cursor", zap.String("cursor", p.Before))
+			}
+			return nil, err
+		}
+	}
+
+	if err := s.checkTodo(ctx, todoID); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.repo.ListPage(ctx, q)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to list comment page", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	hasMore := len(comments) > limit
+	if hasMore {
+		// The extra row sits on the side furthest from the cursor.
+		if q.Before != nil {
+			comments = comments[1:]
+		} else {
+			comments = comments[:limit]
+		}
+	}
+
+	page := &domain.CommentPage{Items: comments}
+	if len(comments) > 0 {
+		first := encodeCursor(commentCursor(comments[0]))
+		last := encodeCursor(commentCursor(comments[len(comments)-1]))
+		page.NextCursor, page.PrevCursor = pageCursors(first, last, q.After != nil, q.Before != nil, hasMore)
+	}
+
+	if log != nil {
+		log.Info("comment page fetched", zap.Int("todo_id", todoID), zap.Int("count", len(comments)))
+	}
+	return page, nil
+}
+
+// commentCursor returns the cursor pointing at c within the comments on
+// its todo.
+func commentCursor(c domain.Comment) domain.Cursor {
+	return domain.Cursor{ID: c.ID, CreatedAt: c.CreatedAt, Sort: commentCursorSort}
+}
+
+// Update replaces the body of a comment. The author of a comment cannot be
+// changed.
+func (s *commentService) Update(ctx context.Context, comment domain.Comment) (*domain.Comment, error) {
+	log := logger.FromContext(ctx)
+
+	comment.Body = strings.TrimSpace(comment.Body)
+	if comment.Body == "" {
+		if log != nil {
+			log.Warn("invalid comment", zap.Error(domain.ErrInvalidCommentBody))
+		}
+		return nil, domain.ErrInvalidCommentBody
+	}
+
+	if err := s.checkTodo(ctx, comment.TodoID); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.Update(ctx, comment)
+	if errors.Is(err, domain.ErrCommentNotFound) {
+		if log != nil {
+			log.Warn("comment not found for update", zap.Int("todo_id", comment.TodoID), zap.Int("id", comment.ID))
+		}
+		return nil, err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to update comment", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if log != nil {
+		log.Info("comment updated successfully", zap.Int("todo_id", comment.TodoID), zap.Int("id", comment.ID))
+	}
+	return updated, nil
+}
+
+// Delete removes a comment from a todo.
+func (s *commentService) Delete(ctx context.Context, todoID, id int) error {
+	log := logger.FromContext(ctx)
+
+	if err := s.checkTodo(ctx, todoID); err != nil {
+		return err
+	}
+
+	err := s.repo.Delete(ctx, todoID, id)
+	if errors.Is(err, domain.ErrCommentNotFound) {
+		if log != nil {
+			log.Warn("comment not found for delete", zap.Int("todo_id", todoID), zap.Int("id", id))
+		}
+		return err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to delete comment", zap.Error(err))
+		}
+		return err
+	}
+
+	if log != nil {
+		log.Info("comment deleted successfully", zap.Int("todo_id", todoID), zap.Int("id", id))
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// MockCommentRepository implements CommentRepository for testing. Every
+// second comment is created at the same time as the one before it, so that
+// paging has to break ties by ID.
+type MockCommentRepository struct {
+	comments map[int]domain.Comment
+	nextID   int
+}
+
+func NewMockCommentRepository() *MockCommentRepository {
+	return &MockCommentRepository{comments: make(map[int]domain.Comment), nextID: 1}
+}
+
+func (m *MockCommentRepository) Create(ctx context.Context, c domain.Comment) (*domain.Comment, error) {
+	c.ID = m.nextID
+	c.CreatedAt = time.Date(2024, 1, 1, 0, 0, c.ID/2, 0, time.UTC)
+	m.nextID++
+	m.comments[c.ID] = c
+	return &c, nil
+}
+
+func (m *MockCommentRepository) GetByID(ctx context.Context, todoID, id int) (*domain.Comment, error) {
+	c, ok := m.comments[id]
+	if !ok || c.TodoID != todoID {
+		return nil, domain.ErrCommentNotFound
+	}
+	return &c, nil
+}
+
+func (m *MockCommentRepository) ListPage(ctx context.Context, q domain.CommentPageQuery) ([]domain.Comment, error) {
+	compare := func(c domain.Comment, cursor *domain.Cursor) int {
+		return cmp.Or(c.CreatedAt.Compare(cursor.CreatedAt), cmp.Compare(c.ID, cursor.ID))
+	}
+
+	var comments []domain.Comment
+	for _, c := range m.comments {
+		if c.TodoID != q.TodoID ||
+			(q.After != nil && compare(c, q.After) <= 0) || (q.Before != nil && compare(c, q.Before) >= 0) {
+			continue
+		}
+		comments = append(comments, c)
+	}
+	slices.SortFunc(comments, func(a, b domain.Comment) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	if len(comments) > q.Limit {
+		if q.Before != nil {
+			comments = comments[len(comments)-q.Limit:]
+		} else {
+			comments = comments[:q.Limit]
+		}
+	}
+	return comments, nil
+}
+
+func (m *MockCommentRepository) Update(ctx context.Context, c domain.Comment) (*domain.Comment, error) {
+	current, ok := m.comments[c.ID]
+	if !ok || current.TodoID != c.TodoID {
+		return nil, domain.ErrCommentNotFound
+	}
+	updatedAt := current.CreatedAt.Add(time.Hour)
+	current.Body, current.UpdatedAt = c.Body, &updatedAt
+	m.comments[c.ID] = current
+	return &current, nil
+}
+
+func (m *MockCommentRepository) Delete(ctx context.Context, todoID, id int) error {
+	if c, ok := m.comments[id]; !ok || c.TodoID != todoID {
+		return domain.ErrCommentNotFound
+	}
+	delete(m.comments, id)
+	return nil
+}
+
+// commentIDs returns the IDs of comments.
+func commentIDs(comments []domain.Comment) []int {
+	ids := make([]int, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func TestCommentService_Create(t *testing.T) {
+	todos := NewMockTodoRepository()
+	todoID := createTodos(t, NewTodoService(todos), 1)[0]
+	service := NewCommentService(NewMockCommentRepository(), todos)
+
+	tests := []struct {
+		name    string
+		comment domain.Comment
+		want    error
+	}{
+		{"valid", domain.Comment{TodoID: todoID, Author: " Alice ", Body: " Tiles? "}, nil},
+		{"blank author", domain.Comment{TodoID: todoID, Author: " ", Body: "Tiles?"}, domain.ErrInvalidCommentAuthor},
+		{"blank body", domain.Comment{TodoID: todoID, Author: "Alice", Body: " "}, domain.ErrInvalidCommentBody},
+		{"todo not found", domain.Comment{TodoID: 999, Author: "Alice", Body: "Tiles?"}, domain.ErrTodoNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := service.Create(context.Background(), tt.comment)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && (c.ID == 0 || c.Author != "Alice" || c.Body != "Tiles?") {
+				t.Errorf("Create() = %+v, want a trimmed comment", c)
+			}
+		})
+	}
+}
+
+func TestCommentService_ListPage(t *testing.T) {
+	todos := NewMockTodoRepository()
+	ids := createTodos(t, NewTodoService(todos), 2)
+	service := NewCommentService(NewMockCommentRepository(), todos)
+	ctx := context.Background()
+
+	var want []int
+	for i := 0; i < 5; i++ {
+		for _, todoID := range ids {
+			c, err := service.Create(ctx, domain.Comment{TodoID: todoID, Author: "Alice", Body: "Tiles?"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if todoID == ids[0] {
+				want = append(want, c.ID)
+			}
+		}
+	}
+
+	// Page forwards through the comments on the first todo, then back
+	var got []int
+	page := &domain.CommentPage{}
+	for first := true; first || page.NextCursor != ""; first = false {
+		var err error
+		if page, err = service.ListPage(ctx, ids[0], domain.PageRequest{Limit: 2, After: page.NextCursor}); err != nil {
+			t.Fatalf("ListPage() error = %v", err)
+		}
+		got = append(got, commentIDs(page.Items)...)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ListPage() forwards = %v, want %v", got, want)
+	}
+
+	page, err := service.ListPage(ctx, ids[0], domain.PageRequest{Limit: 2, Before: page.PrevCursor})
+	if err != nil {
+		t.Fatalf("ListPage() error = %v", err)
+	}
+	if got := commentIDs(page.Items); !reflect.DeepEqual(got, want[2:4]) || page.NextCursor == "" ||
+		page.PrevCursor == "" {
+		t.Errorf("ListPage() before = %v (next %q, prev %q), want %v with both cursors",
+			got, page.NextCursor, page.PrevCursor, want[2:4])
+	}
+
+	tests := []struct {
+		name   string
+		todoID int
+		page   domain.PageRequest
+		want   error
+	}{
+		{"todo not found", 999, domain.PageRequest{}, domain.ErrTodoNotFound},
+		{"both cursors", ids[0], domain.PageRequest{After: page.NextCursor, Before: page.PrevCursor},
+			domain.ErrInvalidCursor},
+		{"todo cursor", ids[0], domain.PageRequest{After: encodeCursor(domain.Cursor{ID: 1})}, domain.ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.ListPage(ctx, tt.todoID, tt.page); !errors.Is(err, tt.want) {
+				t.Errorf("ListPage() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommentService_Trash(t *testing.T) {
+	todos := NewMockTodoRepository()
+	todoService := NewTodoService(todos)
+	todoID := createTodos(t, todoService, 1)[0]
+	service := NewCommentService(NewMockCommentRepository(), todos)
+	ctx := context.Background()
+
+	c, err := service.Create(ctx, domain.Comment{TodoID: todoID, Author: "Alice", Body: "Tiles?"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Comments cannot be reached while their todo is in the trash
+	if err := todoService.Delete(ctx, todoID, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := service.ListPage(ctx, todoID, domain.PageRequest{}); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("ListPage() error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+	if _, err := service.GetByID(ctx, todoID, c.ID); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("GetByID() error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+	if err := service.Delete(ctx, todoID, c.ID); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("Delete() error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+
+	// and come back with it
+	if _, err := todoService.Restore(ctx, todoID); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	page, err := service.ListPage(ctx, todoID, domain.PageRequest{})
+	if err != nil || !reflect.DeepEqual(commentIDs(page.Items), []int{c.ID}) {
+		t.Errorf("ListPage() = %v, %v, want the comment back", page, err)
+	}
+}
+
+func TestCommentService_UpdateDelete(t *testing.T) {
+	todos := NewMockTodoRepository()
+	ids := createTodos(t, NewTodoService(todos), 2)
+	service := NewCommentService(NewMockCommentRepository(), todos)
+	ctx := context.Background()
+
+	c, err := service.Create(ctx, domain.Comment{TodoID: ids[0], Author: "Alice", Body: "Tiles?"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	updated, err := service.Update(ctx, domain.Comment{ID: c.ID, TodoID: ids[0], Author: "Bob", Body: " Ordered "})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated.Body != "Ordered" || updated.Author != "Alice" || updated.UpdatedAt == nil {
+		t.Errorf("Update() = %+v, want the body changed by Alice", updated)
+	}
+
+	updates := []struct {
+		name    string
+		comment domain.Comment
+		want    error
+	}{
+		{"blank body", domain.Comment{ID: c.ID, TodoID: ids[0], Body: " "}, domain.ErrInvalidCommentBody},
+		{"other todo", domain.Comment{ID: c.ID, TodoID: ids[1], Body: "Ordered"}, domain.ErrCommentNotFound},
+		{"todo not found", domain.Comment{ID: c.ID, TodoID: 999, Body: "Ordered"}, domain.ErrTodoNotFound},
+	}
+	for _, tt := range updates {
+		t.Run("update "+tt.name, func(t *testing.T) {
+			if _, err := service.Update(ctx, tt.comment); !errors.Is(err, tt.want) {
+				t.Errorf("Update() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if err := service.Delete(ctx, ids[1], c.ID); !errors.Is(err, domain.ErrCommentNotFound) {
+		t.Errorf("Delete() from other todo error = %v, want %v", err, domain.ErrCommentNotFound)
+	}
+	if err := service.Delete(ctx, ids[0], c.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := service.GetByID(ctx, ids[0], c.ID); !errors.Is(err, domain.ErrCommentNotFound) {
+		t.Errorf("GetByID() after delete error = %v, want %v", err, domain.ErrCommentNotFound)
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// encodeCursor turns a cursor into the opaque token handed to clients.
+func encodeCursor(c domain.Cursor) string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a token produced by encodeCursor. Cursors issued for
+// a different sort order are rejected, since their keyset values would
+// point at an unrelated position.
+func decodeCursor(token, sortSpec string) (*domain.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	var c domain.Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 || c.Sort != sortSpec {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// pageCursors returns the cursors of the pages next to one that starts at
+// first and ends at last. It was read after or before a cursor, or from
+// the start when neither is set, and hasMore reports whether more rows lie
+// beyond it in the direction it was read in.
+func pageCursors(first, last string, after, before, hasMore bool) (next, prev string) {
+	switch {
+	case before:
+		next = last
+		if hasMore {
+			prev = first
+		}
+	case after:
+		prev = first
+		if hasMore {
+			next = last
+		}
+	default:
+		if hasMore {
+			next = last
+		}
+	}
+	return next, prev
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// MaxGraphTodos caps the number of todos a dependency graph includes
+const MaxGraphTodos = 500
+
+// AddDependency records that todo id cannot be completed before todo
+// blockedBy is done. It reports whether the dependency is new. A
+// dependency that would make a todo wait on itself, directly or through
+// other todos, is rejected with domain.ErrDependencyCycle.
+func (s *todoService) AddDependency(ctx context.Context, id, blockedBy int) (bool, error) {
+	log := logger.FromContext(ctx)
+
+	if id == blockedBy {
+		if log != nil {
+			log.Warn("todo cannot wait on itself", zap.Int("id", id))
+		}
+		return false, fmt.Errorf("%w: todo %d cannot wait on itself", domain.ErrDependencyCycle, id)
+	}
+
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return false, err
+	}
+	if _, err := s.repo.GetByID(ctx, blockedBy); err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			if log != nil {
+				log.Warn("blocking todo not found", zap.Int("blocked_by_id", blockedBy))
+			}
+			return false, domain.ErrBlockerNotFound
+		}
+		if log != nil {
+			log.Error("failed to get blocking todo", zap.Error(err), zap.Int("blocked_by_id", blockedBy))
+		}
+		return false, err
+	}
+
+	// The graph is locked so that no concurrent change can close a cycle
+	// between the check and the insert.
+	var created bool
+	err := s.repo.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.LockDependencies(ctx); err != nil {
+			return err
+		}
+		if err := s.checkDependencyCycle(ctx, id, blockedBy); err != nil {
+			return err
+		}
+		var err error
+		created, err = s.repo.AddDependency(ctx, domain.Dependency{TodoID: id, BlockedByID: blockedBy})
+		return err
+	})
+	if errors.Is(err, domain.ErrDependencyCycle) || errors.Is(err, domain.ErrTodoNotFound) ||
+		errors.Is(err, domain.ErrBlockerNotFound) {
+		if log != nil {
+			log.Warn("dependency not added", zap.Error(err), zap.Int("id", id), zap.Int("blocked_by_id", blockedBy))
+		}
+		return false, err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to add dependency", zap.Error(err))
+		}
+		return false, err
+	}
+
+	return created, nil
+}
+
+// checkDependencyCycle fails if blocker already waits on todo, directly or
+// through other todos, so that todo waiting on blocker would close a
+// cycle. Todos in the trash are followed as well, since restoring them
+// brings their dependencies back.
+func (s *todoService) checkDependencyCycle(ctx context.Context, todo, blocker int) error {
+	seen := map[int]bool{blocker: true}
+	frontier := []int{blocker}
+
+	for len(frontier) > 0 {
+		deps, err := s.repo.Dependencies(ctx, frontier)
+		if err != nil {
+			return err
+		}
+
+		frontier = frontier[:0]
+		for _, d := range deps {
+			if d.BlockedByID == todo {
+				return fmt.Errorf("%w: todo %d already waits on todo %d", domain.ErrDependencyCycle, blocker, todo)
+			}
+			if !seen[d.BlockedByID] {
+				seen[d.BlockedByID] = true
+				frontier = append(frontier, d.BlockedByID)
+			}
+		}
+	}
+	return nil
+}
+
+// RemoveDependency removes the dependency of todo id on todo blockedBy.
+func (s *todoService) RemoveDependency(ctx context.Context, id, blockedBy int) error {
+	log := logger.FromContext(ctx)
+
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	err := s.repo.RemoveDependency(ctx, domain.Dependency{TodoID: id, BlockedByID: blockedBy})
+	if errors.Is(err, domain.ErrDependencyNotFound) {
+		if log != nil {
+			log.Warn("dependency not found", zap.Int("id", id), zap.Int("blocked_by_id", blockedBy))
+		}
+		return err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to remove dependency", zap.Error(err))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// DependencyGraph returns the todos that todo id transitively waits on or
+// holds up, together with the dependencies between them. Todos in the
+// trash are left out, and so is everything only reachable through them.
+// Past MaxGraphTodos todos the graph is cut short and marked truncated.
+func (s *todoService) DependencyGraph(ctx context.Context, id int) (*domain.DependencyGraph, error) {
+	log := logger.FromContext(ctx)
+
+	root, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &domain.DependencyGraph{TodoID: id, Todos: []domain.Todo{*root}}
+	nodes := map[int]bool{id: true}
+
+	// Walk upstream through the blockers, then downstream through the
+	// todos they block.
+	err = s.walkDependencies(ctx, graph, nodes, s.repo.Dependencies,
+		func(d domain.Dependency) int { return d.BlockedByID })
+	if err == nil {
+		err = s.walkDependencies(ctx, graph, nodes, s.repo.Dependents,
+			func(d domain.Dependency) int { return d.TodoID })
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to walk dependency graph", zap.Error(err), zap.Int("id", id))
+		}
+		return nil, err
+	}
+
+	// The walks only follow dependencies pointing one way from each todo,
+	// so the dependencies between the todos found are read in one go.
+	ids := make([]int, 0, len(graph.Todos))
+	for _, t := range graph.Todos {
+		ids = append(ids, t.ID)
+	}
+	deps, err := s.repo.Dependencies(ctx, ids)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to get dependencies", zap.Error(err), zap.Int("id", id))
+		}
+		return nil, err
+	}
+	for _, d := range deps {
+		if nodes[d.BlockedByID] {
+			graph.Dependencies = append(graph.Dependencies, d)
+		}
+	}
+
+	slices.SortFunc(graph.Todos, func(a, b domain.Todo) int { return a.ID - b.ID })
+	slices.SortFunc(graph.Dependencies, func(a, b domain.Dependency) int {
+		if a.TodoID != b.TodoID {
+			return a.TodoID - b.TodoID
+		}
+		return a.BlockedByID - b.BlockedByID
+	})
+
+	if log != nil {
+		log.Info("dependency graph retrieved", zap.Int("id", id), zap.Int("count", len(graph.Todos)))
+	}
+	return graph, nil
+}
+
+// walkDependencies adds to graph the todos reachable from its root by
+// following deps, where far gives the todo at the other end of each
+// dependency. Todos in nodes are already in the graph and not followed
+// again; todos in the trash are not followed at all.
+func (s *todoService) walkDependencies(ctx context.Context, graph *domain.DependencyGraph, nodes map[int]bool,
+	deps func(context.Context, []int) ([]domain.Dependency, error), far func(domain.Dependency) int) error {
+	frontier := []int{graph.TodoID}
+
+	for len(frontier) > 0 && !graph.Truncated {
+		edges, err := deps(ctx, frontier)
+		if err != nil {
+			return err
+		}
+
+		var next []int
+		for _, d := range edges {
+			id := far(d)
+			if nodes[id] || slices.Contains(next, id) {
+				continue
+			}
+			if len(nodes)+len(next) >= MaxGraphTodos {
+				graph.Truncated = true
+				break
+			}
+			next = append(next, id)
+		}
+		if len(next) == 0 {
+			return nil
+		}
+
+		todos, err := s.repo.ListByIDs(ctx, next)
+		if err != nil {
+			return err
+		}
+
+		frontier = frontier[:0]
+		for _, t := range todos {
+			nodes[t.ID] = true
+			frontier = append(frontier, t.ID)
+			graph.Todos = append(graph.Todos, t)
+		}
+	}
+	return nil
+}
+
+// checkBlockers fails with domain.ErrBlocked, naming the blockers, if todo
+// id waits on any todo that is not done. Blockers in the trash no longer
+// hold it up.
+func (s *todoService) checkBlockers(ctx context.Context, id int) error {
+	deps, err := s.repo.Dependencies(ctx, []int{id})
+	if err != nil || len(deps) == 0 {
+		return err
+	}
+
+	ids := make([]int, 0, len(deps))
+	for _, d := range deps {
+		ids = append(ids, d.BlockedByID)
+	}
+	blockers, err := s.repo.ListByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	var open []string
+	for _, b := range blockers {
+		if !b.IsCompleted() {
+			open = append(open, strconv.Itoa(b.ID))
+		}
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("%w: waiting on %s", domain.ErrBlocked, strings.Join(open, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// createTodos creates n todos titled after their position and returns
+// their IDs.
+func createTodos(t *testing.T, service TodoService, n int) []int {
+	t.Helper()
+	ids := make([]int, n)
+	for i := range ids {
+		id, err := service.Create(context.Background(), domain.Todo{Title: string(rune('A' + i))})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+func TestTodoService_AddDependency(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+	ids := createTodos(t, service, 4)
+	a, b, c, d := ids[0], ids[1], ids[2], ids[3]
+
+	// a waits on b, which waits on c
+	for _, dep := range [][2]int{{a, b}, {b, c}} {
+		created, err := service.AddDependency(ctx, dep[0], dep[1])
+		if err != nil || !created {
+			t.Fatalf("AddDependency(%d, %d) = %v, %v, want a new dependency", dep[0], dep[1], created, err)
+		}
+	}
+	if created, err := service.AddDependency(ctx, a, b); err != nil || created {
+		t.Errorf("AddDependency() again = %v, %v, want the existing dependency", created, err)
+	}
+
+	if err := service.Delete(ctx, d, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		id        int
+		blockedBy int
+		want      error
+	}{
+		{"itself", a, a, domain.ErrDependencyCycle},
+		{"direct cycle", b, a, domain.ErrDependencyCycle},
+		{"transitive cycle", c, a, domain.ErrDependencyCycle},
+		{"todo not found", 999, a, domain.ErrTodoNotFound},
+		{"todo in trash", d, a, domain.ErrTodoNotFound},
+		{"blocker not found", a, 999, domain.ErrBlockerNotFound},
+		{"blocker in trash", a, d, domain.ErrBlockerNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.AddDependency(ctx, tt.id, tt.blockedBy); !errors.Is(err, tt.want) {
+				t.Errorf("AddDependency() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if len(repo.deps) != 2 {
+		t.Errorf("dependencies = %v, want none persisted by failed adds", repo.deps)
+	}
+
+	// The same todos in the other direction are fine
+	if _, err := service.AddDependency(ctx, a, c); err != nil {
+		t.Errorf("AddDependency() shortcut error = %v", err)
+	}
+}
+
+func TestTodoService_RemoveDependency(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+	ids := createTodos(t, service, 2)
+
+	if _, err := service.AddDependency(ctx, ids[0], ids[1]); err != nil {
+		t.Fatalf("AddDependency() error = %v", err)
+	}
+	if err := service.RemoveDependency(ctx, ids[0], ids[1]); err != nil {
+		t.Fatalf("RemoveDependency() error = %v", err)
+	}
+	if err := service.RemoveDependency(ctx, ids[0], ids[1]); !errors.Is(err, domain.ErrDependencyNotFound) {
+		t.Errorf("RemoveDependency() again error = %v, want %v", err, domain.ErrDependencyNotFound)
+	}
+	if err := service.RemoveDependency(ctx, 999, ids[1]); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("RemoveDependency() error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+}
+
+func TestTodoService_CompleteBlocked(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+	ids := createTodos(t, service, 3)
+	todo, first, second := ids[0], ids[1], ids[2]
+	done := domain.StatusDone
+
+	for _, blocker := range []int{first, second} {
+		if _, err := service.AddDependency(ctx, todo, blocker); err != nil {
+			t.Fatalf("AddDependency() error = %v", err)
+		}
+	}
+
+	_, err := service.Update(ctx, todo, domain.TodoUpdate{Status: &done})
+	const want = "todo is blocked by todos that are not done: waiting on 2, 3"
+	if !errors.Is(err, domain.ErrBlocked) || err.Error() != want {
+		t.Fatalf("Update() error = %v, want %v naming both blockers", err, domain.ErrBlocked)
+	}
+	completed := true
+	_, err = service.Update(ctx, todo, domain.TodoUpdate{Completed: &completed})
+	if !errors.Is(err, domain.ErrBlocked) {
+		t.Errorf("Update() completed error = %v, want %v", err, domain.ErrBlocked)
+	}
+	if repo.todos[todo].IsCompleted() {
+		t.Fatal("blocked todo was completed")
+	}
+
+	// Other changes to a blocked todo are fine
+	title := "Still waiting"
+	if _, err := service.Update(ctx, todo, domain.TodoUpdate{Title: &title}); err != nil {
+		t.Errorf("Update() title error = %v", err)
+	}
+
+	if _, err := service.Update(ctx, first, domain.TodoUpdate{Status: &done}); err != nil {
+		t.Fatalf("Update() first blocker error = %v", err)
+	}
+	// A blocker in the trash no longer holds the todo up
+	if err := service.Delete(ctx, second, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := service.Update(ctx, todo, domain.TodoUpdate{Status: &done}); err != nil {
+		t.Errorf("Update() unblocked error = %v", err)
+	}
+}
+
+func TestTodoService_AutoCompleteBlockedParent(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo, WithAutoCompleteParents(true))
+	ctx := context.Background()
+	ids := createTodos(t, service, 2)
+	parent, blocker := ids[0], ids[1]
+	done := domain.StatusDone
+
+	subtask, err := service.Create(ctx, domain.Todo{Title: "Subtask", ParentID: &parent})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := service.AddDependency(ctx, parent, blocker); err != nil {
+		t.Fatalf("AddDependency() error = %v", err)
+	}
+
+	if _, err := service.Update(ctx, subtask, domain.TodoUpdate{Status: &done}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if repo.todos[parent].IsCompleted() {
+		t.Error("blocked parent was auto-completed")
+	}
+}
+
+// lateBlockerRepository adds a dependency while the dependency lock is
+// taken for the time after skips, as an AddDependency that got the lock
+// first would.
+type lateBlockerRepository struct {
+	*MockTodoRepository
+	late  *domain.Dependency
+	skips int
+}
+
+func (r *lateBlockerRepository) LockDependencies(ctx context.Context) error {
+	if r.late != nil && r.skips == 0 {
+		if _, err := r.AddDependency(ctx, *r.late); err != nil {
+			return err
+		}
+		r.late = nil
+	}
+	r.skips--
+	return r.MockTodoRepository.LockDependencies(ctx)
+}
+
+func TestTodoService_CompleteBlockedConcurrently(t *testing.T) {
+	ctx := context.Background()
+	done := domain.StatusDone
+
+	t.Run("todo", func(t *testing.T) {
+		repo := &lateBlockerRepository{MockTodoRepository: NewMockTodoRepository()}
+		service := NewTodoService(repo)
+		ids := createTodos(t, service, 2)
+		repo.late = &domain.Dependency{TodoID: ids[0], BlockedByID: ids[1]}
+
+		if _, err := service.Update(ctx, ids[0], domain.TodoUpdate{Status: &done}); !errors.Is(err,
+			domain.ErrBlocked) {
+			t.Errorf("Update() error = %v, want %v", err, domain.ErrBlocked)
+		}
+		if repo.todos[ids[0]].IsCompleted() {
+			t.Error("blocked todo was completed")
+		}
+	})
+
+	t.Run("parent", func(t *testing.T) {
+		repo := &lateBlockerRepository{MockTodoRepository: NewMockTodoRepository()}
+		service := NewTodoService(repo, WithAutoCompleteParents(true))
+		ids := createTodos(t, service, 2)
+		parent, blocker := ids[0], ids[1]
+		subtask, err := service.Create(ctx, domain.Todo{Title: "Subtask", ParentID: &parent})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+
+		// Completing the subtask takes the lock first, the parent second
+		repo.late, repo.skips = &domain.Dependency{TodoID: parent, BlockedByID: blocker}, 1
+		if _, err := service.Update(ctx, subtask, domain.TodoUpdate{Status: &done}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if repo.todos[parent].IsCompleted() {
+			t.Error("blocked parent was auto-completed")
+		}
+	})
+}
+
+func TestTodoService_DependencyGraph(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+	ids := createTodos(t, service, 7)
+	a, b, c, d, e, f, g := ids[0], ids[1], ids[2], ids[3], ids[4], ids[5], ids[6]
+
+	// c waits on b and a, b waits on a, d waits on c, e waits on d and a;
+	// f is in the trash and g is unrelated to c
+	edges := [][2]int{{c, b}, {c, a}, {b, a}, {d, c}, {e, d}, {e, a}, {f, c}, {g, a}}
+	for _, dep := range edges {
+		if _, err := service.AddDependency(ctx, dep[0], dep[1]); err != nil {
+			t.Fatalf("AddDependency(%d, %d) error = %v", dep[0], dep[1], err)
+		}
+	}
+	if err := service.Delete(ctx, f, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	graph, err := service.DependencyGraph(ctx, c)
+	if err != nil {
+		t.Fatalf("DependencyGraph() error = %v", err)
+	}
+
+	var got []int
+	for _, todo := range graph.Todos {
+		got = append(got, todo.ID)
+	}
+	if want := []int{a, b, c, d, e}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DependencyGraph() todos = %v, want %v", got, want)
+	}
+	wantDeps := []domain.Dependency{
+		{TodoID: b, BlockedByID: a}, {TodoID: c, BlockedByID: a}, {TodoID: c, BlockedByID: b},
+		{TodoID: d, BlockedByID: c}, {TodoID: e, BlockedByID: a}, {TodoID: e, BlockedByID: d},
+	}
+	if !reflect.DeepEqual(graph.Dependencies, wantDeps) || graph.TodoID != c || graph.Truncated {
+		t.Errorf("DependencyGraph() = %+v, want dependencies %v", graph, wantDeps)
+	}
+
+	if _, err := service.DependencyGraph(ctx, 999); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("DependencyGraph() error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+}
+
+func TestTodoService_DependencyGraph_Truncated(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	// A chain one longer than the graph may hold, built directly since
+	// checking each addition for cycles walks the whole chain
+	for i := 1; i <= MaxGraphTodos+1; i++ {
+		repo.todos[i] = &domain.Todo{ID: i, Title: "Step", CreatedAt: time.Now()}
+		if i > 1 {
+			repo.deps[domain.Dependency{TodoID: i, BlockedByID: i - 1}] = true
+		}
+	}
+	repo.nextID = MaxGraphTodos + 2
+
+	graph, err := service.DependencyGraph(ctx, 1)
+	if err != nil {
+		t.Fatalf("DependencyGraph() error = %v", err)
+	}
+	if len(graph.Todos) != MaxGraphTodos || !graph.Truncated || len(graph.Dependencies) != MaxGraphTodos-1 {
+		t.Errorf("DependencyGraph() = %d todos, %d dependencies, truncated %v, want %d todos, truncated",
+			len(graph.Todos), len(graph.Dependencies), graph.Truncated, MaxGraphTodos)
+	}
+}
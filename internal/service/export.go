@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// Export calls fn for every todo matching q, in list order, without
+// holding them all in memory. The todos come from a single snapshot, so
+// changes made while the export runs are not part of it. Export stops at
+// the first error fn returns and returns it.
+func (s *todoService) Export(ctx context.Context, q domain.TodoQuery, fn func(domain.Todo) error) error {
+	log := logger.FromContext(ctx)
+
+	q.Filter = s.resolveFilter(q.Filter)
+
+	count := 0
+	err := s.repo.Export(ctx, q, func(t domain.Todo) error {
+		count++
+		return fn(t)
+	})
+	if err != nil {
+		if log != nil {
+			log.Error("failed to export todos", zap.Error(err), zap.Int("exported", count))
+		}
+		return err
+	}
+
+	if log != nil {
+		log.Info("todos exported", zap.Int("count", count))
+	}
+	return nil
+}
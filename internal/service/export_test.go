@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+func TestTodoService_Export(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	for _, title := range []string{"Buy milk", "Water plants", "Buy bread"} {
+		if _, err := service.Create(ctx, domain.Todo{Title: title}); err != nil {
+			t.Fatalf("Create() unexpected error = %v", err)
+		}
+	}
+
+	var titles []string
+	err := service.Export(ctx, domain.TodoQuery{Filter: domain.TodoFilter{TitleContains: "buy"}}, func(t domain.Todo) error {
+		titles = append(titles, t.Title)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Export() unexpected error = %v", err)
+	}
+	if len(titles) != 2 || titles[0] != "Buy milk" || titles[1] != "Buy bread" {
+		t.Errorf("Export() exported %v, want the two matching todos in order", titles)
+	}
+
+	stop := errors.New("client went away")
+	calls := 0
+	err = service.Export(ctx, domain.TodoQuery{}, func(domain.Todo) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Export() = %v after %d calls, want %v after 1", err, calls, stop)
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// MaxImportSize caps the number of todos in a single import.
+const MaxImportSize = 10000
+
+// Import creates todos in a single transaction. Every todo goes through the
+// same checks as Create, and a creation time, when set, is kept. A todo
+// with a recurrence starts a series, as it would with Create.
+//
+// The returned slice holds, for each todo, why it cannot be imported. It is
+// nil when every todo is valid. Nothing is created unless every todo is
+// valid, and nothing is created at all when dryRun is true.
+func (s *todoService) Import(ctx context.Context, todos []domain.Todo, dryRun bool) ([]error, error) {
+	log := logger.FromContext(ctx)
+
+	if len(todos) > MaxImportSize {
+		if log != nil {
+			log.Warn("import too large", zap.Int("count", len(todos)))
+		}
+		return nil, fmt.Errorf("%w: at most %d todos are allowed", domain.ErrImportTooLarge, MaxImportSize)
+	}
+
+	prepared := make([]domain.Todo, len(todos))
+	errs := make([]error, len(todos))
+	parents := make(map[int]error)
+	invalid := 0
+
+	for i, todo := range todos {
+		errs[i] = s.prepareImport(ctx, &todo, parents)
+		if errs[i] != nil {
+			invalid++
+			continue
+		}
+		prepared[i] = todo
+	}
+
+	if invalid > 0 {
+		if log != nil {
+			log.Warn("invalid todos in import", zap.Int("count", len(todos)), zap.Int("invalid", invalid))
+		}
+		return errs, nil
+	}
+
+	if dryRun {
+		if log != nil {
+			log.Info("import checked", zap.Int("count", len(todos)))
+		}
+		return nil, nil
+	}
+
+	if err := s.importTodos(ctx, prepared); err != nil {
+		if log != nil {
+			log.Error("failed to import todos", zap.Error(err), zap.Int("count", len(todos)))
+		}
+		return nil, err
+	}
+
+	if log != nil {
+		log.Info("todos imported", zap.Int("count", len(todos)))
+	}
+	return nil, nil
+}
+
+// importTodos creates the series of the recurring todos and then the
+// todos, in one transaction.
+func (s *todoService) importTodos(ctx context.Context, todos []domain.Todo) error {
+	return s.repo.InTx(ctx, func(ctx context.Context) error {
+		for i, todo := range todos {
+			if todo.Recurrence == nil {
+				continue
+			}
+			seriesID, err := s.repo.CreateSeries(ctx, domain.Series{
+				Recurrence: *todo.Recurrence,
+				Start:      *todo.DueAt,
+			})
+			if err != nil {
+				return err
+			}
+			todos[i].SeriesID = &seriesID
+		}
+		return s.repo.Import(ctx, todos)
+	})
+}
+
+// prepareImport prepares and validates a todo to import. The outcome of
+// checking each parent is kept in parents, as imported todos often share
+// one.
+func (s *todoService) prepareImport(ctx context.Context, todo *domain.Todo, parents map[int]error) error {
+	if err := prepareTodo(todo); err != nil {
+		return err
+	}
+	if !todo.CreatedAt.IsZero() {
+		todo.CreatedAt = todo.CreatedAt.UTC()
+	}
+
+	if todo.ParentID == nil {
+		return nil
+	}
+
+	err, checked := parents[*todo.ParentID]
+	if !checked {
+		err = s.checkParent(ctx, 0, *todo.ParentID)
+		parents[*todo.ParentID] = err
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+func TestTodoService_Import(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	parent, _ := service.Create(ctx, domain.Todo{Title: "Move house"})
+	created := time.Date(2024, 1, 1, 9, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	todos := []domain.Todo{
+		{Title: "  Pack boxes ", ParentID: &parent, Tags: []string{"Home", "home"}},
+		{Title: "Book van", Status: domain.StatusDone, CreatedAt: created},
+	}
+
+	errs, err := service.Import(ctx, todos, false)
+	if err != nil || errs != nil {
+		t.Fatalf("Import() = %v, %v, want no errors", errs, err)
+	}
+
+	todos, _ = service.List(ctx, domain.TodoQuery{})
+	if len(todos) != 3 {
+		t.Fatalf("List() returned %d todos after import, want 3", len(todos))
+	}
+
+	packed, _ := service.GetByID(ctx, parent+1)
+	if packed.Title != "Pack boxes" || packed.Status != domain.StatusBacklog || packed.Priority != domain.PriorityMedium ||
+		len(packed.Tags) != 1 || packed.Tags[0] != "home" {
+		t.Errorf("imported todo = %+v, want it prepared like a created one", packed)
+	}
+	booked, _ := service.GetByID(ctx, parent+2)
+	if !booked.CreatedAt.Equal(created) || booked.CreatedAt.Location() != time.UTC {
+		t.Errorf("imported creation time = %v, want %v in UTC", booked.CreatedAt, created)
+	}
+}
+
+func TestTodoService_ImportRecurring(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+	done := domain.StatusDone
+
+	due := time.Date(2024, time.March, 1, 8, 0, 0, 0, time.UTC)
+	todos := []domain.Todo{
+		{Title: "Water plants"},
+		{Title: "Pay rent", DueAt: &due, Recurrence: &domain.Recurrence{Rule: "FREQ=MONTHLY"}},
+	}
+
+	errs, err := service.Import(ctx, todos, false)
+	if err != nil || errs != nil {
+		t.Fatalf("Import() = %v, %v, want no errors", errs, err)
+	}
+
+	plants, rent := repo.todos[1], repo.todos[2]
+	if plants.Position >= rent.Position {
+		t.Errorf("imported positions = %v, %v, want them in import order", plants.Position, rent.Position)
+	}
+	if plants.SeriesID != nil || rent.SeriesID == nil {
+		t.Fatalf("imported series = %v, %v, want only the recurring todo in one", plants.SeriesID, rent.SeriesID)
+	}
+	series := repo.series[*rent.SeriesID]
+	if series.Rule != "FREQ=MONTHLY" || series.TimeZone != "UTC" || !series.Start.Equal(due) {
+		t.Errorf("imported series = %+v, want a monthly one starting %v", series, due)
+	}
+
+	// The imported todo recurs like a created one
+	if _, err := service.Update(ctx, rent.ID, domain.TodoUpdate{Status: &done}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	next := repo.todos[3]
+	if next == nil || !equalIDs(next.SeriesID, rent.SeriesID) || !next.DueAt.Equal(due.AddDate(0, 1, 0)) {
+		t.Errorf("next occurrence = %+v, want one due %v", next, due.AddDate(0, 1, 0))
+	}
+}
+
+func TestTodoService_ImportInvalid(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	missing := 999
+	todos := []domain.Todo{
+		{Title: "Buy milk"},
+		{Title: " "},
+		{Title: "Pack boxes", ParentID: &missing},
+		{Title: "Book van", ParentID: &missing},
+		{Title: "Water plants", Tags: []string{"two words"}},
+		{Title: "Pay rent", Recurrence: &domain.Recurrence{Rule: "FREQ=MONTHLY"}},
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		errs, err := service.Import(ctx, todos, dryRun)
+		if err != nil {
+			t.Fatalf("Import(dryRun=%t) unexpected error = %v", dryRun, err)
+		}
+
+		want := []error{nil, domain.ErrInvalidTitle, domain.ErrParentNotFound, domain.ErrParentNotFound,
+			domain.ErrInvalidTag, domain.ErrRecurrenceWithoutDue}
+		if len(errs) != len(want) {
+			t.Fatalf("Import(dryRun=%t) returned %d errors, want %d", dryRun, len(errs), len(want))
+		}
+		for i := range want {
+			if !errors.Is(errs[i], want[i]) {
+				t.Errorf("Import(dryRun=%t) error %d = %v, want %v", dryRun, i, errs[i], want[i])
+			}
+		}
+	}
+
+	if len(repo.todos) != 0 {
+		t.Errorf("import with invalid todos created %d todos, want none", len(repo.todos))
+	}
+}
+
+func TestTodoService_ImportDryRun(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+
+	errs, err := service.Import(context.Background(), []domain.Todo{{Title: "Buy milk"}}, true)
+	if err != nil || errs != nil {
+		t.Fatalf("Import() = %v, %v, want no errors", errs, err)
+	}
+	if len(repo.todos) != 0 {
+		t.Errorf("dry run created %d todos, want none", len(repo.todos))
+	}
+}
+
+func TestTodoService_ImportTooLarge(t *testing.T) {
+	service := NewTodoService(NewMockTodoRepository())
+
+	todos := make([]domain.Todo, MaxImportSize+1)
+	if _, err := service.Import(context.Background(), todos, true); !errors.Is(err, domain.ErrImportTooLarge) {
+		t.Errorf("Import() error = %v, want %v", err, domain.ErrImportTooLarge)
+	}
+}
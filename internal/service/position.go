@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+const (
+	// moveAttempts is how often a move is tried before giving up on
+	// positions that concurrent moves keep taking first
+	moveAttempts = 5
+	// spreadWindow is how many todos on either side of a crowded gap are
+	// spread out at first. The window doubles until there is enough room.
+	spreadWindow = 16
+	// spreadGap is the smallest distance between todos that have been
+	// spread out
+	spreadGap = 1.0
+)
+
+// errNoRoom reports that a gap is too narrow to hold another position.
+var errNoRoom = errors.New("no room between positions")
+
+// Move places todo id directly before or after the anchor todo in list
+// order. A todo moved into a gap that has run out of room has the todos
+// around it spread out first. Moves into the same gap at the same time
+// never share a position: all but one are retried, and
+// domain.ErrPositionTaken is returned if that keeps failing.
+func (s *todoService) Move(ctx context.Context, id int, anchor domain.MoveAnchor) error {
+	log := logger.FromContext(ctx)
+
+	if id == anchor.TodoID {
+		if log != nil {
+			log.Warn("todo cannot be moved next to itself", zap.Int("id", id))
+		}
+		return domain.ErrInvalidMove
+	}
+
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	err := domain.ErrPositionTaken
+	for attempt := 0; attempt < moveAttempts && errors.Is(err, domain.ErrPositionTaken); attempt++ {
+		var gap domain.PositionGap
+		gap, err = s.tryMove(ctx, id, anchor)
+		if errors.Is(err, errNoRoom) {
+			// Once there is room again the move is retried, just like one
+			// that lost a race for its position.
+			if err = s.spreadPositions(ctx, *gap.After); err == nil {
+				err = domain.ErrPositionTaken
+			}
+		}
+	}
+	if errors.Is(err, domain.ErrTodoNotFound) || errors.Is(err, domain.ErrAnchorNotFound) ||
+		errors.Is(err, domain.ErrPositionTaken) {
+		if log != nil {
+			log.Warn("todo not moved", zap.Error(err), zap.Int("id", id), zap.Int("anchor_id", anchor.TodoID))
+		}
+		return err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to move todo", zap.Error(err))
+		}
+		return err
+	}
+
+	if log != nil {
+		log.Info("todo moved successfully", zap.Int("id", id), zap.Int("anchor_id", anchor.TodoID))
+	}
+	return nil
+}
+
+// tryMove places todo id in the gap next to the anchor, unless it is in
+// that gap already. It returns the gap, and errNoRoom if the gap has no
+// room left.
+func (s *todoService) tryMove(ctx context.Context, id int, anchor domain.MoveAnchor) (domain.PositionGap, error) {
+	var gap domain.PositionGap
+	err := s.repo.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.LockPositions(ctx, false); err != nil {
+			return err
+		}
+
+		t, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if gap, err = s.repo.PositionGap(ctx, id, anchor); err != nil {
+			return err
+		}
+		if gap.Contains(t.Position) {
+			return nil
+		}
+
+		position, ok := midpoint(gap)
+		if !ok {
+			return errNoRoom
+		}
+		return s.repo.SetPosition(ctx, id, position)
+	})
+	return gap, err
+}
+
+// midpoint returns the position halfway across gap, or nil for the end of
+// the list. A todo moved to the start goes a whole step before the first.
+// It reports false if the bounds are too close together to tell a
+// position between them apart from either.
+func midpoint(gap domain.PositionGap) (*float64, bool) {
+	switch {
+	case gap.Before == nil:
+		return nil, true
+	case gap.After == nil:
+		position := *gap.Before - spreadGap
+		return &position, true
+	}
+
+	position := *gap.After + (*gap.Before-*gap.After)/2
+	return &position, gap.Contains(position)
+}
+
+// spreadPositions spreads out the todos around position, widening the
+// stretch of the list it rewrites until they can be spaced at least
+// spreadGap apart. Only the rows of the todos in that stretch are locked,
+// and moves wait until it is done.
+func (s *todoService) spreadPositions(ctx context.Context, position float64) error {
+	log := logger.FromContext(ctx)
+
+	return s.repo.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.LockPositions(ctx, true); err != nil {
+			return err
+		}
+
+		for n := spreadWindow; ; n *= 2 {
+			window, err := s.repo.PositionsAround(ctx, position, n)
+			if err != nil {
+				return err
+			}
+
+			positions, ok := spread(window, position, n)
+			if !ok {
+				continue
+			}
+			if log != nil {
+				log.Info("spreading out todo positions", zap.Float64("position", position), zap.Int("count", len(positions)))
+			}
+			return s.repo.SetPositions(ctx, positions)
+		}
+	})
+}
+
+// spread spaces the todos of window, which holds up to n todos at or
+// before position and up to n after it, evenly apart. The first and last
+// todos stay where they are, since they border on todos outside the
+// window, except for the first when the window reaches the start of the
+// list: nothing lies before it, so it is free to move down. The last
+// always stays, so that no todo ends up past positions that are yet to be
+// handed out to new todos. It reports false if the window is too crowded
+// to space its todos at least spreadGap apart.
+func spread(window []domain.TodoPosition, position float64, n int) ([]domain.TodoPosition, bool) {
+	if len(window) < 2 {
+		return nil, true
+	}
+
+	below := 0
+	for _, p := range window {
+		if p.Position <= position {
+			below++
+		}
+	}
+
+	last := window[len(window)-1].Position
+	first, movable := window[0].Position, window[1:len(window)-1]
+	if below < n {
+		movable = window[:len(window)-1]
+		first = min(window[0].Position-spreadGap, last-spreadGap*float64(len(movable)+1))
+	}
+
+	step := (last - first) / float64(len(movable)+1)
+	if step < spreadGap {
+		return nil, false
+	}
+
+	positions := make([]domain.TodoPosition, 0, len(movable))
+	for i, p := range movable {
+		positions = append(positions, domain.TodoPosition{TodoID: p.TodoID, Position: first + step*float64(i+1)})
+	}
+	return positions, true
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// listOrder returns the IDs of the todos that are not in the trash, in
+// list order.
+func listOrder(repo *MockTodoRepository) []int {
+	var ids []int
+	for _, p := range repo.positions() {
+		if _, ok := repo.live(p.TodoID); ok {
+			ids = append(ids, p.TodoID)
+		}
+	}
+	return ids
+}
+
+func TestTodoService_Move(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+	ids := createTodos(t, service, 4)
+	a, b, c, d := ids[0], ids[1], ids[2], ids[3]
+
+	moves := []struct {
+		id     int
+		anchor domain.MoveAnchor
+		want   []int
+	}{
+		{d, domain.MoveAnchor{TodoID: a, After: true}, []int{a, d, b, c}},
+		{c, domain.MoveAnchor{TodoID: a}, []int{c, a, d, b}},
+		{c, domain.MoveAnchor{TodoID: b, After: true}, []int{a, d, b, c}},
+		{a, domain.MoveAnchor{TodoID: b}, []int{d, a, b, c}},
+		// Already in place
+		{a, domain.MoveAnchor{TodoID: d, After: true}, []int{d, a, b, c}},
+		{b, domain.MoveAnchor{TodoID: c}, []int{d, a, b, c}},
+	}
+	for _, m := range moves {
+		if err := service.Move(ctx, m.id, m.anchor); err != nil {
+			t.Fatalf("Move(%d, %+v) error = %v", m.id, m.anchor, err)
+		}
+		if got := listOrder(repo); !reflect.DeepEqual(got, m.want) {
+			t.Fatalf("Move(%d, %+v) order = %v, want %v", m.id, m.anchor, got, m.want)
+		}
+	}
+
+	// Moving to the end takes a position no new todo will be given
+	if err := service.Move(ctx, d, domain.MoveAnchor{TodoID: c, After: true}); err != nil {
+		t.Fatalf("Move() to end error = %v", err)
+	}
+	e := createTodos(t, service, 1)[0]
+	if got, want := listOrder(repo), []int{a, b, c, d, e}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+
+	if err := service.Delete(ctx, c, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		id     int
+		anchor domain.MoveAnchor
+		want   error
+	}{
+		{"next to itself", a, domain.MoveAnchor{TodoID: a}, domain.ErrInvalidMove},
+		{"todo not found", 999, domain.MoveAnchor{TodoID: a}, domain.ErrTodoNotFound},
+		{"todo in trash", c, domain.MoveAnchor{TodoID: a}, domain.ErrTodoNotFound},
+		{"anchor not found", a, domain.MoveAnchor{TodoID: 999}, domain.ErrAnchorNotFound},
+		{"anchor in trash", a, domain.MoveAnchor{TodoID: c}, domain.ErrAnchorNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.Move(ctx, tt.id, tt.anchor); !errors.Is(err, tt.want) {
+				t.Errorf("Move() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodoService_Move_Spreads(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+	ids := createTodos(t, service, 40)
+
+	// Every move halves the gap after the first todo, which runs out of
+	// room several times over unless the todos are spread out
+	first := ids[0]
+	for i := 0; i < 200; i++ {
+		moved := ids[2+i%38]
+		if err := service.Move(ctx, moved, domain.MoveAnchor{TodoID: first, After: true}); err != nil {
+			t.Fatalf("Move() error = %v", err)
+		}
+		order := listOrder(repo)
+		if order[0] != first || order[1] != moved {
+			t.Fatalf("Move() #%d order = %v, want %d right after %d", i, order, moved, first)
+		}
+	}
+
+	positions := repo.positions()
+	for i := 1; i < len(positions); i++ {
+		if !(positions[i-1].Position < positions[i].Position) {
+			t.Fatalf("positions %v and %v are not in order", positions[i-1], positions[i])
+		}
+	}
+	if last := positions[len(positions)-1]; last.Position >= repo.nextPosition {
+		t.Errorf("last position = %v, want it before the next position for new todos", last.Position)
+	}
+}
+
+func TestSpread(t *testing.T) {
+	window := func(positions ...float64) []domain.TodoPosition {
+		w := make([]domain.TodoPosition, 0, len(positions))
+		for i, p := range positions {
+			w = append(w, domain.TodoPosition{TodoID: i + 1, Position: p})
+		}
+		return w
+	}
+	crowded := math.Nextafter(5, 6)
+
+	tests := []struct {
+		name     string
+		window   []domain.TodoPosition
+		position float64
+		n        int
+		want     []float64
+		ok       bool
+	}{
+		{"keeps the ends", window(1, 5, crowded, 10), 5, 2, []float64{4, 7}, true},
+		{"too crowded", window(4, 5, crowded, 6), 5, 2, nil, false},
+		{"moves down at the start", window(5, crowded, 6), 5, 2, []float64{4, 5}, true},
+		{"too few todos", window(5), 5, 2, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			positions, ok := spread(tt.window, tt.position, tt.n)
+			var got []float64
+			for _, p := range positions {
+				got = append(got, p.Position)
+			}
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("spread() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// racingRepository loses the race for the first positions it is asked to
+// take, as if concurrent moves had taken them first.
+type racingRepository struct {
+	*MockTodoRepository
+	losses int
+}
+
+func (r *racingRepository) SetPosition(ctx context.Context, id int, position *float64) error {
+	if r.losses > 0 {
+		r.losses--
+		return domain.ErrPositionTaken
+	}
+	return r.MockTodoRepository.SetPosition(ctx, id, position)
+}
+
+func TestTodoService_Move_Retries(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		losses int
+		want   error
+	}{
+		{"wins the retry", moveAttempts - 1, nil},
+		{"keeps losing", moveAttempts, domain.ErrPositionTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &racingRepository{MockTodoRepository: NewMockTodoRepository(), losses: tt.losses}
+			service := NewTodoService(repo)
+			ids := createTodos(t, service, 3)
+
+			err := service.Move(ctx, ids[2], domain.MoveAnchor{TodoID: ids[0], After: true})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Move() error = %v, want %v", err, tt.want)
+			}
+			moved := slices.Equal(listOrder(repo.MockTodoRepository), []int{ids[0], ids[2], ids[1]})
+			if moved != (tt.want == nil) {
+				t.Errorf("order = %v, moved = %v", listOrder(repo.MockTodoRepository), moved)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// ProjectRepository is the persistence contract for projects.
+// The consumer (the service) owns the interface.
+type ProjectRepository interface {
+	Create(ctx context.Context, project domain.Project) (int, error)
+	GetByID(ctx context.Context, id int) (*domain.Project, error)
+	List(ctx context.Context) ([]domain.Project, error)
+	Update(ctx context.Context, project domain.Project) (*domain.Project, error)
+	Delete(ctx context.Context, id int, cascade bool) error
+}
+
+// ProjectService defines operations available on projects.
+type ProjectService interface {
+	Create(ctx context.Context, project domain.Project) (int, error)
+	GetByID(ctx context.Context, id int) (*domain.Project, error)
+	List(ctx context.Context) ([]domain.Project, error)
+	Update(ctx context.Context, project domain.Project) (*domain.Project, error)
+	Delete(ctx context.Context, id int, cascade bool) error
+}
+
+type projectService struct {
+	repo ProjectRepository
+}
+
+// NewProjectService constructs a new ProjectService.
+func NewProjectService(repo ProjectRepository) ProjectService {
+	return &projectService{repo: repo}
+}
+
+// Create validates input and delegates project creation to repository.
+func (s *projectService) Create(ctx context.Context, project domain.Project) (int, error) {
+	log := logger.FromContext(ctx)
+
+	project.Name = strings.TrimSpace(project.Name)
+	project.Description = strings.TrimSpace(project.Description)
+	if err := project.Validate(); err != nil {
+		if log != nil {
+			log.Warn("invalid project", zap.Error(err))
+		}
+		return 0, err
+	}
+
+	id, err := s.repo.Create(ctx, project)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to create project", zap.Error(err))
+		}
+		return 0, err
+	}
+
+	if log != nil {
+		log.Info("project created successfully", zap.Int("id", id))
+	}
+	return id, nil
+}
+
+// GetByID retrieves a project by id.
+func (s *projectService) GetByID(ctx context.Context, id int) (*domain.Project, error) {
+	log := logger.FromContext(ctx)
+
+	if id <= 0 {
+		if log != nil {
+			log.Warn("invalid project ID provided", zap.Int("id", id))
+		}
+		return nil, domain.ErrProjectNotFound
+	}
+
+	p, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrProjectNotFound) {
+		if log != nil {
+			log.Warn("project not found", zap.Int("id", id))
+		}
+		return nil, err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to get project", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// List retrieves all projects.
+func (s *projectService) List(ctx context.Context) ([]domain.Project, error) {
+	log := logger.FromContext(ctx)
+
+	projects, err := s.repo.List(ctx)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to list projects", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// Update validates input and replaces the name and description of a project.
+func (s *projectService) Update(ctx context.Context, project domain.Project) (*domain.Project, error) {
+	log := logger.FromContext(ctx)
+
+	if project.ID <= 0 {
+		if log != nil {
+			log.Warn("invalid project ID for update", zap.Int("id", project.ID))
+		}
+		return nil, domain.ErrProjectNotFound
+	}
+
+	project.Name = strings.TrimSpace(project.Name)
+	project.Description = strings.TrimSpace(project.Description)
+	if err := project.Validate(); err != nil {
+		if log != nil {
+			log.Warn("invalid project", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	p, err := s.repo.Update(ctx, project)
+	if errors.Is(err, domain.ErrProjectNotFound) {
+		if log != nil {
+			log.Warn("project not found for update", zap.Int("id", project.ID))
+		}
+		return nil, err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to update project", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if log != nil {
+		log.Info("project updated successfully", zap.Int("id", project.ID))
+	}
+	return p, nil
+}
+
+// Delete removes a project by id. With cascade the todos of the project
+// are moved to the trash; otherwise a project that still has todos outside
+// the trash is refused with domain.ErrProjectNotEmpty. Todos in the trash
+// stay there either way, no longer part of the project.
+func (s *projectService) Delete(ctx context.Context, id int, cascade bool) error {
+	log := logger.FromContext(ctx)
+
+	if id <= 0 {
+		if log != nil {
+			log.Warn("invalid project ID for deletion", zap.Int("id", id))
+		}
+		return domain.ErrProjectNotFound
+	}
+
+	err := s.repo.Delete(ctx, id, cascade)
+	if errors.Is(err, domain.ErrProjectNotFound) || errors.Is(err, domain.ErrProjectNotEmpty) {
+		if log != nil {
+			log.Warn("project not deleted", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to delete project", zap.Error(err))
+		}
+		return err
+	}
+
+	if log != nil {
+		log.Info("project deleted successfully", zap.Int("id", id), zap.Bool("cascade", cascade))
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// MockProjectRepository implements ProjectRepository for testing. Todo
+// counts are kept by hand since the mock has no todos table.
+type MockProjectRepository struct {
+	projects map[int]*domain.Project
+	nextID   int
+}
+
+func NewMockProjectRepository() *MockProjectRepository {
+	return &MockProjectRepository{
+		projects: make(map[int]*domain.Project),
+		nextID:   1,
+	}
+}
+
+func (m *MockProjectRepository) Create(ctx context.Context, p domain.Project) (int, error) {
+	id := m.nextID
+	m.nextID++
+
+	p.ID = id
+	m.projects[id] = &p
+
+	return id, nil
+}
+
+func (m *MockProjectRepository) GetByID(ctx context.Context, id int) (*domain.Project, error) {
+	p, exists := m.projects[id]
+	if !exists {
+		return nil, domain.ErrProjectNotFound
+	}
+	return p, nil
+}
+
+func (m *MockProjectRepository) List(ctx context.Context) ([]domain.Project, error) {
+	projects := make([]domain.Project, 0, len(m.projects))
+	for _, p := range m.projects {
+		projects = append(projects, *p)
+	}
+	return projects, nil
+}
+
+func (m *MockProjectRepository) Update(ctx context.Context, p domain.Project) (*domain.Project, error) {
+	current, exists := m.projects[p.ID]
+	if !exists {
+		return nil, domain.ErrProjectNotFound
+	}
+	p.CreatedAt = current.CreatedAt
+	p.TodoCount = current.TodoCount
+	m.projects[p.ID] = &p
+	return &p, nil
+}
+
+func (m *MockProjectRepository) Delete(ctx context.Context, id int, cascade bool) error {
+	p, exists := m.projects[id]
+	if !exists {
+		return domain.ErrProjectNotFound
+	}
+	if p.TodoCount > 0 && !cascade {
+		return domain.ErrProjectNotEmpty
+	}
+	delete(m.projects, id)
+	return nil
+}
+
+func TestProjectService_Create(t *testing.T) {
+	tests := []struct {
+		name     string
+		project  domain.Project
+		wantName string
+		wantErr  error
+	}{
+		{
+			name:     "valid project",
+			project:  domain.Project{Name: "Home", Description: "Chores"},
+			wantName: "Home",
+		},
+		{
+			name:     "name is trimmed",
+			project:  domain.Project{Name: "  Home  "},
+			wantName: "Home",
+		},
+		{
+			name:    "empty name",
+			project: domain.Project{Name: ""},
+			wantErr: domain.ErrInvalidProjectName,
+		},
+		{
+			name:    "whitespace name",
+			project: domain.Project{Name: "   "},
+			wantErr: domain.ErrInvalidProjectName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockProjectRepository()
+			service := NewProjectService(repo)
+
+			id, err := service.Create(context.Background(), tt.project)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Create() unexpected error = %v", err)
+			}
+
+			if got := repo.projects[id].Name; got != tt.wantName {
+				t.Errorf("Create() name = %v, want %v", got, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestProjectService_Update(t *testing.T) {
+	repo := NewMockProjectRepository()
+	service := NewProjectService(repo)
+	ctx := context.Background()
+
+	id, err := service.Create(ctx, domain.Project{Name: "Home"})
+	if err != nil {
+		t.Fatalf("Create() unexpected error = %v", err)
+	}
+
+	p, err := service.Update(ctx, domain.Project{ID: id, Name: " Garden ", Description: "Outside"})
+	if err != nil {
+		t.Fatalf("Update() unexpected error = %v", err)
+	}
+	if p.Name != "Garden" || p.Description != "Outside" {
+		t.Errorf("Update() = %+v, want name Garden and description Outside", p)
+	}
+
+	if _, err := service.Update(ctx, domain.Project{ID: id, Name: " "}); !errors.Is(err, domain.ErrInvalidProjectName) {
+		t.Errorf("Update() with blank name error = %v, want %v", err, domain.ErrInvalidProjectName)
+	}
+
+	if _, err := service.Update(ctx, domain.Project{ID: 999, Name: "Garden"}); !errors.Is(err, domain.ErrProjectNotFound) {
+		t.Errorf("Update() of missing project error = %v, want %v", err, domain.ErrProjectNotFound)
+	}
+}
+
+func TestProjectService_Delete(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        int
+		todoCount int
+		cascade   bool
+		wantErr   error
+	}{
+		{
+			name: "empty project",
+		},
+		{
+			name:      "project with todos is refused",
+			todoCount: 3,
+			wantErr:   domain.ErrProjectNotEmpty,
+		},
+		{
+			name:      "project with todos is deleted with cascade",
+			todoCount: 3,
+			cascade:   true,
+		},
+		{
+			name:    "non-existent project",
+			id:      999,
+			wantErr: domain.ErrProjectNotFound,
+		},
+		{
+			name:    "invalid id",
+			id:      -1,
+			wantErr: domain.ErrProjectNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockProjectRepository()
+			service := NewProjectService(repo)
+			ctx := context.Background()
+
+			id, err := service.Create(ctx, domain.Project{Name: "Home"})
+			if err != nil {
+				t.Fatalf("Create() unexpected error = %v", err)
+			}
+			repo.projects[id].TodoCount = tt.todoCount
+			if tt.id != 0 {
+				id = tt.id
+			}
+
+			err = service.Delete(ctx, id, tt.cascade)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Delete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
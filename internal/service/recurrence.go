@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/rrule"
+)
+
+const (
+	// DefaultOccurrences is the number of occurrences a preview lists when
+	// the client does not ask for another
+	DefaultOccurrences = 10
+	// MaxOccurrences caps the number of occurrences a preview lists
+	MaxOccurrences = 100
+)
+
+// Occurrences returns the series of a recurring todo and the due dates of
+// the next n occurrences that follow it, as completing each in turn would
+// create them. Fewer are returned when the series ends sooner.
+func (s *todoService) Occurrences(ctx context.Context, id int, n int) (*domain.Series, []time.Time, error) {
+	log := logger.FromContext(ctx)
+
+	t, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !t.IsRecurring() || t.DueAt == nil {
+		if log != nil {
+			log.Warn("todo does not recur", zap.Int("id", id))
+		}
+		return nil, nil, domain.ErrNotRecurring
+	}
+
+	series, err := s.repo.GetSeries(ctx, *t.SeriesID)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to get series", zap.Error(err), zap.Int("series_id", *t.SeriesID))
+		}
+		return nil, nil, err
+	}
+
+	rule, loc, err := parseRecurrence(series.Recurrence)
+	if err != nil {
+		if log != nil {
+			log.Error("invalid stored recurrence", zap.Error(err), zap.Int("series_id", series.ID))
+		}
+		return nil, nil, err
+	}
+
+	if n <= 0 {
+		n = DefaultOccurrences
+	}
+	if n > MaxOccurrences {
+		n = MaxOccurrences
+	}
+
+	occurrences := rule.Occurrences(series.Start.In(loc), *t.DueAt, n)
+
+	if log != nil {
+		log.Info("occurrences previewed", zap.Int("id", id), zap.Int("count", len(occurrences)))
+	}
+	return series, occurrences, nil
+}
+
+// prepareRecurrence checks the recurrence of a new todo and writes its
+// rule in canonical form. A missing time zone is UTC.
+func prepareRecurrence(r *domain.Recurrence) error {
+	if r.TimeZone == "" {
+		r.TimeZone = "UTC"
+	}
+	rule, _, err := parseRecurrence(*r)
+	if err != nil {
+		return err
+	}
+	r.Rule = rule.String()
+	return nil
+}
+
+// parseRecurrence reads the rule and time zone of a recurrence.
+func parseRecurrence(r domain.Recurrence) (*rrule.Rule, *time.Location, error) {
+	rule, err := rrule.Parse(r.Rule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", domain.ErrInvalidRecurrence, err)
+	}
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil || r.TimeZone == "Local" {
+		return nil, nil, fmt.Errorf("%w: unknown time zone %q", domain.ErrInvalidRecurrence, r.TimeZone)
+	}
+	return rule, loc, nil
+}
+
+// createRecurring creates a todo as the first occurrence of a new series,
+// which starts at its due date.
+func (s *todoService) createRecurring(ctx context.Context, todo domain.Todo) (int, error) {
+	var id int
+	err := s.repo.InTx(ctx, func(ctx context.Context) error {
+		seriesID, err := s.repo.CreateSeries(ctx, domain.Series{
+			Recurrence: *todo.Recurrence,
+			Start:      *todo.DueAt,
+		})
+		if err != nil {
+			return err
+		}
+		todo.SeriesID = &seriesID
+		id, err = s.repo.Create(ctx, todo)
+		return err
+	})
+	return id, err
+}
+
+// completeOccurrence applies upd, which completes an occurrence of a
+// series, and creates the next occurrence in the same transaction.
+func (s *todoService) completeOccurrence(ctx context.Context, id int, upd domain.TodoUpdate) (*domain.Todo, error) {
+	var t *domain.Todo
+	err := s.repo.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if t, err = s.repo.Update(ctx, id, upd); err != nil {
+			return err
+		}
+		return s.createNextOccurrence(ctx, *t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// createNextOccurrence creates the occurrence that follows t in its
+// series. Nothing is created once the series has ended, nor when the next
+// occurrence already exists because t was completed before, or is being
+// completed concurrently.
+func (s *todoService) createNextOccurrence(ctx context.Context, t domain.Todo) error {
+	log := logger.FromContext(ctx)
+
+	series, err := s.repo.GetSeries(ctx, *t.SeriesID)
+	if err != nil {
+		return err
+	}
+	rule, loc, err := parseRecurrence(series.Recurrence)
+	if err != nil {
+		return err
+	}
+
+	due, ok := rule.Next(series.Start.In(loc), *t.DueAt)
+	if !ok {
+		if log != nil {
+			log.Info("series ended", zap.Int("series_id", series.ID), zap.Int("id", t.ID))
+		}
+		return nil
+	}
+
+	next := nextOccurrence(t, due)
+	if err := prepareTodo(&next); err != nil {
+		return err
+	}
+
+	id, err := s.repo.Create(ctx, next)
+	if errors.Is(err, domain.ErrOccurrenceExists) {
+		if log != nil {
+			log.Info("next occurrence already exists", zap.Int("series_id", series.ID), zap.Time("due_at", due))
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if log != nil {
+		log.Info("next occurrence created", zap.Int("series_id", series.ID), zap.Int("id", id))
+	}
+	return nil
+}
+
+// nextOccurrence returns the occurrence that follows t, due at due. It
+// starts over in the initial status and keeps everything else, including
+// how long before its due date the reminder goes off.
+func nextOccurrence(t domain.Todo, due time.Time) domain.Todo {
+	next := domain.Todo{
+		Title:       t.Title,
+		Description: t.Description,
+		Priority:    t.Priority,
+		DueAt:       &due,
+		ProjectID:   t.ProjectID,
+		ParentID:    t.ParentID,
+		SeriesID:    t.SeriesID,
+		Tags:        t.Tags,
+	}
+	if t.RemindAt != nil {
+		remind := due.Add(t.RemindAt.Sub(*t.DueAt))
+		next.RemindAt = &remind
+	}
+	return next
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// createRent creates a todo due at 09:00 Berlin time on the first of every
+// month, with a reminder the evening before.
+func createRent(t *testing.T, service TodoService, rule string) int {
+	t.Helper()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	due := time.Date(2024, time.March, 1, 9, 0, 0, 0, berlin)
+	id, err := service.Create(context.Background(), domain.Todo{
+		Title:      "Pay rent",
+		Priority:   domain.PriorityHigh,
+		Tags:       []string{"home"},
+		DueAt:      &due,
+		RemindAt:   timePtr(due.Add(-12 * time.Hour)),
+		Recurrence: &domain.Recurrence{Rule: rule, TimeZone: "Europe/Berlin"},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return id
+}
+
+func TestTodoService_CreateRecurring(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	id := createRent(t, service, "rrule:freq=monthly;interval=1")
+	todo := repo.todos[id]
+	if !todo.IsRecurring() {
+		t.Fatalf("Create() todo = %+v, want it to recur", todo)
+	}
+	series := repo.series[*todo.SeriesID]
+	if series.Rule != "FREQ=MONTHLY" || series.TimeZone != "Europe/Berlin" || !series.Start.Equal(*todo.DueAt) {
+		t.Errorf("Create() series = %+v, want the canonical rule starting at the due date", series)
+	}
+
+	due := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		todo domain.Todo
+		want error
+	}{
+		{"invalid rule", domain.Todo{DueAt: &due, Recurrence: &domain.Recurrence{Rule: "FREQ=SOMETIMES"}},
+			domain.ErrInvalidRecurrence},
+		{"unknown time zone", domain.Todo{DueAt: &due,
+			Recurrence: &domain.Recurrence{Rule: "FREQ=DAILY", TimeZone: "Mars/Olympus"}}, domain.ErrInvalidRecurrence},
+		{"local time zone", domain.Todo{DueAt: &due,
+			Recurrence: &domain.Recurrence{Rule: "FREQ=DAILY", TimeZone: "Local"}}, domain.ErrInvalidRecurrence},
+		{"no due date", domain.Todo{Recurrence: &domain.Recurrence{Rule: "FREQ=DAILY"}},
+			domain.ErrRecurrenceWithoutDue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.todo.Title = "Recurring"
+			if _, err := service.Create(ctx, tt.todo); !errors.Is(err, tt.want) {
+				t.Errorf("Create() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if len(repo.series) != 1 {
+		t.Errorf("series = %d, want no series left by failed creates", len(repo.series))
+	}
+}
+
+func TestTodoService_CompleteOccurrence(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+	done, backlog := domain.StatusDone, domain.StatusBacklog
+
+	id := createRent(t, service, "FREQ=MONTHLY;COUNT=2")
+	first := *repo.todos[id]
+
+	// Clearing the due date would leave nothing to recur from
+	clearDue := domain.TodoUpdate{DueAt: domain.Nullable[time.Time]{Set: true}}
+	if _, err := service.Update(ctx, id, clearDue); !errors.Is(err, domain.ErrRecurrenceWithoutDue) {
+		t.Errorf("Update() error = %v, want %v", err, domain.ErrRecurrenceWithoutDue)
+	}
+
+	if _, err := service.Update(ctx, id, domain.TodoUpdate{Status: &done}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if len(repo.todos) != 2 {
+		t.Fatalf("todos = %d, want the next occurrence created", len(repo.todos))
+	}
+	next := *repo.todos[id+1]
+
+	// April starts in summer time: the time of day is kept, not the offset
+	berlin := first.DueAt.Location()
+	wantDue := time.Date(2024, time.April, 1, 9, 0, 0, 0, berlin)
+	if !next.DueAt.Equal(wantDue) || next.DueAt.UTC().Hour() != 7 {
+		t.Errorf("next due = %v, want %v", next.DueAt, wantDue)
+	}
+	if !next.RemindAt.Equal(wantDue.Add(-12 * time.Hour)) {
+		t.Errorf("next reminder = %v, want 12 hours before it is due", next.RemindAt)
+	}
+	if next.Status != domain.StatusBacklog || next.Title != first.Title || next.Priority != first.Priority ||
+		!reflect.DeepEqual(next.Tags, first.Tags) || !equalIDs(next.SeriesID, first.SeriesID) {
+		t.Errorf("next = %+v, want an open copy of %+v", next, first)
+	}
+
+	// Completing the same occurrence again does not create another
+	if _, err := service.Update(ctx, id, domain.TodoUpdate{Status: &backlog}); err != nil {
+		t.Fatalf("Update() reopen error = %v", err)
+	}
+	if _, err := service.Update(ctx, id, domain.TodoUpdate{Status: &done}); err != nil {
+		t.Fatalf("Update() complete again error = %v", err)
+	}
+	if len(repo.todos) != 2 {
+		t.Errorf("todos = %d, want no duplicate occurrence", len(repo.todos))
+	}
+
+	// The rule allows two occurrences, so the second is the last
+	if _, err := service.Update(ctx, next.ID, domain.TodoUpdate{Status: &done}); err != nil {
+		t.Fatalf("Update() last occurrence error = %v", err)
+	}
+	if len(repo.todos) != 2 {
+		t.Errorf("todos = %d, want the series to have ended", len(repo.todos))
+	}
+}
+
+func TestTodoService_CompleteOccurrence_RollsBack(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+	done := domain.StatusDone
+
+	id := createRent(t, service, "FREQ=MONTHLY")
+	delete(repo.series, *repo.todos[id].SeriesID)
+
+	if _, err := service.Update(ctx, id, domain.TodoUpdate{Status: &done}); !errors.Is(err, domain.ErrNotRecurring) {
+		t.Fatalf("Update() error = %v, want %v", err, domain.ErrNotRecurring)
+	}
+	if repo.todos[id].IsCompleted() || len(repo.todos) != 1 {
+		t.Errorf("todos = %+v, want the completion rolled back", repo.todos)
+	}
+}
+
+func TestTodoService_Occurrences(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	id := createRent(t, service, "FREQ=MONTHLY;BYMONTHDAY=1,15")
+
+	series, occurrences, err := service.Occurrences(ctx, id, 3)
+	if err != nil {
+		t.Fatalf("Occurrences() error = %v", err)
+	}
+	if series.Rule != "FREQ=MONTHLY;BYMONTHDAY=1,15" {
+		t.Errorf("Occurrences() series = %+v", series)
+	}
+	var got []string
+	for _, o := range occurrences {
+		got = append(got, o.Format(time.RFC3339))
+	}
+	want := []string{"2024-03-15T09:00:00+01:00", "2024-04-01T09:00:00+02:00", "2024-04-15T09:00:00+02:00"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Occurrences() = %v, want %v", got, want)
+	}
+
+	if _, occurrences, _ = service.Occurrences(ctx, id, 0); len(occurrences) != DefaultOccurrences {
+		t.Errorf("Occurrences(0) = %d occurrences, want %d", len(occurrences), DefaultOccurrences)
+	}
+	if _, occurrences, _ = service.Occurrences(ctx, id, MaxOccurrences+1); len(occurrences) != MaxOccurrences {
+		t.Errorf("Occurrences(max+1) = %d occurrences, want %d", len(occurrences), MaxOccurrences)
+	}
+
+	plain, _ := service.Create(ctx, domain.Todo{Title: "Once"})
+	if _, _, err := service.Occurrences(ctx, plain, 3); !errors.Is(err, domain.ErrNotRecurring) {
+		t.Errorf("Occurrences() error = %v, want %v", err, domain.ErrNotRecurring)
+	}
+	if _, _, err := service.Occurrences(ctx, 999, 3); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("Occurrences() error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+}
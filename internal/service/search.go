@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+const (
+	// MaxSearchLength caps the length of a search query, in characters
+	MaxSearchLength = 256
+	// MaxSearchWords caps the number of words a search query looks for
+	MaxSearchWords = 32
+)
+
+// Search finds the todos matching a full-text search, best matches first.
+// The query is read the way web search engines read theirs: words are all
+// required, "quoted words" must appear together, a leading - excludes a
+// word or phrase, a trailing * matches words starting with it, and "or"
+// separates alternatives. Any other punctuation only separates words, so
+// no query is a syntax error.
+func (s *todoService) Search(ctx context.Context, text string, filter domain.TodoFilter,
+	limit int) ([]domain.SearchResult, error) {
+	log := logger.FromContext(ctx)
+
+	terms, err := parseSearch(text)
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid search query", zap.Error(err), zap.String("query", text))
+		}
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+
+	results, err := s.repo.Search(ctx, domain.SearchQuery{
+		Any:    terms,
+		Filter: s.resolveFilter(filter),
+		Limit:  limit,
+	})
+	if err != nil {
+		if log != nil {
+			log.Error("failed to search todos", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if log != nil {
+		log.Info("todos searched", zap.Int("count", len(results)))
+	}
+	return results, nil
+}
+
+// parseSearch reads a search query into alternatives of terms. Words are
+// lower-cased and hold only letters and digits. Alternatives that only
+// exclude todos are dropped, as they would match nearly everything.
+func parseSearch(text string) ([][]domain.SearchTerm, error) {
+	if utf8.RuneCountInString(text) > MaxSearchLength {
+		return nil, fmt.Errorf("%w: at most %d characters are allowed", domain.ErrInvalidSearch, MaxSearchLength)
+	}
+
+	var (
+		alternatives [][]domain.SearchTerm
+		current      []domain.SearchTerm
+		words        int
+	)
+	for rest := text; ; {
+		var tok searchToken
+		if tok, rest = nextSearchToken(rest); tok.text == "" && rest == "" {
+			break
+		}
+
+		if tok.or() {
+			if len(current) > 0 {
+				alternatives = append(alternatives, current)
+				current = nil
+			}
+			continue
+		}
+
+		term := tok.term()
+		if len(term.Words) == 0 {
+			continue
+		}
+		words += len(term.Words)
+		current = append(current, term)
+	}
+	if len(current) > 0 {
+		alternatives = append(alternatives, current)
+	}
+
+	if words > MaxSearchWords {
+		return nil, fmt.Errorf("%w: at most %d words are allowed", domain.ErrInvalidSearch, MaxSearchWords)
+	}
+
+	alternatives = slices.DeleteFunc(alternatives, func(terms []domain.SearchTerm) bool {
+		return !slices.ContainsFunc(terms, func(t domain.SearchTerm) bool { return !t.Exclude })
+	})
+	if len(alternatives) == 0 {
+		return nil, fmt.Errorf("%w: nothing to search for", domain.ErrInvalidSearch)
+	}
+	return alternatives, nil
+}
+
+// searchToken is a word, a quoted phrase or the "or" operator of a search
+// query, as typed.
+type searchToken struct {
+	text    string
+	exclude bool
+	quoted  bool
+}
+
+// nextSearchToken splits the next token off a search query. An unterminated
+// quote runs to the end of the query.
+func nextSearchToken(query string) (searchToken, string) {
+	var tok searchToken
+
+	query = strings.TrimLeftFunc(query, unicode.IsSpace)
+	if strings.HasPrefix(query, "-") {
+		tok.exclude = true
+		query = query[1:]
+	}
+
+	if strings.HasPrefix(query, `"`) {
+		tok.quoted = true
+		query = query[1:]
+		end := strings.IndexByte(query, '"')
+		if end < 0 {
+			tok.text = query
+			return tok, ""
+		}
+		tok.text = query[:end]
+		return tok, query[end+1:]
+	}
+
+	end := strings.IndexFunc(query, unicode.IsSpace)
+	if end < 0 {
+		end = len(query)
+	}
+	tok.text = query[:end]
+	return tok, query[end:]
+}
+
+// or reports whether the token separates alternatives.
+func (t searchToken) or() bool {
+	return !t.quoted && !t.exclude && strings.EqualFold(t.text, "or")
+}
+
+// term maps the token onto the words it looks for.
+func (t searchToken) term() domain.SearchTerm {
+	words := strings.FieldsFunc(strings.ToLower(t.text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+	return domain.SearchTerm{
+		Words:   words,
+		Exclude: t.exclude,
+		Prefix:  !t.quoted && strings.HasSuffix(t.text, "*"),
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+func TestParseSearch(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  [][]domain.SearchTerm
+	}{
+		{
+			name:  "words",
+			query: "  Buy   MILK ",
+			want:  [][]domain.SearchTerm{{{Words: []string{"buy"}}, {Words: []string{"milk"}}}},
+		},
+		{
+			name:  "phrase",
+			query: `"grocery shopping" list`,
+			want:  [][]domain.SearchTerm{{{Words: []string{"grocery", "shopping"}}, {Words: []string{"list"}}}},
+		},
+		{
+			name:  "exclusion and prefix",
+			query: `milk -"oat milk" gro*`,
+			want: [][]domain.SearchTerm{{
+				{Words: []string{"milk"}},
+				{Words: []string{"oat", "milk"}, Exclude: true},
+				{Words: []string{"gro"}, Prefix: true},
+			}},
+		},
+		{
+			name:  "alternatives",
+			query: "milk OR bread or",
+			want:  [][]domain.SearchTerm{{{Words: []string{"milk"}}}, {{Words: []string{"bread"}}}},
+		},
+		{
+			name:  "punctuation separates words",
+			query: `e-mail (boss) & tsquery:* !x | 'y'`,
+			want: [][]domain.SearchTerm{{
+				{Words: []string{"e", "mail"}},
+				{Words: []string{"boss"}},
+				{Words: []string{"tsquery"}, Prefix: true},
+				{Words: []string{"x"}},
+				{Words: []string{"y"}},
+			}},
+		},
+		{
+			name:  "unterminated quote",
+			query: `"call mum`,
+			want:  [][]domain.SearchTerm{{{Words: []string{"call", "mum"}}}},
+		},
+		{
+			name:  "alternatives that only exclude are dropped",
+			query: "-milk or bread",
+			want:  [][]domain.SearchTerm{{{Words: []string{"bread"}}}},
+		},
+		{
+			name:  "letters beyond ascii",
+			query: "Café Straße",
+			want:  [][]domain.SearchTerm{{{Words: []string{"café"}}, {Words: []string{"straße"}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSearch(tt.query)
+			if err != nil {
+				t.Fatalf("parseSearch(%q) error = %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSearch(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSearch_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "empty", query: ""},
+		{name: "only punctuation", query: `"" - * & |`},
+		{name: "only exclusions", query: "-milk -bread"},
+		{name: "only or", query: "or OR"},
+		{name: "too long", query: strings.Repeat("a", MaxSearchLength+1)},
+		{name: "too many words", query: strings.Repeat("a ", MaxSearchWords+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseSearch(tt.query); !errors.Is(err, domain.ErrInvalidSearch) {
+				t.Errorf("parseSearch(%q) error = %v, want %v", tt.query, err, domain.ErrInvalidSearch)
+			}
+		})
+	}
+}
+
+func TestTodoService_Search(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	_, _ = service.Create(ctx, domain.Todo{Title: "Buy milk"})
+	_, _ = service.Create(ctx, domain.Todo{Title: "Buy oat milk"})
+	_, _ = service.Create(ctx, domain.Todo{Title: "Buy bread", Tags: []string{"shop"}})
+
+	results, err := service.Search(ctx, "buy -oat", domain.TodoFilter{Tags: []string{" Shop "}}, 0)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Todo.Title != "Buy bread" {
+		t.Errorf("Search() = %+v, want Buy bread", results)
+	}
+	if repo.lastSearch.Limit != DefaultPageLimit || !reflect.DeepEqual(repo.lastSearch.Filter.Tags, []string{"shop"}) {
+		t.Errorf("Search() query = %+v, want the default limit and a resolved filter", repo.lastSearch)
+	}
+
+	if _, err := service.Search(ctx, "milk", domain.TodoFilter{}, MaxPageLimit+1); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if repo.lastSearch.Limit != MaxPageLimit {
+		t.Errorf("Search() limit = %d, want %d", repo.lastSearch.Limit, MaxPageLimit)
+	}
+
+	_, _ = service.Create(ctx, domain.Todo{Title: "Bake", Description: "Sourdough, with *rye*"})
+	results, err = service.Search(ctx, "rye", domain.TodoFilter{}, 0)
+	if err != nil || len(results) != 1 || results[0].Todo.Title != "Bake" {
+		t.Errorf("Search() = %+v, %v, want the todo whose description matches", results, err)
+	}
+
+	if _, err := service.Search(ctx, "-milk", domain.TodoFilter{}, 0); !errors.Is(err, domain.ErrInvalidSearch) {
+		t.Errorf("Search() error = %v, want %v", err, domain.ErrInvalidSearch)
+	}
+}
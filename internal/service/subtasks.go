@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// GetTree retrieves a todo by id together with all of its subtasks,
+// nested under Subtasks.
+func (s *todoService) GetTree(ctx context.Context, id int) (*domain.Todo, error) {
+	log := logger.FromContext(ctx)
+
+	t, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	subtasks, err := s.repo.Subtasks(ctx, id)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to get subtasks", zap.Error(err), zap.Int("id", id))
+		}
+		return nil, err
+	}
+
+	tree := buildTree(*t, subtasks)
+	return &tree, nil
+}
+
+// checkParent verifies that todo may become a subtask of parent: the
+// parent must exist, must not be the todo itself or one of its subtasks,
+// and the todo's own subtasks must still fit under the depth limit once
+// moved. todo is 0 for a todo that is being created.
+func (s *todoService) checkParent(ctx context.Context, todo, parent int) error {
+	ancestors, err := s.repo.Ancestors(ctx, parent)
+	if err != nil {
+		return err
+	}
+	if len(ancestors) == 0 {
+		return domain.ErrParentNotFound
+	}
+	if todo != 0 && slices.Contains(ancestors, todo) {
+		return fmt.Errorf("%w: todo %d is an ancestor of todo %d", domain.ErrSubtaskCycle, todo, parent)
+	}
+
+	// ancestors holds the parent and everything above it, so the todo
+	// would sit len(ancestors) levels below the top.
+	depth := len(ancestors)
+	if todo != 0 {
+		subtasks, err := s.repo.Subtasks(ctx, todo)
+		if err != nil {
+			return err
+		}
+		depth += treeHeight(todo, subtasks)
+	}
+
+	if depth > s.maxSubtaskDepth {
+		return fmt.Errorf("%w: at most %d levels are allowed", domain.ErrSubtaskTooDeep, s.maxSubtaskDepth)
+	}
+	return nil
+}
+
+// completeParents marks parent as done if all of its subtasks are done and
+// no open todo blocks it. It runs in the transaction of the update that
+// completed the last subtask, and completing parent completes its own
+// parent in turn. A parent that is blocked or that the workflow does not
+// let move to done is left open; any other failure is returned and rolls
+// back the update that triggered it.
+func (s *todoService) completeParents(ctx context.Context, parent int) error {
+	log := logger.FromContext(ctx)
+
+	subtasks, err := s.repo.Subtasks(ctx, parent)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to get subtasks for auto-completion", zap.Error(err), zap.Int("id", parent))
+		}
+		return err
+	}
+
+	for _, subtask := range subtasks {
+		if equalIDs(subtask.ParentID, &parent) && !subtask.IsCompleted() {
+			return nil
+		}
+	}
+
+	current, err := s.repo.GetByID(ctx, parent)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to get parent for auto-completion", zap.Error(err), zap.Int("id", parent))
+		}
+		return err
+	}
+	if current.IsCompleted() || !s.workflow.Allows(current.Status, domain.StatusDone) {
+		return nil
+	}
+
+	done := domain.StatusDone
+	upd := domain.TodoUpdate{Status: &done, IfVersion: domain.VersionMatch{current.Version}}
+	_, err = s.applyUpdate(ctx, *current, upd, true)
+	if errors.Is(err, domain.ErrBlocked) {
+		if log != nil {
+			log.Info("parent not auto-completed", zap.Error(err), zap.Int("id", parent))
+		}
+		return nil
+	}
+	if errors.Is(err, domain.ErrVersionMismatch) {
+		return fmt.Errorf("%w: parent %d was modified", domain.ErrConcurrentUpdate, parent)
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to auto-complete parent", zap.Error(err), zap.Int("id", parent))
+		}
+		return err
+	}
+
+	if log != nil {
+		log.Info("parent auto-completed", zap.Int("id", parent))
+	}
+	return nil
+}
+
+// buildTree nests the flat list of subtasks under root.
+func buildTree(root domain.Todo, subtasks []domain.Todo) domain.Todo {
+	children := make(map[int][]domain.Todo)
+	for _, t := range subtasks {
+		if t.ParentID != nil {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		}
+	}
+
+	var attach func(t domain.Todo) domain.Todo
+	attach = func(t domain.Todo) domain.Todo {
+		t.Subtasks = make([]domain.Todo, 0, len(children[t.ID]))
+		for _, child := range children[t.ID] {
+			t.Subtasks = append(t.Subtasks, attach(child))
+		}
+		return t
+	}
+	return attach(root)
+}
+
+// treeHeight returns how many levels of subtasks hang below root.
+func treeHeight(root int, subtasks []domain.Todo) int {
+	depth := map[int]int{root: 0}
+	height := 0
+	// Subtasks are ordered by depth, so parents are seen before children.
+	for _, t := range subtasks {
+		if t.ParentID == nil {
+			continue
+		}
+		d := depth[*t.ParentID] + 1
+		depth[t.ID] = d
+		height = max(height, d)
+	}
+	return height
+}
+
+// equalIDs reports whether two optional IDs are the same.
+func equalIDs(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// createChain creates a todo with n levels of subtasks below it and
+// returns their IDs from the top down.
+func createChain(t *testing.T, service TodoService, n int) []int {
+	t.Helper()
+
+	ids := make([]int, 0, n+1)
+	var parent *int
+	for i := 0; i <= n; i++ {
+		id, err := service.Create(context.Background(), domain.Todo{Title: "Level", ParentID: parent})
+		if err != nil {
+			t.Fatalf("Create() level %d unexpected error = %v", i, err)
+		}
+		ids = append(ids, id)
+		parent = &ids[len(ids)-1]
+	}
+	return ids
+}
+
+func TestTodoService_SubtaskParent(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	tests := []struct {
+		name    string
+		chain   int
+		todo    func(ids []int) int
+		parent  func(ids []int) *int
+		wantErr error
+	}{
+		{
+			name:   "move under a sibling branch",
+			chain:  1,
+			parent: func(ids []int) *int { return intPtr(ids[0]) },
+		},
+		{
+			name:    "parent does not exist",
+			parent:  func(ids []int) *int { return intPtr(999) },
+			wantErr: domain.ErrParentNotFound,
+		},
+		{
+			name:    "parent is the todo itself",
+			chain:   1,
+			todo:    func(ids []int) int { return ids[1] },
+			parent:  func(ids []int) *int { return intPtr(ids[1]) },
+			wantErr: domain.ErrSubtaskCycle,
+		},
+		{
+			name:    "parent is a subtask of the todo",
+			chain:   2,
+			todo:    func(ids []int) int { return ids[0] },
+			parent:  func(ids []int) *int { return intPtr(ids[2]) },
+			wantErr: domain.ErrSubtaskCycle,
+		},
+		{
+			name:    "new todo would be nested too deeply",
+			chain:   DefaultMaxSubtaskDepth,
+			parent:  func(ids []int) *int { return intPtr(ids[len(ids)-1]) },
+			wantErr: domain.ErrSubtaskTooDeep,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewTodoService(NewMockTodoRepository())
+			ctx := context.Background()
+			ids := createChain(t, service, tt.chain)
+
+			var err error
+			if tt.todo == nil {
+				_, err = service.Create(ctx, domain.Todo{Title: "New", ParentID: tt.parent(ids)})
+			} else {
+				_, err = service.Update(ctx, tt.todo(ids), domain.TodoUpdate{ParentID: domain.SetTo(tt.parent(ids))})
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTodoService_SubtaskDepthOfMovedTree(t *testing.T) {
+	service := NewTodoService(NewMockTodoRepository(), WithMaxSubtaskDepth(2))
+	ctx := context.Background()
+
+	// A two level tree fits at the top but not below another todo
+	tree := createChain(t, service, 2)
+	other, err := service.Create(ctx, domain.Todo{Title: "Other"})
+	if err != nil {
+		t.Fatalf("Create() unexpected error = %v", err)
+	}
+
+	_, err = service.Update(ctx, tree[0], domain.TodoUpdate{ParentID: domain.SetTo(&other)})
+	if !errors.Is(err, domain.ErrSubtaskTooDeep) {
+		t.Errorf("Update() error = %v, want %v", err, domain.ErrSubtaskTooDeep)
+	}
+
+	// Detaching the deepest level makes room
+	_, err = service.Update(ctx, tree[2], domain.TodoUpdate{ParentID: domain.SetTo[int](nil)})
+	if err != nil {
+		t.Fatalf("Update() detaching unexpected error = %v", err)
+	}
+	if _, err := service.Update(ctx, tree[0], domain.TodoUpdate{ParentID: domain.SetTo(&other)}); err != nil {
+		t.Errorf("Update() unexpected error = %v", err)
+	}
+}
+
+func TestTodoService_GetTree(t *testing.T) {
+	service := NewTodoService(NewMockTodoRepository())
+	ctx := context.Background()
+
+	root, _ := service.Create(ctx, domain.Todo{Title: "Move house"})
+	pack, _ := service.Create(ctx, domain.Todo{Title: "Pack", ParentID: &root})
+	_, _ = service.Create(ctx, domain.Todo{Title: "Books", ParentID: &pack})
+	_, _ = service.Create(ctx, domain.Todo{Title: "Clean", ParentID: &root})
+
+	tree, err := service.GetTree(ctx, root)
+	if err != nil {
+		t.Fatalf("GetTree() unexpected error = %v", err)
+	}
+
+	if len(tree.Subtasks) != 2 || tree.Subtasks[0].Title != "Pack" || tree.Subtasks[1].Title != "Clean" {
+		t.Fatalf("GetTree() subtasks = %+v, want Pack and Clean", tree.Subtasks)
+	}
+	if got := tree.Subtasks[0].Subtasks; len(got) != 1 || got[0].Title != "Books" {
+		t.Errorf("GetTree() subtasks of Pack = %+v, want Books", got)
+	}
+	if got := tree.Subtasks[1].Subtasks; got == nil || len(got) != 0 {
+		t.Errorf("GetTree() subtasks of Clean = %#v, want an empty list", got)
+	}
+
+	if _, err := service.GetTree(ctx, 999); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("GetTree() error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+}
+
+func TestTodoService_AutoCompleteParents(t *testing.T) {
+	done := domain.StatusDone
+
+	for _, enabled := range []bool{false, true} {
+		repo := NewMockTodoRepository()
+		service := NewTodoService(repo, WithAutoCompleteParents(enabled))
+		ctx := context.Background()
+
+		ids := createChain(t, service, 2)
+		sibling, _ := service.Create(ctx, domain.Todo{Title: "Sibling", ParentID: &ids[1]})
+
+		// One open subtask keeps the parent open
+		if _, err := service.Update(ctx, ids[2], domain.TodoUpdate{Status: &done}); err != nil {
+			t.Fatalf("Update() unexpected error = %v", err)
+		}
+		if repo.todos[ids[1]].IsCompleted() {
+			t.Errorf("auto-complete %v: parent completed while a subtask is open", enabled)
+		}
+
+		// Completing the last subtask completes the whole chain
+		if _, err := service.Update(ctx, sibling, domain.TodoUpdate{Status: &done}); err != nil {
+			t.Fatalf("Update() unexpected error = %v", err)
+		}
+		for _, id := range ids[:2] {
+			if got := repo.todos[id].IsCompleted(); got != enabled {
+				t.Errorf("auto-complete %v: todo %d completed = %v, want %v", enabled, id, got, enabled)
+			}
+		}
+	}
+}
+
+// failingUpdateRepository fails every update of the todo fail.
+type failingUpdateRepository struct {
+	*MockTodoRepository
+	fail int
+	err  error
+}
+
+func (r *failingUpdateRepository) Update(ctx context.Context, id int, upd domain.TodoUpdate) (*domain.Todo, error) {
+	if id == r.fail {
+		return nil, r.err
+	}
+	return r.MockTodoRepository.Update(ctx, id, upd)
+}
+
+func TestTodoService_AutoCompleteParentsFailure(t *testing.T) {
+	errConnection := errors.New("connection reset")
+	done := domain.StatusDone
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "repository error", err: errConnection, wantErr: errConnection},
+		{name: "parent modified concurrently", err: domain.ErrVersionMismatch, wantErr: domain.ErrConcurrentUpdate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &failingUpdateRepository{MockTodoRepository: NewMockTodoRepository(), err: tt.err}
+			service := NewTodoService(repo, WithAutoCompleteParents(true))
+			ids := createChain(t, service, 2)
+			repo.fail = ids[0]
+
+			// The top of the chain fails, which rolls back the subtask and
+			// the parent completed before it
+			_, err := service.Update(context.Background(), ids[2], domain.TodoUpdate{Status: &done})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			for _, id := range ids {
+				if repo.todos[id].IsCompleted() {
+					t.Errorf("todo %d completed by a failed update", id)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoService_AutoCompleteRecurringParent(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo, WithAutoCompleteParents(true))
+	ctx := context.Background()
+	done := domain.StatusDone
+
+	parent := createRent(t, service, "FREQ=MONTHLY")
+	subtask, err := service.Create(ctx, domain.Todo{Title: "Transfer money", ParentID: &parent})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Completing the last subtask completes the occurrence like any update
+	// would, which creates the next one
+	if _, err := service.Update(ctx, subtask, domain.TodoUpdate{Status: &done}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if !repo.todos[parent].IsCompleted() {
+		t.Fatalf("parent not auto-completed")
+	}
+
+	var next *domain.Todo
+	for _, todo := range repo.todos {
+		if todo.ID != parent && equalIDs(todo.SeriesID, repo.todos[parent].SeriesID) {
+			next = todo
+		}
+	}
+	wantDue := repo.todos[parent].DueAt.AddDate(0, 1, 0)
+	if next == nil || next.IsCompleted() || !next.DueAt.Equal(wantDue) {
+		t.Errorf("next occurrence = %+v, want an open occurrence due %v", next, wantDue)
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// ListTags returns every tag in use along with how many todos carry it.
+func (s *todoService) ListTags(ctx context.Context) ([]domain.TagCount, error) {
+	log := logger.FromContext(ctx)
+
+	tags, err := s.repo.ListTags(ctx)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to list tags", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// normalizeTag trims and lower-cases a tag name, so that "Work" and
+// " work " are the same tag.
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTags normalizes names, drops duplicates and sorts the result.
+// Tags must be non-empty, at most domain.MaxTagLength characters long and
+// free of whitespace and commas, which separate tags in query strings.
+func normalizeTags(names []string) ([]string, error) {
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		tag := normalizeTag(name)
+		switch {
+		case tag == "":
+			return nil, fmt.Errorf("%w: tags cannot be empty", domain.ErrInvalidTag)
+		case utf8.RuneCountInString(tag) > domain.MaxTagLength:
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", domain.ErrInvalidTag, tag, domain.MaxTagLength)
+		case strings.ContainsFunc(tag, func(r rune) bool { return unicode.IsSpace(r) || r == ',' }):
+			return nil, fmt.Errorf("%w: %q contains whitespace or a comma", domain.ErrInvalidTag, tag)
+		}
+		tags = append(tags, tag)
+	}
+
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+
+	if len(tags) > domain.MaxTagsPerTodo {
+		return nil, fmt.Errorf("%w: a todo can have at most %d tags", domain.ErrInvalidTag, domain.MaxTagsPerTodo)
+	}
+	return tags, nil
+}
@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -14,42 +17,176 @@ import (
 // TodoRepository is the contract the persistence layer must satisfy.
 // The consumer (the service) owns the interface.
 type TodoRepository interface {
-	Create(ctx context.Context, title string) (int, error)
+	Create(ctx context.Context, todo domain.Todo) (int, error)
 	GetByID(ctx context.Context, id int) (*domain.Todo, error)
-	List(ctx context.Context) ([]domain.Todo, error)
-	Delete(ctx context.Context, id int) error
+	List(ctx context.Context, q domain.TodoQuery) ([]domain.Todo, error)
+	ListPage(ctx context.Context, q domain.PageQuery) ([]domain.Todo, error)
+	// Export calls fn for every todo matching q, in list order, reading
+	// them from a single consistent snapshot.
+	Export(ctx context.Context, q domain.TodoQuery, fn func(domain.Todo) error) error
+	// Import creates all of todos in a single transaction, or none of them,
+	// appending them to the manual order in the order given.
+	Import(ctx context.Context, todos []domain.Todo) error
+	// Search returns the todos matching q, best matches first.
+	Search(ctx context.Context, q domain.SearchQuery) ([]domain.SearchResult, error)
+	Update(ctx context.Context, id int, upd domain.TodoUpdate) (*domain.Todo, error)
+	Delete(ctx context.Context, id int, match domain.VersionMatch) error
+	ListTags(ctx context.Context) ([]domain.TagCount, error)
+	Ancestors(ctx context.Context, id int) ([]int, error)
+	Subtasks(ctx context.Context, id int) ([]domain.Todo, error)
+	ListTrash(ctx context.Context) ([]domain.Todo, error)
+	Restore(ctx context.Context, id int) (*domain.Todo, error)
+	DeletePermanently(ctx context.Context, id int) error
+	Purge(ctx context.Context, before time.Time) (int, error)
+	CreateSeries(ctx context.Context, s domain.Series) (int, error)
+	GetSeries(ctx context.Context, id int) (*domain.Series, error)
+	// ListByIDs returns the todos with the given IDs that are not in the
+	// trash, ordered by ID.
+	ListByIDs(ctx context.Context, ids []int) ([]domain.Todo, error)
+	// Dependencies returns the dependencies of the todos with the given
+	// IDs, and Dependents the dependencies on them.
+	Dependencies(ctx context.Context, ids []int) ([]domain.Dependency, error)
+	Dependents(ctx context.Context, ids []int) ([]domain.Dependency, error)
+	AddDependency(ctx context.Context, d domain.Dependency) (bool, error)
+	RemoveDependency(ctx context.Context, d domain.Dependency) error
+	// LockDependencies holds off changes to dependencies by others until
+	// the transaction it is called in ends.
+	LockDependencies(ctx context.Context) error
+	// PositionGap returns the gap in list order that a todo moved next to
+	// anchor is placed into.
+	PositionGap(ctx context.Context, id int, anchor domain.MoveAnchor) (domain.PositionGap, error)
+	// SetPosition moves a todo to position, or to the end of the list when
+	// position is nil.
+	SetPosition(ctx context.Context, id int, position *float64) error
+	// PositionsAround returns up to n positions at or before position and
+	// up to n after it, in list order.
+	PositionsAround(ctx context.Context, position float64, n int) ([]domain.TodoPosition, error)
+	SetPositions(ctx context.Context, positions []domain.TodoPosition) error
+	// LockPositions holds off changes to positions by others until the
+	// transaction it is called in ends. Shared holders only exclude an
+	// exclusive one.
+	LockPositions(ctx context.Context, exclusive bool) error
+	// InTx runs fn in a transaction that every call made with the context
+	// passed to fn takes part in. It is committed only if fn returns nil.
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 // TodoService defines operations available on TODO entities.
 type TodoService interface {
-	Create(ctx context.Context, title string) (int, error)
+	Create(ctx context.Context, todo domain.Todo) (int, error)
 	GetByID(ctx context.Context, id int) (*domain.Todo, error)
-	List(ctx context.Context) ([]domain.Todo, error)
-	Delete(ctx context.Context, id int) error
+	GetTree(ctx context.Context, id int) (*domain.Todo, error)
+	List(ctx context.Context, q domain.TodoQuery) ([]domain.Todo, error)
+	ListPage(ctx context.Context, q domain.TodoQuery, p domain.PageRequest) (*domain.TodoPage, error)
+	Export(ctx context.Context, q domain.TodoQuery, fn func(domain.Todo) error) error
+	Import(ctx context.Context, todos []domain.Todo, dryRun bool) ([]error, error)
+	Search(ctx context.Context, text string, filter domain.TodoFilter, limit int) ([]domain.SearchResult, error)
+	Update(ctx context.Context, id int, upd domain.TodoUpdate) (*domain.Todo, error)
+	Delete(ctx context.Context, id int, match domain.VersionMatch) error
+	ListTags(ctx context.Context) ([]domain.TagCount, error)
+	ListTrash(ctx context.Context) ([]domain.Todo, error)
+	Restore(ctx context.Context, id int) (*domain.Todo, error)
+	DeletePermanently(ctx context.Context, id int) error
+	PurgeTrash(ctx context.Context, retention time.Duration) (int, error)
+	Batch(ctx context.Context, ops []domain.BatchOperation, atomic bool) ([]domain.BatchResult, error)
+	Occurrences(ctx context.Context, id int, n int) (*domain.Series, []time.Time, error)
+	AddDependency(ctx context.Context, id, blockedBy int) (bool, error)
+	RemoveDependency(ctx context.Context, id, blockedBy int) error
+	DependencyGraph(ctx context.Context, id int) (*domain.DependencyGraph, error)
+	Move(ctx context.Context, id int, anchor domain.MoveAnchor) error
 }
 
+const (
+	// DefaultPageLimit is the page size used when the client does not ask for one
+	DefaultPageLimit = 20
+	// MaxPageLimit caps the page size a client may request
+	MaxPageLimit = 100
+	// DefaultMaxSubtaskDepth is how many levels of subtasks a top-level
+	// todo may have unless configured otherwise
+	DefaultMaxSubtaskDepth = 3
+	// updateAttempts is how often an update made without a version is
+	// tried before giving up on a todo that concurrent changes keep
+	// modifying
+	updateAttempts = 5
+)
+
 type todoService struct {
-	repo TodoRepository
+	repo     TodoRepository
+	workflow domain.Workflow
+	now      func() time.Time
+
+	maxSubtaskDepth     int
+	autoCompleteParents bool
+}
+
+// Option customizes a TodoService.
+type Option func(*todoService)
+
+// WithWorkflow replaces the default status workflow.
+func WithWorkflow(w domain.Workflow) Option {
+	return func(s *todoService) {
+		s.workflow = w
+	}
+}
+
+// WithMaxSubtaskDepth limits how many levels of subtasks a top-level todo
+// may have.
+func WithMaxSubtaskDepth(depth int) Option {
+	return func(s *todoService) {
+		s.maxSubtaskDepth = depth
+	}
+}
+
+// WithAutoCompleteParents marks a todo as done once all of its subtasks
+// are done.
+func WithAutoCompleteParents(enabled bool) Option {
+	return func(s *todoService) {
+		s.autoCompleteParents = enabled
+	}
 }
 
 // NewTodoService constructs a new TodoService.
-func NewTodoService(repo TodoRepository) TodoService {
-	return &todoService{repo: repo}
+func NewTodoService(repo TodoRepository, opts ...Option) TodoService {
+	s := &todoService{
+		repo:            repo,
+		workflow:        domain.DefaultWorkflow,
+		now:             time.Now,
+		maxSubtaskDepth: DefaultMaxSubtaskDepth,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// Create validates input and delegates todo creation to repository.
-func (s *todoService) Create(ctx context.Context, title string) (int, error) {
+// Create validates input and delegates todo creation to repository. A todo
+// with a recurrence is created as the first occurrence of a new series.
+func (s *todoService) Create(ctx context.Context, todo domain.Todo) (int, error) {
 	log := logger.FromContext(ctx)
 
-	title = strings.TrimSpace(title)
-	if title == "" {
+	if err := prepareTodo(&todo); err != nil {
 		if log != nil {
-			log.Warn("invalid empty title")
+			log.Warn("invalid todo", zap.Error(err))
 		}
-		return 0, domain.ErrInvalidTitle
+		return 0, err
 	}
 
-	id, err := s.repo.Create(ctx, title)
+	if todo.ParentID != nil {
+		if err := s.checkParent(ctx, 0, *todo.ParentID); err != nil {
+			if log != nil {
+				log.Warn("invalid parent", zap.Error(err), zap.Int("parent_id", *todo.ParentID))
+			}
+			return 0, err
+		}
+	}
+
+	var id int
+	var err error
+	if todo.Recurrence != nil {
+		id, err = s.createRecurring(ctx, todo)
+	} else {
+		id, err = s.repo.Create(ctx, todo)
+	}
 	if err != nil {
 		if log != nil {
 			log.Error("failed to create todo", zap.Error(err))
@@ -63,6 +200,32 @@ func (s *todoService) Create(ctx context.Context, title string) (int, error) {
 	return id, nil
 }
 
+// prepareTodo fills in the defaults of a new todo, normalizes its title
+// and tags and validates it.
+func prepareTodo(todo *domain.Todo) error {
+	todo.Title = strings.TrimSpace(todo.Title)
+	if todo.Status == "" {
+		todo.Status = domain.StatusBacklog
+	}
+	if todo.Priority == "" {
+		todo.Priority = domain.PriorityMedium
+	}
+
+	tags, err := normalizeTags(todo.Tags)
+	if err != nil {
+		return err
+	}
+	todo.Tags = tags
+
+	if todo.Recurrence != nil {
+		if err := prepareRecurrence(todo.Recurrence); err != nil {
+			return err
+		}
+	}
+
+	return todo.Validate()
+}
+
 // GetByID retrieves a todo by id.
 func (s *todoService) GetByID(ctx context.Context, id int) (*domain.Todo, error) {
 	log := logger.FromContext(ctx)
@@ -91,11 +254,13 @@ func (s *todoService) GetByID(ctx context.Context, id int) (*domain.Todo, error)
 	return t, nil
 }
 
-// List retrieves all todos.
-func (s *todoService) List(ctx context.Context) ([]domain.Todo, error) {
+// List retrieves all todos matching q.
+func (s *todoService) List(ctx context.Context, q domain.TodoQuery) ([]domain.Todo, error) {
 	log := logger.FromContext(ctx)
 
-	todos, err := s.repo.List(ctx)
+	q.Filter = s.resolveFilter(q.Filter)
+
+	todos, err := s.repo.List(ctx, q)
 	if err != nil {
 		if log != nil {
 			log.Error("failed to list todos", zap.Error(err))
@@ -109,8 +274,353 @@ func (s *todoService) List(ctx context.Context) ([]domain.Todo, error) {
 	return todos, nil
 }
 
-// Delete removes a todo by id.
-func (s *todoService) Delete(ctx context.Context, id int) error {
+// ListPage retrieves one page of todos matching q. The cursors in p are
+// opaque tokens taken from a previous page of the same query.
+func (s *todoService) ListPage(ctx context.Context, q domain.TodoQuery, p domain.PageRequest) (*domain.TodoPage, error) {
+	log := logger.FromContext(ctx)
+
+	limit := p.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+
+	if p.After != "" && p.Before != "" {
+		if log != nil {
+			log.Warn("both after and before cursors provided")
+		}
+		return nil, domain.ErrInvalidCursor
+	}
+
+	q.Filter = s.resolveFilter(q.Filter)
+	sortSpec := q.SortSpec()
+
+	// Ask for one extra row to find out whether another page exists.
+	pq := domain.PageQuery{TodoQuery: q, Limit: limit + 1}
+	var err error
+	if p.After != "" {
+		if pq.After, err = decodeCursor(p.After, sortSpec); err != nil {
+			if log != nil {
+				log.Warn("invalid after cursor", zap.String("cursor", p.After))
+			}
+			return nil, err
+		}
+	}
+	if p.Before != "" {
+		if pq.Before, err = decodeCursor(p.Before, sortSpec); err != nil {
+			if log != nil {
+				log.Warn("invalid before cursor", zap.String("cursor", p.Before))
+			}
+			return nil, err
+		}
+	}
+
+	todos, err := s.repo.ListPage(ctx, pq)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to list todo page", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	hasMore := len(todos) > limit
+	if hasMore {
+		// The extra row sits on the side furthest from the cursor.
+		if pq.Before != nil {
+			todos = todos[1:]
+		} else {
+			todos = todos[:limit]
+		}
+	}
+
+	page := &domain.TodoPage{Items: todos}
+	if len(todos) > 0 {
+		first := encodeCursor(domain.CursorFor(todos[0], sortSpec))
+		last := encodeCursor(domain.CursorFor(todos[len(todos)-1], sortSpec))
+		page.NextCursor, page.PrevCursor = pageCursors(first, last, pq.After != nil, pq.Before != nil, hasMore)
+	}
+
+	if log != nil {
+		log.Info("todo page fetched", zap.Int("count", len(todos)))
+	}
+	return page, nil
+}
+
+// Update validates the requested changes and applies them to an existing todo.
+// Only the non-nil fields of upd are modified. If upd.IfVersion does not
+// match the todo, domain.ErrVersionMismatch is returned and nothing changes.
+// Without upd.IfVersion the update is only stored if the todo is still as
+// it was validated against; it is retried otherwise, and
+// domain.ErrConcurrentUpdate is returned if that keeps failing.
+func (s *todoService) Update(ctx context.Context, id int, upd domain.TodoUpdate) (*domain.Todo, error) {
+	log := logger.FromContext(ctx)
+
+	if id <= 0 {
+		if log != nil {
+			log.Warn("invalid ID for update", zap.Int("id", id))
+		}
+		return nil, domain.ErrTodoNotFound
+	}
+
+	if upd.Title != nil {
+		title := strings.TrimSpace(*upd.Title)
+		upd.Title = &title
+	}
+
+	if upd.Tags != nil {
+		tags, err := normalizeTags(*upd.Tags)
+		if err != nil {
+			if log != nil {
+				log.Warn("invalid tags", zap.Error(err))
+			}
+			return nil, err
+		}
+		upd.Tags = &tags
+	}
+
+	if upd.IfVersion != nil {
+		return s.tryUpdate(ctx, id, upd)
+	}
+
+	for attempt := 0; attempt < updateAttempts; attempt++ {
+		t, err := s.tryUpdate(ctx, id, upd)
+		if !errors.Is(err, domain.ErrVersionMismatch) {
+			return t, err
+		}
+	}
+	if log != nil {
+		log.Warn("todo kept being modified concurrently", zap.Int("id", id))
+	}
+	return nil, domain.ErrConcurrentUpdate
+}
+
+// tryUpdate validates upd against the current state of todo id and stores
+// it. An update without upd.IfVersion is made conditional on the version
+// it was validated against, so that it fails with
+// domain.ErrVersionMismatch if the todo changes in between.
+func (s *todoService) tryUpdate(ctx context.Context, id int, upd domain.TodoUpdate) (*domain.Todo, error) {
+	log := logger.FromContext(ctx)
+
+	current, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrTodoNotFound) {
+		if log != nil {
+			log.Warn("todo not found for update", zap.Int("id", id))
+		}
+		return nil, err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to get todo for update", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	// Checked here to fail before validation; the repository repeats the
+	// check atomically with the write.
+	if !upd.IfVersion.Matches(current.Version) {
+		if log != nil {
+			log.Warn("todo version mismatch", zap.Int("id", id), zap.Int("version", current.Version))
+		}
+		return nil, domain.ErrVersionMismatch
+	}
+	if upd.IfVersion == nil {
+		upd.IfVersion = domain.VersionMatch{current.Version}
+	}
+
+	if upd, err = s.resolveStatus(*current, upd); err != nil {
+		if log != nil {
+			log.Warn("invalid status change", zap.Error(err), zap.String("from", string(current.Status)))
+		}
+		return nil, err
+	}
+
+	// Rules such as "reminder before due date" span several fields,
+	// so they are checked against the todo as it will look afterwards.
+	updated := upd.Apply(*current)
+	if err := updated.Validate(); err != nil {
+		if log != nil {
+			log.Warn("invalid todo update", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if upd.ParentID.Set && upd.ParentID.Value != nil && !equalIDs(current.ParentID, upd.ParentID.Value) {
+		if err := s.checkParent(ctx, id, *upd.ParentID.Value); err != nil {
+			if log != nil {
+				log.Warn("invalid parent", zap.Error(err), zap.Int("parent_id", *upd.ParentID.Value))
+			}
+			return nil, err
+		}
+	}
+
+	completing := updated.IsCompleted() && !current.IsCompleted()
+	t, err := s.applyUpdate(ctx, *current, upd, completing)
+	if errors.Is(err, domain.ErrBlocked) {
+		if log != nil {
+			log.Warn("todo is blocked", zap.Error(err), zap.Int("id", id))
+		}
+		return nil, err
+	}
+	if errors.Is(err, domain.ErrTodoNotFound) || errors.Is(err, domain.ErrVersionMismatch) ||
+		errors.Is(err, domain.ErrConcurrentUpdate) {
+		if log != nil {
+			log.Warn("todo not updated", zap.Error(err), zap.Int("id", id))
+		}
+		return nil, err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to update todo", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if log != nil {
+		log.Info("todo updated successfully", zap.Int("id", id))
+	}
+
+	return t, nil
+}
+
+// applyUpdate stores upd, which changes the todo current and completes it
+// if completing is set. A todo is only completed if no open todo blocks
+// it: the blockers are checked in the transaction that completes it, under
+// the lock AddDependency takes, so that none can be added in between.
+// Completing an occurrence of a series creates the next one, and with
+// auto-completion enabled the same transaction completes the parents
+// whose last open subtask this was.
+func (s *todoService) applyUpdate(ctx context.Context, current domain.Todo, upd domain.TodoUpdate,
+	completing bool) (*domain.Todo, error) {
+	if !completing {
+		return s.repo.Update(ctx, current.ID, upd)
+	}
+
+	var t *domain.Todo
+	err := s.repo.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.LockDependencies(ctx); err != nil {
+			return err
+		}
+		if err := s.checkBlockers(ctx, current.ID); err != nil {
+			return err
+		}
+		var err error
+		if current.IsRecurring() {
+			t, err = s.completeOccurrence(ctx, current.ID, upd)
+		} else {
+			t, err = s.repo.Update(ctx, current.ID, upd)
+		}
+		if err != nil {
+			return err
+		}
+		if s.autoCompleteParents && t.ParentID != nil {
+			return s.completeParents(ctx, *t.ParentID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// resolveStatus folds the legacy completed flag into a status change and
+// checks the resulting transition against the workflow. Marking a todo as
+// completed moves it to done; un-completing a done todo reopens it as
+// in progress.
+func (s *todoService) resolveStatus(current domain.Todo, upd domain.TodoUpdate) (domain.TodoUpdate, error) {
+	target := current.Status
+
+	switch {
+	case upd.Status != nil:
+		target = *upd.Status
+		if !target.Valid() {
+			return upd, domain.ErrInvalidStatus
+		}
+		if upd.Completed != nil && *upd.Completed != (target == domain.StatusDone) {
+			return upd, fmt.Errorf("%w: completed=%t conflicts with status %q",
+				domain.ErrInvalidStatus, *upd.Completed, target)
+		}
+	case upd.Completed != nil:
+		if *upd.Completed {
+			target = domain.StatusDone
+		} else if current.Status == domain.StatusDone {
+			target = domain.StatusInProgress
+		}
+	}
+
+	if !s.workflow.Allows(current.Status, target) {
+		return upd, fmt.Errorf("%w: %s -> %s", domain.ErrInvalidTransition, current.Status, target)
+	}
+
+	upd.Status = &target
+	upd.Completed = nil
+	return upd, nil
+}
+
+// resolveFilter normalizes f and turns its relative due date filters
+// into absolute bounds based on the current time.
+func (s *todoService) resolveFilter(f domain.TodoFilter) domain.TodoFilter {
+	f.TitleContains = strings.TrimSpace(f.TitleContains)
+
+	if len(f.Tags) > 0 {
+		tags := make([]string, 0, len(f.Tags))
+		for _, tag := range f.Tags {
+			if tag = normalizeTag(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		slices.Sort(tags)
+		f.Tags = slices.Compact(tags)
+	}
+
+	now := s.now()
+	loc := f.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	if f.Overdue {
+		notCompleted := false
+		f.Completed = &notCompleted
+		f.DueUntil = earliest(f.DueUntil, now)
+	}
+
+	if f.DueToday {
+		local := now.In(loc)
+		startOfDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		f.DueFrom = latest(f.DueFrom, startOfDay)
+		f.DueUntil = earliest(f.DueUntil, startOfDay.AddDate(0, 0, 1))
+	}
+
+	if f.DueWithin > 0 {
+		f.DueFrom = latest(f.DueFrom, now)
+		f.DueUntil = earliest(f.DueUntil, now.Add(f.DueWithin))
+	}
+
+	return f
+}
+
+// earliest returns the earlier of bound and t.
+func earliest(bound *time.Time, t time.Time) *time.Time {
+	if bound != nil && bound.Before(t) {
+		return bound
+	}
+	return &t
+}
+
+// latest returns the later of bound and t.
+func latest(bound *time.Time, t time.Time) *time.Time {
+	if bound != nil && bound.After(t) {
+		return bound
+	}
+	return &t
+}
+
+// Delete moves a todo and its subtasks to the trash, provided the todo is
+// at a version allowed by match.
+func (s *todoService) Delete(ctx context.Context, id int, match domain.VersionMatch) error {
 	log := logger.FromContext(ctx)
 
 	if id <= 0 {
@@ -120,10 +630,10 @@ func (s *todoService) Delete(ctx context.Context, id int) error {
 		return domain.ErrTodoNotFound
 	}
 
-	err := s.repo.Delete(ctx, id)
-	if errors.Is(err, domain.ErrTodoNotFound) {
+	err := s.repo.Delete(ctx, id, match)
+	if errors.Is(err, domain.ErrTodoNotFound) || errors.Is(err, domain.ErrVersionMismatch) {
 		if log != nil {
-			log.Warn("todo not found for deletion", zap.Int("id", id))
+			log.Warn("todo not deleted", zap.Error(err), zap.Int("id", id))
 		}
 		return err
 	}
@@ -135,7 +645,7 @@ func (s *todoService) Delete(ctx context.Context, id int) error {
 	}
 
 	if log != nil {
-		log.Info("todo deleted successfully", zap.Int("id", id))
+		log.Info("todo moved to trash", zap.Int("id", id))
 	}
 	return nil
 }
@@ -1,9 +1,17 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
 )
@@ -12,51 +20,549 @@ import (
 type MockTodoRepository struct {
 	todos  map[int]*domain.Todo
 	nextID int
+	series map[int]domain.Series
+	deps   map[domain.Dependency]bool
+
+	// nextPosition mirrors the sequence new todos take their position from
+	nextPosition float64
+
+	// lastSearch is the query of the latest call to Search
+	lastSearch domain.SearchQuery
 }
 
 func NewMockTodoRepository() *MockTodoRepository {
 	return &MockTodoRepository{
 		todos:  make(map[int]*domain.Todo),
 		nextID: 1,
+		series: make(map[int]domain.Series),
+		deps:   make(map[domain.Dependency]bool),
+
+		nextPosition: 1,
 	}
 }
 
-func (m *MockTodoRepository) Create(ctx context.Context, title string) (int, error) {
+func (m *MockTodoRepository) Create(ctx context.Context, t domain.Todo) (int, error) {
+	if m.occurrenceExists(0, t.SeriesID, t.DueAt) {
+		return 0, domain.ErrOccurrenceExists
+	}
+
 	id := m.nextID
 	m.nextID++
 
-	m.todos[id] = &domain.Todo{
-		ID:    id,
-		Title: title,
-	}
+	t.ID = id
+	t.Version = 1
+	t.Position = m.nextPosition
+	m.nextPosition++
+	m.todos[id] = &t
 
 	return id, nil
 }
 
-func (m *MockTodoRepository) GetByID(ctx context.Context, id int) (*domain.Todo, error) {
+// live returns the todo with the given ID unless it is missing or in the trash.
+func (m *MockTodoRepository) live(id int) (*domain.Todo, bool) {
 	todo, exists := m.todos[id]
+	if !exists || todo.IsDeleted() {
+		return nil, false
+	}
+	return todo, true
+}
+
+func (m *MockTodoRepository) GetByID(ctx context.Context, id int) (*domain.Todo, error) {
+	todo, exists := m.live(id)
 	if !exists {
 		return nil, domain.ErrTodoNotFound
 	}
 	return todo, nil
 }
 
-func (m *MockTodoRepository) List(ctx context.Context) ([]domain.Todo, error) {
+func (m *MockTodoRepository) List(ctx context.Context, q domain.TodoQuery) ([]domain.Todo, error) {
 	todos := make([]domain.Todo, 0, len(m.todos))
 	for _, todo := range m.todos {
+		if todo.IsDeleted() {
+			continue
+		}
+		if q.Filter.Completed != nil && todo.IsCompleted() != *q.Filter.Completed {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(todo.Title), strings.ToLower(q.Filter.TitleContains)) {
+			continue
+		}
+		if !matchesTags(todo.Tags, q.Filter.Tags, q.Filter.TagMatch) {
+			continue
+		}
 		todos = append(todos, *todo)
 	}
 	return todos, nil
 }
 
-func (m *MockTodoRepository) Delete(ctx context.Context, id int) error {
-	if _, exists := m.todos[id]; !exists {
+func (m *MockTodoRepository) Export(ctx context.Context, q domain.TodoQuery, fn func(domain.Todo) error) error {
+	todos, _ := m.List(ctx, q)
+	sort.Slice(todos, func(i, j int) bool { return todos[i].ID < todos[j].ID })
+
+	for _, todo := range todos {
+		if err := fn(todo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockTodoRepository) Import(ctx context.Context, todos []domain.Todo) error {
+	for _, todo := range todos {
+		if _, err := m.Create(ctx, todo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search matches terms against the lower-cased title and description,
+// ignoring word boundaries, and orders matches by ID.
+func (m *MockTodoRepository) Search(ctx context.Context, q domain.SearchQuery) ([]domain.SearchResult, error) {
+	m.lastSearch = q
+
+	todos, _ := m.List(ctx, domain.TodoQuery{Filter: q.Filter})
+	sort.Slice(todos, func(i, j int) bool { return todos[i].ID < todos[j].ID })
+
+	results := make([]domain.SearchResult, 0)
+	for _, todo := range todos {
+		if len(results) < q.Limit && slices.ContainsFunc(q.Any, func(terms []domain.SearchTerm) bool {
+			return matchesSearch(todo.Title+"\n"+todo.Description, terms)
+		}) {
+			results = append(results, domain.SearchResult{Todo: todo, Score: 1, Snippet: todo.Title})
+		}
+	}
+	return results, nil
+}
+
+func matchesSearch(text string, terms []domain.SearchTerm) bool {
+	text = strings.ToLower(text)
+	for _, term := range terms {
+		if strings.Contains(text, strings.Join(term.Words, " ")) == term.Exclude {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *MockTodoRepository) ListPage(ctx context.Context, q domain.PageQuery) ([]domain.Todo, error) {
+	todos, _ := m.List(ctx, q.TodoQuery)
+	sort.Slice(todos, func(i, j int) bool { return todos[i].ID < todos[j].ID })
+
+	page := make([]domain.Todo, 0, q.Limit)
+	if q.Before != nil {
+		for i := len(todos) - 1; i >= 0 && len(page) < q.Limit; i-- {
+			if todos[i].ID < q.Before.ID {
+				page = append([]domain.Todo{todos[i]}, page...)
+			}
+		}
+		return page, nil
+	}
+
+	for _, todo := range todos {
+		if len(page) == q.Limit {
+			break
+		}
+		if q.After == nil || todo.ID > q.After.ID {
+			page = append(page, todo)
+		}
+	}
+	return page, nil
+}
+
+func (m *MockTodoRepository) Update(ctx context.Context, id int, upd domain.TodoUpdate) (*domain.Todo, error) {
+	todo, exists := m.live(id)
+	if !exists {
+		return nil, domain.ErrTodoNotFound
+	}
+	if !upd.IfVersion.Matches(todo.Version) {
+		return nil, domain.ErrVersionMismatch
+	}
+	updated := upd.Apply(*todo)
+	if m.occurrenceExists(id, updated.SeriesID, updated.DueAt) {
+		return nil, domain.ErrOccurrenceExists
+	}
+	updated.Version++
+	m.todos[id] = &updated
+	return &updated, nil
+}
+
+// occurrenceExists mirrors the unique index on the series and due date of
+// todos, which todos in the trash take part in too.
+func (m *MockTodoRepository) occurrenceExists(id int, seriesID *int, dueAt *time.Time) bool {
+	if seriesID == nil || dueAt == nil {
+		return false
+	}
+	for _, todo := range m.todos {
+		if todo.ID != id && equalIDs(todo.SeriesID, seriesID) && todo.DueAt != nil && todo.DueAt.Equal(*dueAt) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MockTodoRepository) CreateSeries(ctx context.Context, s domain.Series) (int, error) {
+	s.ID = len(m.series) + 1
+	m.series[s.ID] = s
+	return s.ID, nil
+}
+
+func (m *MockTodoRepository) GetSeries(ctx context.Context, id int) (*domain.Series, error) {
+	s, ok := m.series[id]
+	if !ok {
+		return nil, domain.ErrNotRecurring
+	}
+	return &s, nil
+}
+
+func (m *MockTodoRepository) ListByIDs(ctx context.Context, ids []int) ([]domain.Todo, error) {
+	var todos []domain.Todo
+	for _, id := range slices.Sorted(slices.Values(ids)) {
+		if todo, ok := m.live(id); ok {
+			todos = append(todos, *todo)
+		}
+	}
+	return todos, nil
+}
+
+func (m *MockTodoRepository) Dependencies(ctx context.Context, ids []int) ([]domain.Dependency, error) {
+	return m.dependencies(func(d domain.Dependency) bool { return slices.Contains(ids, d.TodoID) }), nil
+}
+
+func (m *MockTodoRepository) Dependents(ctx context.Context, ids []int) ([]domain.Dependency, error) {
+	return m.dependencies(func(d domain.Dependency) bool { return slices.Contains(ids, d.BlockedByID) }), nil
+}
+
+func (m *MockTodoRepository) dependencies(match func(domain.Dependency) bool) []domain.Dependency {
+	var deps []domain.Dependency
+	for d := range m.deps {
+		if match(d) {
+			deps = append(deps, d)
+		}
+	}
+	slices.SortFunc(deps, func(a, b domain.Dependency) int {
+		if a.TodoID != b.TodoID {
+			return a.TodoID - b.TodoID
+		}
+		return a.BlockedByID - b.BlockedByID
+	})
+	return deps
+}
+
+func (m *MockTodoRepository) AddDependency(ctx context.Context, d domain.Dependency) (bool, error) {
+	if _, ok := m.todos[d.TodoID]; !ok {
+		return false, domain.ErrTodoNotFound
+	}
+	if _, ok := m.todos[d.BlockedByID]; !ok {
+		return false, domain.ErrBlockerNotFound
+	}
+	if m.deps[d] {
+		return false, nil
+	}
+	m.deps[d] = true
+	return true, nil
+}
+
+func (m *MockTodoRepository) RemoveDependency(ctx context.Context, d domain.Dependency) error {
+	if !m.deps[d] {
+		return domain.ErrDependencyNotFound
+	}
+	delete(m.deps, d)
+	return nil
+}
+
+func (m *MockTodoRepository) LockDependencies(ctx context.Context) error {
+	return nil
+}
+
+// PositionGap mirrors the Postgres repository: todos in the trash keep
+// their positions.
+func (m *MockTodoRepository) PositionGap(ctx context.Context, id int, anchor domain.MoveAnchor) (domain.PositionGap,
+	error) {
+	a, ok := m.live(anchor.TodoID)
+	if !ok {
+		return domain.PositionGap{}, domain.ErrAnchorNotFound
+	}
+
+	var neighbour *float64
+	for _, todo := range m.todos {
+		p := todo.Position
+		if todo.ID == id || (anchor.After && p <= a.Position) || (!anchor.After && p >= a.Position) {
+			continue
+		}
+		if neighbour == nil || (anchor.After && p < *neighbour) || (!anchor.After && p > *neighbour) {
+			neighbour = &p
+		}
+	}
+
+	if anchor.After {
+		return domain.PositionGap{After: &a.Position, Before: neighbour}, nil
+	}
+	return domain.PositionGap{After: neighbour, Before: &a.Position}, nil
+}
+
+func (m *MockTodoRepository) SetPosition(ctx context.Context, id int, position *float64) error {
+	todo, ok := m.live(id)
+	if !ok {
+		return domain.ErrTodoNotFound
+	}
+	if position == nil {
+		todo.Position = m.nextPosition
+		m.nextPosition++
+		return nil
+	}
+	for _, other := range m.todos {
+		if other.ID != id && other.Position == *position {
+			return domain.ErrPositionTaken
+		}
+	}
+	todo.Position = *position
+	return nil
+}
+
+func (m *MockTodoRepository) PositionsAround(ctx context.Context, position float64, n int) ([]domain.TodoPosition,
+	error) {
+	all := m.positions()
+	i, _ := slices.BinarySearchFunc(all, position, func(p domain.TodoPosition, position float64) int {
+		if p.Position <= position {
+			return -1
+		}
+		return 1
+	})
+	return all[max(0, i-n):min(len(all), i+n)], nil
+}
+
+func (m *MockTodoRepository) SetPositions(ctx context.Context, positions []domain.TodoPosition) error {
+	for _, p := range positions {
+		if todo, ok := m.todos[p.TodoID]; ok {
+			todo.Position = p.Position
+		}
+	}
+	all := m.positions()
+	for i := 1; i < len(all); i++ {
+		if all[i].Position == all[i-1].Position {
+			return domain.ErrPositionTaken
+		}
+	}
+	return nil
+}
+
+func (m *MockTodoRepository) LockPositions(ctx context.Context, exclusive bool) error {
+	return nil
+}
+
+// positions returns the positions of all todos, trashed ones included, in
+// list order.
+func (m *MockTodoRepository) positions() []domain.TodoPosition {
+	all := make([]domain.TodoPosition, 0, len(m.todos))
+	for _, todo := range m.todos {
+		all = append(all, domain.TodoPosition{TodoID: todo.ID, Position: todo.Position})
+	}
+	slices.SortFunc(all, func(a, b domain.TodoPosition) int { return cmp.Compare(a.Position, b.Position) })
+	return all
+}
+
+func (m *MockTodoRepository) Delete(ctx context.Context, id int, match domain.VersionMatch) error {
+	todo, exists := m.live(id)
+	if !exists {
+		return domain.ErrTodoNotFound
+	}
+	if !match.Matches(todo.Version) {
+		return domain.ErrVersionMismatch
+	}
+
+	// Subtasks go to the trash together with their parent
+	subtasks, _ := m.Subtasks(ctx, id)
+	now := time.Now()
+	todo.DeletedAt = &now
+	todo.Version++
+	for _, subtask := range subtasks {
+		m.todos[subtask.ID].DeletedAt = &now
+		m.todos[subtask.ID].Version++
+	}
+	return nil
+}
+
+func (m *MockTodoRepository) ListTrash(ctx context.Context) ([]domain.Todo, error) {
+	todos := make([]domain.Todo, 0)
+	for _, todo := range m.todos {
+		if todo.IsDeleted() {
+			todos = append(todos, *todo)
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool {
+		if !todos[i].DeletedAt.Equal(*todos[j].DeletedAt) {
+			return todos[i].DeletedAt.After(*todos[j].DeletedAt)
+		}
+		return todos[i].ID < todos[j].ID
+	})
+	return todos, nil
+}
+
+func (m *MockTodoRepository) Restore(ctx context.Context, id int) (*domain.Todo, error) {
+	todo, exists := m.todos[id]
+	if !exists || !todo.IsDeleted() {
+		return nil, domain.ErrTodoNotFound
+	}
+	if todo.ParentID != nil && m.todos[*todo.ParentID].IsDeleted() {
+		return nil, domain.ErrParentDeleted
+	}
+
+	// Restore the subtasks that were deleted along with the todo
+	deletedAt := *todo.DeletedAt
+	restore := []int{id}
+	for len(restore) > 0 {
+		parent := restore[0]
+		restore = restore[1:]
+		m.todos[parent].DeletedAt = nil
+		m.todos[parent].Version++
+		for _, t := range m.todos {
+			if t.ParentID != nil && *t.ParentID == parent && t.IsDeleted() && t.DeletedAt.Equal(deletedAt) {
+				restore = append(restore, t.ID)
+			}
+		}
+	}
+	return todo, nil
+}
+
+func (m *MockTodoRepository) DeletePermanently(ctx context.Context, id int) error {
+	todo, exists := m.todos[id]
+	if !exists || !todo.IsDeleted() {
 		return domain.ErrTodoNotFound
 	}
+	m.purge(id)
+	return nil
+}
+
+func (m *MockTodoRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+	for id, todo := range m.todos {
+		if todo.IsDeleted() && todo.DeletedAt.Before(before) {
+			purged++
+			m.purge(id)
+		}
+	}
+	return purged, nil
+}
+
+// purge removes a todo and, like the foreign keys, all of its subtasks and
+// dependencies.
+func (m *MockTodoRepository) purge(id int) {
 	delete(m.todos, id)
+	maps.DeleteFunc(m.deps, func(d domain.Dependency, _ bool) bool {
+		return d.TodoID == id || d.BlockedByID == id
+	})
+	for _, todo := range m.todos {
+		if todo.ParentID != nil && *todo.ParentID == id {
+			m.purge(todo.ID)
+		}
+	}
+}
+
+func (m *MockTodoRepository) ListTags(ctx context.Context) ([]domain.TagCount, error) {
+	counts := make(map[string]int)
+	for _, todo := range m.todos {
+		if todo.IsDeleted() {
+			continue
+		}
+		for _, tag := range todo.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]domain.TagCount, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, domain.TagCount{Name: name, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+func (m *MockTodoRepository) Ancestors(ctx context.Context, id int) ([]int, error) {
+	ids := make([]int, 0)
+	for todo, ok := m.live(id); ok; {
+		ids = append(ids, todo.ID)
+		if todo.ParentID == nil {
+			break
+		}
+		todo, ok = m.live(*todo.ParentID)
+	}
+	return ids, nil
+}
+
+func (m *MockTodoRepository) Subtasks(ctx context.Context, id int) ([]domain.Todo, error) {
+	var subtasks []domain.Todo
+	level := []int{id}
+	for len(level) > 0 {
+		var children []domain.Todo
+		for _, parent := range level {
+			for _, todo := range m.todos {
+				if todo.ParentID != nil && *todo.ParentID == parent && !todo.IsDeleted() {
+					children = append(children, *todo)
+				}
+			}
+		}
+		sort.Slice(children, func(i, j int) bool { return children[i].ID < children[j].ID })
+
+		level = level[:0]
+		for _, child := range children {
+			level = append(level, child.ID)
+		}
+		subtasks = append(subtasks, children...)
+	}
+	return subtasks, nil
+}
+
+// InTx restores the todos as they were before fn if fn fails.
+func (m *MockTodoRepository) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := make(map[int]*domain.Todo, len(m.todos))
+	for id, todo := range m.todos {
+		t := *todo
+		snapshot[id] = &t
+	}
+	nextID := m.nextID
+	series := maps.Clone(m.series)
+	deps := maps.Clone(m.deps)
+	nextPosition := m.nextPosition
+
+	if err := fn(ctx); err != nil {
+		m.todos = snapshot
+		m.nextID = nextID
+		m.series = series
+		m.deps = deps
+		m.nextPosition = nextPosition
+		return err
+	}
 	return nil
 }
 
+// matchesTags mirrors the tag filter of the Postgres repository.
+func matchesTags(todoTags, want []string, match domain.TagMatch) bool {
+	if len(want) == 0 {
+		return true
+	}
+
+	found := 0
+	for _, tag := range want {
+		for _, todoTag := range todoTags {
+			if tag == todoTag {
+				found++
+				break
+			}
+		}
+	}
+
+	if match == domain.TagMatchAll {
+		return found == len(want)
+	}
+	return found > 0
+}
+
 func TestTodoService_Create(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -89,7 +595,7 @@ func TestTodoService_Create(t *testing.T) {
 			service := NewTodoService(repo)
 
 			ctx := context.Background()
-			id, err := service.Create(ctx, tt.title)
+			id, err := service.Create(ctx, domain.Todo{Title: tt.title})
 
 			if tt.wantErr != nil {
 				if !errors.Is(err, tt.wantErr) {
@@ -132,7 +638,7 @@ func TestTodoService_GetByID(t *testing.T) {
 	ctx := context.Background()
 
 	// Create a todo first
-	id, err := service.Create(ctx, "Test Todo")
+	id, err := service.Create(ctx, domain.Todo{Title: "Test Todo"})
 	if err != nil {
 		t.Fatalf("Failed to create test todo: %v", err)
 	}
@@ -197,7 +703,7 @@ func TestTodoService_List(t *testing.T) {
 	ctx := context.Background()
 
 	// Test empty list
-	todos, err := service.List(ctx)
+	todos, err := service.List(ctx, domain.TodoQuery{})
 	if err != nil {
 		t.Errorf("List() failed on empty repository: %v", err)
 	}
@@ -209,14 +715,14 @@ func TestTodoService_List(t *testing.T) {
 	// Create some todos
 	titles := []string{"Todo 1", "Todo 2", "Todo 3"}
 	for _, title := range titles {
-		_, err := service.Create(ctx, title)
+		_, err := service.Create(ctx, domain.Todo{Title: title})
 		if err != nil {
 			t.Fatalf("Failed to create test todo: %v", err)
 		}
 	}
 
 	// Test list with todos
-	todos, err = service.List(ctx)
+	todos, err = service.List(ctx, domain.TodoQuery{})
 	if err != nil {
 		t.Errorf("List() failed: %v", err)
 	}
@@ -226,30 +732,642 @@ func TestTodoService_List(t *testing.T) {
 	}
 }
 
-func TestTodoService_Delete(t *testing.T) {
+func TestTodoService_ListPage(t *testing.T) {
 	repo := NewMockTodoRepository()
 	service := NewTodoService(repo)
 	ctx := context.Background()
 
-	// Create a todo first
-	id, err := service.Create(ctx, "Test Todo")
+	for i := 1; i <= 5; i++ {
+		if _, err := service.Create(ctx, domain.Todo{Title: fmt.Sprintf("Todo %d", i)}); err != nil {
+			t.Fatalf("Failed to create test todo: %v", err)
+		}
+	}
+
+	ids := func(page *domain.TodoPage) []int {
+		result := make([]int, 0, len(page.Items))
+		for _, todo := range page.Items {
+			result = append(result, todo.ID)
+		}
+		return result
+	}
+
+	// Walk forward through all pages
+	first, err := service.ListPage(ctx, domain.TodoQuery{}, domain.PageRequest{Limit: 2})
 	if err != nil {
-		t.Fatalf("Failed to create test todo: %v", err)
+		t.Fatalf("ListPage() unexpected error = %v", err)
+	}
+	if got := ids(first); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("first page = %v, want [1 2]", got)
+	}
+	if first.PrevCursor != "" || first.NextCursor == "" {
+		t.Errorf("first page cursors = (%q, %q), want only next", first.PrevCursor, first.NextCursor)
+	}
+
+	second, err := service.ListPage(ctx, domain.TodoQuery{}, domain.PageRequest{Limit: 2, After: first.NextCursor})
+	if err != nil {
+		t.Fatalf("ListPage() unexpected error = %v", err)
+	}
+	if got := ids(second); !reflect.DeepEqual(got, []int{3, 4}) {
+		t.Errorf("second page = %v, want [3 4]", got)
+	}
+
+	last, err := service.ListPage(ctx, domain.TodoQuery{}, domain.PageRequest{Limit: 2, After: second.NextCursor})
+	if err != nil {
+		t.Fatalf("ListPage() unexpected error = %v", err)
+	}
+	if got := ids(last); !reflect.DeepEqual(got, []int{5}) {
+		t.Errorf("last page = %v, want [5]", got)
+	}
+	if last.NextCursor != "" || last.PrevCursor == "" {
+		t.Errorf("last page cursors = (%q, %q), want only prev", last.PrevCursor, last.NextCursor)
+	}
+
+	// Walk back from the last page
+	back, err := service.ListPage(ctx, domain.TodoQuery{}, domain.PageRequest{Limit: 2, Before: last.PrevCursor})
+	if err != nil {
+		t.Fatalf("ListPage() unexpected error = %v", err)
+	}
+	if got := ids(back); !reflect.DeepEqual(got, []int{3, 4}) {
+		t.Errorf("previous page = %v, want [3 4]", got)
+	}
+	if back.NextCursor == "" || back.PrevCursor == "" {
+		t.Errorf("previous page cursors = (%q, %q), want both", back.PrevCursor, back.NextCursor)
 	}
 
+	start, err := service.ListPage(ctx, domain.TodoQuery{}, domain.PageRequest{Limit: 2, Before: back.PrevCursor})
+	if err != nil {
+		t.Fatalf("ListPage() unexpected error = %v", err)
+	}
+	if got := ids(start); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("start page = %v, want [1 2]", got)
+	}
+	if start.PrevCursor != "" {
+		t.Errorf("start page prev cursor = %q, want none", start.PrevCursor)
+	}
+
+	// Limits are clamped
+	all, err := service.ListPage(ctx, domain.TodoQuery{}, domain.PageRequest{Limit: MaxPageLimit + 1})
+	if err != nil {
+		t.Fatalf("ListPage() unexpected error = %v", err)
+	}
+	if len(all.Items) != 5 || all.NextCursor != "" {
+		t.Errorf("ListPage() with large limit returned %d items, next %q", len(all.Items), all.NextCursor)
+	}
+}
+
+func TestTodoService_ListPage_InvalidCursor(t *testing.T) {
+	service := NewTodoService(NewMockTodoRepository())
+	ctx := context.Background()
+	valid := encodeCursor(domain.Cursor{ID: 1})
+	sortedByTitle := encodeCursor(domain.Cursor{ID: 1, Sort: "title"})
+
 	tests := []struct {
-		name    string
-		id      int
-		wantErr error
+		name   string
+		after  string
+		before string
+	}{
+		{name: "not base64", after: "%%%"},
+		{name: "not JSON", after: "bm90LWpzb24"},
+		{name: "non-positive id", before: encodeCursor(domain.Cursor{})},
+		{name: "both cursors", after: valid, before: valid},
+		{name: "issued for another sort order", after: sortedByTitle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ListPage(ctx, domain.TodoQuery{}, domain.PageRequest{
+				Limit:  10,
+				After:  tt.after,
+				Before: tt.before,
+			})
+			if !errors.Is(err, domain.ErrInvalidCursor) {
+				t.Errorf("ListPage() error = %v, want %v", err, domain.ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestTodoService_Update(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	boolPtr := func(b bool) *bool { return &b }
+	statusPtr := func(s domain.Status) *domain.Status { return &s }
+	priorityPtr := func(p domain.Priority) *domain.Priority { return &p }
+
+	tests := []struct {
+		name          string
+		id            int
+		upd           domain.TodoUpdate
+		wantTitle     string
+		wantCompleted bool
+		wantStatus    domain.Status
+		wantPriority  domain.Priority
+		wantErr       error
 	}{
 		{
-			name: "existing todo",
-			id:   id,
+			name:          "full replacement",
+			upd:           domain.TodoUpdate{Title: strPtr("Renamed"), Completed: boolPtr(true)},
+			wantTitle:     "Renamed",
+			wantCompleted: true,
+			wantStatus:    domain.StatusDone,
 		},
 		{
-			name:    "non-existent todo",
-			id:      999,
-			wantErr: domain.ErrTodoNotFound,
+			name:          "only completed",
+			upd:           domain.TodoUpdate{Completed: boolPtr(true)},
+			wantTitle:     "Test Todo",
+			wantCompleted: true,
+			wantStatus:    domain.StatusDone,
+		},
+		{
+			name:       "title is trimmed",
+			upd:        domain.TodoUpdate{Title: strPtr("  Renamed  ")},
+			wantTitle:  "Renamed",
+			wantStatus: domain.StatusBacklog,
+		},
+		{
+			name:       "status and priority",
+			upd:        domain.TodoUpdate{Status: statusPtr(domain.StatusInProgress), Priority: priorityPtr(domain.PriorityUrgent)},
+			wantTitle:  "Test Todo",
+			wantStatus: domain.StatusInProgress,
+		},
+		{
+			name:          "status done with matching completed",
+			upd:           domain.TodoUpdate{Status: statusPtr(domain.StatusDone), Completed: boolPtr(true)},
+			wantTitle:     "Test Todo",
+			wantCompleted: true,
+			wantStatus:    domain.StatusDone,
+		},
+		{
+			name:    "completed conflicting with status",
+			upd:     domain.TodoUpdate{Status: statusPtr(domain.StatusInProgress), Completed: boolPtr(true)},
+			wantErr: domain.ErrInvalidStatus,
+		},
+		{
+			name:    "unknown status",
+			upd:     domain.TodoUpdate{Status: statusPtr("archived")},
+			wantErr: domain.ErrInvalidStatus,
+		},
+		{
+			name:    "unknown priority",
+			upd:     domain.TodoUpdate{Priority: priorityPtr("critical")},
+			wantErr: domain.ErrInvalidPriority,
+		},
+		{
+			name:    "whitespace title",
+			upd:     domain.TodoUpdate{Title: strPtr("   ")},
+			wantErr: domain.ErrInvalidTitle,
+		},
+		{
+			name:    "non-existent todo",
+			id:      999,
+			upd:     domain.TodoUpdate{Completed: boolPtr(true)},
+			wantErr: domain.ErrTodoNotFound,
+		},
+		{
+			name:    "invalid id - negative",
+			id:      -1,
+			upd:     domain.TodoUpdate{Completed: boolPtr(true)},
+			wantErr: domain.ErrTodoNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockTodoRepository()
+			service := NewTodoService(repo)
+			ctx := context.Background()
+
+			id, err := service.Create(ctx, domain.Todo{Title: "Test Todo"})
+			if err != nil {
+				t.Fatalf("Failed to create test todo: %v", err)
+			}
+			if tt.id != 0 {
+				id = tt.id
+			}
+
+			todo, err := service.Update(ctx, id, tt.upd)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Update() unexpected error = %v", err)
+				return
+			}
+
+			if todo.Title != tt.wantTitle {
+				t.Errorf("Update() title = %v, want %v", todo.Title, tt.wantTitle)
+			}
+
+			if todo.IsCompleted() != tt.wantCompleted {
+				t.Errorf("Update() completed = %v, want %v", todo.IsCompleted(), tt.wantCompleted)
+			}
+
+			if todo.Status != tt.wantStatus {
+				t.Errorf("Update() status = %v, want %v", todo.Status, tt.wantStatus)
+			}
+
+			wantPriority := domain.PriorityMedium
+			if tt.upd.Priority != nil {
+				wantPriority = *tt.upd.Priority
+			}
+			if todo.Priority != wantPriority {
+				t.Errorf("Update() priority = %v, want %v", todo.Priority, wantPriority)
+			}
+		})
+	}
+}
+
+func TestTodoService_StatusWorkflow(t *testing.T) {
+	boolPtr := func(b bool) *bool { return &b }
+
+	// A strict workflow where work must be started before it is finished
+	// and finished todos cannot be reopened.
+	workflow, err := domain.ParseWorkflow("backlog:in_progress; in_progress:blocked,done; blocked:in_progress")
+	if err != nil {
+		t.Fatalf("ParseWorkflow() unexpected error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		steps   []domain.Status
+		wantErr error
+	}{
+		{
+			name:  "allowed path",
+			steps: []domain.Status{domain.StatusInProgress, domain.StatusBlocked, domain.StatusInProgress, domain.StatusDone},
+		},
+		{
+			name:  "same status is always allowed",
+			steps: []domain.Status{domain.StatusBacklog},
+		},
+		{
+			name:    "skipping in progress",
+			steps:   []domain.Status{domain.StatusDone},
+			wantErr: domain.ErrInvalidTransition,
+		},
+		{
+			name:    "reopening a done todo",
+			steps:   []domain.Status{domain.StatusInProgress, domain.StatusDone, domain.StatusBacklog},
+			wantErr: domain.ErrInvalidTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewTodoService(NewMockTodoRepository(), WithWorkflow(workflow))
+			ctx := context.Background()
+
+			id, err := service.Create(ctx, domain.Todo{Title: "Test Todo"})
+			if err != nil {
+				t.Fatalf("Create() unexpected error = %v", err)
+			}
+
+			for _, status := range tt.steps {
+				_, err = service.Update(ctx, id, domain.TodoUpdate{Status: &status})
+				if err != nil {
+					break
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("completed flag follows the workflow", func(t *testing.T) {
+		service := NewTodoService(NewMockTodoRepository(), WithWorkflow(workflow))
+		ctx := context.Background()
+
+		id, err := service.Create(ctx, domain.Todo{Title: "Test Todo"})
+		if err != nil {
+			t.Fatalf("Create() unexpected error = %v", err)
+		}
+
+		if _, err := service.Update(ctx, id, domain.TodoUpdate{Completed: boolPtr(true)}); !errors.Is(err, domain.ErrInvalidTransition) {
+			t.Errorf("Update() completing a backlog todo error = %v, want %v", err, domain.ErrInvalidTransition)
+		}
+	})
+
+	t.Run("default workflow reopens done todos as in progress", func(t *testing.T) {
+		service := NewTodoService(NewMockTodoRepository())
+		ctx := context.Background()
+
+		id, err := service.Create(ctx, domain.Todo{Title: "Test Todo", Status: domain.StatusDone})
+		if err != nil {
+			t.Fatalf("Create() unexpected error = %v", err)
+		}
+
+		todo, err := service.Update(ctx, id, domain.TodoUpdate{Completed: boolPtr(false)})
+		if err != nil {
+			t.Fatalf("Update() unexpected error = %v", err)
+		}
+		if todo.Status != domain.StatusInProgress {
+			t.Errorf("Update() status = %v, want %v", todo.Status, domain.StatusInProgress)
+		}
+	})
+}
+
+func TestParseWorkflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "valid", spec: "backlog:in_progress,done;done:backlog"},
+		{name: "surrounding whitespace", spec: " backlog : in_progress ; "},
+		{name: "empty", spec: "", wantErr: true},
+		{name: "missing colon", spec: "backlog", wantErr: true},
+		{name: "unknown source status", spec: "archived:backlog", wantErr: true},
+		{name: "unknown target status", spec: "backlog:archived", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := domain.ParseWorkflow(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseWorkflow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTodoService_Tags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "normalized, deduplicated and sorted",
+			tags: []string{" Work", "home", "WORK", "p1"},
+			want: []string{"home", "p1", "work"},
+		},
+		{
+			name: "no tags",
+			want: []string{},
+		},
+		{
+			name:    "blank tag",
+			tags:    []string{"work", "   "},
+			wantErr: domain.ErrInvalidTag,
+		},
+		{
+			name:    "tag with inner whitespace",
+			tags:    []string{"high priority"},
+			wantErr: domain.ErrInvalidTag,
+		},
+		{
+			name:    "tag with comma",
+			tags:    []string{"a,b"},
+			wantErr: domain.ErrInvalidTag,
+		},
+		{
+			name:    "tag too long",
+			tags:    []string{strings.Repeat("x", domain.MaxTagLength+1)},
+			wantErr: domain.ErrInvalidTag,
+		},
+		{
+			name:    "too many tags",
+			tags:    strings.Split("a b c d e f g h i j k l m n o p q r s t u", " "),
+			wantErr: domain.ErrInvalidTag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockTodoRepository()
+			service := NewTodoService(repo)
+			ctx := context.Background()
+
+			id, err := service.Create(ctx, domain.Todo{Title: "Test Todo", Tags: tt.tags})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create() unexpected error = %v", err)
+			}
+
+			if got := repo.todos[id].Tags; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Create() tags = %v, want %v", got, tt.want)
+			}
+
+			// Updates go through the same normalization
+			todo, err := service.Update(ctx, id, domain.TodoUpdate{Tags: &tt.tags})
+			if err != nil {
+				t.Fatalf("Update() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(todo.Tags, tt.want) {
+				t.Errorf("Update() tags = %v, want %v", todo.Tags, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodoService_TagFilterAndCounts(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	for _, todo := range []domain.Todo{
+		{Title: "Report", Tags: []string{"work", "p1"}},
+		{Title: "Standup", Tags: []string{"work"}},
+		{Title: "Laundry", Tags: []string{"home"}},
+	} {
+		if _, err := service.Create(ctx, todo); err != nil {
+			t.Fatalf("Create() unexpected error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		tags      []string
+		match     domain.TagMatch
+		wantTitle []string
+	}{
+		{name: "any", tags: []string{"P1", "home"}, wantTitle: []string{"Laundry", "Report"}},
+		{name: "all", tags: []string{"work", " p1 "}, match: domain.TagMatchAll, wantTitle: []string{"Report"}},
+		{name: "all with duplicates", tags: []string{"work", "WORK"}, match: domain.TagMatchAll, wantTitle: []string{"Report", "Standup"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos, err := service.List(ctx, domain.TodoQuery{Filter: domain.TodoFilter{Tags: tt.tags, TagMatch: tt.match}})
+			if err != nil {
+				t.Fatalf("List() unexpected error = %v", err)
+			}
+
+			titles := make([]string, 0, len(todos))
+			for _, todo := range todos {
+				titles = append(titles, todo.Title)
+			}
+			sort.Strings(titles)
+
+			if !reflect.DeepEqual(titles, tt.wantTitle) {
+				t.Errorf("List() titles = %v, want %v", titles, tt.wantTitle)
+			}
+		})
+	}
+
+	tags, err := service.ListTags(ctx)
+	if err != nil {
+		t.Fatalf("ListTags() unexpected error = %v", err)
+	}
+	want := []domain.TagCount{{Name: "work", Count: 2}, {Name: "home", Count: 1}, {Name: "p1", Count: 1}}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("ListTags() = %v, want %v", tags, want)
+	}
+}
+
+func TestTodoService_Reminders(t *testing.T) {
+	due := time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC)
+	before := due.Add(-time.Hour)
+	after := due.Add(time.Hour)
+
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	if _, err := service.Create(ctx, domain.Todo{Title: "Pay rent", DueAt: &due, RemindAt: &after}); !errors.Is(err, domain.ErrInvalidReminder) {
+		t.Errorf("Create() with reminder after due date error = %v, want %v", err, domain.ErrInvalidReminder)
+	}
+
+	id, err := service.Create(ctx, domain.Todo{Title: "Pay rent", DueAt: &due, RemindAt: &before})
+	if err != nil {
+		t.Fatalf("Create() unexpected error = %v", err)
+	}
+
+	// Moving the due date before the existing reminder is rejected
+	earlier := before.Add(-time.Hour)
+	_, err = service.Update(ctx, id, domain.TodoUpdate{DueAt: domain.SetTo(&earlier)})
+	if !errors.Is(err, domain.ErrInvalidReminder) {
+		t.Errorf("Update() moving due date before reminder error = %v, want %v", err, domain.ErrInvalidReminder)
+	}
+
+	// Clearing the due date keeps the reminder valid
+	todo, err := service.Update(ctx, id, domain.TodoUpdate{DueAt: domain.SetTo[time.Time](nil)})
+	if err != nil {
+		t.Fatalf("Update() clearing due date unexpected error = %v", err)
+	}
+	if todo.DueAt != nil || todo.RemindAt == nil {
+		t.Errorf("Update() due = %v, remind = %v, want only the reminder kept", todo.DueAt, todo.RemindAt)
+	}
+}
+
+func TestTodoService_ResolveFilter(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// 23:30 UTC on March 1st is already March 2nd in Berlin
+	now := time.Date(2024, 3, 1, 23, 30, 0, 0, time.UTC)
+	svc := &todoService{repo: NewMockTodoRepository(), now: func() time.Time { return now }}
+
+	tests := []struct {
+		name          string
+		filter        domain.TodoFilter
+		wantFrom      *time.Time
+		wantUntil     *time.Time
+		wantCompleted *bool
+	}{
+		{
+			name:   "no relative filters",
+			filter: domain.TodoFilter{},
+		},
+		{
+			name:          "overdue",
+			filter:        domain.TodoFilter{Overdue: true},
+			wantUntil:     &now,
+			wantCompleted: new(bool),
+		},
+		{
+			name:      "due today in UTC",
+			filter:    domain.TodoFilter{DueToday: true},
+			wantFrom:  timePtr(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+			wantUntil: timePtr(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:      "due today in client time zone",
+			filter:    domain.TodoFilter{DueToday: true, Location: berlin},
+			wantFrom:  timePtr(time.Date(2024, 3, 2, 0, 0, 0, 0, berlin)),
+			wantUntil: timePtr(time.Date(2024, 3, 3, 0, 0, 0, 0, berlin)),
+		},
+		{
+			name:      "due within",
+			filter:    domain.TodoFilter{DueWithin: 48 * time.Hour},
+			wantFrom:  &now,
+			wantUntil: timePtr(now.Add(48 * time.Hour)),
+		},
+		{
+			name:      "due today and within an hour intersect",
+			filter:    domain.TodoFilter{DueToday: true, DueWithin: time.Hour},
+			wantFrom:  &now,
+			wantUntil: timePtr(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := svc.resolveFilter(tt.filter)
+
+			if !sameTime(got.DueFrom, tt.wantFrom) {
+				t.Errorf("resolveFilter() DueFrom = %v, want %v", got.DueFrom, tt.wantFrom)
+			}
+			if !sameTime(got.DueUntil, tt.wantUntil) {
+				t.Errorf("resolveFilter() DueUntil = %v, want %v", got.DueUntil, tt.wantUntil)
+			}
+			if (got.Completed == nil) != (tt.wantCompleted == nil) ||
+				(got.Completed != nil && *got.Completed != *tt.wantCompleted) {
+				t.Errorf("resolveFilter() Completed = %v, want %v", got.Completed, tt.wantCompleted)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func TestTodoService_Delete(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	// Create a todo first
+	id, err := service.Create(ctx, domain.Todo{Title: "Test Todo"})
+	if err != nil {
+		t.Fatalf("Failed to create test todo: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		id      int
+		wantErr error
+	}{
+		{
+			name: "existing todo",
+			id:   id,
+		},
+		{
+			name:    "non-existent todo",
+			id:      999,
+			wantErr: domain.ErrTodoNotFound,
 		},
 		{
 			name:    "invalid id - zero",
@@ -265,7 +1383,7 @@ func TestTodoService_Delete(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := service.Delete(ctx, tt.id)
+			err := service.Delete(ctx, tt.id, nil)
 
 			if tt.wantErr != nil {
 				if !errors.Is(err, tt.wantErr) {
@@ -287,3 +1405,115 @@ func TestTodoService_Delete(t *testing.T) {
 		})
 	}
 }
+
+// concurrentRepository changes a todo right after each of its next races
+// reads, as a concurrent request would.
+type concurrentRepository struct {
+	*MockTodoRepository
+	races  int
+	change func(t *domain.Todo)
+}
+
+func (r *concurrentRepository) GetByID(ctx context.Context, id int) (*domain.Todo, error) {
+	todo, ok := r.live(id)
+	if !ok {
+		return nil, domain.ErrTodoNotFound
+	}
+	read := *todo
+	if r.races > 0 {
+		r.races--
+		r.change(todo)
+		todo.Version++
+	}
+	return &read, nil
+}
+
+func TestTodoService_UpdateRace(t *testing.T) {
+	workflow, err := domain.ParseWorkflow("backlog:in_progress; in_progress:backlog,done")
+	if err != nil {
+		t.Fatalf("ParseWorkflow() unexpected error = %v", err)
+	}
+	ctx := context.Background()
+	done := domain.StatusDone
+
+	t.Run("revalidated against the concurrent change", func(t *testing.T) {
+		repo := &concurrentRepository{MockTodoRepository: NewMockTodoRepository(), races: 1,
+			change: func(t *domain.Todo) { t.Status = domain.StatusBacklog }}
+		service := NewTodoService(repo, WithWorkflow(workflow))
+		id, _ := service.Create(ctx, domain.Todo{Title: "Write report", Status: domain.StatusInProgress})
+
+		// Checked against in progress, the todo could be done; by the time
+		// it is written it is back in the backlog, from where it cannot
+		if _, err := service.Update(ctx, id, domain.TodoUpdate{Status: &done}); !errors.Is(err,
+			domain.ErrInvalidTransition) {
+			t.Errorf("Update() error = %v, want %v", err, domain.ErrInvalidTransition)
+		}
+		if todo, _ := service.GetByID(ctx, id); todo.Status != domain.StatusBacklog {
+			t.Errorf("status = %s, want %s", todo.Status, domain.StatusBacklog)
+		}
+	})
+
+	t.Run("retried", func(t *testing.T) {
+		repo := &concurrentRepository{MockTodoRepository: NewMockTodoRepository(), races: updateAttempts - 1,
+			change: func(t *domain.Todo) { t.Title += "!" }}
+		service := NewTodoService(repo, WithWorkflow(workflow))
+		id, _ := service.Create(ctx, domain.Todo{Title: "Write report", Status: domain.StatusInProgress})
+
+		todo, err := service.Update(ctx, id, domain.TodoUpdate{Status: &done})
+		if err != nil || todo.Status != done {
+			t.Errorf("Update() = %+v, %v, want it done", todo, err)
+		}
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		repo := &concurrentRepository{MockTodoRepository: NewMockTodoRepository(), races: updateAttempts,
+			change: func(t *domain.Todo) { t.Title += "!" }}
+		service := NewTodoService(repo, WithWorkflow(workflow))
+		id, _ := service.Create(ctx, domain.Todo{Title: "Write report", Status: domain.StatusInProgress})
+
+		if _, err := service.Update(ctx, id, domain.TodoUpdate{Status: &done}); !errors.Is(err,
+			domain.ErrConcurrentUpdate) {
+			t.Errorf("Update() error = %v, want %v", err, domain.ErrConcurrentUpdate)
+		}
+	})
+}
+
+func TestTodoService_Versions(t *testing.T) {
+	service := NewTodoService(NewMockTodoRepository())
+	ctx := context.Background()
+
+	id, _ := service.Create(ctx, domain.Todo{Title: "Draft report"})
+	title := "Final report"
+
+	tests := []struct {
+		name        string
+		match       domain.VersionMatch
+		wantErr     error
+		wantVersion int
+	}{
+		{name: "any version", match: nil, wantVersion: 2},
+		{name: "current version", match: domain.VersionMatch{2}, wantVersion: 3},
+		{name: "one of several versions", match: domain.VersionMatch{1, 3}, wantVersion: 4},
+		{name: "stale version", match: domain.VersionMatch{3}, wantErr: domain.ErrVersionMismatch},
+		{name: "no acceptable version", match: domain.VersionMatch{}, wantErr: domain.ErrVersionMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.Update(ctx, id, domain.TodoUpdate{Title: &title, IfVersion: tt.match})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Version != tt.wantVersion {
+				t.Errorf("Update() version = %d, want %d", got.Version, tt.wantVersion)
+			}
+		})
+	}
+
+	if err := service.Delete(ctx, id, domain.VersionMatch{1}); !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Errorf("Delete() with stale version error = %v, want %v", err, domain.ErrVersionMismatch)
+	}
+	if err := service.Delete(ctx, id, domain.VersionMatch{4}); err != nil {
+		t.Errorf("Delete() with current version unexpected error = %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// ListTrash retrieves every todo in the trash, most recently deleted first.
+func (s *todoService) ListTrash(ctx context.Context) ([]domain.Todo, error) {
+	log := logger.FromContext(ctx)
+
+	todos, err := s.repo.ListTrash(ctx)
+	if err != nil {
+		if log != nil {
+			log.Error("failed to list trash", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if log != nil {
+		log.Info("trash fetched", zap.Int("count", len(todos)))
+	}
+	return todos, nil
+}
+
+// Restore takes a todo out of the trash, together with the subtasks that
+// were deleted along with it.
+func (s *todoService) Restore(ctx context.Context, id int) (*domain.Todo, error) {
+	log := logger.FromContext(ctx)
+
+	if id <= 0 {
+		if log != nil {
+			log.Warn("invalid ID for restore", zap.Int("id", id))
+		}
+		return nil, domain.ErrTodoNotFound
+	}
+
+	t, err := s.repo.Restore(ctx, id)
+	if errors.Is(err, domain.ErrTodoNotFound) || errors.Is(err, domain.ErrParentDeleted) {
+		if log != nil {
+			log.Warn("todo cannot be restored", zap.Error(err), zap.Int("id", id))
+		}
+		return nil, err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to restore todo", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if log != nil {
+		log.Info("todo restored successfully", zap.Int("id", id))
+	}
+	return t, nil
+}
+
+// DeletePermanently removes a todo in the trash for good.
+func (s *todoService) DeletePermanently(ctx context.Context, id int) error {
+	log := logger.FromContext(ctx)
+
+	if id <= 0 {
+		if log != nil {
+			log.Warn("invalid ID for permanent deletion", zap.Int("id", id))
+		}
+		return domain.ErrTodoNotFound
+	}
+
+	err := s.repo.DeletePermanently(ctx, id)
+	if errors.Is(err, domain.ErrTodoNotFound) {
+		if log != nil {
+			log.Warn("todo not found in trash", zap.Int("id", id))
+		}
+		return err
+	}
+	if err != nil {
+		if log != nil {
+			log.Error("failed to permanently delete todo", zap.Error(err))
+		}
+		return err
+	}
+
+	if log != nil {
+		log.Info("todo permanently deleted", zap.Int("id", id))
+	}
+	return nil
+}
+
+// PurgeTrash permanently removes the todos that have been in the trash for
+// longer than retention and returns how many were removed.
+func (s *todoService) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	log := logger.FromContext(ctx)
+
+	purged, err := s.repo.Purge(ctx, s.now().Add(-retention))
+	if err != nil {
+		if log != nil {
+			log.Error("failed to purge trash", zap.Error(err))
+		}
+		return 0, err
+	}
+
+	if log != nil && purged > 0 {
+		log.Info("trash purged", zap.Int("count", purged))
+	}
+	return purged, nil
+}
+
+// TrashPurger periodically removes todos that have been in the trash for
+// longer than the retention period.
+type TrashPurger struct {
+	service   TodoService
+	retention time.Duration
+	interval  time.Duration
+	log       logger.Logger
+}
+
+// NewTrashPurger constructs a purger that checks the trash every interval.
+func NewTrashPurger(s TodoService, retention, interval time.Duration, log logger.Logger) *TrashPurger {
+	return &TrashPurger{
+		service:   s,
+		retention: retention,
+		interval:  interval,
+		log:       log,
+	}
+}
+
+// Run purges the trash right away and then once per interval, until ctx
+// is cancelled. Failures are logged and retried on the next tick.
+func (p *TrashPurger) Run(ctx context.Context) {
+	ctx = logger.Inject(ctx, p.log)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		// Errors are logged by the service
+		_, _ = p.service.PurgeTrash(ctx, p.retention)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+func TestTodoService_Trash(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	id, _ := service.Create(ctx, domain.Todo{Title: "Water plants"})
+	kept, _ := service.Create(ctx, domain.Todo{Title: "Buy milk"})
+
+	if err := service.Delete(ctx, id, nil); err != nil {
+		t.Fatalf("Delete() unexpected error = %v", err)
+	}
+
+	// Deleted todos are hidden everywhere but the trash
+	if _, err := service.GetByID(ctx, id); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("GetByID() error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+	todos, _ := service.List(ctx, domain.TodoQuery{})
+	if len(todos) != 1 || todos[0].ID != kept {
+		t.Errorf("List() = %+v, want only todo %d", todos, kept)
+	}
+	if _, err := service.Update(ctx, id, domain.TodoUpdate{}); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("Update() error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+	if err := service.Delete(ctx, id, nil); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("Delete() twice error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+
+	trash, err := service.ListTrash(ctx)
+	if err != nil {
+		t.Fatalf("ListTrash() unexpected error = %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != id || !trash[0].IsDeleted() {
+		t.Fatalf("ListTrash() = %+v, want deleted todo %d", trash, id)
+	}
+
+	// Only todos in the trash can be restored
+	if _, err := service.Restore(ctx, kept); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("Restore() live todo error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+
+	restored, err := service.Restore(ctx, id)
+	if err != nil {
+		t.Fatalf("Restore() unexpected error = %v", err)
+	}
+	if restored.IsDeleted() {
+		t.Errorf("Restore() returned a todo that is still deleted")
+	}
+	if _, err := service.GetByID(ctx, id); err != nil {
+		t.Errorf("GetByID() after restore unexpected error = %v", err)
+	}
+}
+
+func TestTodoService_TrashSubtasks(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	ids := createChain(t, service, 2)
+
+	// The deepest subtask is deleted on its own before its ancestors
+	if err := service.Delete(ctx, ids[2], nil); err != nil {
+		t.Fatalf("Delete() unexpected error = %v", err)
+	}
+	repo.todos[ids[2]].DeletedAt = ptr(time.Now().Add(-time.Hour))
+
+	if err := service.Delete(ctx, ids[0], nil); err != nil {
+		t.Fatalf("Delete() unexpected error = %v", err)
+	}
+	if _, err := service.GetByID(ctx, ids[1]); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("GetByID() of subtask error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+
+	if _, err := service.Restore(ctx, ids[1]); !errors.Is(err, domain.ErrParentDeleted) {
+		t.Errorf("Restore() of subtask error = %v, want %v", err, domain.ErrParentDeleted)
+	}
+
+	if _, err := service.Restore(ctx, ids[0]); err != nil {
+		t.Fatalf("Restore() unexpected error = %v", err)
+	}
+	if _, err := service.GetByID(ctx, ids[1]); err != nil {
+		t.Errorf("GetByID() of subtask deleted with its parent: unexpected error = %v", err)
+	}
+	if _, err := service.GetByID(ctx, ids[2]); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("GetByID() of subtask deleted on its own: error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+}
+
+func TestTodoService_DeletePermanently(t *testing.T) {
+	repo := NewMockTodoRepository()
+	service := NewTodoService(repo)
+	ctx := context.Background()
+
+	id, _ := service.Create(ctx, domain.Todo{Title: "Old receipts"})
+	subtask, _ := service.Create(ctx, domain.Todo{Title: "Shred", ParentID: &id})
+
+	if err := service.DeletePermanently(ctx, id); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("DeletePermanently() of live todo error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+
+	_ = service.Delete(ctx, id, nil)
+	if err := service.DeletePermanently(ctx, id); err != nil {
+		t.Fatalf("DeletePermanently() unexpected error = %v", err)
+	}
+
+	if _, ok := repo.todos[subtask]; ok {
+		t.Errorf("subtask %d survived the permanent deletion of its parent", subtask)
+	}
+	if _, err := service.Restore(ctx, id); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("Restore() after permanent deletion error = %v, want %v", err, domain.ErrTodoNotFound)
+	}
+}
+
+func TestTrashPurger(t *testing.T) {
+	repo := NewMockTodoRepository()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := &todoService{repo: repo, workflow: domain.DefaultWorkflow, now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	old, _ := service.Create(ctx, domain.Todo{Title: "Deleted last month"})
+	recent, _ := service.Create(ctx, domain.Todo{Title: "Deleted yesterday"})
+	live, _ := service.Create(ctx, domain.Todo{Title: "Not deleted"})
+
+	repo.todos[old].DeletedAt = ptr(now.AddDate(0, -1, 0))
+	repo.todos[recent].DeletedAt = ptr(now.AddDate(0, 0, -1))
+
+	// A cancelled context makes Run purge once and return
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	NewTrashPurger(service, 7*24*time.Hour, time.Hour, nil).Run(ctx)
+
+	for id, want := range map[int]bool{old: false, recent: true, live: true} {
+		if _, ok := repo.todos[id]; ok != want {
+			t.Errorf("todo %d kept = %v, want %v", id, ok, want)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,238 @@
+package codec
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Errors returned when no codec fits a request.
+var (
+	ErrNotAcceptable        = errors.New("no acceptable media type")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// Encoder writes values in a single media type.
+type Encoder interface {
+	// ContentType is the Content-Type of the responses it writes.
+	ContentType() string
+	Encode(w io.Writer, v any) error
+}
+
+// Decoder reads request bodies in a single media type.
+type Decoder interface {
+	Decode(r io.Reader, v any) error
+}
+
+// Selective is implemented by encoders that can only write some values,
+// such as CSV, which needs a table. Negotiation skips them for other values.
+type Selective interface {
+	CanEncode(v any) bool
+}
+
+// encoderEntry is an encoder together with the media type it is offered
+// under.
+type encoderEntry struct {
+	mediaType string
+	encoder   Encoder
+}
+
+// Registry holds the encoders and decoders available to the API. Encoders
+// are preferred in the order they were registered when the client accepts
+// several of them equally, and the first decoder handles bodies sent
+// without a Content-Type.
+type Registry struct {
+	encoders     []encoderEntry
+	decoders     map[string]Decoder
+	firstDecoder Decoder
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{decoders: make(map[string]Decoder)}
+}
+
+// Default is the registry used by the API, with JSON as the preferred
+// format followed by CSV, NDJSON, YAML, MessagePack and iCalendar.
+var Default = newDefault()
+
+func newDefault() *Registry {
+	reg := NewRegistry()
+
+	reg.RegisterEncoder(JSON, JSONMediaType)
+	reg.RegisterDecoder(JSON, JSONMediaType)
+
+	// Clients that ask for problem details often list nothing else, and
+	// expect their other responses as plain JSON
+	reg.RegisterEncoder(JSON, "application/problem+json")
+
+	reg.RegisterEncoder(CSV, CSVMediaType)
+	reg.RegisterEncoder(NDJSON, NDJSONMediaType, "application/jsonl")
+
+	reg.RegisterEncoder(YAML, YAMLMediaType, "application/x-yaml", "text/yaml")
+	reg.RegisterDecoder(YAML, YAMLMediaType, "application/x-yaml", "text/yaml")
+
+	reg.RegisterEncoder(MessagePack, MessagePackMediaType, "application/x-msgpack", "application/vnd.msgpack")
+	reg.RegisterDecoder(MessagePack, MessagePackMediaType, "application/x-msgpack", "application/vnd.msgpack")
+
+	reg.RegisterEncoder(ICalendar, ICalendarMediaType)
+
+	return reg
+}
+
+// RegisterEncoder offers enc for each of the given media types.
+func (r *Registry) RegisterEncoder(enc Encoder, mediaTypes ...string) {
+	for _, mediaType := range mediaTypes {
+		r.encoders = append(r.encoders, encoderEntry{mediaType: strings.ToLower(mediaType), encoder: enc})
+	}
+}
+
+// RegisterDecoder makes dec handle bodies of each of the given media types.
+func (r *Registry) RegisterDecoder(dec Decoder, mediaTypes ...string) {
+	if r.firstDecoder == nil {
+		r.firstDecoder = dec
+	}
+	for _, mediaType := range mediaTypes {
+		r.decoders[strings.ToLower(mediaType)] = dec
+	}
+}
+
+// Negotiate returns the encoder that the Accept header values rank highest
+// among those able to write v. Without an Accept header the first suitable
+// encoder is used. ErrNotAcceptable is returned if the client accepts none
+// of them.
+func (r *Registry) Negotiate(accept []string, v any) (Encoder, error) {
+	ranges := parseAccept(accept)
+
+	var best Encoder
+	bestQuality := 0.0
+	for _, entry := range r.encoders {
+		if s, ok := entry.encoder.(Selective); ok && !s.CanEncode(v) {
+			continue
+		}
+
+		quality := 1.0
+		if len(ranges) > 0 {
+			quality = qualityOf(ranges, entry.mediaType)
+		}
+		if quality > bestQuality {
+			best, bestQuality = entry.encoder, quality
+		}
+	}
+
+	if best == nil {
+		return nil, ErrNotAcceptable
+	}
+	return best, nil
+}
+
+// Acceptable reports whether the Accept header values admit at least one
+// registered media type. It lets a request be refused before it is handled,
+// while Negotiate still decides for the actual response.
+func (r *Registry) Acceptable(accept []string) bool {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return true
+	}
+
+	for _, entry := range r.encoders {
+		if qualityOf(ranges, entry.mediaType) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Decoder returns the decoder for a Content-Type header value. An empty
+// value selects the first registered decoder, for clients that do not set
+// the header. ErrUnsupportedMediaType is returned for anything else that
+// has no decoder.
+func (r *Registry) Decoder(contentType string) (Decoder, error) {
+	if strings.TrimSpace(contentType) == "" {
+		if r.firstDecoder == nil {
+			return nil, ErrUnsupportedMediaType
+		}
+		return r.firstDecoder, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	dec, ok := r.decoders[mediaType]
+	if !ok {
+		return nil, ErrUnsupportedMediaType
+	}
+	return dec, nil
+}
+
+// mediaRange is a single entry of an Accept header.
+type mediaRange struct {
+	typ, subtype string
+	quality      float64
+}
+
+// parseAccept parses Accept header values into media ranges. Malformed
+// ranges are ignored, and a missing or unparseable quality counts as 1.
+func parseAccept(values []string) []mediaRange {
+	var ranges []mediaRange
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+
+			typ, subtype, ok := strings.Cut(mediaType, "/")
+			if !ok {
+				continue
+			}
+
+			quality := 1.0
+			if q, ok := params["q"]; ok {
+				if parsed, err := strconv.ParseFloat(q, 64); err == nil && parsed >= 0 && parsed <= 1 {
+					quality = parsed
+				}
+			}
+			ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, quality: quality})
+		}
+	}
+
+	// The most specific range decides, so check exact types before
+	// type/* and */*
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+	return ranges
+}
+
+func (m mediaRange) specificity() int {
+	switch {
+	case m.typ == "*":
+		return 0
+	case m.subtype == "*":
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (m mediaRange) matches(typ, subtype string) bool {
+	return (m.typ == "*" || m.typ == typ) && (m.subtype == "*" || m.subtype == subtype)
+}
+
+// qualityOf returns the quality the most specific matching range gives
+// mediaType, or 0 if no range matches.
+func qualityOf(ranges []mediaRange, mediaType string) float64 {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	for _, m := range ranges {
+		if m.matches(typ, subtype) {
+			return m.quality
+		}
+	}
+	return 0
+}
@@ -0,0 +1,197 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/ical"
+)
+
+type item struct {
+	ID    int      `json:"id"`
+	Title string   `json:"title"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func TestRegistry_Negotiate(t *testing.T) {
+	rows := []item{{ID: 1, Title: "Buy milk"}}
+
+	tests := []struct {
+		name   string
+		accept []string
+		value  any
+		want   Encoder
+		err    error
+	}{
+		{name: "no accept header", value: rows, want: JSON},
+		{name: "wildcard", accept: []string{"*/*"}, value: rows, want: JSON},
+		{name: "exact type", accept: []string{"text/csv"}, value: rows, want: CSV},
+		{name: "alias", accept: []string{"application/x-msgpack"}, value: rows, want: MessagePack},
+		{
+			name:   "highest quality wins",
+			accept: []string{"application/json;q=0.5, application/yaml"},
+			value:  rows,
+			want:   YAML,
+		},
+		{
+			name:   "values of several headers are combined",
+			accept: []string{"application/json;q=0.2", "application/msgpack;q=0.9"},
+			value:  rows,
+			want:   MessagePack,
+		},
+		{
+			name:   "most specific range decides",
+			accept: []string{"application/*;q=0.1, application/msgpack;q=0, */*;q=0.5"},
+			value:  rows,
+			want:   CSV,
+		},
+		{name: "ndjson", accept: []string{"application/x-ndjson"}, value: rows, want: NDJSON},
+		{name: "type wildcard", accept: []string{"text/*"}, value: rows, want: CSV},
+		{
+			name:   "selective encoder is skipped",
+			accept: []string{"text/csv, application/yaml;q=0.1"},
+			value:  map[string]int{"count": 1},
+			want:   YAML,
+		},
+		{name: "problem details", accept: []string{"application/problem+json"}, value: rows, want: JSON},
+		{name: "calendar", accept: []string{"text/calendar"}, value: &ical.Calendar{}, want: ICalendar},
+		{name: "calendar of rows", accept: []string{"text/calendar"}, value: rows, err: ErrNotAcceptable},
+		{name: "nothing acceptable", accept: []string{"application/xml"}, value: rows, err: ErrNotAcceptable},
+		{name: "everything refused", accept: []string{"*/*;q=0"}, value: rows, err: ErrNotAcceptable},
+		{
+			name:   "only encoder refuses the value",
+			accept: []string{"text/csv"},
+			value:  map[string]int{"count": 1},
+			err:    ErrNotAcceptable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Default.Negotiate(tt.accept, tt.value)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Negotiate() error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Negotiate() = %T, want %T", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_Acceptable(t *testing.T) {
+	for accept, want := range map[string]bool{
+		"":                          true,
+		"text/html, */*;q=0.8":      true,
+		"application/yaml":          true,
+		"application/xml":           false,
+		"application/json;q=0":      false,
+		"image/*, text/plain;q=0.5": false,
+	} {
+		var values []string
+		if accept != "" {
+			values = []string{accept}
+		}
+		if got := Default.Acceptable(values); got != want {
+			t.Errorf("Acceptable(%q) = %t, want %t", accept, got, want)
+		}
+	}
+}
+
+func TestRegistry_Decoder(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        Decoder
+		err         error
+	}{
+		{contentType: "", want: JSON},
+		{contentType: "application/json; charset=utf-8", want: JSON},
+		{contentType: "Application/YAML", want: YAML},
+		{contentType: "application/vnd.msgpack", want: MessagePack},
+		{contentType: "text/csv", err: ErrUnsupportedMediaType},
+		{contentType: "application/xml", err: ErrUnsupportedMediaType},
+		{contentType: "not a media type", err: ErrUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		got, err := Default.Decoder(tt.contentType)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("Decoder(%q) = %T, %v, want %T, %v", tt.contentType, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+type request struct {
+	Title string     `json:"title"`
+	DueAt *time.Time `json:"due_at,omitempty"`
+	Tags  []string   `json:"tags,omitempty"`
+	Count int        `json:"count"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	due := time.Date(2023, 1, 2, 17, 0, 0, 0, time.UTC)
+	in := request{Title: "123", DueAt: &due, Tags: []string{"home", "yes"}, Count: 3}
+
+	for name, c := range map[string]interface {
+		Encoder
+		Decoder
+	}{"json": JSON, "yaml": YAML, "msgpack": MessagePack} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := c.Encode(&buf, in); err != nil {
+				t.Fatalf("Encode() unexpected error = %v", err)
+			}
+
+			var out request
+			if err := c.Decode(&buf, &out); err != nil {
+				t.Fatalf("Decode() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(out, in) {
+				t.Errorf("round trip = %+v, want %+v", out, in)
+			}
+		})
+	}
+}
+
+func TestYAML_Encode(t *testing.T) {
+	var buf bytes.Buffer
+	if err := YAML.Encode(&buf, []item{{ID: 1, Title: "true", Tags: []string{"home"}}}); err != nil {
+		t.Fatalf("Encode() unexpected error = %v", err)
+	}
+
+	want := "- id: 1\n  title: \"true\"\n  tags:\n    - home\n"
+	if buf.String() != want {
+		t.Errorf("Encode() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestYAML_DecodeInvalid(t *testing.T) {
+	var out request
+	if err := YAML.Decode(strings.NewReader("title: [unclosed"), &out); err == nil {
+		t.Errorf("Decode() expected error for malformed YAML")
+	}
+	if err := YAML.Decode(strings.NewReader("count: many"), &out); err == nil {
+		t.Errorf("Decode() expected error for mistyped field")
+	}
+}
+
+func TestNDJSON_Encode(t *testing.T) {
+	var buf bytes.Buffer
+	value := []item{{ID: 1, Title: "Buy milk"}, {ID: 2, Title: "Water\nplants"}}
+	if err := NDJSON.Encode(&buf, value); err != nil {
+		t.Fatalf("Encode() unexpected error = %v", err)
+	}
+
+	want := `{"id":1,"title":"Buy milk"}` + "\n" + `{"id":2,"title":"Water\nplants"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("Encode() = %q, want %q", buf.String(), want)
+	}
+
+	if NDJSON.CanEncode(item{ID: 1}) {
+		t.Errorf("CanEncode(struct) = true, want false")
+	}
+}
@@ -0,0 +1,212 @@
+package codec
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVMediaType is the media type of CSV.
+const CSVMediaType = "text/csv"
+
+// CSV writes tabular values as CSV: a struct as a single row and a slice
+// of structs as one row per element, under a header of their JSON field
+// names. It cannot decode.
+var CSV csvCodec
+
+// Tabler is implemented by values whose tabular form is not the value
+// itself, such as a page of items whose table is just the items.
+type Tabler interface {
+	Table() any
+}
+
+type csvCodec struct{}
+
+func (csvCodec) ContentType() string {
+	return CSVMediaType + "; charset=utf-8"
+}
+
+// CanEncode reports whether v is a struct or a slice of structs, possibly
+// behind pointers or a Tabler.
+func (csvCodec) CanEncode(v any) bool {
+	_, ok := rowType(table(v))
+	return ok
+}
+
+// Encode writes the table of v. Nested slices of strings are joined with
+// commas, other nested values are written as JSON, and nil values as empty
+// cells.
+func (csvCodec) Encode(w io.Writer, v any) error {
+	value := table(v)
+
+	typ, ok := rowType(value)
+	if !ok {
+		return fmt.Errorf("csv: cannot encode %T as a table", v)
+	}
+	columns := columnsOf(typ)
+
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rowsOf(value) {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			cell, err := formatCell(row.FieldByIndex(c.index))
+			if err != nil {
+				return err
+			}
+			record[i] = cell
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// table returns the value to tabulate for v.
+func table(v any) reflect.Value {
+	if t, ok := v.(Tabler); ok {
+		v = t.Table()
+	}
+	return indirect(reflect.ValueOf(v))
+}
+
+// rowType returns the struct type of the rows of value.
+func rowType(value reflect.Value) (reflect.Type, bool) {
+	if !value.IsValid() {
+		return nil, false
+	}
+
+	typ := value.Type()
+	if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+	}
+
+	if typ.Kind() != reflect.Struct || typ == reflect.TypeOf(time.Time{}) {
+		return nil, false
+	}
+	return typ, true
+}
+
+// rowsOf returns the structs to write as rows, skipping nil elements.
+func rowsOf(value reflect.Value) []reflect.Value {
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return []reflect.Value{value}
+	}
+
+	rows := make([]reflect.Value, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		if row := indirect(value.Index(i)); row.IsValid() {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// column is a field written as a CSV column.
+type column struct {
+	name  string
+	index []int
+}
+
+// columnsOf lists the fields of typ the way encoding/json names them,
+// including those promoted from embedded structs.
+func columnsOf(typ reflect.Type) []column {
+	var columns []column
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous && field.Type.Kind() == reflect.Struct {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, column{name: name, index: field.Index})
+	}
+	return columns
+}
+
+// indirect follows pointers and interfaces down to the value they hold,
+// returning the zero Value for nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// formatCell returns the text of a single cell.
+func formatCell(v reflect.Value) (string, error) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return "", nil
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339), nil
+	}
+
+	switch {
+	case v.Kind() == reflect.String:
+		return escapeFormula(v.String()), nil
+	case v.Kind() == reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case v.CanInt():
+		return strconv.FormatInt(v.Int(), 10), nil
+	case v.CanUint():
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case v.CanFloat():
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		if v.Len() == 0 {
+			return "", nil
+		}
+		if v.Type().Elem().Kind() == reflect.String {
+			items := make([]string, v.Len())
+			for i := range items {
+				items[i] = v.Index(i).String()
+			}
+			return escapeFormula(strings.Join(items, ",")), nil
+		}
+	}
+
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return "", err
+	}
+	return escapeFormula(string(data)), nil
+}
+
+// escapeFormula keeps spreadsheets from evaluating text that starts like a
+// formula, by prefixing it with a single quote.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
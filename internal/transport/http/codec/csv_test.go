@@ -0,0 +1,102 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type page struct {
+	Items []item `json:"items"`
+	Next  string `json:"next,omitempty"`
+}
+
+func (p page) Table() any {
+	return p.Items
+}
+
+type row struct {
+	item
+	Done     bool       `json:"done"`
+	Score    float64    `json:"score"`
+	DueAt    *time.Time `json:"due_at,omitempty"`
+	Children []item     `json:"children,omitempty"`
+	Secret   string     `json:"-"`
+	internal string
+}
+
+func TestCSV_Encode(t *testing.T) {
+	due := time.Date(2023, 1, 2, 17, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{
+			name: "slice of structs",
+			value: []item{
+				{ID: 1, Title: "Buy milk", Tags: []string{"home", "errands"}},
+				{ID: 2, Title: "Call \"Bob\""},
+			},
+			want: "id,title,tags\n1,Buy milk,\"home,errands\"\n2,\"Call \"\"Bob\"\"\",\n",
+		},
+		{
+			name:  "single struct",
+			value: &item{ID: 1, Title: "Buy milk"},
+			want:  "id,title,tags\n1,Buy milk,\n",
+		},
+		{
+			name:  "tabler",
+			value: page{Items: []item{{ID: 1, Title: "Buy milk"}}, Next: "abc"},
+			want:  "id,title,tags\n1,Buy milk,\n",
+		},
+		{
+			name:  "empty table",
+			value: []item{},
+			want:  "id,title,tags\n",
+		},
+		{
+			name: "embedded, nested and skipped fields",
+			value: []*row{
+				{item: item{ID: 1, Title: "Buy milk"}, Done: true, Score: 0.5, DueAt: &due,
+					Children: []item{{ID: 2, Title: "Find shop"}}, Secret: "s", internal: "i"},
+				nil,
+			},
+			want: "id,title,tags,done,score,due_at,children\n" +
+				"1,Buy milk,,true,0.5,2023-01-02T17:00:00Z,\"[{\"\"id\"\":2,\"\"title\"\":\"\"Find shop\"\"}]\"\n",
+		},
+		{
+			name:  "formulas are escaped",
+			value: []item{{ID: -1, Title: "=HYPERLINK(\"http://evil\")", Tags: []string{"@home"}}},
+			want:  "id,title,tags\n-1,\"'=HYPERLINK(\"\"http://evil\"\")\",'@home\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !CSV.CanEncode(tt.value) {
+				t.Fatalf("CanEncode() = false, want true")
+			}
+
+			var buf bytes.Buffer
+			if err := CSV.Encode(&buf, tt.value); err != nil {
+				t.Fatalf("Encode() unexpected error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Encode() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCSV_CanEncode(t *testing.T) {
+	for _, v := range []any{nil, "text", 42, []string{"a"}, map[string]int{"a": 1}, time.Now(), (*item)(nil)} {
+		if CSV.CanEncode(v) {
+			t.Errorf("CanEncode(%#v) = true, want false", v)
+		}
+		if err := CSV.Encode(&bytes.Buffer{}, v); err == nil {
+			t.Errorf("Encode(%#v) expected error", v)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+// Package codec encodes responses and decodes request bodies in the media
+// types the API speaks: JSON, CSV, YAML, MessagePack and iCalendar.
+//
+// Codecs are kept in a Registry. The encoder for a response is chosen from
+// the Accept header of the request, honouring quality values, and the
+// decoder for a request body from its Content-Type. A request that cannot
+// be served in any acceptable media type, or whose body is in a media type
+// no decoder handles, is reported with ErrNotAcceptable or
+// ErrUnsupportedMediaType so that the transport can answer with 406 or 415.
+//
+// JSON is the canonical representation. YAML and MessagePack are
+// translated from and to JSON, so values are written with the same field
+// names and request types behave the same whatever format they were sent
+// in, including custom UnmarshalJSON methods. CSV and NDJSON can only be
+// written, and only for lists or, in the case of CSV, single structs.
+// iCalendar can only be written, and only for calendars of to-dos.
+package codec
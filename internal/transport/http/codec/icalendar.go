@@ -0,0 +1,35 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/ical"
+)
+
+// ICalendarMediaType is the media type of iCalendar streams.
+const ICalendarMediaType = ical.MediaType
+
+// ICalendar writes calendars of to-dos as iCalendar streams. It can only
+// encode *ical.Calendar values and cannot decode.
+var ICalendar icalendarCodec
+
+type icalendarCodec struct{}
+
+func (icalendarCodec) ContentType() string {
+	return ICalendarMediaType + "; charset=utf-8"
+}
+
+// CanEncode reports whether v is a calendar.
+func (icalendarCodec) CanEncode(v any) bool {
+	_, ok := v.(*ical.Calendar)
+	return ok
+}
+
+func (icalendarCodec) Encode(w io.Writer, v any) error {
+	cal, ok := v.(*ical.Calendar)
+	if !ok {
+		return fmt.Errorf("icalendar: cannot encode %T as a calendar", v)
+	}
+	return ical.Encode(w, cal)
+}
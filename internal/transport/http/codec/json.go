@@ -0,0 +1,77 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// JSONMediaType is the media type of JSON.
+const JSONMediaType = "application/json"
+
+// JSON encodes and decodes JSON.
+var JSON jsonCodec
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return JSONMediaType
+}
+
+func (jsonCodec) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (jsonCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// fromJSON returns the generic representation of v as JSON: maps, slices,
+// strings, booleans, nil, and integers as int64 and other numbers as
+// float64. It is what the MessagePack encoder writes.
+func fromJSON(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return numbers(generic), nil
+}
+
+// numbers replaces the json.Number values within v by int64 or float64.
+func numbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, value := range v {
+			v[key] = numbers(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = numbers(value)
+		}
+	}
+	return v
+}
+
+// viaJSON decodes a generic value read from another format into v, as if
+// it had been sent as JSON.
+func viaJSON(generic, v any) error {
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
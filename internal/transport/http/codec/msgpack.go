@@ -0,0 +1,41 @@
+package codec
+
+import (
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MessagePackMediaType is the media type of MessagePack.
+const MessagePackMediaType = "application/msgpack"
+
+// MessagePack encodes and decodes MessagePack.
+var MessagePack messagePackCodec
+
+type messagePackCodec struct{}
+
+func (messagePackCodec) ContentType() string {
+	return MessagePackMediaType
+}
+
+// Encode writes the JSON form of v as MessagePack, with map keys sorted so
+// that equal values are encoded the same.
+func (messagePackCodec) Encode(w io.Writer, v any) error {
+	generic, err := fromJSON(v)
+	if err != nil {
+		return err
+	}
+
+	enc := msgpack.NewEncoder(w)
+	enc.SetSortMapKeys(true)
+	enc.UseCompactInts(true)
+	return enc.Encode(generic)
+}
+
+func (messagePackCodec) Decode(r io.Reader, v any) error {
+	var generic any
+	if err := msgpack.NewDecoder(r).Decode(&generic); err != nil {
+		return err
+	}
+	return viaJSON(generic, v)
+}
@@ -0,0 +1,43 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// NDJSONMediaType is the media type of newline-delimited JSON.
+const NDJSONMediaType = "application/x-ndjson"
+
+// NDJSON writes lists as newline-delimited JSON, one element per line. It
+// cannot decode.
+var NDJSON ndjsonCodec
+
+type ndjsonCodec struct{}
+
+func (ndjsonCodec) ContentType() string {
+	return NDJSONMediaType
+}
+
+// CanEncode reports whether v is a slice, possibly behind pointers or a
+// Tabler.
+func (ndjsonCodec) CanEncode(v any) bool {
+	kind := table(v).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+func (ndjsonCodec) Encode(w io.Writer, v any) error {
+	value := table(v)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return fmt.Errorf("ndjson: cannot encode %T as lines", v)
+	}
+
+	enc := json.NewEncoder(w)
+	for i := 0; i < value.Len(); i++ {
+		if err := enc.Encode(value.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package codec
+
+import (
+	"encoding/json"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLMediaType is the media type of YAML.
+const YAMLMediaType = "application/yaml"
+
+// YAML encodes and decodes YAML.
+var YAML yamlCodec
+
+type yamlCodec struct{}
+
+func (yamlCodec) ContentType() string {
+	return YAMLMediaType
+}
+
+// Encode writes v as YAML with its fields in the order JSON has them. JSON
+// is a subset of YAML, so the JSON form of v is parsed as a YAML document
+// and written out again in block style.
+func (yamlCodec) Encode(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func (yamlCodec) Decode(r io.Reader, v any) error {
+	var generic any
+	if err := yaml.NewDecoder(r).Decode(&generic); err != nil {
+		return err
+	}
+	return viaJSON(generic, v)
+}
+
+// blockStyle drops the flow style and quoting node and its children were
+// parsed with. Strings that would otherwise be read back as another type
+// are still quoted when written.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
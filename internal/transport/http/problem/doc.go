@@ -0,0 +1,10 @@
+// Package problem writes error responses as problem details (RFC 9457),
+// served as application/problem+json.
+//
+// Problem details replace the older error bodies of the API, which are
+// still written for clients that have not moved over yet. The format is
+// chosen per request: clients that list application/problem+json in their
+// Accept header get problem details, and everyone else gets the configured
+// default. Callers pass both representations to Write and the negotiated
+// one is sent.
+package problem
@@ -0,0 +1,145 @@
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MediaType is the media type of problem details.
+const MediaType = "application/problem+json"
+
+// typePrefix turns an error code into the URI of its problem type.
+const typePrefix = "urn:problem-type:todo-api:"
+
+// Problem is a problem details object. Code and Errors are extension
+// members: the machine-readable error code the API has always returned,
+// and the fields that failed validation.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code,omitempty"`
+	TraceID  string       `json:"trace_id,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes a single field that failed validation.
+type FieldError struct {
+	Field  string `json:"field"`
+	Detail string `json:"detail"`
+}
+
+// New returns the problem for an error with the given status, code and
+// human-readable detail. The type is derived from the code, and problems
+// without a code have the type about:blank.
+func New(status int, code, detail string) Problem {
+	typ := "about:blank"
+	if code != "" {
+		typ = typePrefix + strings.ReplaceAll(strings.ToLower(code), "_", "-")
+	}
+
+	return Problem{
+		Type:   typ,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// WithErrors returns a copy of p listing the given field errors, sorted by
+// field.
+func (p Problem) WithErrors(details map[string]string) Problem {
+	if len(details) == 0 {
+		return p
+	}
+
+	p.Errors = make([]FieldError, 0, len(details))
+	for field, detail := range details {
+		p.Errors = append(p.Errors, FieldError{Field: field, Detail: detail})
+	}
+	sort.Slice(p.Errors, func(i, j int) bool { return p.Errors[i].Field < p.Errors[j].Field })
+	return p
+}
+
+// Format selects how errors are written.
+type Format string
+
+const (
+	// FormatLegacy writes the error bodies the API used before problem details.
+	FormatLegacy Format = "legacy"
+	// FormatProblem writes problem details.
+	FormatProblem Format = "problem"
+)
+
+type ctxFormatKey struct{}
+
+// WithFormat returns a copy of ctx in which errors are written in format f.
+func WithFormat(ctx context.Context, f Format) context.Context {
+	return context.WithValue(ctx, ctxFormatKey{}, f)
+}
+
+// FormatFromContext returns the format errors are written in for requests
+// with the given context, FormatLegacy if none was chosen.
+func FormatFromContext(ctx context.Context) Format {
+	if f, ok := ctx.Value(ctxFormatKey{}).(Format); ok {
+		return f
+	}
+	return FormatLegacy
+}
+
+// Negotiate returns the format errors should be written in for r: problem
+// details if the Accept header asks for them, and def otherwise.
+func Negotiate(r *http.Request, def Format) Format {
+	if accepts(r.Header.Values("Accept"), MediaType) {
+		return FormatProblem
+	}
+	return def
+}
+
+// accepts reports whether the Accept header values list mediaType itself
+// with a non-zero quality. Wildcards do not count: a client that accepts
+// anything has not asked for problem details.
+func accepts(values []string, mediaType string) bool {
+	for _, value := range values {
+		for _, mediaRange := range strings.Split(value, ",") {
+			typ, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil || typ != mediaType {
+				continue
+			}
+			if q, ok := params["q"]; ok {
+				if quality, err := strconv.ParseFloat(q, 64); err != nil || quality <= 0 {
+					continue
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes p as problem details if that is the format chosen for r,
+// and legacy as plain JSON otherwise. Instance defaults to the request
+// path. The status has been sent by the time an encoding error is returned.
+func Write(w http.ResponseWriter, r *http.Request, p Problem, legacy any) error {
+	if FormatFromContext(r.Context()) != FormatProblem {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(p.Status)
+		return json.NewEncoder(w).Encode(legacy)
+	}
+
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+
+	w.Header().Set("Content-Type", MediaType)
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,126 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	p := New(http.StatusNotFound, "TODO_NOT_FOUND", "todo not found")
+
+	want := Problem{
+		Type:   "urn:problem-type:todo-api:todo-not-found",
+		Title:  "Not Found",
+		Status: http.StatusNotFound,
+		Detail: "todo not found",
+		Code:   "TODO_NOT_FOUND",
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("New() = %+v, want %+v", p, want)
+	}
+
+	if p := New(http.StatusInternalServerError, "", "boom"); p.Type != "about:blank" {
+		t.Errorf("New() without code type = %q, want about:blank", p.Type)
+	}
+}
+
+func TestWithErrors(t *testing.T) {
+	p := New(http.StatusBadRequest, "VALIDATION_ERROR", "validation failed").WithErrors(map[string]string{
+		"Title":    "Title is required",
+		"Priority": "Priority is invalid",
+	})
+
+	want := []FieldError{
+		{Field: "Priority", Detail: "Priority is invalid"},
+		{Field: "Title", Detail: "Title is required"},
+	}
+	if !reflect.DeepEqual(p.Errors, want) {
+		t.Errorf("WithErrors() errors = %+v, want %+v", p.Errors, want)
+	}
+
+	if p := New(http.StatusBadRequest, "VALIDATION_ERROR", "invalid JSON").WithErrors(nil); p.Errors != nil {
+		t.Errorf("WithErrors(nil) errors = %+v, want none", p.Errors)
+	}
+}
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		def    Format
+		want   Format
+	}{
+		{name: "no accept header", def: FormatLegacy, want: FormatLegacy},
+		{name: "no accept header with problem default", def: FormatProblem, want: FormatProblem},
+		{name: "problem+json", accept: "application/problem+json", def: FormatLegacy, want: FormatProblem},
+		{
+			name:   "problem+json among others",
+			accept: "application/json, application/problem+json;q=0.5",
+			def:    FormatLegacy,
+			want:   FormatProblem,
+		},
+		{name: "problem+json refused", accept: "application/problem+json;q=0", def: FormatLegacy, want: FormatLegacy},
+		{name: "wildcard", accept: "*/*", def: FormatLegacy, want: FormatLegacy},
+		{name: "plain json", accept: "application/json", def: FormatProblem, want: FormatProblem},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/todos", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			if got := Negotiate(r, tt.def); got != tt.want {
+				t.Errorf("Negotiate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	p := New(http.StatusNotFound, "TODO_NOT_FOUND", "todo not found")
+	legacy := map[string]string{"error": "todo not found", "code": "TODO_NOT_FOUND"}
+
+	t.Run("legacy", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/7", nil)
+
+		if err := Write(w, r, p, legacy); err != nil {
+			t.Fatalf("Write() unexpected error = %v", err)
+		}
+
+		if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Write() = %d %q, want 404 application/json", w.Code, w.Header().Get("Content-Type"))
+		}
+		var got map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || !reflect.DeepEqual(got, legacy) {
+			t.Errorf("Write() body = %s, want %v", w.Body, legacy)
+		}
+	})
+
+	t.Run("problem", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/7?expand=subtasks", nil)
+		r = r.WithContext(WithFormat(r.Context(), FormatProblem))
+
+		if err := Write(w, r, p, legacy); err != nil {
+			t.Fatalf("Write() unexpected error = %v", err)
+		}
+
+		if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != MediaType {
+			t.Errorf("Write() = %d %q, want 404 %s", w.Code, w.Header().Get("Content-Type"), MediaType)
+		}
+		var got Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("Write() body is not a problem: %v", err)
+		}
+		want := p
+		want.Instance = "/api/v1/todos/7"
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Write() body = %+v, want %+v", got, want)
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+const (
+	// multipartOverhead is how much larger than the file itself an upload
+	// may be, to make room for the boundaries and part headers
+	multipartOverhead = 64 << 10 // 64 KiB
+	// multipartMemory is how much of an upload is kept in memory; the rest
+	// goes to a temporary file
+	multipartMemory = 1 << 20 // 1 MiB
+)
+
+// AttachmentHandler provides HTTP endpoints for the files attached to
+// todos.
+type AttachmentHandler struct {
+	service service.AttachmentService
+	maxSize int64
+}
+
+// NewAttachmentHandler initializes the handler. Uploads larger than
+// maxSize bytes are refused before they are read in full.
+func NewAttachmentHandler(s service.AttachmentService, maxSize int64) *AttachmentHandler {
+	return &AttachmentHandler{service: s, maxSize: maxSize}
+}
+
+// RegisterRoutes attaches the routes answered with JSON to a router.
+func (h *AttachmentHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/todos/{id}/attachments", h.upload).Methods("POST")
+	r.HandleFunc("/todos/{id}/attachments", h.list).Methods("GET")
+	r.HandleFunc("/todos/{id}/attachments/{attachmentID}", h.getByID).Methods("GET")
+	r.HandleFunc("/todos/{id}/attachments/{attachmentID}", h.delete).Methods("DELETE")
+}
+
+// RegisterContentRoutes attaches the route serving the files themselves to
+// a router. Their type is whatever was uploaded, so the router should not
+// negotiate it.
+func (h *AttachmentHandler) RegisterContentRoutes(r *mux.Router) {
+	r.HandleFunc("/todos/{id}/attachments/{attachmentID}/content", h.download).Methods("GET", "HEAD")
+}
+
+// newAttachmentResponse maps a domain attachment onto its JSON
+// representation.
+func newAttachmentResponse(a domain.Attachment) AttachmentResponse {
+	return AttachmentResponse{
+		ID:          a.ID,
+		TodoID:      a.TodoID,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		CreatedAt:   a.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// attachmentIDs parses the todo and attachment ID path parameters.
+func attachmentIDs(w http.ResponseWriter, r *http.Request) (todoID, id int, ok bool) {
+	if todoID, ok = pathID(w, r, "id"); !ok {
+		return 0, 0, false
+	}
+	if id, ok = pathID(w, r, "attachmentID"); !ok {
+		return 0, 0, false
+	}
+	return todoID, id, true
+}
+
+// UploadAttachment godoc
+//
+//	@Summary		Attach a file to a todo
+//	@Description	Uploads a file as the file field of a multipart/form-data body. Its type is sniffed from its
+//	@Description	contents, whatever the client claims, and must be one of the allowed types (by default PNG,
+//	@Description	JPEG, GIF and WebP images, PDF and plain text). Files are limited to ATTACHMENT_MAX_SIZE
+//	@Description	bytes, 10 MiB by default. Todos in the trash cannot have files attached.
+//	@Tags			attachments
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			id				path		int					true	"Todo ID"
+//	@Param			file			formData	file				true	"File to attach"
+//	@Param			Idempotency-Key	header		string				false	"Key that makes retries of the request safe"
+//	@Success		201				{object}	AttachmentResponse	"File attached"
+//	@Failure		400				{object}	ErrorResponse		"Missing or empty file"
+//	@Failure		404				{object}	ErrorResponse		"Todo not found"
+//	@Failure		413				{object}	ErrorResponse		"File too large"
+//	@Failure		415				{object}	ErrorResponse		"File type not allowed"
+//	@Failure		500				{object}	ErrorResponse		"Internal server error"
+//	@Router			/todos/{id}/attachments [post]
+func (h *AttachmentHandler) upload(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	todoID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxSize+multipartOverhead)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		if log != nil {
+			log.Warn("failed to read upload", zap.Error(err))
+		}
+		WriteError(w, r, h.uploadError(err))
+		return
+	}
+	defer func() {
+		if err := r.MultipartForm.RemoveAll(); err != nil && log != nil {
+			log.Warn("failed to remove upload", zap.Error(err))
+		}
+	}()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		WriteError(w, r, NewValidationError("file is required"))
+		return
+	}
+	defer file.Close()
+
+	a, err := h.service.Upload(r.Context(), domain.Attachment{
+		TodoID:   todoID,
+		Filename: header.Filename,
+		Size:     header.Size,
+	}, file)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSONSafe(w, r, http.StatusCreated, newAttachmentResponse(*a))
+}
+
+// uploadError maps an error reading an upload onto the error it is
+// reported with.
+func (h *AttachmentHandler) uploadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return fmt.Errorf("%w: files may be at most %d bytes", domain.ErrAttachmentTooLarge, h.maxSize)
+	}
+	return NewValidationError("body must be multipart/form-data with a file field")
+}
+
+// ListAttachments godoc
+//
+//	@Summary		List the files attached to a todo
+//	@Description	Retrieves the files attached to a todo, oldest first
+//	@Tags			attachments
+//	@Produce		json
+//	@Param			id	path		int					true	"Todo ID"
+//	@Success		200	{array}		AttachmentResponse	"Successfully retrieved attachments"
+//	@Failure		400	{object}	ErrorResponse		"Invalid ID parameter"
+//	@Failure		404	{object}	ErrorResponse		"Todo not found"
+//	@Failure		500	{object}	ErrorResponse		"Internal server error"
+//	@Router			/todos/{id}/attachments [get]
+func (h *AttachmentHandler) list(w http.ResponseWriter, r *http.Request) {
+	todoID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	attachments, err := h.service.List(r.Context(), todoID)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	resp := make([]AttachmentResponse, 0, len(attachments))
+	for _, a := range attachments {
+		resp = append(resp, newAttachmentResponse(a))
+	}
+
+	WriteJSONSafe(w, r, http.StatusOK, resp)
+}
+
+// GetAttachment godoc
+//
+//	@Summary		Get a file attached to a todo
+//	@Description	Retrieves the details of a single file attached to a todo
+//	@Tags			attachments
+//	@Produce		json
+//	@Param			id				path		int					true	"Todo ID"
+//	@Param			attachmentID	path		int					true	"Attachment ID"
+//	@Success		200				{object}	AttachmentResponse	"Successfully retrieved attachment"
+//	@Failure		400				{object}	ErrorResponse		"Invalid ID parameter"
+//	@Failure		404				{object}	ErrorResponse		"Todo or attachment not found"
+//	@Failure		500				{object}	ErrorResponse		"Internal server error"
+//	@Router			/todos/{id}/attachments/{attachmentID} [get]
+func (h *AttachmentHandler) getByID(w http.ResponseWriter, r *http.Request) {
+	todoID, id, ok := attachmentIDs(w, r)
+	if !ok {
+		return
+	}
+
+	a, err := h.service.GetByID(r.Context(), todoID, id)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSONSafe(w, r, http.StatusOK, newAttachmentResponse(*a))
+}
+
+// DownloadAttachment godoc
+//
+//	@Summary		Download a file attached to a todo
+//	@Description	Serves the contents of an attached file as a download. Range and conditional requests are
+//	@Description	supported, so interrupted downloads can be resumed.
+//	@Tags			attachments
+//	@Produce		octet-stream
+//	@Param			id				path		int				true	"Todo ID"
+//	@Param			attachmentID	path		int				true	"Attachment ID"
+//	@Param			Range			header		string			false	"Byte ranges to download, such as bytes=0-1023"
+//	@Success		200				{file}		file			"File contents"
+//	@Success		206				{file}		file			"Requested ranges of the file"
+//	@Failure		400				{object}	ErrorResponse	"Invalid ID parameter"
+//	@Failure		404				{object}	ErrorResponse	"Todo or attachment not found"
+//	@Failure		416				"Requested range not satisfiable"
+//	@Failure		500				{object}	ErrorResponse	"Internal server error"
+//	@Router			/todos/{id}/attachments/{attachmentID}/content [get]
+func (h *AttachmentHandler) download(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	todoID, id, ok := attachmentIDs(w, r)
+	if !ok {
+		return
+	}
+
+	a, blob, err := h.service.Open(r.Context(), todoID, id)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	defer func() {
+		if err := blob.Close(); err != nil && log != nil {
+			log.Warn("failed to close attachment", zap.Error(err))
+		}
+	}()
+
+	// The file is never rendered as the page of this API: browsers are
+	// told to save it, and not to second-guess its sniffed type
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, a.Filename, a.CreatedAt, blob)
+}
+
+// DeleteAttachment godoc
+//
+//	@Summary		Delete a file attached to a todo
+//	@Description	Removes a file from a todo and deletes its contents
+//	@Tags			attachments
+//	@Param			id				path	int	true	"Todo ID"
+//	@Param			attachmentID	path	int	true	"Attachment ID"
+//	@Success		204				"Attachment deleted"
+//	@Failure		400				{object}	ErrorResponse	"Invalid ID parameter"
+//	@Failure		404				{object}	ErrorResponse	"Todo or attachment not found"
+//	@Failure		500				{object}	ErrorResponse	"Internal server error"
+//	@Router			/todos/{id}/attachments/{attachmentID} [delete]
+func (h *AttachmentHandler) delete(w http.ResponseWriter, r *http.Request) {
+	todoID, id, ok := attachmentIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), todoID, id); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,248 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+// attachmentService knows todos 1 to 3, of which only todo 2 has a file
+// attached, attachment 4. Uploads go through a real service, so that they
+// are checked the same way.
+type attachmentService struct {
+	service.AttachmentService
+}
+
+const testAttachmentBody = "0123456789"
+
+var testAttachment = domain.Attachment{
+	ID:          4,
+	TodoID:      2,
+	Filename:    "plan \"v2\".txt",
+	ContentType: "text/plain; charset=utf-8",
+	Size:        int64(len(testAttachmentBody)),
+	CreatedAt:   time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+}
+
+func (s *attachmentService) Upload(ctx context.Context, a domain.Attachment, r io.Reader) (*domain.Attachment,
+	error) {
+	if a.TodoID > 3 {
+		return nil, domain.ErrTodoNotFound
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) != a.Size || len(body) == 0 {
+		return nil, domain.ErrAttachmentEmpty
+	}
+	if strings.HasPrefix(string(body), "<html>") {
+		return nil, domain.ErrAttachmentType
+	}
+	a.ID, a.ContentType, a.CreatedAt = 5, "text/plain; charset=utf-8", testAttachment.CreatedAt
+	return &a, nil
+}
+
+func (s *attachmentService) GetByID(ctx context.Context, todoID, id int) (*domain.Attachment, error) {
+	if todoID > 3 {
+		return nil, domain.ErrTodoNotFound
+	}
+	if todoID != testAttachment.TodoID || id != testAttachment.ID {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	a := testAttachment
+	return &a, nil
+}
+
+func (s *attachmentService) List(ctx context.Context, todoID int) ([]domain.Attachment, error) {
+	if todoID > 3 {
+		return nil, domain.ErrTodoNotFound
+	}
+	if todoID != testAttachment.TodoID {
+		return nil, nil
+	}
+	return []domain.Attachment{testAttachment}, nil
+}
+
+func (s *attachmentService) Open(ctx context.Context, todoID, id int) (*domain.Attachment, io.ReadSeekCloser,
+	error) {
+	a, err := s.GetByID(ctx, todoID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, struct {
+		io.ReadSeeker
+		io.Closer
+	}{strings.NewReader(testAttachmentBody), io.NopCloser(nil)}, nil
+}
+
+func (s *attachmentService) Delete(ctx context.Context, todoID, id int) error {
+	_, err := s.GetByID(ctx, todoID, id)
+	return err
+}
+
+// multipartBody returns a multipart/form-data body holding content as the
+// named file field, and its Content-Type.
+func multipartBody(t *testing.T, field, content string) (*bytes.Buffer, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile(field, "notes.txt")
+	if err != nil {
+		t.Fatalf("CreateFormFile() error = %v", err)
+	}
+	if _, err := io.WriteString(fw, content); err != nil {
+		t.Fatalf("WriteString() error = %v", err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return &buf, mw.FormDataContentType()
+}
+
+func TestAttachmentHandler_Upload(t *testing.T) {
+	h := NewAttachmentHandler(&attachmentService{}, 100)
+
+	tests := []struct {
+		name    string
+		id      string
+		field   string
+		content string
+		status  int
+		code    string
+	}{
+		{"created", "2", "file", "hello", http.StatusCreated, ""},
+		{"missing file", "2", "other", "hello", http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"empty file", "2", "file", "", http.StatusBadRequest, "ATTACHMENT_EMPTY"},
+		{"too large", "2", "file", strings.Repeat("a", 100+multipartOverhead), http.StatusRequestEntityTooLarge,
+			"ATTACHMENT_TOO_LARGE"},
+		{"type not allowed", "2", "file", "<html></html>", http.StatusUnsupportedMediaType,
+			"UNSUPPORTED_ATTACHMENT_TYPE"},
+		{"todo not found", "9", "file", "hello", http.StatusNotFound, "TODO_NOT_FOUND"},
+		{"invalid id", "abc", "file", "hello", http.StatusBadRequest, "VALIDATION_ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, contentType := multipartBody(t, tt.field, tt.content)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/todos/"+tt.id+"/attachments", body)
+			r.Header.Set("Content-Type", contentType)
+			h.upload(w, mux.SetURLVars(r, map[string]string{"id": tt.id}))
+
+			if w.Code != tt.status {
+				t.Fatalf("upload() status = %d, want %d, body %s", w.Code, tt.status, w.Body)
+			}
+			if tt.code != "" && !strings.Contains(w.Body.String(), `"`+tt.code+`"`) {
+				t.Errorf("upload() body = %s, want code %s", w.Body, tt.code)
+			}
+			if tt.status == http.StatusCreated {
+				var resp AttachmentResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.ID != 5 || resp.TodoID != 2 ||
+					resp.Filename != "notes.txt" || resp.Size != 5 {
+					t.Errorf("upload() body = %s, want the new attachment", w.Body)
+				}
+			}
+		})
+	}
+
+	// A body that is not multipart is a validation error
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/todos/2/attachments", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+	h.upload(w, mux.SetURLVars(r, map[string]string{"id": "2"}))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("upload() of JSON status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestAttachmentHandler_Routes(t *testing.T) {
+	r := mux.NewRouter()
+	h := NewAttachmentHandler(&attachmentService{}, 100)
+	h.RegisterRoutes(r)
+	h.RegisterContentRoutes(r)
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		code   string
+	}{
+		{http.MethodGet, "/todos/2/attachments", http.StatusOK, ""},
+		{http.MethodGet, "/todos/9/attachments", http.StatusNotFound, "TODO_NOT_FOUND"},
+		{http.MethodGet, "/todos/2/attachments/4", http.StatusOK, ""},
+		{http.MethodGet, "/todos/2/attachments/5", http.StatusNotFound, "ATTACHMENT_NOT_FOUND"},
+		{http.MethodGet, "/todos/2/attachments/abc", http.StatusBadRequest, "VALIDATION_ERROR"},
+		{http.MethodGet, "/todos/2/attachments/5/content", http.StatusNotFound, "ATTACHMENT_NOT_FOUND"},
+		{http.MethodDelete, "/todos/2/attachments/4", http.StatusNoContent, ""},
+		{http.MethodDelete, "/todos/3/attachments/4", http.StatusNotFound, "ATTACHMENT_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.status, w.Body)
+			}
+			if tt.code != "" && !strings.Contains(w.Body.String(), `"`+tt.code+`"`) {
+				t.Errorf("body = %s, want code %s", w.Body, tt.code)
+			}
+		})
+	}
+}
+
+func TestAttachmentHandler_Download(t *testing.T) {
+	r := mux.NewRouter()
+	NewAttachmentHandler(&attachmentService{}, 100).RegisterContentRoutes(r)
+
+	download := func(rangeHeader string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/todos/2/attachments/4/content", nil)
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := download("")
+	if w.Code != http.StatusOK || w.Body.String() != testAttachmentBody {
+		t.Fatalf("download status = %d, body %q, want the whole file", w.Code, w.Body)
+	}
+	headers := map[string]string{
+		"Content-Type":           "text/plain; charset=utf-8",
+		"Content-Disposition":    `attachment; filename="plan \"v2\".txt"`,
+		"X-Content-Type-Options": "nosniff",
+		"Accept-Ranges":          "bytes",
+		"Last-Modified":          "Mon, 01 Jan 2024 12:00:00 GMT",
+	}
+	for name, want := range headers {
+		if got := w.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	w = download("bytes=2-5")
+	if w.Code != http.StatusPartialContent || w.Body.String() != "2345" ||
+		w.Header().Get("Content-Range") != "bytes 2-5/10" {
+		t.Errorf("ranged download status = %d, body %q, Content-Range %q, want bytes 2-5",
+			w.Code, w.Body, w.Header().Get("Content-Range"))
+	}
+
+	if w = download("bytes=20-"); w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("download past the end status = %d, want %d", w.Code, http.StatusRequestedRangeNotSatisfiable)
+	}
+}
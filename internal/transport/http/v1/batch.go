@@ -0,0 +1,222 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+const (
+	// maxBatchBodySize limits how much of a batch request is read
+	maxBatchBodySize = 4 << 20 // 4 MiB
+)
+
+// BatchTodos godoc
+//
+//	@Summary		Create, update and delete many todo items at once
+//	@Description	Applies a list of operations and returns one result per operation, in order. create takes a
+//	@Description	CreateTodoRequest as todo, update a PatchTodoRequest and an id, and delete an id.
+//	@Description	By default every operation is attempted on its own and the response is 200 whatever the
+//	@Description	outcome of each operation. With atomic=true the operations run in one transaction and stop at
+//	@Description	the first failure: the response then carries the status of the failed operation, and every
+//	@Description	other operation reports 424 BATCH_ABORTED.
+//	@Tags			todos
+//	@Accept			json
+//	@Produce		json
+//	@Param			atomic			query		bool					false	"Apply all operations or none"
+//	@Param			operations		body		[]BatchOperationRequest	true	"Operations to apply"
+//	@Param			Idempotency-Key	header		string					false	"Key that makes retries of the request safe"
+//	@Success		200				{object}	BatchResponse			"Result of every operation"
+//	@Failure		400				{object}	ErrorResponse			"Malformed request or, when atomic, an invalid operation"
+//	@Failure		413				{object}	ErrorResponse			"Too many operations or body too large"
+//	@Failure		500				{object}	ErrorResponse			"Internal server error"
+//	@Router			/todos:batch [post]
+func (h *TodoHandler) batch(w http.ResponseWriter, r *http.Request) {
+	atomic, err := parseBoolParam(r.URL.Query(), "atomic")
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	// The body is read in full before it is decoded, so that one that is
+	// too large is refused rather than decoded up to where it was cut off
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchBodySize))
+	if err != nil {
+		WriteError(w, r, batchReadError(err))
+		return
+	}
+
+	var reqs []BatchOperationRequest
+	if err := decodeBody(r, bytes.NewReader(body), &reqs); err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			WriteValidationError(w, r, validationErr)
+			return
+		}
+		WriteError(w, r, err)
+		return
+	}
+
+	if len(reqs) > service.MaxBatchSize {
+		WriteError(w, r, fmt.Errorf("%w: at most %d operations are allowed",
+			domain.ErrBatchTooLarge, service.MaxBatchSize))
+		return
+	}
+
+	// Operations that fail validation are answered right away; the rest
+	// are passed on, remembering where each came from.
+	results := make([]BatchResultResponse, len(reqs))
+	ops := make([]domain.BatchOperation, 0, len(reqs))
+	positions := make([]int, 0, len(reqs))
+	invalid := false
+
+	for i, req := range reqs {
+		op, validationErr := newBatchOperation(req)
+		if validationErr != nil {
+			results[i] = BatchResultResponse{
+				Status:  http.StatusBadRequest,
+				Error:   validationErr.Message,
+				Code:    "VALIDATION_ERROR",
+				Details: validationErr.Details,
+			}
+			invalid = true
+			continue
+		}
+		ops = append(ops, op)
+		positions = append(positions, i)
+	}
+
+	if atomic && invalid {
+		for _, i := range positions {
+			results[i] = newBatchResult(r, domain.BatchCreate, domain.BatchResult{Err: domain.ErrBatchAborted})
+		}
+		WriteJSONSafe(w, r, http.StatusBadRequest, BatchResponse{Results: results})
+		return
+	}
+
+	out, err := h.service.Batch(r.Context(), ops, atomic)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	for j, result := range out {
+		i := positions[j]
+		results[i] = newBatchResult(r, ops[j].Action, result)
+
+		if atomic && result.Err != nil && !errors.Is(result.Err, domain.ErrBatchAborted) {
+			status = results[i].Status
+		}
+	}
+
+	WriteJSONSafe(w, r, status, BatchResponse{Results: results})
+}
+
+// batchReadError maps an error reading a batch onto the error it is
+// reported with.
+func batchReadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return fmt.Errorf("%w: the body may be at most %d bytes", domain.ErrBatchTooLarge, maxBytesErr.Limit)
+	}
+	return NewValidationError("invalid request body")
+}
+
+// newBatchOperation validates a batch operation and maps it onto the
+// change it makes.
+func newBatchOperation(req BatchOperationRequest) (domain.BatchOperation, *ValidationError) {
+	if err := validate.Struct(&req); err != nil {
+		return domain.BatchOperation{}, &ValidationError{
+			Message: "validation failed",
+			Details: validationDetails(err),
+		}
+	}
+
+	op := domain.BatchOperation{Action: domain.BatchAction(req.Op), ID: req.ID}
+
+	if op.Action != domain.BatchCreate && req.ID == 0 {
+		return op, &ValidationError{
+			Message: "validation failed",
+			Details: map[string]string{"ID": getValidationMessage("ID", "required", "")},
+		}
+	}
+
+	var match domain.VersionMatch
+	if req.IfMatch != "" {
+		match = parseIfMatch([]string{req.IfMatch})
+	}
+
+	switch op.Action {
+	case domain.BatchCreate:
+		var todo CreateTodoRequest
+		if err := decodeBatchTodo(req.Todo, &todo); err != nil {
+			return op, err
+		}
+		op.Todo = newTodo(todo)
+	case domain.BatchUpdate:
+		var patch PatchTodoRequest
+		if err := decodeBatchTodo(req.Todo, &patch); err != nil {
+			return op, err
+		}
+		op.Update = newPatchUpdate(patch)
+		op.Update.IfVersion = match
+	case domain.BatchDelete:
+		op.IfVersion = match
+	}
+
+	return op, nil
+}
+
+// decodeBatchTodo decodes and validates the todo of a batch operation.
+func decodeBatchTodo(raw json.RawMessage, target any) *ValidationError {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return &ValidationError{
+			Message: "validation failed",
+			Details: map[string]string{"Todo": getValidationMessage("Todo", "required", "")},
+		}
+	}
+
+	if err := json.Unmarshal(raw, target); err != nil {
+		return &ValidationError{Message: "invalid JSON format"}
+	}
+
+	if err := validate.Struct(target); err != nil {
+		return &ValidationError{
+			Message: "validation failed",
+			Details: validationDetails(err),
+		}
+	}
+
+	return nil
+}
+
+// newBatchResult maps the outcome of a batch operation onto its JSON
+// representation, with the status the operation would have been answered
+// with on its own.
+func newBatchResult(r *http.Request, action domain.BatchAction, result domain.BatchResult) BatchResultResponse {
+	if result.Err != nil {
+		if !errors.Is(result.Err, domain.ErrBatchAborted) {
+			logError(r, result.Err)
+		}
+
+		status, code, message := describeError(result.Err)
+		return BatchResultResponse{Status: status, ID: result.ID, Error: message, Code: code}
+	}
+
+	switch action {
+	case domain.BatchCreate:
+		return BatchResultResponse{Status: http.StatusCreated, ID: result.ID}
+	case domain.BatchUpdate:
+		todo := newTodoResponse(*result.Todo)
+		return BatchResultResponse{Status: http.StatusOK, ID: result.ID, Todo: &todo}
+	default:
+		return BatchResultResponse{Status: http.StatusNoContent, ID: result.ID}
+	}
+}
@@ -0,0 +1,153 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+func TestNewBatchOperation(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		want        domain.BatchOperation
+		wantDetails map[string]string
+		wantErr     bool
+	}{
+		{
+			name: "create",
+			body: `{"op":"create","todo":{"title":"Buy milk","priority":"high"}}`,
+			want: domain.BatchOperation{
+				Action: domain.BatchCreate,
+				Todo:   domain.Todo{Title: "Buy milk", Priority: domain.PriorityHigh},
+			},
+		},
+		{
+			name: "conditional delete",
+			body: `{"op":"delete","id":3,"if_match":"\"2\""}`,
+			want: domain.BatchOperation{Action: domain.BatchDelete, ID: 3, IfVersion: domain.VersionMatch{2}},
+		},
+		{
+			name:        "unknown op",
+			body:        `{"op":"archive","id":3}`,
+			wantDetails: map[string]string{"Op": "Op must be one of: create, update, delete"},
+		},
+		{
+			name:        "update without id",
+			body:        `{"op":"update","todo":{"title":"Buy milk"}}`,
+			wantDetails: map[string]string{"ID": "ID is required"},
+		},
+		{
+			name:        "create without todo",
+			body:        `{"op":"create"}`,
+			wantDetails: map[string]string{"Todo": "Todo is required"},
+		},
+		{
+			name:        "invalid todo",
+			body:        `{"op":"create","todo":{"title":""}}`,
+			wantDetails: map[string]string{"Title": "Title is required"},
+		},
+		{
+			name:    "malformed todo",
+			body:    `{"op":"update","id":1,"todo":{"title":42}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req BatchOperationRequest
+			if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+				t.Fatalf("invalid test body: %v", err)
+			}
+
+			op, err := newBatchOperation(req)
+
+			if tt.wantDetails != nil || tt.wantErr {
+				if err == nil {
+					t.Fatalf("newBatchOperation() expected error, got %+v", op)
+				}
+				if tt.wantDetails != nil && !reflect.DeepEqual(err.Details, tt.wantDetails) {
+					t.Errorf("newBatchOperation() details = %v, want %v", err.Details, tt.wantDetails)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("newBatchOperation() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(op, tt.want) {
+				t.Errorf("newBatchOperation() = %+v, want %+v", op, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBatchResult(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/todos:batch", nil)
+
+	tests := []struct {
+		name       string
+		action     domain.BatchAction
+		result     domain.BatchResult
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "created", action: domain.BatchCreate, result: domain.BatchResult{ID: 1}, wantStatus: http.StatusCreated},
+		{
+			name:       "updated",
+			action:     domain.BatchUpdate,
+			result:     domain.BatchResult{ID: 1, Todo: &domain.Todo{ID: 1, Title: "Buy milk"}},
+			wantStatus: http.StatusOK,
+		},
+		{name: "deleted", action: domain.BatchDelete, result: domain.BatchResult{ID: 1}, wantStatus: http.StatusNoContent},
+		{
+			name:       "domain error",
+			action:     domain.BatchDelete,
+			result:     domain.BatchResult{ID: 1, Err: domain.ErrTodoNotFound},
+			wantStatus: http.StatusNotFound,
+			wantCode:   "TODO_NOT_FOUND",
+		},
+		{
+			name:       "aborted",
+			action:     domain.BatchCreate,
+			result:     domain.BatchResult{Err: domain.ErrBatchAborted},
+			wantStatus: http.StatusFailedDependency,
+			wantCode:   "BATCH_ABORTED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newBatchResult(r, tt.action, tt.result)
+
+			if got.Status != tt.wantStatus || got.Code != tt.wantCode {
+				t.Errorf("newBatchResult() = %d %q, want %d %q", got.Status, got.Code, tt.wantStatus, tt.wantCode)
+			}
+			if (got.Todo != nil) != (tt.result.Todo != nil) {
+				t.Errorf("newBatchResult() todo = %+v, want it only for updates", got.Todo)
+			}
+		})
+	}
+}
+
+func TestTodoHandler_BatchTooLarge(t *testing.T) {
+	// A YAML list cut off after some operation still parses, so an oversized
+	// body must be refused rather than read up to the limit
+	op := "- op: delete\n  id: 1\n"
+	body := strings.Repeat(op, maxBatchBodySize/len(op)+1)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/todos:batch", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/yaml")
+	NewTodoHandler(nil).batch(w, r)
+
+	if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), `"BATCH_TOO_LARGE"`) {
+		t.Errorf("batch() status = %d, body %s, want 413 BATCH_TOO_LARGE", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,151 @@
+package v1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/ical"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/codec"
+)
+
+const (
+	// calendarName is the name calendar applications show for the feed
+	calendarName = "Todos"
+	// calendarUIDDomain makes the UIDs of todos globally unique, as
+	// calendar applications expect
+	calendarUIDDomain = "todos.go-rest-api-example"
+)
+
+// icalPriorities maps priorities onto the iCalendar scale, where 1 is the
+// highest and 9 the lowest.
+var icalPriorities = map[domain.Priority]int{
+	domain.PriorityUrgent: 1,
+	domain.PriorityHigh:   3,
+	domain.PriorityMedium: 5,
+	domain.PriorityLow:    9,
+}
+
+// CalendarTodos godoc
+//
+//	@Summary		Subscribe to todo items as a calendar
+//	@Description	Renders every todo matching the filters as an iCalendar (RFC 5545) VTODO component, for
+//	@Description	calendar applications to subscribe to. Done todos have STATUS:COMPLETED and in-progress
+//	@Description	ones STATUS:IN-PROCESS; due dates, tags and parents are included. The filters and sort are
+//	@Description	those of GET /todos. The ETag header changes whenever the feed does and may be sent back in
+//	@Description	If-None-Match. VTODO components can be imported with POST /todos/import.
+//	@Tags			todos
+//	@Produce		text/calendar
+//	@Param			completed		query		bool	false	"Only todos with this completion state"
+//	@Param			status			query		[]string	false	"Only todos in one of these statuses"	collectionFormat(multi)	Enums(backlog, in_progress, blocked, done)
+//	@Param			priority		query		[]string	false	"Only todos with one of these priorities"	collectionFormat(multi)	Enums(low, medium, high, urgent)
+//	@Param			project_id		query		int		false	"Only todos in this project"
+//	@Param			tag				query		[]string	false	"Only todos carrying these tags"	collectionFormat(multi)
+//	@Param			tag_match		query		string	false	"Whether todos need any or all of the tags (default any)"	Enums(any, all)
+//	@Param			created_after	query		string	false	"Only todos created after this RFC 3339 time"
+//	@Param			created_before	query		string	false	"Only todos created before this RFC 3339 time"
+//	@Param			title_contains	query		string	false	"Only todos whose title contains this text (case-insensitive)"
+//	@Param			overdue			query		bool	false	"Only open todos whose due date has passed"
+//	@Param			due_today		query		bool	false	"Only todos due today in the tz time zone"
+//	@Param			due_within		query		string	false	"Only todos due within this duration from now"	example(48h)
+//	@Param			tz				query		string	false	"IANA time zone for due_today (default UTC)"	example(Europe/Berlin)
+//	@Param			sort			query		string	false	"Comma-separated sort fields, prefix with - for descending"	example(-created_at,title)
+//	@Param			If-None-Match	header		string	false	"ETag of a cached copy of the feed"
+//	@Success		200				{string}	string	"iCalendar stream of VTODO components"
+//	@Header			200				{string}	ETag	"Hash of the feed"
+//	@Success		304				"Feed has not changed since the given ETag"
+//	@Failure		400				{object}	ErrorResponse	"Invalid filter or sort parameters"
+//	@Failure		500				{object}	ErrorResponse	"Internal server error"
+//	@Router			/todos.ics [get]
+func (h *TodoHandler) calendar(w http.ResponseWriter, r *http.Request) {
+	tq, err := parseUnpagedTodoQuery(r.URL.Query())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	todos, err := h.service.List(r.Context(), tq)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	// The feed is rendered in full before anything is sent, so that its
+	// entity tag can be sent with it
+	var buf bytes.Buffer
+	if err := codec.ICalendar.Encode(&buf, newCalendar(todos)); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if notModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", codec.ICalendar.ContentType())
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		if log := logger.FromContext(r.Context()); log != nil {
+			log.Error("failed to write calendar", zap.Error(err))
+		}
+	}
+}
+
+// newCalendar maps todos onto a calendar of VTODO components.
+func newCalendar(todos []domain.Todo) *ical.Calendar {
+	cal := &ical.Calendar{Name: calendarName, Todos: make([]ical.Todo, 0, len(todos))}
+	for _, t := range todos {
+		cal.Todos = append(cal.Todos, newCalendarTodo(t))
+	}
+	return cal
+}
+
+// newCalendarTodo maps a todo onto a VTODO component. Todos do not record
+// when they were last changed, so the component is stamped with the
+// creation time and its revisions are counted by the version of the todo.
+// Stamping it with the current time instead would change the feed, and its
+// entity tag, on every request.
+func newCalendarTodo(t domain.Todo) ical.Todo {
+	created := t.CreatedAt
+	todo := ical.Todo{
+		UID:         calendarUID(t.ID),
+		Stamp:       created,
+		Created:     &created,
+		Summary:     t.Title,
+		Description: t.Description,
+		Priority:    icalPriorities[t.Priority],
+		Due:         t.DueAt,
+		Categories:  t.Tags,
+		Sequence:    max(t.Version-1, 0),
+	}
+
+	switch t.Status {
+	case domain.StatusDone:
+		todo.Status = ical.StatusCompleted
+	case domain.StatusInProgress:
+		todo.Status = ical.StatusInProcess
+	default:
+		todo.Status = ical.StatusNeedsAction
+	}
+
+	if t.ParentID != nil {
+		todo.Parent = calendarUID(*t.ParentID)
+	}
+	return todo
+}
+
+// calendarUID returns the UID of the VTODO component of a todo.
+func calendarUID(id int) string {
+	return fmt.Sprintf("todo-%d@%s", id, calendarUIDDomain)
+}
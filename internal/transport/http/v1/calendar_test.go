@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/ical"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+// listService lists a fixed set of todos and records the query.
+type listService struct {
+	service.TodoService
+	todos []domain.Todo
+	query domain.TodoQuery
+}
+
+func (s *listService) List(ctx context.Context, q domain.TodoQuery) ([]domain.Todo, error) {
+	s.query = q
+	return s.todos, nil
+}
+
+func TestCalendar(t *testing.T) {
+	created := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	due := time.Date(2024, 1, 5, 17, 0, 0, 0, time.UTC)
+	parent := 1
+
+	svc := &listService{todos: []domain.Todo{
+		{ID: 1, Title: "Move house", Status: domain.StatusInProgress, Priority: domain.PriorityHigh,
+			CreatedAt: created, Version: 1},
+		{ID: 2, Title: "Pack boxes", Description: "Books first,\nthen **kitchen**", Status: domain.StatusDone,
+			Priority: domain.PriorityMedium, CreatedAt: created, DueAt: &due, Tags: []string{"home"},
+			ParentID: &parent, Version: 3},
+	}}
+	h := NewTodoHandler(svc)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/todos.ics?tag=home&sort=-id", nil)
+	h.calendar(w, r)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+		t.Fatalf("calendar() = %d %q, want 200 text/calendar", w.Code, w.Header().Get("Content-Type"))
+	}
+	if len(svc.query.Filter.Tags) != 1 || len(svc.query.Sort) != 1 {
+		t.Errorf("calendar() query = %+v, want the tag filter and sort", svc.query)
+	}
+
+	cal, err := ical.Parse(strings.NewReader(w.Body.String()))
+	if err != nil {
+		t.Fatalf("calendar() body is not a calendar: %v\n%s", err, w.Body)
+	}
+	if len(cal.Todos) != 2 {
+		t.Fatalf("calendar() has %d todos, want 2", len(cal.Todos))
+	}
+
+	moving, packing := cal.Todos[0], cal.Todos[1]
+	if moving.UID != "todo-1@todos.go-rest-api-example" || moving.Status != ical.StatusInProcess ||
+		moving.Priority != 3 || moving.Due != nil || !moving.Stamp.Equal(created) {
+		t.Errorf("in-progress todo = %+v", moving)
+	}
+	if packing.Status != ical.StatusCompleted || packing.Due == nil || !packing.Due.Equal(due) ||
+		packing.Parent != moving.UID || packing.Sequence != 2 || len(packing.Categories) != 1 ||
+		packing.Description != "Books first,\nthen **kitchen**" {
+		t.Errorf("done todo = %+v", packing)
+	}
+
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("calendar() sent no ETag")
+	}
+
+	t.Run("unchanged feed is not modified", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos.ics?tag=home&sort=-id", nil)
+		r.Header.Set("If-None-Match", etag)
+		h.calendar(w, r)
+
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("calendar() = %d with %d bytes, want 304 without a body", w.Code, w.Body.Len())
+		}
+	})
+
+	t.Run("changed feed is sent again", func(t *testing.T) {
+		svc.todos[1].Version++
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos.ics?tag=home&sort=-id", nil)
+		r.Header.Set("If-None-Match", etag)
+		h.calendar(w, r)
+
+		if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+			t.Errorf("calendar() = %d with ETag %s, want 200 with a new ETag", w.Code, w.Header().Get("ETag"))
+		}
+	})
+}
+
+func TestCalendar_InvalidQuery(t *testing.T) {
+	for _, query := range []string{"limit=10", "status=someday", "format=ics"} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos.ics?"+query, nil)
+		NewTodoHandler(&listService{}).calendar(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("calendar(%s) status = %d, want 400", query, w.Code)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+// CommentHandler provides HTTP endpoints for the comments on todos.
+type CommentHandler struct {
+	service service.CommentService
+}
+
+// NewCommentHandler initializes the handler.
+func NewCommentHandler(s service.CommentService) *CommentHandler {
+	return &CommentHandler{service: s}
+}
+
+// RegisterRoutes attaches routes to a router.
+func (h *CommentHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/todos/{id}/comments", h.create).Methods("POST")
+	r.HandleFunc("/todos/{id}/comments", h.list).Methods("GET")
+	r.HandleFunc("/todos/{id}/comments/{commentID}", h.getByID).Methods("GET")
+	r.HandleFunc("/todos/{id}/comments/{commentID}", h.update).Methods("PUT")
+	r.HandleFunc("/todos/{id}/comments/{commentID}", h.delete).Methods("DELETE")
+}
+
+// newCommentResponse maps a domain comment onto its JSON representation.
+func newCommentResponse(c domain.Comment) CommentResponse {
+	return CommentResponse{
+		ID:        c.ID,
+		TodoID:    c.TodoID,
+		Author:    c.Author,
+		Body:      c.Body,
+		CreatedAt: c.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: formatTime(c.UpdatedAt),
+	}
+}
+
+// pathID parses the named path parameter, writing a validation error when
+// it is not a number.
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	log := logger.FromContext(r.Context())
+
+	idStr := mux.Vars(r)[name]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid "+name, zap.String("param", idStr))
+		}
+		WriteError(w, r, NewValidationError("invalid "+name+" parameter"))
+		return 0, false
+	}
+	return id, true
+}
+
+// commentIDs parses the todo and comment ID path parameters.
+func commentIDs(w http.ResponseWriter, r *http.Request) (todoID, id int, ok bool) {
+	if todoID, ok = pathID(w, r, "id"); !ok {
+		return 0, 0, false
+	}
+	if id, ok = pathID(w, r, "commentID"); !ok {
+		return 0, 0, false
+	}
+	return todoID, id, true
+}
+
+// CreateComment godoc
+//
+//	@Summary		Comment on a todo
+//	@Description	Adds a comment to the discussion of a todo. Todos in the trash cannot be commented on.
+//	@Tags			comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		int						true	"Todo ID"
+//	@Param			comment			body		CreateCommentRequest	true	"Comment"
+//	@Param			Idempotency-Key	header		string					false	"Key that makes retries of the request safe"
+//	@Success		201				{object}	CommentResponse			"Comment created"
+//	@Failure		400				{object}	ValidationError			"Validation error"
+//	@Failure		404				{object}	ErrorResponse			"Todo not found"
+//	@Failure		500				{object}	ErrorResponse			"Internal server error"
+//	@Router			/todos/{id}/comments [post]
+func (h *CommentHandler) create(w http.ResponseWriter, r *http.Request) {
+	todoID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := DecodeAndValidateJSON(r, &req); err != nil {
+		if validationErr, ok := err.(*ValidationError); ok {
+			WriteValidationError(w, r, validationErr)
+			return
+		}
+		WriteError(w, r, err)
+		return
+	}
+
+	c, err := h.service.Create(r.Context(), domain.Comment{
+		TodoID: todoID,
+		Author: req.Author,
+		Body:   req.Body,
+	})
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSONSafe(w, r, http.StatusCreated, newCommentResponse(*c))
+}
+
+// ListComments godoc
+//
+//	@Summary		List the comments on a todo
+//	@Description	Retrieves a page of the comments on a todo, oldest first. Use the returned cursors (also
+//	@Description	sent as RFC 8288 Link headers) with after or before to move between pages.
+//	@Tags			comments
+//	@Produce		json
+//	@Produce		application/yaml
+//	@Produce		application/msgpack
+//	@Param			id		path		int					true	"Todo ID"
+//	@Param			limit	query		int					false	"Page size (default 20, max 100)"
+//	@Param			after	query		string				false	"Cursor of the page to continue after"
+//	@Param			before	query		string				false	"Cursor of the page to continue before"
+//	@Success		200		{object}	CommentListResponse	"Successfully retrieved comments"
+//	@Failure		400		{object}	ErrorResponse		"Invalid ID or pagination parameters"
+//	@Failure		404		{object}	ErrorResponse		"Todo not found"
+//	@Failure		406		{object}	ErrorResponse		"None of the accepted media types is supported"
+//	@Failure		500		{object}	ErrorResponse		"Internal server error"
+//	@Router			/todos/{id}/comments [get]
+func (h *CommentHandler) list(w http.ResponseWriter, r *http.Request) {
+	todoID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit := 0
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			WriteError(w, r, NewValidationError("limit must be a positive integer"))
+			return
+		}
+	}
+
+	page, err := h.service.ListPage(r.Context(), todoID, domain.PageRequest{
+		Limit:  limit,
+		After:  query.Get("after"),
+		Before: query.Get("before"),
+	})
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	resp := CommentListResponse{
+		Items:      make([]CommentResponse, 0, len(page.Items)),
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+	for _, c := range page.Items {
+		resp.Items = append(resp.Items, newCommentResponse(c))
+	}
+
+	setPaginationLinks(w, r, page.NextCursor, page.PrevCursor)
+	WriteJSONSafe(w, r, http.StatusOK, resp)
+}
+
+// GetComment godoc
+//
+//	@Summary		Get a comment on a todo
+//	@Description	Retrieves a single comment on a todo
+//	@Tags			comments
+//	@Produce		json
+//	@Param			id			path		int				true	"Todo ID"
+//	@Param			commentID	path		int				true	"Comment ID"
+//	@Success		200			{object}	CommentResponse	"Successfully retrieved comment"
+//	@Failure		400			{object}	ErrorResponse	"Invalid ID parameter"
+//	@Failure		404			{object}	ErrorResponse	"Todo or comment not found"
+//	@Failure		500			{object}	ErrorResponse	"Internal server error"
+//	@Router			/todos/{id}/comments/{commentID} [get]
+func (h *CommentHandler) getByID(w http.ResponseWriter, r *http.Request) {
+	todoID, id, ok := commentIDs(w, r)
+	if !ok {
+		return
+	}
+
+	c, err := h.service.GetByID(r.Context(), todoID, id)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSONSafe(w, r, http.StatusOK, newCommentResponse(*c))
+}
+
+// UpdateComment godoc
+//
+//	@Summary		Edit a comment
+//	@Description	Replaces the body of a comment and records when it was edited. The author cannot be changed.
+//	@Tags			comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int						true	"Todo ID"
+//	@Param			commentID	path		int						true	"Comment ID"
+//	@Param			comment		body		UpdateCommentRequest	true	"New comment body"
+//	@Success		200			{object}	CommentResponse			"Successfully updated comment"
+//	@Failure		400			{object}	ValidationError			"Validation error"
+//	@Failure		404			{object}	ErrorResponse			"Todo or comment not found"
+//	@Failure		500			{object}	ErrorResponse			"Internal server error"
+//	@Router			/todos/{id}/comments/{commentID} [put]
+func (h *CommentHandler) update(w http.ResponseWriter, r *http.Request) {
+	todoID, id, ok := commentIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateCommentRequest
+	if err := DecodeAndValidateJSON(r, &req); err != nil {
+		if validationErr, ok := err.(*ValidationError); ok {
+			WriteValidationError(w, r, validationErr)
+			return
+		}
+		WriteError(w, r, err)
+		return
+	}
+
+	c, err := h.service.Update(r.Context(), domain.Comment{ID: id, TodoID: todoID, Body: req.Body})
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSONSafe(w, r, http.StatusOK, newCommentResponse(*c))
+}
+
+// DeleteComment godoc
+//
+//	@Summary		Delete a comment
+//	@Description	Deletes a comment on a todo
+//	@Tags			comments
+//	@Param			id			path	int	true	"Todo ID"
+//	@Param			commentID	path	int	true	"Comment ID"
+//	@Success		204			"Comment deleted"
+//	@Failure		400			{object}	ErrorResponse	"Invalid ID parameter"
+//	@Failure		404			{object}	ErrorResponse	"Todo or comment not found"
+//	@Failure		500			{object}	ErrorResponse	"Internal server error"
+//	@Router			/todos/{id}/comments/{commentID} [delete]
+func (h *CommentHandler) delete(w http.ResponseWriter, r *http.Request) {
+	todoID, id, ok := commentIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), todoID, id); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+// commentService knows todos 1 to 3, of which only todo 2 has a comment,
+// comment 5.
+type commentService struct {
+	service.CommentService
+	lastPage domain.PageRequest
+}
+
+var testComment = domain.Comment{
+	ID:        5,
+	TodoID:    2,
+	Author:    "Alice",
+	Body:      "Tiles?",
+	CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+}
+
+func (s *commentService) Create(ctx context.Context, c domain.Comment) (*domain.Comment, error) {
+	if c.TodoID > 3 {
+		return nil, domain.ErrTodoNotFound
+	}
+	c.ID, c.CreatedAt = 6, testComment.CreatedAt
+	return &c, nil
+}
+
+func (s *commentService) GetByID(ctx context.Context, todoID, id int) (*domain.Comment, error) {
+	if todoID > 3 {
+		return nil, domain.ErrTodoNotFound
+	}
+	if todoID != testComment.TodoID || id != testComment.ID {
+		return nil, domain.ErrCommentNotFound
+	}
+	c := testComment
+	return &c, nil
+}
+
+func (s *commentService) ListPage(ctx context.Context, todoID int, p domain.PageRequest) (*domain.CommentPage,
+	error) {
+	s.lastPage = p
+	if todoID > 3 {
+		return nil, domain.ErrTodoNotFound
+	}
+	return &domain.CommentPage{Items: []domain.Comment{testComment}, NextCursor: "next"}, nil
+}
+
+func (s *commentService) Update(ctx context.Context, c domain.Comment) (*domain.Comment, error) {
+	current, err := s.GetByID(ctx, c.TodoID, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt := current.CreatedAt.Add(time.Hour)
+	current.Body, current.UpdatedAt = c.Body, &updatedAt
+	return current, nil
+}
+
+func (s *commentService) Delete(ctx context.Context, todoID, id int) error {
+	_, err := s.GetByID(ctx, todoID, id)
+	return err
+}
+
+func TestCommentHandler_Create(t *testing.T) {
+	h := NewCommentHandler(&commentService{})
+
+	tests := []struct {
+		name   string
+		id     string
+		body   string
+		status int
+	}{
+		{"created", "2", `{"author": "Bob", "body": "Ordered"}`, http.StatusCreated},
+		{"missing body", "2", `{"author": "Bob"}`, http.StatusBadRequest},
+		{"invalid id", "abc", `{"author": "Bob", "body": "Ordered"}`, http.StatusBadRequest},
+		{"todo not found", "9", `{"author": "Bob", "body": "Ordered"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/todos/"+tt.id+"/comments", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			h.create(w, mux.SetURLVars(r, map[string]string{"id": tt.id}))
+
+			if w.Code != tt.status {
+				t.Fatalf("create() status = %d, want %d, body %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status == http.StatusCreated {
+				var resp CommentResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.ID != 6 || resp.TodoID != 2 ||
+					resp.Author != "Bob" || resp.CreatedAt != "2024-01-01T12:00:00Z" || resp.UpdatedAt != nil {
+					t.Errorf("create() body = %s, want the new comment", w.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestCommentHandler_List(t *testing.T) {
+	svc := &commentService{}
+	h := NewCommentHandler(svc)
+
+	list := func(id, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/"+id+"/comments"+query, nil)
+		h.list(w, mux.SetURLVars(r, map[string]string{"id": id}))
+		return w
+	}
+
+	w := list("2", "?limit=1&after=abc")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list() status = %d, body %s", w.Code, w.Body)
+	}
+	if svc.lastPage != (domain.PageRequest{Limit: 1, After: "abc"}) {
+		t.Errorf("list() requested page %+v", svc.lastPage)
+	}
+	var resp CommentListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Items) != 1 ||
+		resp.Items[0].ID != 5 || resp.NextCursor != "next" {
+		t.Errorf("list() body = %s, want comment 5 and the next cursor", w.Body)
+	}
+	if link := w.Header().Get("Link"); !strings.Contains(link, "after=next") || strings.Contains(link, "after=abc") {
+		t.Errorf("list() Link = %q, want a link to the next page", link)
+	}
+
+	tests := []struct {
+		name   string
+		id     string
+		query  string
+		status int
+	}{
+		{"invalid limit", "2", "?limit=0", http.StatusBadRequest},
+		{"invalid id", "abc", "", http.StatusBadRequest},
+		{"todo not found", "9", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := list(tt.id, tt.query); w.Code != tt.status {
+				t.Errorf("list() status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestCommentHandler_Routes(t *testing.T) {
+	r := mux.NewRouter()
+	NewCommentHandler(&commentService{}).RegisterRoutes(r)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{http.MethodGet, "/todos/2/comments/5", "", http.StatusOK, ""},
+		{http.MethodGet, "/todos/2/comments/6", "", http.StatusNotFound, "COMMENT_NOT_FOUND"},
+		{http.MethodGet, "/todos/9/comments/5", "", http.StatusNotFound, "TODO_NOT_FOUND"},
+		{http.MethodGet, "/todos/2/comments/abc", "", http.StatusBadRequest, "VALIDATION_ERROR"},
+		{http.MethodPut, "/todos/2/comments/5", `{"body": "Ordered"}`, http.StatusOK, ""},
+		{http.MethodPut, "/todos/2/comments/5", `{"body": ""}`, http.StatusBadRequest, ""},
+		{http.MethodPut, "/todos/3/comments/5", `{"body": "Ordered"}`, http.StatusNotFound, "COMMENT_NOT_FOUND"},
+		{http.MethodDelete, "/todos/2/comments/5", "", http.StatusNoContent, ""},
+		{http.MethodDelete, "/todos/2/comments/6", "", http.StatusNotFound, "COMMENT_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.status, w.Body)
+			}
+			if tt.code != "" && !strings.Contains(w.Body.String(), `"`+tt.code+`"`) {
+				t.Errorf("body = %s, want code %s", w.Body, tt.code)
+			}
+		})
+	}
+
+	// An edited comment says when it was edited
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/todos/2/comments/5", strings.NewReader(`{"body": "Ordered"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	var resp CommentResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Body != "Ordered" || resp.UpdatedAt == nil {
+		t.Errorf("update body = %s, want the edited comment", w.Body)
+	}
+}
@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// AddDependency godoc
+//
+//	@Summary		Make a todo wait on another
+//	@Description	Records that the todo cannot be completed before the blocking todo is done. A dependency
+//	@Description	that would make a todo wait on itself, directly or through other todos, is rejected.
+//	@Description	Adding a dependency that already exists changes nothing and answers 200 instead of 201.
+//	@Tags			dependencies
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int						true	"Todo ID"
+//	@Param			dependency	body		AddDependencyRequest	true	"Blocking todo"
+//	@Success		201			{object}	DependencyResponse		"Dependency added"
+//	@Success		200			{object}	DependencyResponse		"Dependency already existed"
+//	@Failure		400			{object}	ValidationError			"Validation error"
+//	@Failure		404			{object}	ErrorResponse			"Todo not found"
+//	@Failure		422			{object}	ErrorResponse			"Blocking todo not found or dependency cycle"
+//	@Failure		500			{object}	ErrorResponse			"Internal server error"
+//	@Router			/todos/{id}/dependencies [post]
+func (h *TodoHandler) addDependency(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid id", zap.String("param", idStr))
+		}
+		WriteError(w, r, NewValidationError("invalid id parameter"))
+		return
+	}
+
+	var req AddDependencyRequest
+	if err := DecodeAndValidateJSON(r, &req); err != nil {
+		if validationErr, ok := err.(*ValidationError); ok {
+			WriteValidationError(w, r, validationErr)
+			return
+		}
+		WriteError(w, r, err)
+		return
+	}
+
+	created, err := h.service.AddDependency(r.Context(), id, req.BlockedBy)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	WriteJSONSafe(w, r, status, DependencyResponse{TodoID: id, BlockedByID: req.BlockedBy})
+}
+
+// RemoveDependency godoc
+//
+//	@Summary		Stop a todo waiting on another
+//	@Description	Removes the dependency of the todo on the blocking todo
+//	@Tags			dependencies
+//	@Param			id			path	int	true	"Todo ID"
+//	@Param			blockerID	path	int	true	"Blocking todo ID"
+//	@Success		204			"Dependency removed"
+//	@Failure		400			{object}	ErrorResponse	"Invalid ID parameter"
+//	@Failure		404			{object}	ErrorResponse	"Todo or dependency not found"
+//	@Failure		500			{object}	ErrorResponse	"Internal server error"
+//	@Router			/todos/{id}/dependencies/{blockerID} [delete]
+func (h *TodoHandler) removeDependency(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid id", zap.String("param", vars["id"]))
+		}
+		WriteError(w, r, NewValidationError("invalid id parameter"))
+		return
+	}
+	blockerID, err := strconv.Atoi(vars["blockerID"])
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid blocker id", zap.String("param", vars["blockerID"]))
+		}
+		WriteError(w, r, NewValidationError("invalid blockerID parameter"))
+		return
+	}
+
+	if err := h.service.RemoveDependency(r.Context(), id, blockerID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDependencyGraph godoc
+//
+//	@Summary		Get the dependency graph of a todo
+//	@Description	Returns the todos the todo waits on, directly or through other todos, the todos waiting on
+//	@Description	it in the same way, and the dependencies between them. Todos in the trash are left out. Very
+//	@Description	large graphs are cut short and marked as truncated.
+//	@Tags			dependencies
+//	@Produce		json
+//	@Produce		application/yaml
+//	@Produce		application/msgpack
+//	@Param			id	path		int						true	"Todo ID"
+//	@Success		200	{object}	DependencyGraphResponse	"Dependency graph"
+//	@Failure		400	{object}	ErrorResponse			"Invalid ID parameter"
+//	@Failure		404	{object}	ErrorResponse			"Todo not found"
+//	@Failure		406	{object}	ErrorResponse			"None of the accepted media types is supported"
+//	@Failure		500	{object}	ErrorResponse			"Internal server error"
+//	@Router			/todos/{id}/graph [get]
+func (h *TodoHandler) dependencyGraph(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid id", zap.String("param", idStr))
+		}
+		WriteError(w, r, NewValidationError("invalid id parameter"))
+		return
+	}
+
+	graph, err := h.service.DependencyGraph(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	resp := DependencyGraphResponse{
+		TodoID:       graph.TodoID,
+		Todos:        make([]TodoResponse, 0, len(graph.Todos)),
+		Dependencies: make([]DependencyResponse, 0, len(graph.Dependencies)),
+		Truncated:    graph.Truncated,
+	}
+	for _, t := range graph.Todos {
+		resp.Todos = append(resp.Todos, newTodoResponse(t))
+	}
+	for _, d := range graph.Dependencies {
+		resp.Dependencies = append(resp.Dependencies, DependencyResponse{TodoID: d.TodoID, BlockedByID: d.BlockedByID})
+	}
+
+	WriteJSONSafe(w, r, http.StatusOK, resp)
+}
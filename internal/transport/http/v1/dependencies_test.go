@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+// dependencyService knows todos 1 to 3, where 2 already waits on 1, and
+// rejects todo 1 waiting on anything as a cycle.
+type dependencyService struct {
+	service.TodoService
+}
+
+func (s *dependencyService) AddDependency(ctx context.Context, id, blockedBy int) (bool, error) {
+	switch {
+	case id > 3:
+		return false, domain.ErrTodoNotFound
+	case blockedBy > 3:
+		return false, domain.ErrBlockerNotFound
+	case id == 1:
+		return false, fmt.Errorf("%w: todo %d already waits on todo 1", domain.ErrDependencyCycle, blockedBy)
+	}
+	return !(id == 2 && blockedBy == 1), nil
+}
+
+func (s *dependencyService) RemoveDependency(ctx context.Context, id, blockedBy int) error {
+	if id == 2 && blockedBy == 1 {
+		return nil
+	}
+	return domain.ErrDependencyNotFound
+}
+
+func (s *dependencyService) DependencyGraph(ctx context.Context, id int) (*domain.DependencyGraph, error) {
+	if id > 3 {
+		return nil, domain.ErrTodoNotFound
+	}
+	return &domain.DependencyGraph{
+		TodoID:       id,
+		Todos:        []domain.Todo{{ID: 1, Title: "Pour foundation"}, {ID: 2, Title: "Build walls"}},
+		Dependencies: []domain.Dependency{{TodoID: 2, BlockedByID: 1}},
+	}, nil
+}
+
+func TestAddDependency(t *testing.T) {
+	h := NewTodoHandler(&dependencyService{})
+
+	tests := []struct {
+		name   string
+		id     string
+		body   string
+		status int
+		code   string
+	}{
+		{"created", "3", `{"blocked_by": 1}`, http.StatusCreated, ""},
+		{"already exists", "2", `{"blocked_by": 1}`, http.StatusOK, ""},
+		{"invalid id", "abc", `{"blocked_by": 1}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"missing blocker", "2", `{}`, http.StatusBadRequest, ""},
+		{"todo not found", "9", `{"blocked_by": 1}`, http.StatusNotFound, "TODO_NOT_FOUND"},
+		{"blocker not found", "2", `{"blocked_by": 9}`, http.StatusUnprocessableEntity, "BLOCKER_NOT_FOUND"},
+		{"cycle", "1", `{"blocked_by": 2}`, http.StatusUnprocessableEntity, "DEPENDENCY_CYCLE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/todos/"+tt.id+"/dependencies",
+				strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			h.addDependency(w, mux.SetURLVars(r, map[string]string{"id": tt.id}))
+
+			if w.Code != tt.status {
+				t.Fatalf("addDependency() status = %d, want %d, body %s", w.Code, tt.status, w.Body)
+			}
+			if tt.code != "" && !strings.Contains(w.Body.String(), `"`+tt.code+`"`) {
+				t.Errorf("addDependency() body = %s, want code %s", w.Body, tt.code)
+			}
+			if tt.status < http.StatusBadRequest {
+				var resp DependencyResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.TodoID == 0 || resp.BlockedByID != 1 {
+					t.Errorf("addDependency() body = %s, want the dependency", w.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveDependency(t *testing.T) {
+	h := NewTodoHandler(&dependencyService{})
+
+	tests := []struct {
+		name      string
+		id        string
+		blockerID string
+		status    int
+	}{
+		{"removed", "2", "1", http.StatusNoContent},
+		{"not found", "3", "1", http.StatusNotFound},
+		{"invalid id", "abc", "1", http.StatusBadRequest},
+		{"invalid blocker id", "2", "abc", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/api/v1/todos/"+tt.id+"/dependencies/"+tt.blockerID, nil)
+			h.removeDependency(w, mux.SetURLVars(r, map[string]string{"id": tt.id, "blockerID": tt.blockerID}))
+
+			if w.Code != tt.status {
+				t.Errorf("removeDependency() status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestDependencyGraph(t *testing.T) {
+	h := NewTodoHandler(&dependencyService{})
+
+	get := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/"+id+"/graph", nil)
+		h.dependencyGraph(w, mux.SetURLVars(r, map[string]string{"id": id}))
+		return w
+	}
+
+	w := get("2")
+	if w.Code != http.StatusOK {
+		t.Fatalf("dependencyGraph() status = %d, body %s", w.Code, w.Body)
+	}
+	var resp DependencyGraphResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("dependencyGraph() body is not a DependencyGraphResponse: %v", err)
+	}
+	if resp.TodoID != 2 || len(resp.Todos) != 2 || resp.Todos[1].Title != "Build walls" || resp.Truncated {
+		t.Errorf("dependencyGraph() = %+v", resp)
+	}
+	if want := []DependencyResponse{{TodoID: 2, BlockedByID: 1}}; !reflect.DeepEqual(resp.Dependencies, want) {
+		t.Errorf("dependencyGraph() dependencies = %+v, want %+v", resp.Dependencies, want)
+	}
+
+	if w := get("9"); w.Code != http.StatusNotFound {
+		t.Errorf("dependencyGraph() status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w := get("abc"); w.Code != http.StatusBadRequest {
+		t.Errorf("dependencyGraph() status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestWriteError_Blocked(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPatch, "/api/v1/todos/3", nil)
+	WriteError(w, r, fmt.Errorf("%w: waiting on 1, 2", domain.ErrBlocked))
+
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), `"TODO_BLOCKED"`) ||
+		!strings.Contains(w.Body.String(), "waiting on 1, 2") {
+		t.Errorf("WriteError() = %d %s, want 409 naming the blockers", w.Code, w.Body)
+	}
+}
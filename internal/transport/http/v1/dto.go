@@ -1,14 +1,285 @@
 package v1
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // CreateTodoRequest is the payload for creating a new todo.
 type CreateTodoRequest struct {
-	Title string `json:"title" validate:"required,min=1,max=255" example:"Buy groceries"`
+	Title       string     `json:"title" validate:"required,min=1,max=255" example:"Buy groceries"`
+	Description string     `json:"description,omitempty" validate:"max=10000" example:"Whole milk and **rye** bread"`
+	Status      string     `json:"status,omitempty" validate:"omitempty,oneof=backlog in_progress blocked done" example:"backlog"`
+	Priority    string     `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent" example:"medium"`
+	DueAt       *time.Time `json:"due_at,omitempty" example:"2023-01-02T17:00:00Z"`
+	RemindAt    *time.Time `json:"remind_at,omitempty" example:"2023-01-02T16:00:00Z"`
+	Tags        []string   `json:"tags,omitempty" validate:"max=20,dive,min=1,max=32" example:"home,errands"`
+	ProjectID   *int       `json:"project_id,omitempty" validate:"omitempty,gt=0" example:"1"`
+	ParentID    *int       `json:"parent_id,omitempty" validate:"omitempty,gt=0" example:"3"`
+	// Recurrence makes the todo the first occurrence of a series. It needs
+	// a due date, which the series starts at.
+	Recurrence *RecurrenceRequest `json:"recurrence,omitempty"`
+}
+
+// RecurrenceRequest makes a new todo recur. Completing an occurrence
+// creates the next one, due at the next time the rule gives.
+type RecurrenceRequest struct {
+	// Rule is an RFC 5545 RRULE, with or without the "RRULE:" prefix
+	Rule string `json:"rule" validate:"required,max=500" example:"FREQ=MONTHLY;BYMONTHDAY=1"`
+	// TimeZone is the IANA time zone the rule is evaluated in (default UTC)
+	TimeZone string `json:"time_zone,omitempty" validate:"max=64" example:"Europe/Berlin"`
+}
+
+// UpdateTodoRequest is the payload for replacing a todo.
+// Either status or the legacy completed flag must be provided. Omitted
+// descriptions, dates, tags, project and parent are cleared and an omitted
+// priority is reset to medium.
+type UpdateTodoRequest struct {
+	Title       string     `json:"title" validate:"required,min=1,max=255" example:"Buy groceries"`
+	Description string     `json:"description,omitempty" validate:"max=10000" example:"Whole milk and **rye** bread"`
+	Completed   *bool      `json:"completed,omitempty" validate:"required_without=Status" example:"true"`
+	Status      *string    `json:"status,omitempty" validate:"omitempty,oneof=backlog in_progress blocked done" example:"done"`
+	Priority    string     `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent" example:"high"`
+	DueAt       *time.Time `json:"due_at,omitempty" example:"2023-01-02T17:00:00Z"`
+	RemindAt    *time.Time `json:"remind_at,omitempty" example:"2023-01-02T16:00:00Z"`
+	Tags        []string   `json:"tags,omitempty" validate:"max=20,dive,min=1,max=32" example:"home,errands"`
+	ProjectID   *int       `json:"project_id,omitempty" validate:"omitempty,gt=0" example:"1"`
+	ParentID    *int       `json:"parent_id,omitempty" validate:"omitempty,gt=0" example:"3"`
+}
+
+// PatchTodoRequest is the payload for partially updating a todo.
+// Omitted fields are left unchanged; dates, project_id and parent_id set to
+// null are cleared.
+type PatchTodoRequest struct {
+	Title       *string      `json:"title,omitempty" validate:"omitempty,min=1,max=255" example:"Buy groceries"`
+	Description *string      `json:"description,omitempty" validate:"omitempty,max=10000" example:"Whole milk and **rye** bread"`
+	Completed   *bool        `json:"completed,omitempty" example:"true"`
+	Status      *string      `json:"status,omitempty" validate:"omitempty,oneof=backlog in_progress blocked done" example:"in_progress"`
+	Priority    *string      `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent" example:"urgent"`
+	DueAt       NullableTime `json:"due_at,omitempty" swaggertype:"string" format:"date-time" example:"2023-01-02T17:00:00Z"`
+	RemindAt    NullableTime `json:"remind_at,omitempty" swaggertype:"string" format:"date-time" example:"2023-01-02T16:00:00Z"`
+	Tags        *[]string    `json:"tags,omitempty" validate:"omitempty,max=20,dive,min=1,max=32" example:"work"`
+	ProjectID   NullableInt  `json:"project_id,omitempty" validate:"omitempty,gt=0" swaggertype:"integer" example:"1"`
+	ParentID    NullableInt  `json:"parent_id,omitempty" validate:"omitempty,gt=0" swaggertype:"integer" example:"3"`
 }
 
 // TodoResponse is the JSON representation returned to clients.
+// Completed is derived from the status and kept for older clients.
+// Subtasks is only present when the subtask tree was requested, and
+// DescriptionHTML when the description was rendered as HTML.
 type TodoResponse struct {
-	ID        int    `json:"id" example:"1"`
-	Title     string `json:"title" example:"Buy groceries"`
-	Completed bool   `json:"completed" example:"false"`
-	CreatedAt string `json:"created_at" example:"2023-01-01T12:00:00Z"`
+	ID              int            `json:"id" example:"1"`
+	Title           string         `json:"title" example:"Buy groceries"`
+	Description     string         `json:"description" example:"Whole milk and **rye** bread"`
+	DescriptionHTML *string        `json:"description_html,omitempty" example:"<p>Whole milk and <strong>rye</strong> bread</p>"`
+	Status          string         `json:"status" example:"in_progress"`
+	Priority        string         `json:"priority" example:"medium"`
+	Completed       bool           `json:"completed" example:"false"`
+	CreatedAt       string         `json:"created_at" example:"2023-01-01T12:00:00Z"`
+	DueAt           *string        `json:"due_at,omitempty" example:"2023-01-02T17:00:00Z"`
+	RemindAt        *string        `json:"remind_at,omitempty" example:"2023-01-02T16:00:00Z"`
+	Tags            []string       `json:"tags" example:"home,errands"`
+	ProjectID       *int           `json:"project_id,omitempty" example:"1"`
+	ParentID        *int           `json:"parent_id,omitempty" example:"3"`
+	SeriesID        *int           `json:"series_id,omitempty" example:"2"`
+	Subtasks        []TodoResponse `json:"subtasks,omitempty"`
+	DeletedAt       *string        `json:"deleted_at,omitempty" example:"2023-01-03T09:00:00Z"`
+	ETag            string         `json:"etag" example:"\"3\""`
+}
+
+// TodoListResponse is a single page of todos. The cursors are opaque and
+// should be passed back unchanged as the after or before query parameter.
+type TodoListResponse struct {
+	Items      []TodoResponse `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty" example:"eyJpZCI6MjB9"`
+	PrevCursor string         `json:"prev_cursor,omitempty" example:"eyJpZCI6MX0"`
+}
+
+// Table returns the items of the page, which is all a CSV response holds.
+// The cursors are still sent in the Link header.
+func (r TodoListResponse) Table() any {
+	return r.Items
+}
+
+// OccurrencesResponse previews the occurrences of a recurring todo that
+// follow it. Due dates are given in the time zone of the series.
+type OccurrencesResponse struct {
+	SeriesID int                  `json:"series_id" example:"2"`
+	Rule     string               `json:"rule" example:"FREQ=MONTHLY;BYMONTHDAY=1"`
+	TimeZone string               `json:"time_zone" example:"Europe/Berlin"`
+	Items    []OccurrenceResponse `json:"items"`
+}
+
+// Table returns the occurrences, which is all a CSV response holds.
+func (r OccurrencesResponse) Table() any {
+	return r.Items
+}
+
+// OccurrenceResponse is a future occurrence of a recurring todo.
+type OccurrenceResponse struct {
+	DueAt string `json:"due_at" example:"2023-02-01T09:00:00+01:00"`
+}
+
+// AddDependencyRequest names the todo that must be done before another.
+type AddDependencyRequest struct {
+	BlockedBy int `json:"blocked_by" validate:"required,gt=0" example:"4"`
+}
+
+// DependencyResponse says that a todo cannot be completed before the todo
+// blocking it is done.
+type DependencyResponse struct {
+	TodoID      int `json:"todo_id" example:"7"`
+	BlockedByID int `json:"blocked_by_id" example:"4"`
+}
+
+// DependencyGraphResponse holds the todos a todo transitively waits on or
+// holds up, the todo itself included, and the dependencies between them.
+// Truncated is set when the graph was too large to return in full.
+type DependencyGraphResponse struct {
+	TodoID       int                  `json:"todo_id" example:"7"`
+	Todos        []TodoResponse       `json:"todos"`
+	Dependencies []DependencyResponse `json:"dependencies"`
+	Truncated    bool                 `json:"truncated" example:"false"`
+}
+
+// MoveTodoRequest names the todo another todo is moved directly before or
+// directly after. Exactly one of the two is set.
+type MoveTodoRequest struct {
+	Before *int `json:"before,omitempty" validate:"omitempty,gt=0" example:"4"`
+	After  *int `json:"after,omitempty" validate:"omitempty,gt=0"`
+}
+
+// TagResponse is a tag together with the number of todos carrying it.
+type TagResponse struct {
+	Name  string `json:"name" example:"work"`
+	Count int    `json:"count" example:"3"`
+}
+
+// BatchOperationRequest is a single operation of a batch request. Todo is a
+// CreateTodoRequest for create and a PatchTodoRequest for update, and is
+// not used by delete. IfMatch makes an update or delete conditional, like
+// the If-Match header does for a single todo.
+type BatchOperationRequest struct {
+	Op      string          `json:"op" validate:"required,oneof=create update delete" example:"update"`
+	ID      int             `json:"id,omitempty" validate:"omitempty,gt=0" example:"1"`
+	IfMatch string          `json:"if_match,omitempty" example:"\"3\""`
+	Todo    json.RawMessage `json:"todo,omitempty" swaggertype:"object"`
+}
+
+// BatchResultResponse is the outcome of a single batch operation. Status is
+// the HTTP status the operation would have been answered with on its own;
+// created todos carry their id and updated todos the whole todo.
+type BatchResultResponse struct {
+	Status  int               `json:"status" example:"200"`
+	ID      int               `json:"id,omitempty" example:"1"`
+	Todo    *TodoResponse     `json:"todo,omitempty"`
+	Error   string            `json:"error,omitempty" example:"todo not found"`
+	Code    string            `json:"code,omitempty" example:"TODO_NOT_FOUND"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// BatchResponse holds one result per operation, in the order of the request.
+type BatchResponse struct {
+	Results []BatchResultResponse `json:"results"`
+}
+
+// SearchResultResponse is a todo matching a search. Snippet is the
+// matching text as HTML, with the matched words in <mark> elements.
+type SearchResultResponse struct {
+	TodoResponse
+	Score   float64 `json:"score" example:"0.1"`
+	Snippet string  `json:"snippet" example:"Buy <mark>groceries</mark>"`
+}
+
+// SearchResponse holds the todos matching a search, best matches first.
+type SearchResponse struct {
+	Items []SearchResultResponse `json:"items"`
+}
+
+// Table returns the matching todos, which is all a CSV response holds.
+func (r SearchResponse) Table() any {
+	return r.Items
+}
+
+// ImportLineError describes why the todo on a line of an import was
+// rejected.
+type ImportLineError struct {
+	Line    int               `json:"line" example:"3"`
+	Error   string            `json:"error" example:"validation failed"`
+	Code    string            `json:"code" example:"VALIDATION_ERROR"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// ImportResponse is the outcome of an import. Imported is zero unless
+// every todo was valid and the import was not a dry run.
+type ImportResponse struct {
+	DryRun   bool              `json:"dry_run" example:"false"`
+	Total    int               `json:"total" example:"12"`
+	Imported int               `json:"imported" example:"12"`
+	Errors   []ImportLineError `json:"errors,omitempty"`
+}
+
+// CreateProjectRequest is the payload for creating a new project.
+type CreateProjectRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=100" example:"Home renovation"`
+	Description string `json:"description,omitempty" validate:"max=1000" example:"Everything for the new kitchen"`
+}
+
+// UpdateProjectRequest is the payload for replacing a project.
+// An omitted description is cleared.
+type UpdateProjectRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=100" example:"Home renovation"`
+	Description string `json:"description,omitempty" validate:"max=1000" example:"Everything for the new kitchen"`
+}
+
+// ProjectResponse is the JSON representation of a project.
+type ProjectResponse struct {
+	ID          int    `json:"id" example:"1"`
+	Name        string `json:"name" example:"Home renovation"`
+	Description string `json:"description" example:"Everything for the new kitchen"`
+	TodoCount   int    `json:"todo_count" example:"12"`
+	CreatedAt   string `json:"created_at" example:"2023-01-01T12:00:00Z"`
+}
+
+// CreateCommentRequest is the payload for commenting on a todo.
+type CreateCommentRequest struct {
+	Author string `json:"author" validate:"required,min=1,max=100" example:"Alice"`
+	Body   string `json:"body" validate:"required,min=1,max=10000" example:"Should we order the tiles this week?"`
+}
+
+// UpdateCommentRequest is the payload for editing a comment. Only the body
+// of a comment can be changed.
+type UpdateCommentRequest struct {
+	Body string `json:"body" validate:"required,min=1,max=10000" example:"Tiles are ordered for Monday."`
+}
+
+// CommentResponse is the JSON representation of a comment on a todo.
+// UpdatedAt is only present once the comment has been edited.
+type CommentResponse struct {
+	ID        int     `json:"id" example:"5"`
+	TodoID    int     `json:"todo_id" example:"7"`
+	Author    string  `json:"author" example:"Alice"`
+	Body      string  `json:"body" example:"Should we order the tiles this week?"`
+	CreatedAt string  `json:"created_at" example:"2023-01-01T12:00:00Z"`
+	UpdatedAt *string `json:"updated_at,omitempty" example:"2023-01-01T12:30:00Z"`
+}
+
+// CommentListResponse is a single page of the comments on a todo, oldest
+// first. The cursors are opaque and should be passed back unchanged as the
+// after or before query parameter.
+type CommentListResponse struct {
+	Items      []CommentResponse `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty" example:"eyJpZCI6MjB9"`
+	PrevCursor string            `json:"prev_cursor,omitempty" example:"eyJpZCI6MX0"`
+}
+
+// AttachmentResponse is the JSON representation of a file attached to a
+// todo. ContentType is the type sniffed from the file on upload.
+type AttachmentResponse struct {
+	ID          int    `json:"id" example:"3"`
+	TodoID      int    `json:"todo_id" example:"7"`
+	Filename    string `json:"filename" example:"floor-plan.pdf"`
+	ContentType string `json:"content_type" example:"application/pdf"`
+	Size        int64  `json:"size" example:"48213"`
+	CreatedAt   string `json:"created_at" example:"2023-01-01T12:00:00Z"`
 }
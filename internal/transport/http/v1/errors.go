@@ -1,7 +1,7 @@
 package v1
 
 import (
-	"encoding/json"
+	"bytes"
 	"errors"
 	"net/http"
 
@@ -9,6 +9,8 @@ import (
 
 	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
 	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/codec"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/problem"
 )
 
 // ErrorResponse represents a structured error response
@@ -58,120 +60,214 @@ func (e *AppError) WithContext(key string, value interface{}) *AppError {
 	return e
 }
 
-// WriteJSONSafe writes JSON response and handles encoding errors gracefully
+// WriteJSONSafe writes v in the media type negotiated from the Accept
+// header, JSON unless the client prefers another supported format. A
+// client that accepts none of them gets 406. The response is encoded
+// before anything is sent, so encoding errors are still answered with 500.
 func WriteJSONSafe(w http.ResponseWriter, r *http.Request, code int, v interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
+	w.Header().Add("Vary", "Accept")
+
+	enc, err := codec.Default.Negotiate(r.Header.Values("Accept"), v)
+	if err != nil {
+		WriteError(w, r, NewNotAcceptableError(r.Header.Get("Accept")))
+		return
+	}
 
-	if err := json.NewEncoder(w).Encode(v); err != nil {
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, v); err != nil {
 		log := logger.FromContext(r.Context())
 		if log != nil {
-			log.Error("failed to encode JSON response",
+			log.Error("failed to encode response",
 				zap.Error(err),
+				zap.String("content_type", enc.ContentType()),
 				zap.Any("response", v),
 				zap.Int("status_code", code),
 			)
 		}
 
-		// If JSON encoding fails, write a minimal error response
+		// If encoding fails, write a minimal error response
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		if _, writeErr := w.Write([]byte(`{"error":"internal server error","code":"ENCODING_ERROR"}`)); writeErr != nil {
 			if log != nil {
 				log.Error("failed to write error response", zap.Error(writeErr))
 			}
 		}
+		return
+	}
+
+	w.Header().Set("Content-Type", enc.ContentType())
+	w.WriteHeader(code)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		if log := logger.FromContext(r.Context()); log != nil {
+			log.Error("failed to write response", zap.Error(err))
+		}
 	}
 }
 
-// WriteError handles error responses with proper logging and security
-func WriteError(w http.ResponseWriter, r *http.Request, err error) {
-	log := logger.FromContext(r.Context())
-	traceID := getTraceID(r)
+// domainError describes how a domain error is reported to clients. An
+// empty message exposes the text of the error itself, so that details
+// wrapped around the domain error reach the client.
+type domainError struct {
+	err     error
+	status  int
+	code    string
+	message string
+	// logMessage is logged as a warning when the error is returned
+	logMessage string
+}
 
-	var appErr *AppError
-	var response ErrorResponse
+// domainErrors lists the domain errors that are safe to expose, in the
+// order they are checked.
+var domainErrors = []domainError{
+	{domain.ErrTodoNotFound, http.StatusNotFound, "TODO_NOT_FOUND", "todo not found", "todo not found"},
+	{domain.ErrProjectNotFound, http.StatusNotFound, "PROJECT_NOT_FOUND", "project not found", "project not found"},
+	{domain.ErrProjectNotEmpty, http.StatusConflict, "PROJECT_NOT_EMPTY",
+		"project still has todos; delete them first or pass cascade=true", "project still has todos"},
+	{domain.ErrInvalidProjectName, http.StatusBadRequest, "INVALID_PROJECT_NAME",
+		"project name cannot be empty", "invalid project name provided"},
+	{domain.ErrParentNotFound, http.StatusUnprocessableEntity, "PARENT_NOT_FOUND",
+		"parent todo not found", "parent todo not found"},
+	{domain.ErrSubtaskCycle, http.StatusUnprocessableEntity, "SUBTASK_CYCLE", "", "subtask cycle rejected"},
+	{domain.ErrSubtaskTooDeep, http.StatusUnprocessableEntity, "SUBTASK_TOO_DEEP", "", "subtask nesting too deep"},
+	{domain.ErrParentDeleted, http.StatusConflict, "PARENT_IN_TRASH", "", "parent of restored todo is in trash"},
+	{domain.ErrInvalidTitle, http.StatusBadRequest, "INVALID_TITLE", "title cannot be empty", "invalid title provided"},
+	{domain.ErrInvalidReminder, http.StatusBadRequest, "INVALID_REMINDER",
+		"reminder cannot be after the due date", "invalid reminder provided"},
+	{domain.ErrInvalidStatus, http.StatusBadRequest, "INVALID_STATUS", "", "invalid status provided"},
+	{domain.ErrInvalidPriority, http.StatusBadRequest, "INVALID_PRIORITY",
+		"priority must be one of: low, medium, high, urgent", "invalid priority provided"},
+	{domain.ErrInvalidTag, http.StatusBadRequest, "INVALID_TAG", "", "invalid tag provided"},
+	{domain.ErrInvalidTransition, http.StatusConflict, "INVALID_STATUS_TRANSITION", "", "status transition not allowed"},
+	{domain.ErrVersionMismatch, http.StatusPreconditionFailed, "PRECONDITION_FAILED", "", "todo version mismatch"},
+	{domain.ErrConcurrentUpdate, http.StatusConflict, "UPDATE_CONFLICT", "", "update lost to concurrent changes"},
+	{domain.ErrInvalidCursor, http.StatusBadRequest, "INVALID_CURSOR",
+		"invalid pagination cursor", "invalid pagination cursor"},
+	{domain.ErrBatchTooLarge, http.StatusRequestEntityTooLarge, "BATCH_TOO_LARGE", "", "batch too large"},
+	{domain.ErrBatchAborted, http.StatusFailedDependency, "BATCH_ABORTED", "", "batch operation rolled back"},
+	{domain.ErrImportTooLarge, http.StatusRequestEntityTooLarge, "IMPORT_TOO_LARGE", "", "import too large"},
+	{domain.ErrPatchTooLarge, http.StatusRequestEntityTooLarge, "PATCH_TOO_LARGE", "", "patch document too large"},
+	{domain.ErrInvalidSearch, http.StatusBadRequest, "INVALID_SEARCH_QUERY", "", "invalid search query"},
+	{domain.ErrInvalidRecurrence, http.StatusBadRequest, "INVALID_RECURRENCE", "", "invalid recurrence provided"},
+	{domain.ErrRecurrenceWithoutDue, http.StatusBadRequest, "RECURRENCE_WITHOUT_DUE_DATE", "",
+		"recurring todo without due date"},
+	{domain.ErrNotRecurring, http.StatusNotFound, "TODO_NOT_RECURRING", "", "todo does not recur"},
+	{domain.ErrOccurrenceExists, http.StatusConflict, "OCCURRENCE_EXISTS", "", "occurrence already exists"},
+	{domain.ErrBlockerNotFound, http.StatusUnprocessableEntity, "BLOCKER_NOT_FOUND",
+		"blocking todo not found", "blocking todo not found"},
+	{domain.ErrDependencyCycle, http.StatusUnprocessableEntity, "DEPENDENCY_CYCLE", "", "dependency cycle rejected"},
+	{domain.ErrDependencyNotFound, http.StatusNotFound, "DEPENDENCY_NOT_FOUND",
+		"dependency not found", "dependency not found"},
+	{domain.ErrBlocked, http.StatusConflict, "TODO_BLOCKED", "", "completion of blocked todo rejected"},
+	{domain.ErrInvalidMove, http.StatusUnprocessableEntity, "INVALID_MOVE", "", "todo moved next to itself"},
+	{domain.ErrAnchorNotFound, http.StatusUnprocessableEntity, "ANCHOR_NOT_FOUND", "", "anchor todo not found"},
+	{domain.ErrPositionTaken, http.StatusConflict, "MOVE_CONFLICT", "", "move lost to concurrent moves"},
+	{domain.ErrCommentNotFound, http.StatusNotFound, "COMMENT_NOT_FOUND", "", "comment not found"},
+	{domain.ErrInvalidCommentAuthor, http.StatusBadRequest, "INVALID_COMMENT", "", "invalid comment author provided"},
+	{domain.ErrInvalidCommentBody, http.StatusBadRequest, "INVALID_COMMENT", "", "invalid comment body provided"},
+	{domain.ErrAttachmentNotFound, http.StatusNotFound, "ATTACHMENT_NOT_FOUND", "", "attachment not found"},
+	{domain.ErrAttachmentTooLarge, http.StatusRequestEntityTooLarge, "ATTACHMENT_TOO_LARGE", "", "attachment too large"},
+	{domain.ErrAttachmentEmpty, http.StatusBadRequest, "ATTACHMENT_EMPTY", "", "empty attachment"},
+	{domain.ErrAttachmentType, http.StatusUnsupportedMediaType, "UNSUPPORTED_ATTACHMENT_TYPE", "",
+		"attachment type not allowed"},
+}
 
+// describeError returns the status, code and message err is reported with.
+// Unexpected errors are reported as a generic internal error, so that no
+// internal details leak to clients.
+func describeError(err error) (status int, code, message string) {
+	var appErr *AppError
 	if errors.As(err, &appErr) {
-		// Handle application errors
-		response = ErrorResponse{
-			Error:   appErr.Error(),
-			Code:    appErr.Code,
-			TraceID: traceID,
-		}
+		return appErr.HTTPStatus, appErr.Code, appErr.Error()
+	}
 
-		if log != nil {
-			fields := []zap.Field{
-				zap.Error(appErr.Err),
-				zap.String("app_error_code", appErr.Code),
-				zap.String("trace_id", traceID),
-				zap.Int("http_status", appErr.HTTPStatus),
+	for _, de := range domainErrors {
+		if errors.Is(err, de.err) {
+			message := de.message
+			if message == "" {
+				message = err.Error()
 			}
+			return de.status, de.code, message
+		}
+	}
 
-			// Add context fields
-			for key, value := range appErr.Context {
-				fields = append(fields, zap.Any(key, value))
-			}
+	return http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error"
+}
 
-			log.Error("application error", fields...)
-		}
+// WriteError handles error responses with proper logging and security
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	logError(r, err)
 
-		WriteJSONSafe(w, r, appErr.HTTPStatus, response)
-		return
-	}
+	status, code, message := describeError(err)
+	traceID := getTraceID(r)
 
-	// Handle domain errors
-	switch {
-	case errors.Is(err, domain.ErrTodoNotFound):
-		response = ErrorResponse{
-			Error:   "todo not found",
-			Code:    "TODO_NOT_FOUND",
-			TraceID: traceID,
-		}
+	p := problem.New(status, code, message)
+	p.TraceID = traceID
 
-		if log != nil {
-			log.Warn("todo not found",
+	writeProblem(w, r, p, ErrorResponse{
+		Error:   message,
+		Code:    code,
+		TraceID: traceID,
+	})
+}
+
+// writeProblem writes an error response as problem details or in its
+// legacy shape, whichever was negotiated for the request.
+func writeProblem(w http.ResponseWriter, r *http.Request, p problem.Problem, legacy any) {
+	if err := problem.Write(w, r, p, legacy); err != nil {
+		if log := logger.FromContext(r.Context()); log != nil {
+			log.Error("failed to encode error response",
 				zap.Error(err),
-				zap.String("trace_id", traceID),
+				zap.Int("status_code", p.Status),
 			)
 		}
+	}
+}
 
-		WriteJSONSafe(w, r, http.StatusNotFound, response)
+// logError logs an error returned to the client: application errors with
+// their context, known domain errors as warnings and anything else in full.
+func logError(r *http.Request, err error) {
+	log := logger.FromContext(r.Context())
+	if log == nil {
+		return
+	}
+	traceID := getTraceID(r)
 
-	case errors.Is(err, domain.ErrInvalidTitle):
-		response = ErrorResponse{
-			Error:   "title cannot be empty",
-			Code:    "INVALID_TITLE",
-			TraceID: traceID,
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		fields := []zap.Field{
+			zap.Error(appErr.Err),
+			zap.String("app_error_code", appErr.Code),
+			zap.String("trace_id", traceID),
+			zap.Int("http_status", appErr.HTTPStatus),
 		}
 
-		if log != nil {
-			log.Warn("invalid title provided",
-				zap.Error(err),
-				zap.String("trace_id", traceID),
-			)
+		// Add context fields
+		for key, value := range appErr.Context {
+			fields = append(fields, zap.Any(key, value))
 		}
 
-		WriteJSONSafe(w, r, http.StatusBadRequest, response)
-
-	default:
-		// Handle unexpected errors - log full details but return generic message
-		response = ErrorResponse{
-			Error:   "internal server error",
-			Code:    "INTERNAL_ERROR",
-			TraceID: traceID,
-		}
+		log.Error("application error", fields...)
+		return
+	}
 
-		if log != nil {
-			log.Error("unexpected error occurred",
+	for _, de := range domainErrors {
+		if errors.Is(err, de.err) {
+			log.Warn(de.logMessage,
 				zap.Error(err),
 				zap.String("trace_id", traceID),
-				zap.String("method", r.Method),
-				zap.String("path", r.URL.Path),
 			)
+			return
 		}
-
-		WriteJSONSafe(w, r, http.StatusInternalServerError, response)
 	}
+
+	// Unexpected errors are logged in full but reported with a generic message
+	log.Error("unexpected error occurred",
+		zap.Error(err),
+		zap.String("trace_id", traceID),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+	)
 }
 
 // getTraceID extracts trace ID from request context or generates a fallback
@@ -199,6 +295,20 @@ func NewValidationError(message string) *AppError {
 	return NewAppError(nil, message, "VALIDATION_ERROR", http.StatusBadRequest)
 }
 
+// Unsupported media type error helpers
+func NewUnsupportedMediaTypeError(mediaType string) *AppError {
+	return NewAppError(nil, "unsupported content type: "+mediaType, "UNSUPPORTED_MEDIA_TYPE",
+		http.StatusUnsupportedMediaType).
+		WithContext("content_type", mediaType)
+}
+
+// Not acceptable error helpers
+func NewNotAcceptableError(accept string) *AppError {
+	return NewAppError(nil, "none of the accepted media types can be produced: "+accept, "NOT_ACCEPTABLE",
+		http.StatusNotAcceptable).
+		WithContext("accept", accept)
+}
+
 // Not found error helpers
 func NewNotFoundError(resource string) *AppError {
 	return NewAppError(nil, resource+" not found", "NOT_FOUND", http.StatusNotFound).
@@ -0,0 +1,164 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/problem"
+)
+
+func TestDescribeError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+	}{
+		{
+			name:        "domain error",
+			err:         domain.ErrTodoNotFound,
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "TODO_NOT_FOUND",
+			wantMessage: "todo not found",
+		},
+		{
+			name:        "wrapped domain error keeps its details",
+			err:         fmt.Errorf("%w: backlog -> done", domain.ErrInvalidTransition),
+			wantStatus:  http.StatusConflict,
+			wantCode:    "INVALID_STATUS_TRANSITION",
+			wantMessage: "status transition not allowed: backlog -> done",
+		},
+		{
+			name:        "application error",
+			err:         NewValidationError("invalid id parameter"),
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "VALIDATION_ERROR",
+			wantMessage: "invalid id parameter",
+		},
+		{
+			name:        "unexpected error is not exposed",
+			err:         errors.New("connection refused"),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    "INTERNAL_ERROR",
+			wantMessage: "internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code, message := describeError(tt.err)
+			if status != tt.wantStatus || code != tt.wantCode || message != tt.wantMessage {
+				t.Errorf("describeError() = %d %q %q, want %d %q %q",
+					status, code, message, tt.wantStatus, tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestWriteError_ProblemDetails(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/7", nil)
+	r = r.WithContext(problem.WithFormat(r.Context(), problem.FormatProblem))
+
+	WriteError(w, r, domain.ErrTodoNotFound)
+
+	if ct := w.Header().Get("Content-Type"); ct != problem.MediaType {
+		t.Errorf("WriteError() Content-Type = %q, want %q", ct, problem.MediaType)
+	}
+
+	var got problem.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("WriteError() body is not a problem: %v", err)
+	}
+	if got.Status != http.StatusNotFound || got.Code != "TODO_NOT_FOUND" || got.Detail != "todo not found" ||
+		got.Instance != "/api/v1/todos/7" {
+		t.Errorf("WriteError() problem = %+v", got)
+	}
+}
+
+func TestWriteValidationError_ProblemDetails(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/todos", nil)
+	r = r.WithContext(problem.WithFormat(r.Context(), problem.FormatProblem))
+
+	WriteValidationError(w, r, &ValidationError{
+		Message: "validation failed",
+		Details: map[string]string{"Title": "Title is required"},
+	})
+
+	var got problem.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("WriteValidationError() body is not a problem: %v", err)
+	}
+	if got.Status != http.StatusBadRequest || got.Code != "VALIDATION_ERROR" ||
+		len(got.Errors) != 1 || got.Errors[0] != (problem.FieldError{Field: "Title", Detail: "Title is required"}) {
+		t.Errorf("WriteValidationError() problem = %+v", got)
+	}
+}
+
+func TestWriteJSONSafe_Negotiation(t *testing.T) {
+	resp := TodoListResponse{Items: []TodoResponse{{ID: 1, Title: "Buy milk", Tags: []string{}}}, NextCursor: "abc"}
+
+	tests := []struct {
+		name            string
+		accept          string
+		wantStatus      int
+		wantContentType string
+	}{
+		{name: "default", wantStatus: http.StatusOK, wantContentType: "application/json"},
+		{name: "csv", accept: "text/csv", wantStatus: http.StatusOK, wantContentType: "text/csv; charset=utf-8"},
+		{
+			name:            "preferred yaml",
+			accept:          "application/json;q=0.8, application/yaml",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/yaml",
+		},
+		{name: "msgpack", accept: "application/msgpack", wantStatus: http.StatusOK, wantContentType: "application/msgpack"},
+		{
+			name:            "unsupported",
+			accept:          "application/xml",
+			wantStatus:      http.StatusNotAcceptable,
+			wantContentType: "application/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/todos", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			WriteJSONSafe(w, r, http.StatusOK, resp)
+
+			if w.Code != tt.wantStatus || w.Header().Get("Content-Type") != tt.wantContentType {
+				t.Errorf("WriteJSONSafe() = %d %q, want %d %q",
+					w.Code, w.Header().Get("Content-Type"), tt.wantStatus, tt.wantContentType)
+			}
+			if w.Header().Get("Vary") != "Accept" {
+				t.Errorf("WriteJSONSafe() Vary = %q, want Accept", w.Header().Get("Vary"))
+			}
+		})
+	}
+
+	t.Run("csv holds the items", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos", nil)
+		r.Header.Set("Accept", "text/csv")
+
+		WriteJSONSafe(w, r, http.StatusOK, resp)
+
+		want := "id,title,description,description_html,status,priority,completed,created_at,due_at,remind_at,tags," +
+			"project_id,parent_id,series_id,subtasks,deleted_at,etag\n1,Buy milk,,,,,false,,,,,,,,,,\n"
+		if w.Body.String() != want {
+			t.Errorf("WriteJSONSafe() body = %q, want %q", w.Body.String(), want)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/codec"
+)
+
+// todoETag returns the strong entity tag of a todo at the given version.
+func todoETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// representationETag returns the entity tag of the representation of a todo
+// at the given version that r is answered with. The strong tag belongs to
+// plain JSON; other formats and rendered descriptions hold the same version
+// in different bytes, so they get the weak tag of the version.
+func representationETag(r *http.Request, version int) string {
+	etag := todoETag(version)
+	enc, err := codec.Default.Negotiate(r.Header.Values("Accept"), TodoResponse{})
+	if (err == nil && enc != codec.JSON) || r.URL.Query().Get("render") != "" {
+		return "W/" + etag
+	}
+	return etag
+}
+
+// setETag sends the entity tag of t with the response to r and returns it.
+func setETag(w http.ResponseWriter, r *http.Request, t domain.Todo) string {
+	etag := representationETag(r, t.Version)
+	w.Header().Set("ETag", etag)
+	return etag
+}
+
+// ifMatch converts the If-Match header into the versions the todo must be
+// at for a write to go ahead. Without the header, or with "*", any version
+// matches. If-Match uses the strong comparison, so weak and malformed
+// entity tags never match.
+func ifMatch(r *http.Request) domain.VersionMatch {
+	return parseIfMatch(r.Header.Values("If-Match"))
+}
+
+// parseIfMatch converts If-Match values into the versions they allow.
+func parseIfMatch(values []string) domain.VersionMatch {
+	if len(values) == 0 {
+		return nil
+	}
+
+	match := domain.VersionMatch{}
+	for _, tag := range splitETags(values) {
+		if tag == "*" {
+			return nil
+		}
+		if version, ok := parseETag(tag); ok {
+			match = append(match, version)
+		}
+	}
+	return match
+}
+
+// notModified reports whether the If-None-Match header of r matches etag.
+// If-None-Match uses the weak comparison, so W/ prefixes are ignored.
+func notModified(r *http.Request, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range splitETags(r.Header.Values("If-None-Match")) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// splitETags splits header values holding comma-separated entity tags.
+func splitETags(values []string) []string {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// parseETag extracts the version from a strong entity tag made by todoETag.
+func parseETag(tag string) (int, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    domain.VersionMatch
+	}{
+		{name: "no header", want: nil},
+		{name: "any", headers: []string{"*"}, want: nil},
+		{name: "single", headers: []string{`"3"`}, want: domain.VersionMatch{3}},
+		{name: "list", headers: []string{`"3", "5"`}, want: domain.VersionMatch{3, 5}},
+		{name: "repeated header", headers: []string{`"3"`, `"5"`}, want: domain.VersionMatch{3, 5}},
+		{name: "weak tags never match", headers: []string{`W/"3"`}, want: domain.VersionMatch{}},
+		{name: "malformed tags never match", headers: []string{`3, "abc", "0"`}, want: domain.VersionMatch{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/api/v1/todos/1", nil)
+			for _, h := range tt.headers {
+				r.Header.Add("If-Match", h)
+			}
+
+			if got := ifMatch(r); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ifMatch() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	etag := todoETag(3)
+
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "no header", want: false},
+		{name: "same version", header: `"3"`, want: true},
+		{name: "weak comparison", header: `W/"3"`, want: true},
+		{name: "one of several", header: `"2", "3"`, want: true},
+		{name: "any", header: "*", want: true},
+		{name: "other version", header: `"2"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/todos/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-None-Match", tt.header)
+			}
+
+			if got := notModified(r, etag); got != tt.want {
+				t.Errorf("notModified(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+			if got := notModified(r, "W/"+etag); got != tt.want {
+				t.Errorf("notModified(%q) of a weak tag = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/codec"
+)
+
+const (
+	// exportFlushRows is how many todos are written between flushes
+	exportFlushRows = 100
+	// exportFlushInterval is the longest a written todo waits to be flushed
+	// while more are being written
+	exportFlushInterval = time.Second
+)
+
+// pageParams are list parameters that make no sense for an export or a
+// calendar feed.
+var pageParams = []string{"limit", "after", "before", "all"}
+
+// parseUnpagedTodoQuery parses the filters and sort of a request for every
+// matching todo, which takes the parameters of GET /todos except for those
+// that page through the list.
+func parseUnpagedTodoQuery(query url.Values) (domain.TodoQuery, error) {
+	for _, name := range pageParams {
+		if query.Has(name) {
+			return domain.TodoQuery{}, NewValidationError("unknown query parameter: " + name)
+		}
+	}
+	return parseTodoQuery(query)
+}
+
+// ExportTodos godoc
+//
+//	@Summary		Export todo items
+//	@Description	Streams every todo matching the filters as newline-delimited JSON, one TodoResponse per line,
+//	@Description	without paginating. The filters and sort are those of GET /todos. All todos come from one
+//	@Description	snapshot, so changes made during the export are not part of it. An export that fails once it
+//	@Description	has started is broken off, so that clients can tell it is incomplete.
+//	@Tags			todos
+//	@Produce		application/x-ndjson
+//	@Param			format			query		string	false	"Export format (default ndjson)"	Enums(ndjson)
+//	@Param			completed		query		bool	false	"Only todos with this completion state"
+//	@Param			status			query		[]string	false	"Only todos in one of these statuses"	collectionFormat(multi)	Enums(backlog, in_progress, blocked, done)
+//	@Param			priority		query		[]string	false	"Only todos with one of these priorities"	collectionFormat(multi)	Enums(low, medium, high, urgent)
+//	@Param			project_id		query		int		false	"Only todos in this project"
+//	@Param			tag				query		[]string	false	"Only todos carrying these tags"	collectionFormat(multi)
+//	@Param			tag_match		query		string	false	"Whether todos need any or all of the tags (default any)"	Enums(any, all)
+//	@Param			created_after	query		string	false	"Only todos created after this RFC 3339 time"
+//	@Param			created_before	query		string	false	"Only todos created before this RFC 3339 time"
+//	@Param			title_contains	query		string	false	"Only todos whose title contains this text (case-insensitive)"
+//	@Param			overdue			query		bool	false	"Only open todos whose due date has passed"
+//	@Param			due_today		query		bool	false	"Only todos due today in the tz time zone"
+//	@Param			due_within		query		string	false	"Only todos due within this duration from now"	example(48h)
+//	@Param			tz				query		string	false	"IANA time zone for due_today (default UTC)"	example(Europe/Berlin)
+//	@Param			sort			query		string	false	"Comma-separated sort fields, prefix with - for descending"	example(-created_at,title)
+//	@Success		200				{array}		TodoResponse	"One todo per line"
+//	@Failure		400				{object}	ErrorResponse	"Invalid format, filter or sort parameters"
+//	@Failure		500				{object}	ErrorResponse	"Internal server error"
+//	@Router			/todos/export [get]
+func (h *TodoHandler) export(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if format := query.Get("format"); format != "" && format != "ndjson" {
+		WriteError(w, r, NewValidationError("format must be ndjson"))
+		return
+	}
+	query.Del("format")
+
+	tq, err := parseUnpagedTodoQuery(query)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	stream := newExportStream(w)
+	err = h.service.Export(r.Context(), tq, func(t domain.Todo) error {
+		return stream.write(newTodoResponse(t))
+	})
+	if err == nil {
+		err = stream.flush()
+	}
+	if err == nil {
+		return
+	}
+
+	if !stream.started {
+		WriteError(w, r, err)
+		return
+	}
+
+	// The status has been sent, so the only way left to tell the client
+	// that the export is incomplete is to break off the response
+	if log := logger.FromContext(r.Context()); log != nil {
+		log.Error("export aborted", zap.Error(err), zap.Int("written", stream.written))
+	}
+	panic(http.ErrAbortHandler)
+}
+
+// exportStream writes todos as newline-delimited JSON. The response is only
+// started with the first todo or the final flush, so that an export that
+// fails right away can still be answered with an error. Todos are flushed
+// every exportFlushRows todos or exportFlushInterval, whichever comes first.
+type exportStream struct {
+	w         http.ResponseWriter
+	rc        *http.ResponseController
+	started   bool
+	written   int
+	unflushed int
+	lastFlush time.Time
+}
+
+func newExportStream(w http.ResponseWriter) *exportStream {
+	return &exportStream{w: w, rc: http.NewResponseController(w)}
+}
+
+func (s *exportStream) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.lastFlush = time.Now()
+
+	// An export may well take longer than the server write timeout allows
+	// for ordinary responses. Writers without deadlines keep the default.
+	_ = s.rc.SetWriteDeadline(time.Time{})
+
+	s.w.Header().Set("Content-Type", codec.NDJSON.ContentType())
+	s.w.WriteHeader(http.StatusOK)
+}
+
+func (s *exportStream) write(todo TodoResponse) error {
+	s.start()
+
+	if err := codec.JSON.Encode(s.w, todo); err != nil {
+		return err
+	}
+	s.written++
+	s.unflushed++
+
+	if s.unflushed >= exportFlushRows || time.Since(s.lastFlush) >= exportFlushInterval {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *exportStream) flush() error {
+	s.start()
+
+	s.unflushed = 0
+	s.lastFlush = time.Now()
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+// exportService exports todos, failing after failAfter of them if set.
+type exportService struct {
+	service.TodoService
+	todos     []domain.Todo
+	failAfter int
+	query     domain.TodoQuery
+}
+
+func (s *exportService) Export(ctx context.Context, q domain.TodoQuery, fn func(domain.Todo) error) error {
+	s.query = q
+	for i, t := range s.todos {
+		if s.failAfter > 0 && i == s.failAfter {
+			return errors.New("connection reset")
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	if s.failAfter < 0 {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestExport(t *testing.T) {
+	todos := make([]domain.Todo, 250)
+	for i := range todos {
+		todos[i] = domain.Todo{ID: i + 1, Title: "Todo", Status: domain.StatusBacklog, Version: 1}
+	}
+	svc := &exportService{todos: todos}
+	h := NewTodoHandler(svc)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/export?format=ndjson&status=backlog&sort=-id", nil)
+	h.export(w, r)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("export() = %d %q, want 200 application/x-ndjson", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !w.Flushed {
+		t.Errorf("export() did not flush")
+	}
+	if len(svc.query.Filter.Statuses) != 1 || len(svc.query.Sort) != 1 {
+		t.Errorf("export() query = %+v, want the status filter and sort", svc.query)
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var todo TodoResponse
+		if err := json.Unmarshal(scanner.Bytes(), &todo); err != nil {
+			t.Fatalf("line %d is not a todo: %v", lines+1, err)
+		}
+		lines++
+		if todo.ID != lines {
+			t.Fatalf("line %d has todo %d", lines, todo.ID)
+		}
+	}
+	if lines != len(todos) {
+		t.Errorf("export() wrote %d lines, want %d", lines, len(todos))
+	}
+}
+
+func TestExport_Empty(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/export", nil)
+	NewTodoHandler(&exportService{}).export(w, r)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-ndjson" || w.Body.Len() != 0 {
+		t.Errorf("export() = %d %q %q, want an empty 200 application/x-ndjson",
+			w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+}
+
+func TestExport_InvalidParameters(t *testing.T) {
+	for _, target := range []string{
+		"/api/v1/todos/export?format=csv",
+		"/api/v1/todos/export?limit=10",
+		"/api/v1/todos/export?status=archived",
+	} {
+		w := httptest.NewRecorder()
+		NewTodoHandler(&exportService{}).export(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want %d", target, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestExport_Failure(t *testing.T) {
+	t.Run("before the first todo", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/export", nil)
+		NewTodoHandler(&exportService{failAfter: -1}).export(w, r)
+
+		if w.Code != http.StatusInternalServerError || w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("export() = %d %q, want a 500 error", w.Code, w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("midway", func(t *testing.T) {
+		svc := &exportService{todos: []domain.Todo{{ID: 1}, {ID: 2}}, failAfter: 1}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/export", nil)
+
+		defer func() {
+			if recovered := recover(); recovered != http.ErrAbortHandler {
+				t.Errorf("export() recovered %v, want the response to be aborted", recovered)
+			}
+			if w.Code != http.StatusOK {
+				t.Errorf("export() status = %d, want the 200 already sent", w.Code)
+			}
+		}()
+		NewTodoHandler(svc).export(w, r)
+	})
+}
@@ -0,0 +1,192 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/importer"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+const (
+	// maxImportBodySize limits how much of an import is read
+	maxImportBodySize = 10 << 20 // 10 MiB
+)
+
+// ImportTodos godoc
+//
+//	@Summary		Import todo items
+//	@Description	Creates todos from a CSV file, a JSON array, a todo.txt file or the VTODO components of an
+//	@Description	iCalendar file, all of them or none. The format is taken from the format parameter or else from
+//	@Description	the Content-Type: text/csv, application/json, text/plain for todo.txt or text/calendar. CSV
+//	@Description	needs a header row with at least a title column and uses the columns of the CSV export; ids
+//	@Description	are ignored and a parent_id must refer to an existing todo. A VTODO with an RRULE becomes the
+//	@Description	first occurrence of a recurring todo, like one created with a recurrence.
+//	@Description	Every todo is validated like one sent to POST /todos. If any todo is invalid nothing is
+//	@Description	imported and the response lists the errors by line, with status 422. With dry_run=true the
+//	@Description	todos are only validated.
+//	@Tags			todos
+//	@Accept			plain
+//	@Accept			json
+//	@Accept			text/csv
+//	@Accept			text/calendar
+//	@Produce		json
+//	@Param			format			query		string			false	"Import format, overriding the Content-Type"	Enums(csv, json, todotxt, ical)
+//	@Param			dry_run			query		bool			false	"Only validate the todos"
+//	@Param			todos			body		string			true	"Todos to import"
+//	@Param			Idempotency-Key	header		string			false	"Key that makes retries of the request safe"
+//	@Success		200				{object}	ImportResponse	"The todos are valid (dry run)"
+//	@Success		201				{object}	ImportResponse	"The todos were imported"
+//	@Failure		400				{object}	ErrorResponse	"Malformed file or invalid parameters"
+//	@Failure		413				{object}	ErrorResponse	"File too large or too many todos"
+//	@Failure		415				{object}	ErrorResponse	"Unsupported format"
+//	@Failure		422				{object}	ImportResponse	"Some todos are invalid; nothing was imported"
+//	@Failure		500				{object}	ErrorResponse	"Internal server error"
+//	@Router			/todos/import [post]
+func (h *TodoHandler) importTodos(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := parseBoolParam(r.URL.Query(), "dry_run")
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	format, err := importFormat(r)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	records, err := importer.Parse(http.MaxBytesReader(w, r.Body, maxImportBodySize), format)
+	if err != nil {
+		WriteError(w, r, importParseError(err))
+		return
+	}
+
+	if len(records) > service.MaxImportSize {
+		WriteError(w, r, fmt.Errorf("%w: at most %d todos are allowed",
+			domain.ErrImportTooLarge, service.MaxImportSize))
+		return
+	}
+
+	// Records that cannot be read or fail validation are reported right
+	// away; the rest are checked by the service, remembering where each
+	// came from.
+	var lineErrors []ImportLineError
+	todos := make([]domain.Todo, 0, len(records))
+	lines := make([]int, 0, len(records))
+
+	for _, record := range records {
+		todo, lineErr := newImportTodo(record)
+		if lineErr != nil {
+			lineErrors = append(lineErrors, *lineErr)
+			continue
+		}
+		todos = append(todos, todo)
+		lines = append(lines, record.Line)
+	}
+
+	// Nothing may be written once a record is known to be invalid
+	errs, err := h.service.Import(r.Context(), todos, dryRun || len(lineErrors) > 0)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			lineErrors = append(lineErrors, newImportLineError(r, lines[i], err))
+		}
+	}
+	sort.SliceStable(lineErrors, func(i, j int) bool { return lineErrors[i].Line < lineErrors[j].Line })
+
+	resp := ImportResponse{DryRun: dryRun, Total: len(records), Errors: lineErrors}
+	switch {
+	case len(lineErrors) > 0:
+		WriteJSONSafe(w, r, http.StatusUnprocessableEntity, resp)
+	case dryRun:
+		WriteJSONSafe(w, r, http.StatusOK, resp)
+	default:
+		resp.Imported = len(todos)
+		WriteJSONSafe(w, r, http.StatusCreated, resp)
+	}
+}
+
+// importFormat returns the format of the import: the one named by the
+// format parameter, or else the one of the Content-Type.
+func importFormat(r *http.Request) (importer.Format, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		format, err := importer.ParseFormat(name)
+		if err != nil {
+			return "", NewValidationError("format must be one of: csv, json, todotxt, ical")
+		}
+		return format, nil
+	}
+
+	mediaType := requestMediaType(r)
+	format, ok := importer.FormatForMediaType(mediaType)
+	if !ok {
+		return "", NewUnsupportedMediaTypeError(mediaType)
+	}
+	return format, nil
+}
+
+// importParseError maps an error reading an import onto the error it is
+// reported with.
+func importParseError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return fmt.Errorf("%w: the file may be at most %d bytes", domain.ErrImportTooLarge, maxBytesErr.Limit)
+	}
+	if errors.Is(err, importer.ErrMalformed) {
+		return NewValidationError(err.Error())
+	}
+	return err
+}
+
+// newImportTodo validates a todo read from an import the same way a todo
+// sent to POST /todos is validated.
+func newImportTodo(record importer.Record) (domain.Todo, *ImportLineError) {
+	if record.Err != nil {
+		return domain.Todo{}, &ImportLineError{Line: record.Line, Error: record.Err.Error(), Code: "VALIDATION_ERROR"}
+	}
+
+	t := record.Todo
+	req := CreateTodoRequest{
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      string(t.Status),
+		Priority:    string(t.Priority),
+		DueAt:       t.DueAt,
+		RemindAt:    t.RemindAt,
+		Tags:        t.Tags,
+		ProjectID:   t.ProjectID,
+		ParentID:    t.ParentID,
+	}
+	if t.Recurrence != nil {
+		req.Recurrence = &RecurrenceRequest{Rule: t.Recurrence.Rule, TimeZone: t.Recurrence.TimeZone}
+	}
+	if err := validate.Struct(&req); err != nil {
+		return domain.Todo{}, &ImportLineError{
+			Line:    record.Line,
+			Error:   "validation failed",
+			Code:    "VALIDATION_ERROR",
+			Details: validationDetails(err),
+		}
+	}
+
+	todo := newTodo(req)
+	todo.CreatedAt = t.CreatedAt
+	return todo, nil
+}
+
+// newImportLineError reports why the todo on a line of an import was
+// rejected by the service.
+func newImportLineError(r *http.Request, line int, err error) ImportLineError {
+	logError(r, err)
+
+	_, code, message := describeError(err)
+	return ImportLineError{Line: line, Error: message, Code: code}
+}
@@ -0,0 +1,276 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/middleware"
+)
+
+// importService rejects todos with a parent, as if the parent did not
+// exist, and records what it was asked to import.
+type importService struct {
+	service.TodoService
+	todos    []domain.Todo
+	dryRun   bool
+	imported int
+}
+
+func (s *importService) Import(ctx context.Context, todos []domain.Todo, dryRun bool) ([]error, error) {
+	s.todos, s.dryRun = todos, dryRun
+
+	var errs []error
+	for i, todo := range todos {
+		if todo.ParentID != nil {
+			if errs == nil {
+				errs = make([]error, len(todos))
+			}
+			errs[i] = domain.ErrParentNotFound
+		}
+	}
+	if errs == nil && !dryRun {
+		s.imported += len(todos)
+	}
+	return errs, nil
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		wantStatus  int
+		wantResp    ImportResponse
+		wantDryRun  bool
+		wantWritten int
+	}{
+		{
+			name:        "csv",
+			url:         "/api/v1/todos/import",
+			contentType: "text/csv",
+			body:        "title,priority,tags\nBuy milk,high,\"home,errands\"\nWater plants,,\n",
+			wantStatus:  http.StatusCreated,
+			wantResp:    ImportResponse{Total: 2, Imported: 2},
+			wantWritten: 2,
+		},
+		{
+			name:        "format overrides content type",
+			url:         "/api/v1/todos/import?format=todotxt",
+			contentType: "application/octet-stream",
+			body:        "(A) Call mom +family\n",
+			wantStatus:  http.StatusCreated,
+			wantResp:    ImportResponse{Total: 1, Imported: 1},
+			wantWritten: 1,
+		},
+		{
+			name:        "icalendar",
+			url:         "/api/v1/todos/import",
+			contentType: "text/calendar",
+			body:        "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:Buy milk\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+			wantStatus:  http.StatusCreated,
+			wantResp:    ImportResponse{Total: 1, Imported: 1},
+			wantWritten: 1,
+		},
+		{
+			name:        "dry run",
+			url:         "/api/v1/todos/import?dry_run=true",
+			contentType: "application/json",
+			body:        `[{"title": "Buy milk"}]`,
+			wantStatus:  http.StatusOK,
+			wantResp:    ImportResponse{DryRun: true, Total: 1},
+			wantDryRun:  true,
+		},
+		{
+			name:        "invalid todos",
+			url:         "/api/v1/todos/import",
+			contentType: "application/json",
+			body: `[
+  {"title": "Buy milk"},
+  {"title": "Pack boxes", "parent_id": 9},
+  {"title": "Fix bike", "priority": "whenever"},
+  {"title": 42}
+]`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantResp: ImportResponse{Total: 4, Errors: []ImportLineError{
+				{Line: 3, Error: "parent todo not found", Code: "PARENT_NOT_FOUND"},
+				{Line: 4, Error: "validation failed", Code: "VALIDATION_ERROR", Details: map[string]string{
+					"Priority": "Priority must be one of: low, medium, high, urgent",
+				}},
+				{Line: 5, Error: "title cannot be a number", Code: "VALIDATION_ERROR"},
+			}},
+			wantDryRun: true,
+		},
+		{
+			name:        "invalid todos in a dry run",
+			url:         "/api/v1/todos/import?dry_run=1",
+			contentType: "text/plain; charset=utf-8",
+			body:        "Renew passport due:soon\n",
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantResp: ImportResponse{DryRun: true, Total: 1, Errors: []ImportLineError{
+				{Line: 1, Error: "due must be an RFC 3339 time or a date", Code: "VALIDATION_ERROR"},
+			}},
+			wantDryRun: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &importService{}
+			h := NewTodoHandler(svc)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			h.importTodos(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("importTodos() status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var got ImportResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("importTodos() body is not an import response: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.wantResp) {
+				t.Errorf("importTodos() = %+v, want %+v", got, tt.wantResp)
+			}
+			if svc.dryRun != tt.wantDryRun || svc.imported != tt.wantWritten {
+				t.Errorf("service dry run = %t and imported %d, want %t and %d",
+					svc.dryRun, svc.imported, tt.wantDryRun, tt.wantWritten)
+			}
+		})
+	}
+}
+
+func TestImport_Recurring(t *testing.T) {
+	svc := &importService{}
+	h := NewTodoHandler(svc)
+
+	body := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"SUMMARY:Pay rent\r\n" +
+		"DUE;TZID=Europe/Berlin:20240301T090000\r\n" +
+		"RRULE:FREQ=MONTHLY\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/todos/import", strings.NewReader(body))
+	r.Header.Set("Content-Type", "text/calendar")
+	h.importTodos(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("importTodos() status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	want := &domain.Recurrence{Rule: "FREQ=MONTHLY", TimeZone: "Europe/Berlin"}
+	if len(svc.todos) != 1 || !reflect.DeepEqual(svc.todos[0].Recurrence, want) {
+		t.Errorf("imported todos = %+v, want one with recurrence %+v", svc.todos, want)
+	}
+}
+
+func TestImport_Rejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    string
+	}{
+		{
+			name:        "unsupported content type",
+			url:         "/api/v1/todos/import",
+			contentType: "application/xml",
+			body:        "<todos/>",
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantCode:    "UNSUPPORTED_MEDIA_TYPE",
+		},
+		{
+			name:        "unknown format",
+			url:         "/api/v1/todos/import?format=xlsx",
+			contentType: "text/csv",
+			body:        "title\nBuy milk\n",
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "VALIDATION_ERROR",
+		},
+		{
+			name:        "malformed file",
+			url:         "/api/v1/todos/import",
+			contentType: "application/json",
+			body:        `{"title": "Buy milk"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "VALIDATION_ERROR",
+		},
+		{
+			name:        "file too large",
+			url:         "/api/v1/todos/import",
+			contentType: "text/plain",
+			body:        strings.Repeat("Buy milk\n", maxImportBodySize/9+1),
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantCode:    "IMPORT_TOO_LARGE",
+		},
+		{
+			name:        "too many todos",
+			url:         "/api/v1/todos/import",
+			contentType: "text/plain",
+			body:        strings.Repeat("x\n", service.MaxImportSize+1),
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantCode:    "IMPORT_TOO_LARGE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &importService{}
+			h := NewTodoHandler(svc)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			h.importTodos(w, r)
+
+			var got ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("importTodos() body is not an error: %v", err)
+			}
+			if w.Code != tt.wantStatus || got.Code != tt.wantCode {
+				t.Errorf("importTodos() = %d %q, want %d %q", w.Code, got.Code, tt.wantStatus, tt.wantCode)
+			}
+			if svc.todos != nil {
+				t.Errorf("importTodos() passed %d todos to the service, want none", len(svc.todos))
+			}
+		})
+	}
+}
+
+func TestImport_Idempotent(t *testing.T) {
+	// Imports may be far larger than what is kept in memory to make a
+	// request idempotent
+	svc := &importService{}
+	handler := middleware.Idempotency(middleware.NewMemoryIdempotencyStore(), time.Hour, MaxBodySize(0))(
+		http.HandlerFunc(NewTodoHandler(svc).importTodos))
+	body := strings.Repeat(strings.Repeat("a", 199)+"\n", 6000)
+
+	for i := range 2 {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/todos/import", strings.NewReader(body))
+		r.Header.Set("Content-Type", "text/plain")
+		r.Header.Set(middleware.IdempotencyKeyHeader, "import-1")
+		handler.ServeHTTP(w, r)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("import %d status = %d, body %s, want %d", i, w.Code, w.Body, http.StatusCreated)
+		}
+	}
+	if svc.imported != 6000 {
+		t.Errorf("imported %d todos, want 6000 imported once", svc.imported)
+	}
+}
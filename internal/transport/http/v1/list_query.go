@@ -0,0 +1,235 @@
+package v1
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// listParams whitelists the query parameters accepted when listing todos.
+var listParams = map[string]bool{
+	"limit":          true,
+	"after":          true,
+	"before":         true,
+	"all":            true,
+	"sort":           true,
+	"completed":      true,
+	"status":         true,
+	"priority":       true,
+	"project_id":     true,
+	"tag":            true,
+	"tag_match":      true,
+	"created_after":  true,
+	"created_before": true,
+	"title_contains": true,
+	"overdue":        true,
+	"due_today":      true,
+	"due_within":     true,
+	"tz":             true,
+}
+
+// sortFields whitelists the fields accepted by the sort parameter.
+var sortFields = map[string]domain.SortField{
+	"id":         domain.SortByID,
+	"title":      domain.SortByTitle,
+	"completed":  domain.SortByCompleted,
+	"status":     domain.SortByStatus,
+	"priority":   domain.SortByPriority,
+	"created_at": domain.SortByCreatedAt,
+	"position":   domain.SortByPosition,
+}
+
+// parseTodoQuery builds filtering and ordering from list query parameters.
+// Unknown parameters and sort fields are rejected with a validation error.
+func parseTodoQuery(values url.Values) (domain.TodoQuery, error) {
+	var q domain.TodoQuery
+
+	for name := range values {
+		if !listParams[name] {
+			return q, NewValidationError("unknown query parameter: " + name)
+		}
+	}
+
+	if v := values.Get("completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			return q, NewValidationError("completed must be true or false")
+		}
+		q.Filter.Completed = &completed
+	}
+
+	for _, v := range splitListParam(values, "status") {
+		status := domain.Status(v)
+		if !status.Valid() {
+			return q, NewValidationError("unknown status: " + v)
+		}
+		q.Filter.Statuses = append(q.Filter.Statuses, status)
+	}
+
+	for _, v := range splitListParam(values, "priority") {
+		priority := domain.Priority(v)
+		if !priority.Valid() {
+			return q, NewValidationError("unknown priority: " + v)
+		}
+		q.Filter.Priorities = append(q.Filter.Priorities, priority)
+	}
+
+	if v := values.Get("project_id"); v != "" {
+		projectID, err := strconv.Atoi(v)
+		if err != nil || projectID <= 0 {
+			return q, NewValidationError("project_id must be a positive integer")
+		}
+		q.Filter.ProjectID = &projectID
+	}
+
+	q.Filter.Tags = splitListParam(values, "tag")
+	switch match := domain.TagMatch(values.Get("tag_match")); match {
+	case "":
+	case domain.TagMatchAny, domain.TagMatchAll:
+		q.Filter.TagMatch = match
+	default:
+		return q, NewValidationError("tag_match must be any or all")
+	}
+
+	var err error
+	if q.Filter.CreatedAfter, err = parseTimeParam(values, "created_after"); err != nil {
+		return q, err
+	}
+	if q.Filter.CreatedBefore, err = parseTimeParam(values, "created_before"); err != nil {
+		return q, err
+	}
+
+	q.Filter.TitleContains = values.Get("title_contains")
+
+	if err := parseDueFilter(values, &q.Filter); err != nil {
+		return q, err
+	}
+
+	if q.Sort, err = parseSort(values.Get("sort")); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+// parseDueFilter parses the relative due date filters and the IANA time
+// zone that decides where "today" begins for due_today.
+func parseDueFilter(values url.Values, f *domain.TodoFilter) error {
+	var err error
+	if f.Overdue, err = parseBoolParam(values, "overdue"); err != nil {
+		return err
+	}
+	if f.Overdue && f.Completed != nil && *f.Completed {
+		return NewValidationError("overdue cannot be combined with completed=true")
+	}
+
+	if f.DueToday, err = parseBoolParam(values, "due_today"); err != nil {
+		return err
+	}
+
+	if v := values.Get("due_within"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return NewValidationError("due_within must be a positive duration such as 48h")
+		}
+		f.DueWithin = d
+	}
+
+	if v := values.Get("tz"); v != "" {
+		loc, err := time.LoadLocation(v)
+		if err != nil || v == "Local" {
+			return NewValidationError("tz must be an IANA time zone such as Europe/Berlin")
+		}
+		f.Location = loc
+	}
+
+	return nil
+}
+
+// parseBoolParam parses an optional boolean query parameter.
+func parseBoolParam(values url.Values, name string) (bool, error) {
+	v := values.Get(name)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, NewValidationError(name + " must be true or false")
+	}
+	return b, nil
+}
+
+// splitListParam returns the values of a multi-valued query parameter,
+// which may be repeated (?status=a&status=b) or comma separated
+// (?status=a,b).
+func splitListParam(values url.Values, name string) []string {
+	var out []string
+	for _, v := range values[name] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+// parseSort parses a sort specification such as "-created_at,title".
+// A leading "-" sorts the field in descending order.
+func parseSort(spec string) ([]domain.SortKey, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(spec, ",")
+	keys := make([]domain.SortKey, 0, len(parts))
+	seen := make(map[domain.SortField]bool, len(parts))
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(part, "-")
+
+		field, ok := sortFields[name]
+		if !ok {
+			return nil, NewValidationError("unsupported sort field: " + name +
+				" (allowed: " + strings.Join(allowedSortFields(), ", ") + ")")
+		}
+		if seen[field] {
+			return nil, NewValidationError("duplicate sort field: " + name)
+		}
+		seen[field] = true
+
+		keys = append(keys, domain.SortKey{Field: field, Desc: desc})
+	}
+
+	return keys, nil
+}
+
+// parseTimeParam parses an optional RFC 3339 timestamp query parameter.
+func parseTimeParam(values url.Values, name string) (*time.Time, error) {
+	v := values.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, NewValidationError(name + " must be an RFC 3339 timestamp")
+	}
+	return &t, nil
+}
+
+func allowedSortFields() []string {
+	names := make([]string, 0, len(sortFields))
+	for name := range sortFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+func TestParseTodoQuery(t *testing.T) {
+	completed := true
+	createdAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    domain.TodoQuery
+		wantErr bool
+	}{
+		{
+			name:  "no parameters",
+			query: "",
+		},
+		{
+			name:  "filters",
+			query: "completed=true&created_after=2024-01-01T00:00:00Z&title_contains=milk",
+			want: domain.TodoQuery{Filter: domain.TodoFilter{
+				Completed:     &completed,
+				CreatedAfter:  &createdAfter,
+				TitleContains: "milk",
+			}},
+		},
+		{
+			name:  "status and priority filters",
+			query: "status=backlog,in_progress&status=blocked&priority=urgent",
+			want: domain.TodoQuery{Filter: domain.TodoFilter{
+				Statuses:   []domain.Status{domain.StatusBacklog, domain.StatusInProgress, domain.StatusBlocked},
+				Priorities: []domain.Priority{domain.PriorityUrgent},
+			}},
+		},
+		{
+			name:    "invalid project_id",
+			query:   "project_id=0",
+			wantErr: true,
+		},
+		{
+			name:  "tag filter",
+			query: "tag=work&tag=p1&tag_match=all",
+			want: domain.TodoQuery{Filter: domain.TodoFilter{
+				Tags:     []string{"work", "p1"},
+				TagMatch: domain.TagMatchAll,
+			}},
+		},
+		{
+			name:    "unknown tag match",
+			query:   "tag=work&tag_match=some",
+			wantErr: true,
+		},
+		{
+			name:    "unknown status",
+			query:   "status=archived",
+			wantErr: true,
+		},
+		{
+			name:    "unknown priority",
+			query:   "priority=critical",
+			wantErr: true,
+		},
+		{
+			name:  "sort by priority and status",
+			query: "sort=-priority,status",
+			want: domain.TodoQuery{Sort: []domain.SortKey{
+				{Field: domain.SortByPriority, Desc: true},
+				{Field: domain.SortByStatus},
+			}},
+		},
+		{
+			name:  "multi-key sort",
+			query: "sort=-created_at,title",
+			want: domain.TodoQuery{Sort: []domain.SortKey{
+				{Field: domain.SortByCreatedAt, Desc: true},
+				{Field: domain.SortByTitle},
+			}},
+		},
+		{
+			name:  "sort by position",
+			query: "sort=position",
+			want:  domain.TodoQuery{Sort: []domain.SortKey{{Field: domain.SortByPosition}}},
+		},
+		{
+			name:  "pagination parameters are allowed",
+			query: "limit=10&after=abc",
+		},
+		{
+			name:  "due filters",
+			query: "overdue=true&due_within=48h",
+			want:  domain.TodoQuery{Filter: domain.TodoFilter{Overdue: true, DueWithin: 48 * time.Hour}},
+		},
+		{
+			name:    "overdue and completed",
+			query:   "overdue=true&completed=true",
+			wantErr: true,
+		},
+		{
+			name:    "negative due_within",
+			query:   "due_within=-1h",
+			wantErr: true,
+		},
+		{
+			name:    "unknown time zone",
+			query:   "due_today=true&tz=Mars/Olympus_Mons",
+			wantErr: true,
+		},
+		{
+			name:    "unknown parameter",
+			query:   "owner=bob",
+			wantErr: true,
+		},
+		{
+			name:    "unknown sort field",
+			query:   "sort=-owner",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate sort field",
+			query:   "sort=title,-title",
+			wantErr: true,
+		},
+		{
+			name:    "invalid completed",
+			query:   "completed=maybe",
+			wantErr: true,
+		},
+		{
+			name:    "invalid timestamp",
+			query:   "created_before=yesterday",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("invalid test query: %v", err)
+			}
+
+			got, err := parseTodoQuery(values)
+
+			if tt.wantErr {
+				var appErr *AppError
+				if !errors.As(err, &appErr) || appErr.Code != "VALIDATION_ERROR" || appErr.HTTPStatus != http.StatusBadRequest {
+					t.Errorf("parseTodoQuery() error = %v, want VALIDATION_ERROR", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseTodoQuery() unexpected error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTodoQuery() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
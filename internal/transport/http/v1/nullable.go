@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// NullableTime is a timestamp in a partial update. It tells an omitted
+// field (leave unchanged) apart from an explicit null (clear the value).
+type NullableTime struct {
+	Set   bool
+	Value *time.Time
+}
+
+// UnmarshalJSON records that the field was present and parses its value.
+func (n *NullableTime) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Value = nil
+		return nil
+	}
+
+	var t time.Time
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	n.Value = &t
+	return nil
+}
+
+// Update converts n into the domain representation of a nullable change.
+func (n NullableTime) Update() domain.Nullable[time.Time] {
+	return domain.Nullable[time.Time]{Set: n.Set, Value: n.Value}
+}
+
+// NullableInt is an integer in a partial update. Like NullableTime it
+// tells an omitted field apart from an explicit null.
+type NullableInt struct {
+	Set   bool
+	Value *int
+}
+
+// UnmarshalJSON records that the field was present and parses its value.
+func (n *NullableInt) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Value = nil
+		return nil
+	}
+
+	var i int
+	if err := json.Unmarshal(data, &i); err != nil {
+		return err
+	}
+	n.Value = &i
+	return nil
+}
+
+// Update converts n into the domain representation of a nullable change.
+func (n NullableInt) Update() domain.Nullable[int] {
+	return domain.Nullable[int]{Set: n.Set, Value: n.Value}
+}
+
+// formatTime renders an optional timestamp for a response.
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/jsonpatch"
+)
+
+const (
+	// maxPatchBodySize limits how much of a patch document is read
+	maxPatchBodySize = 1 << 20 // 1 MiB
+)
+
+// todoPatchDocument is the JSON document that merge patches and JSON patches
+// are applied to. It embeds CreateTodoRequest so that a patched title is held
+// to the same rules as the title of a newly created todo. Description, Tags,
+// ProjectID and ParentID shadow the embedded fields so that they are always
+// present: JSON patches can then replace an empty description, append to
+// the tags with "/tags/-" and replace a null project or parent.
+// Recurrence is not part of the current state and is rejected when a patch
+// adds it, as a series can only be started when the todo is created.
+type todoPatchDocument struct {
+	CreateTodoRequest
+	Description string   `json:"description" validate:"max=10000"`
+	Completed   bool     `json:"completed"`
+	Tags        []string `json:"tags" validate:"max=20,dive,min=1,max=32"`
+	ProjectID   *int     `json:"project_id" validate:"omitempty,gt=0"`
+	ParentID    *int     `json:"parent_id" validate:"omitempty,gt=0"`
+}
+
+// applyTodoPatch applies a patch of the given media type to t and returns the
+// validated result. Failures are returned as *AppError or *ValidationError.
+func applyTodoPatch(t domain.Todo, mediaType string, patch []byte) (*todoPatchDocument, error) {
+	tags := t.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	current, err := json.Marshal(todoPatchDocument{
+		CreateTodoRequest: CreateTodoRequest{
+			Title:    t.Title,
+			Status:   string(t.Status),
+			Priority: string(t.Priority),
+			DueAt:    t.DueAt,
+			RemindAt: t.RemindAt,
+		},
+		Description: t.Description,
+		Completed:   t.IsCompleted(),
+		Tags:        tags,
+		ProjectID:   t.ProjectID,
+		ParentID:    t.ParentID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch mediaType {
+	case jsonpatch.MergePatchMediaType:
+		patched, err = jsonpatch.MergePatch(current, patch)
+	case jsonpatch.JSONPatchMediaType:
+		patched, err = jsonpatch.Apply(current, patch)
+	default:
+		return nil, NewUnsupportedMediaTypeError(mediaType)
+	}
+	if err != nil {
+		return nil, newPatchError(err)
+	}
+
+	var doc todoPatchDocument
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, NewAppError(err, "patched todo is not a valid todo: "+err.Error(),
+			"INVALID_PATCH_RESULT", http.StatusUnprocessableEntity)
+	}
+
+	if doc.Recurrence != nil {
+		return nil, &ValidationError{
+			Message: "patched todo failed validation",
+			Details: map[string]string{"Recurrence": "Recurrence can only be set when a todo is created"},
+		}
+	}
+
+	if err := validate.Struct(&doc); err != nil {
+		return nil, &ValidationError{
+			Message: "patched todo failed validation",
+			Details: validationDetails(err),
+		}
+	}
+
+	return &doc, nil
+}
+
+// newPatchError maps jsonpatch errors onto application errors:
+// malformed patches are 400, failed tests are 409 and patches that
+// reference missing locations are 422.
+func newPatchError(err error) *AppError {
+	switch {
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return NewAppError(err, err.Error(), "PATCH_TEST_FAILED", http.StatusConflict)
+	case errors.Is(err, jsonpatch.ErrPathNotFound):
+		return NewAppError(err, err.Error(), "PATCH_PATH_NOT_FOUND", http.StatusUnprocessableEntity)
+	case errors.Is(err, jsonpatch.ErrInvalidPatch):
+		return NewAppError(err, err.Error(), "INVALID_PATCH", http.StatusBadRequest)
+	default:
+		return NewAppError(err, fmt.Sprintf("failed to apply patch: %v", err), "INVALID_PATCH", http.StatusBadRequest)
+	}
+}
+
+// patchReadError maps an error reading a patch document onto the error it
+// is reported with.
+func patchReadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return fmt.Errorf("%w: it may be at most %d bytes", domain.ErrPatchTooLarge, maxBytesErr.Limit)
+	}
+	return NewValidationError("invalid request body")
+}
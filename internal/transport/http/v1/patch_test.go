@@ -0,0 +1,225 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/jsonpatch"
+)
+
+func TestApplyTodoPatch(t *testing.T) {
+	current := domain.Todo{
+		ID:       1,
+		Title:    "Buy groceries",
+		Status:   domain.StatusBacklog,
+		Priority: domain.PriorityMedium,
+		Tags:     []string{"home"},
+	}
+
+	tests := []struct {
+		name            string
+		mediaType       string
+		patch           string
+		wantTitle       string
+		wantDescription string
+		wantCompleted   bool
+		wantTodoStatus  string
+		wantTags        []string
+		wantStatus      int
+		wantValidation  bool
+	}{
+		{
+			name:          "merge patch sets completed",
+			mediaType:     jsonpatch.MergePatchMediaType,
+			patch:         `{"completed": true}`,
+			wantTitle:     "Buy groceries",
+			wantCompleted: true,
+		},
+		{
+			name:           "merge patch sets status",
+			mediaType:      jsonpatch.MergePatchMediaType,
+			patch:          `{"status": "in_progress"}`,
+			wantTitle:      "Buy groceries",
+			wantTodoStatus: "in_progress",
+		},
+		{
+			name:           "merge patch with unknown priority fails validation",
+			mediaType:      jsonpatch.MergePatchMediaType,
+			patch:          `{"priority": "critical"}`,
+			wantValidation: true,
+		},
+		{
+			name:      "json patch appends a tag",
+			mediaType: jsonpatch.JSONPatchMediaType,
+			patch:     `[{"op": "add", "path": "/tags/-", "value": "errands"}]`,
+			wantTitle: "Buy groceries",
+			wantTags:  []string{"home", "errands"},
+		},
+		{
+			name:      "json patch tests current status",
+			mediaType: jsonpatch.JSONPatchMediaType,
+			patch: `[{"op": "test", "path": "/status", "value": "backlog"},
+				{"op": "replace", "path": "/priority", "value": "high"}]`,
+			wantTitle: "Buy groceries",
+		},
+		{
+			name:           "merge patch removing title fails validation",
+			mediaType:      jsonpatch.MergePatchMediaType,
+			patch:          `{"title": null}`,
+			wantValidation: true,
+		},
+		{
+			name:           "merge patch adding recurrence",
+			mediaType:      jsonpatch.MergePatchMediaType,
+			patch:          `{"recurrence": {"rule": "FREQ=DAILY"}}`,
+			wantValidation: true,
+		},
+		{
+			name:           "json patch adding recurrence",
+			mediaType:      jsonpatch.JSONPatchMediaType,
+			patch:          `[{"op": "add", "path": "/recurrence", "value": {"rule": "FREQ=DAILY"}}]`,
+			wantValidation: true,
+		},
+		{
+			name:       "merge patch adding unknown field",
+			mediaType:  jsonpatch.MergePatchMediaType,
+			patch:      `{"owner": "bob"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "malformed merge patch",
+			mediaType:  jsonpatch.MergePatchMediaType,
+			patch:      `{"completed":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "json patch with passing test",
+			mediaType: jsonpatch.JSONPatchMediaType,
+			patch: `[{"op": "test", "path": "/title", "value": "Buy groceries"},
+				{"op": "replace", "path": "/title", "value": "Buy milk"}]`,
+			wantTitle: "Buy milk",
+		},
+		{
+			name:      "json patch with failing test",
+			mediaType: jsonpatch.JSONPatchMediaType,
+			patch: `[{"op": "test", "path": "/title", "value": "Something else"},
+				{"op": "replace", "path": "/title", "value": "Buy milk"}]`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "json patch with missing path",
+			mediaType:  jsonpatch.JSONPatchMediaType,
+			patch:      `[{"op": "remove", "path": "/due"}]`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "json patch with overlong title",
+			mediaType:      jsonpatch.JSONPatchMediaType,
+			patch:          `[{"op": "replace", "path": "/title", "value": "` + generateLongString(300) + `"}]`,
+			wantValidation: true,
+		},
+		{
+			name:            "json patch replaces the empty description",
+			mediaType:       jsonpatch.JSONPatchMediaType,
+			patch:           `[{"op": "replace", "path": "/description", "value": "Whole milk"}]`,
+			wantTitle:       "Buy groceries",
+			wantDescription: "Whole milk",
+		},
+		{
+			name:           "merge patch with overlong description",
+			mediaType:      jsonpatch.MergePatchMediaType,
+			patch:          `{"description": "` + generateLongString(10001) + `"}`,
+			wantValidation: true,
+		},
+		{
+			name:       "json patch with unknown operation",
+			mediaType:  jsonpatch.JSONPatchMediaType,
+			patch:      `[{"op": "explode", "path": "/title"}]`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported media type",
+			mediaType:  "text/plain",
+			patch:      `completed`,
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := applyTodoPatch(current, tt.mediaType, []byte(tt.patch))
+
+			if tt.wantValidation {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Errorf("applyTodoPatch() error = %v, want *ValidationError", err)
+				}
+				return
+			}
+
+			if tt.wantStatus != 0 {
+				var appErr *AppError
+				if !errors.As(err, &appErr) {
+					t.Fatalf("applyTodoPatch() error = %v, want *AppError", err)
+				}
+				if appErr.HTTPStatus != tt.wantStatus {
+					t.Errorf("applyTodoPatch() status = %v, want %v", appErr.HTTPStatus, tt.wantStatus)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("applyTodoPatch() unexpected error = %v", err)
+			}
+
+			if doc.Title != tt.wantTitle {
+				t.Errorf("applyTodoPatch() title = %v, want %v", doc.Title, tt.wantTitle)
+			}
+			if doc.Description != tt.wantDescription {
+				t.Errorf("applyTodoPatch() description = %q, want %q", doc.Description, tt.wantDescription)
+			}
+
+			wantTodoStatus := tt.wantTodoStatus
+			if wantTodoStatus == "" {
+				wantTodoStatus = string(current.Status)
+			}
+			if doc.Status != wantTodoStatus {
+				t.Errorf("applyTodoPatch() status = %v, want %v", doc.Status, wantTodoStatus)
+			}
+
+			wantTags := tt.wantTags
+			if wantTags == nil {
+				wantTags = current.Tags
+			}
+			if !reflect.DeepEqual(doc.Tags, wantTags) {
+				t.Errorf("applyTodoPatch() tags = %v, want %v", doc.Tags, wantTags)
+			}
+
+			if doc.Completed != tt.wantCompleted {
+				t.Errorf("applyTodoPatch() completed = %v, want %v", doc.Completed, tt.wantCompleted)
+			}
+		})
+	}
+}
+
+func TestTodoHandler_PatchTooLarge(t *testing.T) {
+	// A patch document over the limit is refused rather than cut off and
+	// reported as malformed
+	body := `{"description":"` + strings.Repeat("a", maxPatchBodySize) + `"}`
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPatch, "/api/v1/todos/1", strings.NewReader(body))
+	r.Header.Set("Content-Type", jsonpatch.MergePatchMediaType)
+	NewTodoHandler(nil).patch(w, mux.SetURLVars(r, map[string]string{"id": "1"}))
+
+	if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), `"PATCH_TOO_LARGE"`) {
+		t.Errorf("patch() status = %d, want 413 PATCH_TOO_LARGE", w.Code)
+	}
+}
@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// MoveTodo godoc
+//
+//	@Summary		Move a todo in list order
+//	@Description	Places the todo directly before or directly after another todo, as seen when listing with
+//	@Description	sort=position. Exactly one of before and after must be given. Moving a todo to where it
+//	@Description	already is changes nothing.
+//	@Tags			todos
+//	@Accept			json
+//	@Param			id		path	int				true	"Todo ID"
+//	@Param			move	body	MoveTodoRequest	true	"Todo to move next to"
+//	@Success		204		"Todo moved"
+//	@Failure		400		{object}	ValidationError	"Validation error"
+//	@Failure		404		{object}	ErrorResponse	"Todo not found"
+//	@Failure		409		{object}	ErrorResponse	"Position kept being taken by concurrent moves"
+//	@Failure		422		{object}	ErrorResponse	"Anchor todo not found or todo moved next to itself"
+//	@Failure		500		{object}	ErrorResponse	"Internal server error"
+//	@Router			/todos/{id}/move [post]
+func (h *TodoHandler) move(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid id", zap.String("param", idStr))
+		}
+		WriteError(w, r, NewValidationError("invalid id parameter"))
+		return
+	}
+
+	var req MoveTodoRequest
+	if err := DecodeAndValidateJSON(r, &req); err != nil {
+		if validationErr, ok := err.(*ValidationError); ok {
+			WriteValidationError(w, r, validationErr)
+			return
+		}
+		WriteError(w, r, err)
+		return
+	}
+	if (req.Before == nil) == (req.After == nil) {
+		WriteError(w, r, NewValidationError("exactly one of before and after is required"))
+		return
+	}
+
+	anchor := domain.MoveAnchor{After: req.After != nil}
+	if anchor.After {
+		anchor.TodoID = *req.After
+	} else {
+		anchor.TodoID = *req.Before
+	}
+
+	if err := h.service.Move(r.Context(), id, anchor); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+// moveService knows todos 1 to 3 and records the last move.
+type moveService struct {
+	service.TodoService
+	moved  int
+	anchor domain.MoveAnchor
+}
+
+func (s *moveService) Move(ctx context.Context, id int, anchor domain.MoveAnchor) error {
+	switch {
+	case id == anchor.TodoID:
+		return domain.ErrInvalidMove
+	case id > 3:
+		return domain.ErrTodoNotFound
+	case anchor.TodoID > 3:
+		return domain.ErrAnchorNotFound
+	}
+	s.moved, s.anchor = id, anchor
+	return nil
+}
+
+func TestMove(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		body   string
+		status int
+		code   string
+		anchor domain.MoveAnchor
+	}{
+		{"before", "3", `{"before": 1}`, http.StatusNoContent, "", domain.MoveAnchor{TodoID: 1}},
+		{"after", "3", `{"after": 2}`, http.StatusNoContent, "", domain.MoveAnchor{TodoID: 2, After: true}},
+		{"invalid id", "abc", `{"after": 2}`, http.StatusBadRequest, "VALIDATION_ERROR", domain.MoveAnchor{}},
+		{"no anchor", "3", `{}`, http.StatusBadRequest, "VALIDATION_ERROR", domain.MoveAnchor{}},
+		{"both anchors", "3", `{"before": 1, "after": 2}`, http.StatusBadRequest, "VALIDATION_ERROR",
+			domain.MoveAnchor{}},
+		{"negative anchor", "3", `{"after": -2}`, http.StatusBadRequest, "", domain.MoveAnchor{}},
+		{"todo not found", "9", `{"after": 2}`, http.StatusNotFound, "TODO_NOT_FOUND", domain.MoveAnchor{}},
+		{"anchor not found", "3", `{"after": 9}`, http.StatusUnprocessableEntity, "ANCHOR_NOT_FOUND",
+			domain.MoveAnchor{}},
+		{"next to itself", "3", `{"before": 3}`, http.StatusUnprocessableEntity, "INVALID_MOVE", domain.MoveAnchor{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &moveService{}
+			h := NewTodoHandler(svc)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/todos/"+tt.id+"/move", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			h.move(w, mux.SetURLVars(r, map[string]string{"id": tt.id}))
+
+			if w.Code != tt.status {
+				t.Fatalf("move() status = %d, want %d, body %s", w.Code, tt.status, w.Body)
+			}
+			if tt.code != "" && !strings.Contains(w.Body.String(), `"`+tt.code+`"`) {
+				t.Errorf("move() body = %s, want code %s", w.Body, tt.code)
+			}
+			if tt.status == http.StatusNoContent && (svc.moved != 3 || svc.anchor != tt.anchor) {
+				t.Errorf("move() moved %d next to %+v, want 3 next to %+v", svc.moved, svc.anchor, tt.anchor)
+			}
+		})
+	}
+}
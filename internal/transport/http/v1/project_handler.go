@@ -0,0 +1,288 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+// ProjectHandler provides HTTP endpoints for managing projects and the
+// todos nested under them.
+type ProjectHandler struct {
+	service service.ProjectService
+	todos   *TodoHandler
+}
+
+// NewProjectHandler initializes the handler. Nested todo routes are served
+// by todos, scoped to the project in the path.
+func NewProjectHandler(s service.ProjectService, todos *TodoHandler) *ProjectHandler {
+	return &ProjectHandler{service: s, todos: todos}
+}
+
+// RegisterRoutes attaches routes to a router.
+func (h *ProjectHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/projects", h.create).Methods("POST")
+	r.HandleFunc("/projects", h.list).Methods("GET")
+	r.HandleFunc("/projects/{projectID}", h.getByID).Methods("GET")
+	r.HandleFunc("/projects/{projectID}", h.update).Methods("PUT")
+	r.HandleFunc("/projects/{projectID}", h.delete).Methods("DELETE")
+	r.HandleFunc("/projects/{projectID}/todos", h.createTodo).Methods("POST")
+	r.HandleFunc("/projects/{projectID}/todos", h.listTodos).Methods("GET")
+}
+
+// newProjectResponse maps a domain project onto its JSON representation.
+func newProjectResponse(p domain.Project) ProjectResponse {
+	return ProjectResponse{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		TodoCount:   p.TodoCount,
+		CreatedAt:   p.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// projectID parses the projectID path parameter, writing a validation
+// error when it is not a number.
+func projectID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	log := logger.FromContext(r.Context())
+
+	idStr := mux.Vars(r)["projectID"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid project id", zap.String("param", idStr))
+		}
+		WriteError(w, r, NewValidationError("invalid project id parameter"))
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateProject godoc
+//
+//	@Summary		Create a new project
+//	@Description	Creates a new project that todos can be grouped under
+//	@Tags			projects
+//	@Accept			json
+//	@Produce		json
+//	@Param			project	body		CreateProjectRequest	true	"Project creation request"
+//	@Success		201		{object}	map[string]int			"Successfully created project"
+//	@Failure		400		{object}	ValidationError			"Validation error"
+//	@Failure		500		{object}	ErrorResponse			"Internal server error"
+//	@Router			/projects [post]
+func (h *ProjectHandler) create(w http.ResponseWriter, r *http.Request) {
+	var req CreateProjectRequest
+	if err := DecodeAndValidateJSON(r, &req); err != nil {
+		if validationErr, ok := err.(*ValidationError); ok {
+			WriteValidationError(w, r, validationErr)
+			return
+		}
+		WriteError(w, r, err)
+		return
+	}
+
+	id, err := h.service.Create(r.Context(), domain.Project{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSONSafe(w, r, http.StatusCreated, map[string]any{"id": id})
+}
+
+// ListProjects godoc
+//
+//	@Summary		List projects
+//	@Description	Retrieves every project, ordered by ID, with the number of todos in each
+//	@Tags			projects
+//	@Produce		json
+//	@Success		200	{array}		ProjectResponse	"Successfully retrieved projects"
+//	@Failure		500	{object}	ErrorResponse	"Internal server error"
+//	@Router			/projects [get]
+func (h *ProjectHandler) list(w http.ResponseWriter, r *http.Request) {
+	projects, err := h.service.List(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	resp := make([]ProjectResponse, 0, len(projects))
+	for _, p := range projects {
+		resp = append(resp, newProjectResponse(p))
+	}
+
+	WriteJSONSafe(w, r, http.StatusOK, resp)
+}
+
+// GetProjectByID godoc
+//
+//	@Summary		Get a project by ID
+//	@Description	Retrieves a specific project with the number of todos in it
+//	@Tags			projects
+//	@Produce		json
+//	@Param			projectID	path		int				true	"Project ID"
+//	@Success		200			{object}	ProjectResponse	"Successfully retrieved project"
+//	@Failure		400			{object}	ErrorResponse	"Invalid ID parameter"
+//	@Failure		404			{object}	ErrorResponse	"Project not found"
+//	@Failure		500			{object}	ErrorResponse	"Internal server error"
+//	@Router			/projects/{projectID} [get]
+func (h *ProjectHandler) getByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := projectID(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSONSafe(w, r, http.StatusOK, newProjectResponse(*p))
+}
+
+// UpdateProject godoc
+//
+//	@Summary		Replace a project
+//	@Description	Replaces the name and description of a project
+//	@Tags			projects
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID	path		int						true	"Project ID"
+//	@Param			project		body		UpdateProjectRequest	true	"Project replacement request"
+//	@Success		200			{object}	ProjectResponse			"Successfully updated project"
+//	@Failure		400			{object}	ValidationError			"Validation error"
+//	@Failure		404			{object}	ErrorResponse			"Project not found"
+//	@Failure		500			{object}	ErrorResponse			"Internal server error"
+//	@Router			/projects/{projectID} [put]
+func (h *ProjectHandler) update(w http.ResponseWriter, r *http.Request) {
+	id, ok := projectID(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateProjectRequest
+	if err := DecodeAndValidateJSON(r, &req); err != nil {
+		if validationErr, ok := err.(*ValidationError); ok {
+			WriteValidationError(w, r, validationErr)
+			return
+		}
+		WriteError(w, r, err)
+		return
+	}
+
+	p, err := h.service.Update(r.Context(), domain.Project{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteJSONSafe(w, r, http.StatusOK, newProjectResponse(*p))
+}
+
+// DeleteProject godoc
+//
+//	@Summary		Delete a project
+//	@Description	Deletes a project. A project that still has todos outside the trash is refused with 409
+//	@Description	unless cascade=true is passed, in which case its todos are moved to the trash, together with
+//	@Description	their subtasks. Todos in the trash are never deleted by this: they stay in the trash, no
+//	@Description	longer part of any project, and can be restored until they are purged.
+//	@Tags			projects
+//	@Param			projectID	path	int		true	"Project ID"
+//	@Param			cascade		query	bool	false	"Also move the todos of the project to the trash"
+//	@Success		204			"Successfully deleted project"
+//	@Failure		400			{object}	ErrorResponse	"Invalid ID or cascade parameter"
+//	@Failure		404			{object}	ErrorResponse	"Project not found"
+//	@Failure		409			{object}	ErrorResponse	"Project still has todos"
+//	@Failure		500			{object}	ErrorResponse	"Internal server error"
+//	@Router			/projects/{projectID} [delete]
+func (h *ProjectHandler) delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := projectID(w, r)
+	if !ok {
+		return
+	}
+
+	cascade, err := parseBoolParam(r.URL.Query(), "cascade")
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, cascade); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// withProject runs next only if the project in the path exists.
+func (h *ProjectHandler) withProject(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	id, ok := projectID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.service.GetByID(r.Context(), id); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	next(w, r)
+}
+
+// CreateProjectTodo godoc
+//
+//	@Summary		Create a todo in a project
+//	@Description	Creates a new todo item that belongs to the project; project_id in the body is ignored
+//	@Tags			projects
+//	@Accept			json
+//	@Produce		json
+//	@Param			projectID		path		int					true	"Project ID"
+//	@Param			todo			body		CreateTodoRequest	true	"Todo creation request"
+//	@Param			Idempotency-Key	header		string				false	"Key that makes retries of the request safe"
+//	@Success		201				{object}	map[string]int		"Successfully created todo"
+//	@Failure		400				{object}	ValidationError		"Validation error"
+//	@Failure		404				{object}	ErrorResponse		"Project not found"
+//	@Failure		409				{object}	ErrorResponse		"A request with the same Idempotency-Key is in progress"
+//	@Failure		422				{object}	ErrorResponse		"Idempotency-Key was used for a different request"
+//	@Failure		500				{object}	ErrorResponse		"Internal server error"
+//	@Router			/projects/{projectID}/todos [post]
+func (h *ProjectHandler) createTodo(w http.ResponseWriter, r *http.Request) {
+	h.withProject(w, r, h.todos.create)
+}
+
+// ListProjectTodos godoc
+//
+//	@Summary		List the todos of a project
+//	@Description	Retrieves a filtered, sorted page of the todos in a project. Accepts the same
+//	@Description	filtering, sorting and pagination parameters as GET /todos.
+//	@Tags			projects
+//	@Produce		json
+//	@Param			projectID	path		int					true	"Project ID"
+//	@Param			sort		query		string				false	"Comma-separated sort fields, prefix with - for descending"
+//	@Param			limit		query		int					false	"Page size (default 20, max 100)"
+//	@Param			after		query		string				false	"Cursor of the page to continue after"
+//	@Param			before		query		string				false	"Cursor of the page to continue before"
+//	@Success		200			{object}	TodoListResponse	"Successfully retrieved todos"
+//	@Failure		400			{object}	ErrorResponse		"Invalid filter, sort or pagination parameters"
+//	@Failure		404			{object}	ErrorResponse		"Project not found"
+//	@Failure		500			{object}	ErrorResponse		"Internal server error"
+//	@Router			/projects/{projectID}/todos [get]
+func (h *ProjectHandler) listTodos(w http.ResponseWriter, r *http.Request) {
+	h.withProject(w, r, h.todos.list)
+}
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// PreviewOccurrences godoc
+//
+//	@Summary		Preview the occurrences of a recurring todo
+//	@Description	Lists the due dates of the occurrences that follow a recurring todo, in the order completing
+//	@Description	each in turn would create them, together with the rule of its series. Fewer are listed when
+//	@Description	the series ends sooner. Due dates keep their time of day in the time zone of the series across
+//	@Description	daylight saving changes, and are given with that zone's offset.
+//	@Tags			todos
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		application/yaml
+//	@Produce		application/msgpack
+//	@Param			id		path		int					true	"Todo ID"
+//	@Param			count	query		int					false	"Number of occurrences (default 10, max 100)"
+//	@Success		200		{object}	OccurrencesResponse	"Upcoming occurrences"
+//	@Failure		400		{object}	ErrorResponse		"Invalid ID or count parameter"
+//	@Failure		404		{object}	ErrorResponse		"Todo not found or not recurring"
+//	@Failure		406		{object}	ErrorResponse		"None of the accepted media types is supported"
+//	@Failure		500		{object}	ErrorResponse		"Internal server error"
+//	@Router			/todos/{id}/occurrences [get]
+func (h *TodoHandler) occurrences(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid id", zap.String("param", idStr))
+		}
+		WriteError(w, r, NewValidationError("invalid id parameter"))
+		return
+	}
+
+	count := 0
+	if v := r.URL.Query().Get("count"); v != "" {
+		if count, err = strconv.Atoi(v); err != nil || count <= 0 {
+			WriteError(w, r, NewValidationError("count must be a positive integer"))
+			return
+		}
+	}
+
+	series, occurrences, err := h.service.Occurrences(r.Context(), id, count)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	resp := OccurrencesResponse{
+		SeriesID: series.ID,
+		Rule:     series.Rule,
+		TimeZone: series.TimeZone,
+		Items:    make([]OccurrenceResponse, 0, len(occurrences)),
+	}
+	for _, o := range occurrences {
+		resp.Items = append(resp.Items, OccurrenceResponse{DueAt: o.Format(time.RFC3339)})
+	}
+
+	WriteJSONSafe(w, r, http.StatusOK, resp)
+}
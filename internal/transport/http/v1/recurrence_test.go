@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+// occurrencesService previews a monthly series for todo 1 and knows no
+// other recurring todo.
+type occurrencesService struct {
+	service.TodoService
+	count int
+}
+
+func (s *occurrencesService) Occurrences(ctx context.Context, id int, n int) (*domain.Series, []time.Time,
+	error) {
+	if id != 1 {
+		return nil, nil, domain.ErrNotRecurring
+	}
+	s.count = n
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	series := &domain.Series{ID: 2, Recurrence: domain.Recurrence{Rule: "FREQ=MONTHLY", TimeZone: "Europe/Berlin"}}
+	return series, []time.Time{
+		time.Date(2024, time.March, 1, 9, 0, 0, 0, berlin),
+		time.Date(2024, time.April, 1, 9, 0, 0, 0, berlin),
+	}, nil
+}
+
+func TestOccurrences(t *testing.T) {
+	svc := &occurrencesService{}
+	h := NewTodoHandler(svc)
+
+	get := func(id, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/"+id+"/occurrences"+query, nil)
+		h.occurrences(w, mux.SetURLVars(r, map[string]string{"id": id}))
+		return w
+	}
+
+	w := get("1", "?count=2")
+	if w.Code != http.StatusOK {
+		t.Fatalf("occurrences() status = %d, body %s", w.Code, w.Body)
+	}
+	var resp OccurrencesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("occurrences() body is not an OccurrencesResponse: %v", err)
+	}
+	want := OccurrencesResponse{
+		SeriesID: 2,
+		Rule:     "FREQ=MONTHLY",
+		TimeZone: "Europe/Berlin",
+		Items:    []OccurrenceResponse{{DueAt: "2024-03-01T09:00:00+01:00"}, {DueAt: "2024-04-01T09:00:00+02:00"}},
+	}
+	if !reflect.DeepEqual(resp, want) || svc.count != 2 {
+		t.Errorf("occurrences() = %+v for count %d, want %+v", resp, svc.count, want)
+	}
+
+	tests := []struct {
+		name   string
+		id     string
+		query  string
+		status int
+	}{
+		{"invalid id", "abc", "", http.StatusBadRequest},
+		{"invalid count", "1", "?count=0", http.StatusBadRequest},
+		{"not a number", "1", "?count=many", http.StatusBadRequest},
+		{"not recurring", "3", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := get(tt.id, tt.query); w.Code != tt.status {
+				t.Errorf("occurrences() status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestNewTodo_Recurrence(t *testing.T) {
+	todo := newTodo(CreateTodoRequest{
+		Title:      "Pay rent",
+		Recurrence: &RecurrenceRequest{Rule: "FREQ=MONTHLY", TimeZone: "Europe/Berlin"},
+	})
+	want := &domain.Recurrence{Rule: "FREQ=MONTHLY", TimeZone: "Europe/Berlin"}
+	if !reflect.DeepEqual(todo.Recurrence, want) {
+		t.Errorf("newTodo() recurrence = %+v, want %+v", todo.Recurrence, want)
+	}
+	if todo := newTodo(CreateTodoRequest{Title: "Once"}); todo.Recurrence != nil {
+		t.Errorf("newTodo() recurrence = %+v, want none", todo.Recurrence)
+	}
+}
@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// snippetMarkup replaces the highlight markers of an HTML-escaped snippet
+// with <mark> elements.
+var snippetMarkup = strings.NewReplacer(domain.HighlightStart, "<mark>", domain.HighlightEnd, "</mark>")
+
+// SearchTodos godoc
+//
+//	@Summary		Search todo items
+//	@Description	Finds the todos whose title or description matches a full-text search, best matches first,
+//	@Description	with matches in the title ranked above those in the description. Words are
+//	@Description	matched in any form ("shopping" finds "shop"); all of them are required. Quote words to find
+//	@Description	them next to each other, prefix a word or quoted phrase with - to exclude it, end a word with
+//	@Description	* to match words starting with it and separate alternatives with or. Other punctuation is
+//	@Description	ignored. The filters are those of GET /todos. Each result carries its score and a snippet of
+//	@Description	HTML in which the matched words are wrapped in <mark> elements.
+//	@Tags			todos
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		application/yaml
+//	@Produce		application/msgpack
+//	@Param			q				query		string	true	"Search query"	example(groceries -"oat milk")
+//	@Param			limit			query		int		false	"Maximum number of results (default 20, max 100)"
+//	@Param			completed		query		bool	false	"Only todos with this completion state"
+//	@Param			status			query		[]string	false	"Only todos in one of these statuses"	collectionFormat(multi)	Enums(backlog, in_progress, blocked, done)
+//	@Param			priority		query		[]string	false	"Only todos with one of these priorities"	collectionFormat(multi)	Enums(low, medium, high, urgent)
+//	@Param			project_id		query		int		false	"Only todos in this project"
+//	@Param			tag				query		[]string	false	"Only todos carrying these tags"	collectionFormat(multi)
+//	@Param			tag_match		query		string	false	"Whether todos need any or all of the tags (default any)"	Enums(any, all)
+//	@Param			created_after	query		string	false	"Only todos created after this RFC 3339 time"
+//	@Param			created_before	query		string	false	"Only todos created before this RFC 3339 time"
+//	@Param			title_contains	query		string	false	"Only todos whose title contains this text (case-insensitive)"
+//	@Param			overdue			query		bool	false	"Only open todos whose due date has passed"
+//	@Param			due_today		query		bool	false	"Only todos due today in the tz time zone"
+//	@Param			due_within		query		string	false	"Only todos due within this duration from now"	example(48h)
+//	@Param			tz				query		string	false	"IANA time zone for due_today (default UTC)"	example(Europe/Berlin)
+//	@Success		200				{object}	SearchResponse	"Matching todos"
+//	@Failure		400				{object}	ErrorResponse	"Missing or invalid query, or invalid filters"
+//	@Failure		406				{object}	ErrorResponse	"None of the accepted media types is supported"
+//	@Failure		500				{object}	ErrorResponse	"Internal server error"
+//	@Router			/todos/search [get]
+func (h *TodoHandler) search(w http.ResponseWriter, r *http.Request) {
+	text, limit, filter, err := parseSearchQuery(r.URL.Query())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	results, err := h.service.Search(r.Context(), text, filter, limit)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	resp := SearchResponse{Items: make([]SearchResultResponse, 0, len(results))}
+	for _, res := range results {
+		resp.Items = append(resp.Items, SearchResultResponse{
+			TodoResponse: newTodoResponse(res.Todo),
+			Score:        res.Score,
+			Snippet:      snippetMarkup.Replace(html.EscapeString(res.Snippet)),
+		})
+	}
+
+	WriteJSONSafe(w, r, http.StatusOK, resp)
+}
+
+// parseSearchQuery parses the query parameters of a search: the search
+// text, the number of results and the filters of GET /todos. Results are
+// ordered by how well they match, so neither sort nor paging parameters
+// are accepted.
+func parseSearchQuery(query url.Values) (string, int, domain.TodoFilter, error) {
+	text := query.Get("q")
+	if strings.TrimSpace(text) == "" {
+		return "", 0, domain.TodoFilter{}, NewValidationError("q is required")
+	}
+
+	limit := 0
+	if v := query.Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			return "", 0, domain.TodoFilter{}, NewValidationError("limit must be a positive integer")
+		}
+	}
+
+	query.Del("q")
+	query.Del("limit")
+	for _, name := range append([]string{"sort"}, pageParams...) {
+		if query.Has(name) {
+			return "", 0, domain.TodoFilter{}, NewValidationError("unknown query parameter: " + name)
+		}
+	}
+
+	tq, err := parseTodoQuery(query)
+	if err != nil {
+		return "", 0, domain.TodoFilter{}, err
+	}
+	return text, limit, tq.Filter, nil
+}
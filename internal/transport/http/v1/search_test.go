@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+// searchService returns fixed results and records the search.
+type searchService struct {
+	service.TodoService
+	results []domain.SearchResult
+	text    string
+	filter  domain.TodoFilter
+	limit   int
+}
+
+func (s *searchService) Search(ctx context.Context, text string, filter domain.TodoFilter,
+	limit int) ([]domain.SearchResult, error) {
+	s.text, s.filter, s.limit = text, filter, limit
+	return s.results, nil
+}
+
+func TestSearch(t *testing.T) {
+	svc := &searchService{results: []domain.SearchResult{{
+		Todo: domain.Todo{ID: 3, Title: "Buy <b>milk</b> & bread", Status: domain.StatusBacklog,
+			Priority: domain.PriorityMedium, CreatedAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Version: 1},
+		Score:   0.2,
+		Snippet: "Buy <b>" + domain.HighlightStart + "milk" + domain.HighlightEnd + "</b> & bread",
+	}}}
+	h := NewTodoHandler(svc)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/search?q=milk+-oat&limit=5&tag=shop", nil)
+	h.search(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("search() status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if svc.text != "milk -oat" || svc.limit != 5 || len(svc.filter.Tags) != 1 {
+		t.Errorf("search() called the service with %q %d %+v", svc.text, svc.limit, svc.filter)
+	}
+
+	var resp SearchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("search() body is not a SearchResponse: %v", err)
+	}
+	if len(resp.Items) != 1 {
+		t.Fatalf("search() returned %d items, want 1", len(resp.Items))
+	}
+	got := resp.Items[0]
+	if got.ID != 3 || got.Title != "Buy <b>milk</b> & bread" || got.Score != 0.2 {
+		t.Errorf("search() item = %+v", got)
+	}
+	if want := "Buy &lt;b&gt;<mark>milk</mark>&lt;/b&gt; &amp; bread"; got.Snippet != want {
+		t.Errorf("search() snippet = %q, want %q", got.Snippet, want)
+	}
+}
+
+func TestSearch_InvalidParameters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "missing q", query: ""},
+		{name: "blank q", query: "?q=+"},
+		{name: "invalid limit", query: "?q=milk&limit=0"},
+		{name: "sort", query: "?q=milk&sort=title"},
+		{name: "cursor", query: "?q=milk&after=abc"},
+		{name: "unknown parameter", query: "?q=milk&foo=bar"},
+		{name: "invalid filter", query: "?q=milk&status=later"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &searchService{}
+			h := NewTodoHandler(svc)
+
+			w := httptest.NewRecorder()
+			h.search(w, httptest.NewRequest(http.MethodGet, "/api/v1/todos/search"+tt.query, nil))
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("search() status = %d, want 400", w.Code)
+			}
+			if svc.text != "" {
+				t.Errorf("search() called the service")
+			}
+		})
+	}
+}
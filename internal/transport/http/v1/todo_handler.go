@@ -1,14 +1,22 @@
 package v1
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/jsonpatch"
 	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/markdown"
 	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
 )
 
@@ -25,22 +33,161 @@ func NewTodoHandler(s service.TodoService) *TodoHandler {
 // RegisterRoutes attaches routes to a router.
 func (h *TodoHandler) RegisterRoutes(r *mux.Router) {
 	r.HandleFunc("/todos", h.create).Methods("POST")
+	r.HandleFunc("/todos/export", h.export).Methods("GET")
+	r.HandleFunc("/todos/import", h.importTodos).Methods("POST")
+	r.HandleFunc("/todos/search", h.search).Methods("GET")
+	r.HandleFunc("/todos.ics", h.calendar).Methods("GET")
 	r.HandleFunc("/todos/{id}", h.getByID).Methods("GET")
 	r.HandleFunc("/todos", h.list).Methods("GET")
+	r.HandleFunc("/todos:batch", h.batch).Methods("POST")
+	r.HandleFunc("/todos/{id}", h.update).Methods("PUT")
+	r.HandleFunc("/todos/{id}", h.patch).Methods("PATCH")
 	r.HandleFunc("/todos/{id}", h.delete).Methods("DELETE")
+	r.HandleFunc("/todos/{id}/restore", h.restore).Methods("POST")
+	r.HandleFunc("/todos/{id}/occurrences", h.occurrences).Methods("GET")
+	r.HandleFunc("/todos/{id}/dependencies", h.addDependency).Methods("POST")
+	r.HandleFunc("/todos/{id}/dependencies/{blockerID}", h.removeDependency).Methods("DELETE")
+	r.HandleFunc("/todos/{id}/graph", h.dependencyGraph).Methods("GET")
+	r.HandleFunc("/todos/{id}/move", h.move).Methods("POST")
+	r.HandleFunc("/trash", h.listTrash).Methods("GET")
+	r.HandleFunc("/trash/{id}", h.deletePermanently).Methods("DELETE")
+	r.HandleFunc("/tags", h.listTags).Methods("GET")
+}
+
+// newTodoResponse maps a domain todo onto its JSON representation.
+func newTodoResponse(t domain.Todo) TodoResponse {
+	tags := t.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	var subtasks []TodoResponse
+	if t.Subtasks != nil {
+		subtasks = make([]TodoResponse, 0, len(t.Subtasks))
+		for _, subtask := range t.Subtasks {
+			subtasks = append(subtasks, newTodoResponse(subtask))
+		}
+	}
+
+	return TodoResponse{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      string(t.Status),
+		Priority:    string(t.Priority),
+		Completed:   t.IsCompleted(),
+		CreatedAt:   t.CreatedAt.Format(time.RFC3339),
+		DueAt:       formatTime(t.DueAt),
+		RemindAt:    formatTime(t.RemindAt),
+		Tags:        tags,
+		ProjectID:   t.ProjectID,
+		ParentID:    t.ParentID,
+		SeriesID:    t.SeriesID,
+		Subtasks:    subtasks,
+		DeletedAt:   formatTime(t.DeletedAt),
+		ETag:        todoETag(t.Version),
+	}
+}
+
+// renderDescriptions renders the description of a todo and of each of its
+// subtasks as sanitized HTML.
+func renderDescriptions(resp *TodoResponse) error {
+	rendered, err := markdown.Render(resp.Description)
+	if err != nil {
+		return err
+	}
+	resp.DescriptionHTML = &rendered
+
+	for i := range resp.Subtasks {
+		if err := renderDescriptions(&resp.Subtasks[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scopedProjectID returns the project of a nested /projects/{projectID}/todos
+// route. ProjectHandler validates the parameter before the todo handlers run.
+func scopedProjectID(r *http.Request) (int, bool) {
+	v, ok := mux.Vars(r)["projectID"]
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.Atoi(v)
+	return id, err == nil
+}
+
+// newTodo maps a creation request onto the todo to create.
+func newTodo(req CreateTodoRequest) domain.Todo {
+	var recurrence *domain.Recurrence
+	if req.Recurrence != nil {
+		recurrence = &domain.Recurrence{Rule: req.Recurrence.Rule, TimeZone: req.Recurrence.TimeZone}
+	}
+	return domain.Todo{
+		Title:       req.Title,
+		Description: req.Description,
+		Status:      domain.Status(req.Status),
+		Priority:    domain.Priority(req.Priority),
+		DueAt:       req.DueAt,
+		RemindAt:    req.RemindAt,
+		Tags:        req.Tags,
+		ProjectID:   req.ProjectID,
+		ParentID:    req.ParentID,
+		Recurrence:  recurrence,
+	}
+}
+
+// newPatchUpdate maps a partial update request onto the changes it makes.
+func newPatchUpdate(req PatchTodoRequest) domain.TodoUpdate {
+	return domain.TodoUpdate{
+		Title:       req.Title,
+		Description: req.Description,
+		Completed:   req.Completed,
+		Status:      optionalStatus(req.Status),
+		Priority:    optionalPriority(req.Priority),
+		DueAt:       req.DueAt.Update(),
+		RemindAt:    req.RemindAt.Update(),
+		ProjectID:   req.ProjectID.Update(),
+		ParentID:    req.ParentID.Update(),
+		Tags:        req.Tags,
+	}
+}
+
+// optionalStatus converts an optional status from a request body.
+func optionalStatus(s *string) *domain.Status {
+	if s == nil {
+		return nil
+	}
+	status := domain.Status(*s)
+	return &status
+}
+
+// optionalPriority converts an optional priority from a request body.
+func optionalPriority(p *string) *domain.Priority {
+	if p == nil {
+		return nil
+	}
+	priority := domain.Priority(*p)
+	return &priority
 }
 
 // CreateTodo godoc
 //
 //	@Summary		Create a new todo item
-//	@Description	Creates a new todo item with the provided title
+//	@Description	Creates a new todo item with the provided title and optional status, priority, due date and reminder.
+//	@Description	Status defaults to backlog and priority to medium. A todo with a recurrence is the first
+//	@Description	occurrence of a series: completing it creates the next occurrence, due at the next time the
+//	@Description	RFC 5545 rule gives in the rule's time zone.
 //	@Tags			todos
 //	@Accept			json
 //	@Produce		json
-//	@Param			todo	body		CreateTodoRequest	true	"Todo creation request"
-//	@Success		201		{object}	map[string]int		"Successfully created todo"
-//	@Failure		400		{object}	ValidationError		"Validation error"
-//	@Failure		500		{object}	ErrorResponse		"Internal server error"
+//	@Param			todo			body		CreateTodoRequest	true	"Todo creation request"
+//	@Param			Idempotency-Key	header		string				false	"Key that makes retries of the request safe"
+//	@Success		201				{object}	map[string]int		"Successfully created todo"
+//	@Failure		400				{object}	ValidationError		"Validation error"
+//	@Failure		409				{object}	ErrorResponse		"A request with the same Idempotency-Key is in progress"
+//	@Failure		422				{object}	ErrorResponse		"Idempotency-Key was used for a different request"
+//	@Failure		500				{object}	ErrorResponse		"Internal server error"
 //	@Router			/todos [post]
 func (h *TodoHandler) create(w http.ResponseWriter, r *http.Request) {
 	var req CreateTodoRequest
@@ -49,11 +196,16 @@ func (h *TodoHandler) create(w http.ResponseWriter, r *http.Request) {
 			WriteValidationError(w, r, validationErr)
 			return
 		}
-		WriteError(w, r, NewValidationError("invalid request body"))
+		WriteError(w, r, err)
 		return
 	}
 
-	id, err := h.service.Create(r.Context(), req.Title)
+	// Todos created under /projects/{projectID}/todos belong to that project
+	if projectID, ok := scopedProjectID(r); ok {
+		req.ProjectID = &projectID
+	}
+
+	id, err := h.service.Create(r.Context(), newTodo(req))
 	if err != nil {
 		WriteError(w, r, err)
 		return
@@ -65,14 +217,28 @@ func (h *TodoHandler) create(w http.ResponseWriter, r *http.Request) {
 // GetTodoByID godoc
 //
 //	@Summary		Get a todo item by ID
-//	@Description	Retrieves a specific todo item by its ID
+//	@Description	Retrieves a specific todo item by its ID. With expand=subtasks the response includes
+//	@Description	the whole tree of subtasks below the todo. With render=html descriptions are also rendered
+//	@Description	from Markdown into description_html, sanitized so that it is safe to embed: scripts, event
+//	@Description	handlers, styles and links other than http, https and mailto are stripped. The ETag header
+//	@Description	holds the version of the todo and may be sent back in If-None-Match; it is omitted when
+//	@Description	subtasks are expanded. Only plain JSON gets a strong ETag: other formats and rendered
+//	@Description	descriptions get a weak one, and If-Match takes the etag field of the body instead.
 //	@Tags			todos
 //	@Produce		json
-//	@Param			id	path		int	true	"Todo ID"
-//	@Success		200	{object}	TodoResponse		"Successfully retrieved todo"
-//	@Failure		400	{object}	ErrorResponse		"Invalid ID parameter"
-//	@Failure		404	{object}	ErrorResponse		"Todo not found"
-//	@Failure		500	{object}	ErrorResponse		"Internal server error"
+//	@Produce		text/csv
+//	@Produce		application/yaml
+//	@Produce		application/msgpack
+//	@Param			id				path		int		true	"Todo ID"
+//	@Param			expand			query		string	false	"Related data to include"	Enums(subtasks)
+//	@Param			render			query		string	false	"Also render descriptions in this format"	Enums(html)
+//	@Param			If-None-Match	header		string	false	"ETag of a cached copy of the todo"
+//	@Success		200				{object}	TodoResponse		"Successfully retrieved todo"
+//	@Header			200				{string}	ETag				"Version of the todo"
+//	@Success		304				"Todo has not changed since the given ETag"
+//	@Failure		400		{object}	ErrorResponse		"Invalid ID, expand or render parameter"
+//	@Failure		404		{object}	ErrorResponse		"Todo not found"
+//	@Failure		500		{object}	ErrorResponse		"Internal server error"
 //	@Router			/todos/{id} [get]
 func (h *TodoHandler) getByID(w http.ResponseWriter, r *http.Request) {
 	log := logger.FromContext(r.Context())
@@ -87,17 +253,44 @@ func (h *TodoHandler) getByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	t, err := h.service.GetByID(r.Context(), id)
+	expand := r.URL.Query().Get("expand")
+	render := r.URL.Query().Get("render")
+	if render != "" && render != "html" {
+		WriteError(w, r, NewValidationError("unsupported render value: "+render+" (allowed: html)"))
+		return
+	}
+
+	var t *domain.Todo
+	switch expand {
+	case "":
+		t, err = h.service.GetByID(r.Context(), id)
+	case "subtasks":
+		t, err = h.service.GetTree(r.Context(), id)
+	default:
+		WriteError(w, r, NewValidationError("unsupported expand value: "+expand+" (allowed: subtasks)"))
+		return
+	}
 	if err != nil {
 		WriteError(w, r, err)
 		return
 	}
 
-	resp := TodoResponse{
-		ID:        t.ID,
-		Title:     t.Title,
-		Completed: t.Completed,
-		CreatedAt: t.CreatedAt.Format(time.RFC3339),
+	// The version of the todo says nothing about its subtasks, so an
+	// expanded tree is sent without a validator.
+	if expand == "" {
+		if notModified(r, setETag(w, r, *t)) {
+			w.Header().Add("Vary", "Accept")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	resp := newTodoResponse(*t)
+	if render != "" {
+		if err := renderDescriptions(&resp); err != nil {
+			WriteError(w, r, err)
+			return
+		}
 	}
 
 	WriteJSONSafe(w, r, http.StatusOK, resp)
@@ -105,15 +298,100 @@ func (h *TodoHandler) getByID(w http.ResponseWriter, r *http.Request) {
 
 // ListTodos godoc
 //
-//	@Summary		List all todo items
-//	@Description	Retrieves a list of all todo items
+//	@Summary		List todo items
+//	@Description	Retrieves a filtered, sorted page of todo items (ordered by ID by default; sort=position
+//	@Description	follows the order set by moving todos). Use the returned cursors (also sent as RFC 8288 Link
+//	@Description	headers) with after or before to move between pages.
+//	@Description	Pass all=true to receive every matching todo as a plain array instead.
+//	@Description	The Accept header may ask for CSV, YAML or MessagePack instead of JSON.
 //	@Tags			todos
 //	@Produce		json
-//	@Success		200	{array}		TodoResponse	"Successfully retrieved todos"
-//	@Failure		500	{object}	ErrorResponse	"Internal server error"
+//	@Produce		text/csv
+//	@Produce		application/yaml
+//	@Produce		application/msgpack
+//	@Param			completed		query		bool	false	"Only todos with this completion state"
+//	@Param			status			query		[]string	false	"Only todos in one of these statuses"	collectionFormat(multi)	Enums(backlog, in_progress, blocked, done)
+//	@Param			priority		query		[]string	false	"Only todos with one of these priorities"	collectionFormat(multi)	Enums(low, medium, high, urgent)
+//	@Param			project_id		query		int		false	"Only todos in this project"
+//	@Param			tag				query		[]string	false	"Only todos carrying these tags"	collectionFormat(multi)
+//	@Param			tag_match		query		string	false	"Whether todos need any or all of the tags (default any)"	Enums(any, all)
+//	@Param			created_after	query		string	false	"Only todos created after this RFC 3339 time"
+//	@Param			created_before	query		string	false	"Only todos created before this RFC 3339 time"
+//	@Param			title_contains	query		string	false	"Only todos whose title contains this text (case-insensitive)"
+//	@Param			overdue			query		bool	false	"Only open todos whose due date has passed"
+//	@Param			due_today		query		bool	false	"Only todos due today in the tz time zone"
+//	@Param			due_within		query		string	false	"Only todos due within this duration from now"	example(48h)
+//	@Param			tz				query		string	false	"IANA time zone for due_today (default UTC)"	example(Europe/Berlin)
+//	@Param			sort			query		string	false	"Comma-separated sort fields, prefix with - for descending"	example(-created_at,title)
+//	@Param			limit			query		int		false	"Page size (default 20, max 100)"
+//	@Param			after			query		string	false	"Cursor of the page to continue after"
+//	@Param			before			query		string	false	"Cursor of the page to continue before"
+//	@Param			all				query		bool	false	"Return all todos as an unpaginated array"
+//	@Success		200				{object}	TodoListResponse	"Successfully retrieved todos"
+//	@Failure		400				{object}	ErrorResponse		"Invalid filter, sort or pagination parameters"
+//	@Failure		406				{object}	ErrorResponse		"None of the accepted media types is supported"
+//	@Failure		500				{object}	ErrorResponse		"Internal server error"
 //	@Router			/todos [get]
 func (h *TodoHandler) list(w http.ResponseWriter, r *http.Request) {
-	todos, err := h.service.List(r.Context())
+	query := r.URL.Query()
+
+	tq, err := parseTodoQuery(query)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	if projectID, ok := scopedProjectID(r); ok {
+		tq.Filter.ProjectID = &projectID
+	}
+
+	if all := query.Get("all"); all != "" {
+		unbounded, err := strconv.ParseBool(all)
+		if err != nil {
+			WriteError(w, r, NewValidationError("invalid all parameter"))
+			return
+		}
+		if unbounded {
+			h.listAll(w, r, tq)
+			return
+		}
+	}
+
+	limit := 0
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			WriteError(w, r, NewValidationError("limit must be a positive integer"))
+			return
+		}
+	}
+
+	page, err := h.service.ListPage(r.Context(), tq, domain.PageRequest{
+		Limit:  limit,
+		After:  query.Get("after"),
+		Before: query.Get("before"),
+	})
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	resp := TodoListResponse{
+		Items:      make([]TodoResponse, 0, len(page.Items)),
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+	for _, t := range page.Items {
+		resp.Items = append(resp.Items, newTodoResponse(t))
+	}
+
+	setPaginationLinks(w, r, page.NextCursor, page.PrevCursor)
+	WriteJSONSafe(w, r, http.StatusOK, resp)
+}
+
+// listAll writes every todo as a plain array, the response shape used
+// before pagination was introduced.
+func (h *TodoHandler) listAll(w http.ResponseWriter, r *http.Request, q domain.TodoQuery) {
+	todos, err := h.service.List(r.Context(), q)
 	if err != nil {
 		WriteError(w, r, err)
 		return
@@ -121,27 +399,267 @@ func (h *TodoHandler) list(w http.ResponseWriter, r *http.Request) {
 
 	resp := make([]TodoResponse, 0, len(todos))
 	for _, t := range todos {
-		resp = append(resp, TodoResponse{
-			ID:        t.ID,
-			Title:     t.Title,
-			Completed: t.Completed,
-			CreatedAt: t.CreatedAt.Format(time.RFC3339),
-		})
+		resp = append(resp, newTodoResponse(t))
 	}
 
 	WriteJSONSafe(w, r, http.StatusOK, resp)
 }
 
+// setPaginationLinks adds RFC 8288 Link headers pointing at the neighbouring
+// pages. All other query parameters of the request are preserved.
+func setPaginationLinks(w http.ResponseWriter, r *http.Request, next, prev string) {
+	link := func(param, cursor, rel string) string {
+		query := r.URL.Query()
+		query.Del("after")
+		query.Del("before")
+		query.Set(param, cursor)
+
+		u := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+		return fmt.Sprintf("<%s>; rel=%q", u.String(), rel)
+	}
+
+	if next != "" {
+		w.Header().Add("Link", link("after", next, "next"))
+	}
+	if prev != "" {
+		w.Header().Add("Link", link("before", prev, "prev"))
+	}
+}
+
+// UpdateTodo godoc
+//
+//	@Summary		Replace a todo item
+//	@Description	Replaces a todo item. The new status must be reachable from the current one in the configured workflow;
+//	@Description	the legacy completed flag may be sent instead of a status.
+//	@Tags			todos
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int					true	"Todo ID"
+//	@Param			If-Match	header		string				false	"Only update the todo if its ETag matches"
+//	@Param			todo		body		UpdateTodoRequest	true	"Todo replacement request"
+//	@Success		200			{object}	TodoResponse		"Successfully updated todo"
+//	@Header			200			{string}	ETag				"New version of the todo"
+//	@Failure		400			{object}	ValidationError		"Validation error"
+//	@Failure		404			{object}	ErrorResponse		"Todo not found"
+//	@Failure		409			{object}	ErrorResponse		"Status transition not allowed, todo blocked or update conflict"
+//	@Failure		412			{object}	ErrorResponse		"Todo has been modified since the given ETag"
+//	@Failure		500			{object}	ErrorResponse		"Internal server error"
+//	@Router			/todos/{id} [put]
+func (h *TodoHandler) update(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid id", zap.String("param", idStr))
+		}
+		WriteError(w, r, NewValidationError("invalid id parameter"))
+		return
+	}
+
+	var req UpdateTodoRequest
+	if err := DecodeAndValidateJSON(r, &req); err != nil {
+		if validationErr, ok := err.(*ValidationError); ok {
+			WriteValidationError(w, r, validationErr)
+			return
+		}
+		WriteError(w, r, err)
+		return
+	}
+
+	priority := domain.PriorityMedium
+	if req.Priority != "" {
+		priority = domain.Priority(req.Priority)
+	}
+
+	tags := req.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	t, err := h.service.Update(r.Context(), id, domain.TodoUpdate{
+		Title:       &req.Title,
+		Description: &req.Description,
+		Completed:   req.Completed,
+		Status:      optionalStatus(req.Status),
+		Priority:    &priority,
+		DueAt:       domain.SetTo(req.DueAt),
+		RemindAt:    domain.SetTo(req.RemindAt),
+		ProjectID:   domain.SetTo(req.ProjectID),
+		ParentID:    domain.SetTo(req.ParentID),
+		Tags:        &tags,
+		IfVersion:   ifMatch(r),
+	})
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	setETag(w, r, *t)
+	WriteJSONSafe(w, r, http.StatusOK, newTodoResponse(*t))
+}
+
+// PatchTodo godoc
+//
+//	@Summary		Partially update a todo item
+//	@Description	Updates only the provided fields of a todo item. The body format is chosen by Content-Type:
+//	@Description	application/json (PatchTodoRequest), application/merge-patch+json (RFC 7396)
+//	@Description	or application/json-patch+json (RFC 6902, including "test" operations).
+//	@Tags			todos
+//	@Accept			json
+//	@Accept			application/merge-patch+json
+//	@Accept			application/json-patch+json
+//	@Produce		json
+//	@Param			id			path		int					true	"Todo ID"
+//	@Param			If-Match	header		string				false	"Only update the todo if its ETag matches"
+//	@Param			todo		body		PatchTodoRequest	true	"Todo partial update request"
+//	@Success		200			{object}	TodoResponse		"Successfully updated todo"
+//	@Header			200			{string}	ETag				"New version of the todo"
+//	@Failure		400			{object}	ValidationError		"Validation error or malformed patch"
+//	@Failure		404			{object}	ErrorResponse		"Todo not found"
+//	@Failure		409			{object}	ErrorResponse		"Patch test failed, transition not allowed, blocked or conflict"
+//	@Failure		412			{object}	ErrorResponse		"Todo has been modified since the given ETag"
+//	@Failure		413			{object}	ErrorResponse		"Patch document too large"
+//	@Failure		415			{object}	ErrorResponse		"Unsupported content type"
+//	@Failure		422			{object}	ValidationError		"Patch cannot be applied or yields an invalid todo"
+//	@Failure		500			{object}	ErrorResponse		"Internal server error"
+//	@Router			/todos/{id} [patch]
+func (h *TodoHandler) patch(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid id", zap.String("param", idStr))
+		}
+		WriteError(w, r, NewValidationError("invalid id parameter"))
+		return
+	}
+
+	// Patch documents are handled on their own; any other body is a
+	// PatchTodoRequest in one of the supported formats
+	mediaType := requestMediaType(r)
+	if mediaType == jsonpatch.MergePatchMediaType || mediaType == jsonpatch.JSONPatchMediaType {
+		h.patchDocument(w, r, id, mediaType)
+		return
+	}
+
+	var req PatchTodoRequest
+	if err := DecodeAndValidateJSON(r, &req); err != nil {
+		if validationErr, ok := err.(*ValidationError); ok {
+			WriteValidationError(w, r, validationErr)
+			return
+		}
+		WriteError(w, r, err)
+		return
+	}
+
+	upd := newPatchUpdate(req)
+	upd.IfVersion = ifMatch(r)
+
+	t, err := h.service.Update(r.Context(), id, upd)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	setETag(w, r, *t)
+	WriteJSONSafe(w, r, http.StatusOK, newTodoResponse(*t))
+}
+
+// patchDocument applies a merge patch or JSON patch to the current state
+// of the todo and stores the validated result. The result is only stored
+// if the todo is still at the version the patch was applied to.
+func (h *TodoHandler) patchDocument(w http.ResponseWriter, r *http.Request, id int, mediaType string) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchBodySize))
+	if err != nil {
+		WriteError(w, r, patchReadError(err))
+		return
+	}
+
+	current, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	if !ifMatch(r).Matches(current.Version) {
+		WriteError(w, r, domain.ErrVersionMismatch)
+		return
+	}
+
+	doc, err := applyTodoPatch(*current, mediaType, body)
+	if err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			writeValidationErrorStatus(w, r, http.StatusUnprocessableEntity, validationErr)
+			return
+		}
+		WriteError(w, r, err)
+		return
+	}
+
+	// Only send the fields that drive the status if the patch changed
+	// them, so that a patch touching one of them is not contradicted by the
+	// stale value of the other.
+	upd := domain.TodoUpdate{
+		Title:       &doc.Title,
+		Description: &doc.Description,
+		Priority:    optionalPriority(&doc.Priority),
+		DueAt:       domain.SetTo(doc.DueAt),
+		RemindAt:    domain.SetTo(doc.RemindAt),
+		ProjectID:   domain.SetTo(doc.ProjectID),
+		ParentID:    domain.SetTo(doc.ParentID),
+		Tags:        &doc.Tags,
+		IfVersion:   domain.VersionMatch{current.Version},
+	}
+	if domain.Status(doc.Status) != current.Status {
+		upd.Status = optionalStatus(&doc.Status)
+	}
+	if doc.Completed != current.IsCompleted() {
+		upd.Completed = &doc.Completed
+	}
+
+	t, err := h.service.Update(r.Context(), id, upd)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	setETag(w, r, *t)
+	WriteJSONSafe(w, r, http.StatusOK, newTodoResponse(*t))
+}
+
+// requestMediaType returns the media type of the request body without
+// any parameters such as charset.
+func requestMediaType(r *http.Request) string {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return ""
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mediaType
+}
+
 // DeleteTodo godoc
 //
 //	@Summary		Delete a todo item
-//	@Description	Deletes a specific todo item by its ID
+//	@Description	Moves a todo item and its subtasks to the trash. They can be restored until they are
+//	@Description	purged after the retention period, or deleted permanently through DELETE /trash/{id}.
 //	@Tags			todos
-//	@Param			id	path	int	true	"Todo ID"
-//	@Success		204	"Successfully deleted todo"
-//	@Failure		400	{object}	ErrorResponse	"Invalid ID parameter"
-//	@Failure		404	{object}	ErrorResponse	"Todo not found"
-//	@Failure		500	{object}	ErrorResponse	"Internal server error"
+//	@Param			id			path	int		true	"Todo ID"
+//	@Param			If-Match	header	string	false	"Only delete the todo if its ETag matches"
+//	@Success		204			"Successfully moved todo to trash"
+//	@Failure		400			{object}	ErrorResponse	"Invalid ID parameter"
+//	@Failure		404			{object}	ErrorResponse	"Todo not found"
+//	@Failure		412			{object}	ErrorResponse	"Todo has been modified since the given ETag"
+//	@Failure		500			{object}	ErrorResponse	"Internal server error"
 //	@Router			/todos/{id} [delete]
 func (h *TodoHandler) delete(w http.ResponseWriter, r *http.Request) {
 	log := logger.FromContext(r.Context())
@@ -156,7 +674,7 @@ func (h *TodoHandler) delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.Delete(r.Context(), id)
+	err = h.service.Delete(r.Context(), id, ifMatch(r))
 	if err != nil {
 		WriteError(w, r, err)
 		return
@@ -164,3 +682,27 @@ func (h *TodoHandler) delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// ListTags godoc
+//
+//	@Summary		List tags
+//	@Description	Lists every tag in use with the number of todos carrying it, most used first
+//	@Tags			tags
+//	@Produce		json
+//	@Success		200	{array}		TagResponse		"Successfully retrieved tags"
+//	@Failure		500	{object}	ErrorResponse	"Internal server error"
+//	@Router			/tags [get]
+func (h *TodoHandler) listTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.service.ListTags(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	resp := make([]TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		resp = append(resp, TagResponse{Name: tag.Name, Count: tag.Count})
+	}
+
+	WriteJSONSafe(w, r, http.StatusOK, resp)
+}
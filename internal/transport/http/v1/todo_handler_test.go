@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/service"
+)
+
+// getService returns a fixed todo, with its subtasks for GetTree.
+type getService struct {
+	service.TodoService
+	todo domain.Todo
+}
+
+func (s *getService) GetByID(ctx context.Context, id int) (*domain.Todo, error) {
+	todo := s.todo
+	todo.Subtasks = nil
+	return &todo, nil
+}
+
+func (s *getService) GetTree(ctx context.Context, id int) (*domain.Todo, error) {
+	return &s.todo, nil
+}
+
+func TestGetByID_Render(t *testing.T) {
+	svc := &getService{todo: domain.Todo{
+		ID:          1,
+		Title:       "Move house",
+		Description: "Call **movers**<script>alert(1)</script>",
+		Version:     1,
+		Subtasks: []domain.Todo{
+			{ID: 2, Title: "Pack", Description: "[list](javascript:alert(1))", Subtasks: []domain.Todo{}},
+		},
+	}}
+	h := NewTodoHandler(svc)
+
+	get := func(t *testing.T, query string) (int, TodoResponse) {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/1"+query, nil)
+		h.getByID(w, mux.SetURLVars(r, map[string]string{"id": "1"}))
+
+		var resp TodoResponse
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("getByID() body is not a TodoResponse: %v", err)
+			}
+		}
+		return w.Code, resp
+	}
+
+	t.Run("markdown by default", func(t *testing.T) {
+		status, resp := get(t, "")
+		if status != http.StatusOK || resp.Description != svc.todo.Description || resp.DescriptionHTML != nil {
+			t.Errorf("getByID() = %d %+v, want the description as stored and no HTML", status, resp)
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		status, resp := get(t, "?render=html")
+		if status != http.StatusOK || resp.DescriptionHTML == nil {
+			t.Fatalf("getByID() = %d %+v, want rendered HTML", status, resp)
+		}
+		if want := "<p>Call <strong>movers</strong>alert(1)</p>\n"; *resp.DescriptionHTML != want {
+			t.Errorf("getByID() description_html = %q, want %q", *resp.DescriptionHTML, want)
+		}
+		if resp.Description != svc.todo.Description {
+			t.Errorf("getByID() description = %q, want it as stored", resp.Description)
+		}
+	})
+
+	t.Run("html for subtasks", func(t *testing.T) {
+		status, resp := get(t, "?render=html&expand=subtasks")
+		if status != http.StatusOK || len(resp.Subtasks) != 1 || resp.Subtasks[0].DescriptionHTML == nil {
+			t.Fatalf("getByID() = %d %+v, want rendered subtasks", status, resp)
+		}
+		if want := "<p>list</p>\n"; *resp.Subtasks[0].DescriptionHTML != want {
+			t.Errorf("getByID() subtask description_html = %q, want %q", *resp.Subtasks[0].DescriptionHTML, want)
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		if status, _ := get(t, "?render=pdf"); status != http.StatusBadRequest {
+			t.Errorf("getByID() status = %d, want 400", status)
+		}
+	})
+}
+
+func TestGetByID_ETag(t *testing.T) {
+	h := NewTodoHandler(&getService{todo: domain.Todo{ID: 1, Title: "Move house", Version: 3}})
+
+	get := func(query, accept, ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/todos/1"+query, nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		h.getByID(w, mux.SetURLVars(r, map[string]string{"id": "1"}))
+		return w
+	}
+
+	// Only plain JSON carries the strong tag; other representations of the
+	// same version differ in their bytes
+	tests := []struct {
+		name   string
+		query  string
+		accept string
+		want   string
+	}{
+		{"json", "", "", `"3"`},
+		{"problem details", "", "application/problem+json", `"3"`},
+		{"yaml", "", "application/yaml", `W/"3"`},
+		{"msgpack", "", "application/msgpack", `W/"3"`},
+		{"csv", "", "text/csv", `W/"3"`},
+		{"rendered", "?render=html", "", `W/"3"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(tt.query, tt.accept, "")
+			if got := w.Header().Get("ETag"); w.Code != http.StatusOK || got != tt.want {
+				t.Errorf("getByID() = %d with ETag %s, want 200 with %s", w.Code, got, tt.want)
+			}
+
+			w = get(tt.query, tt.accept, tt.want)
+			if w.Code != http.StatusNotModified || w.Header().Get("Vary") != "Accept" {
+				t.Errorf("getByID() cached = %d with Vary %q, want 304 with Vary Accept",
+					w.Code, w.Header().Get("Vary"))
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+)
+
+// RestoreTodo godoc
+//
+//	@Summary		Restore a todo item from the trash
+//	@Description	Takes a todo item out of the trash, together with the subtasks that were deleted along with it.
+//	@Description	A subtask cannot be restored while its parent is still in the trash.
+//	@Tags			trash
+//	@Produce		json
+//	@Param			id	path		int				true	"Todo ID"
+//	@Success		200	{object}	TodoResponse	"Successfully restored todo"
+//	@Failure		400	{object}	ErrorResponse	"Invalid ID parameter"
+//	@Failure		404	{object}	ErrorResponse	"Todo not found in trash"
+//	@Failure		409	{object}	ErrorResponse	"Parent todo is still in the trash"
+//	@Failure		500	{object}	ErrorResponse	"Internal server error"
+//	@Router			/todos/{id}/restore [post]
+func (h *TodoHandler) restore(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid id", zap.String("param", idStr))
+		}
+		WriteError(w, r, NewValidationError("invalid id parameter"))
+		return
+	}
+
+	t, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	setETag(w, r, *t)
+	WriteJSONSafe(w, r, http.StatusOK, newTodoResponse(*t))
+}
+
+// ListTrash godoc
+//
+//	@Summary		List the trash
+//	@Description	Retrieves every todo item in the trash, most recently deleted first
+//	@Tags			trash
+//	@Produce		json
+//	@Success		200	{array}		TodoResponse	"Successfully retrieved trash"
+//	@Failure		500	{object}	ErrorResponse	"Internal server error"
+//	@Router			/trash [get]
+func (h *TodoHandler) listTrash(w http.ResponseWriter, r *http.Request) {
+	todos, err := h.service.ListTrash(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	resp := make([]TodoResponse, 0, len(todos))
+	for _, t := range todos {
+		resp = append(resp, newTodoResponse(t))
+	}
+
+	WriteJSONSafe(w, r, http.StatusOK, resp)
+}
+
+// DeleteTodoPermanently godoc
+//
+//	@Summary		Permanently delete a todo item
+//	@Description	Permanently deletes a todo item in the trash together with its subtasks. This cannot be undone.
+//	@Tags			trash
+//	@Param			id	path	int	true	"Todo ID"
+//	@Success		204	"Successfully deleted todo"
+//	@Failure		400	{object}	ErrorResponse	"Invalid ID parameter"
+//	@Failure		404	{object}	ErrorResponse	"Todo not found in trash"
+//	@Failure		500	{object}	ErrorResponse	"Internal server error"
+//	@Router			/trash/{id} [delete]
+func (h *TodoHandler) deletePermanently(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		if log != nil {
+			log.Warn("invalid id", zap.String("param", idStr))
+		}
+		WriteError(w, r, NewValidationError("invalid id parameter"))
+		return
+	}
+
+	if err := h.service.DeletePermanently(r.Context(), id); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,17 +1,35 @@
 package v1
 
 import (
-	"encoding/json"
+	"io"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 
 	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/codec"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/problem"
 )
 
 // validator instance for the v1 package
-var validate = validator.New()
+var validate = newValidator()
+
+// newValidator returns a validator that checks a NullableInt like the *int
+// it holds, so that omitted and null ones pass "omitempty" and a value set
+// to 0 is still checked.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if n, ok := field.Interface().(NullableInt); ok {
+			return n.Value
+		}
+		return nil
+	}, NullableInt{})
+	return v
+}
 
 // ValidationError represents a validation error response
 type ValidationError struct {
@@ -23,47 +41,80 @@ func (e *ValidationError) Error() string {
 	return e.Message
 }
 
-// DecodeAndValidateJSON decodes JSON from request body and validates it
+// DecodeAndValidateJSON decodes the request body in the format given by
+// its Content-Type and validates it. Bodies without a Content-Type are
+// read as JSON. A *ValidationError is returned for invalid bodies, and an
+// *AppError with status 415 for formats that cannot be decoded.
 func DecodeAndValidateJSON(r *http.Request, target interface{}) error {
-	if err := json.NewDecoder(r.Body).Decode(target); err != nil {
-		return &ValidationError{
-			Message: "invalid JSON format",
-		}
+	if err := decodeBody(r, r.Body, target); err != nil {
+		return err
 	}
 
 	if err := validate.Struct(target); err != nil {
-		details := make(map[string]string)
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			for _, fieldError := range validationErrors {
-				field := fieldError.Field()
-				tag := fieldError.Tag()
-				details[field] = getValidationMessage(field, tag, fieldError.Param())
-			}
-		}
-
 		return &ValidationError{
 			Message: "validation failed",
-			Details: details,
+			Details: validationDetails(err),
 		}
 	}
 
 	return nil
 }
 
+// decodeBody decodes body, the body of r or part of it, in the format given
+// by the Content-Type of r.
+func decodeBody(r *http.Request, body io.Reader, target interface{}) error {
+	dec, err := codec.Default.Decoder(r.Header.Get("Content-Type"))
+	if err != nil {
+		return NewUnsupportedMediaTypeError(requestMediaType(r))
+	}
+
+	if err := dec.Decode(body, target); err != nil {
+		message := "invalid JSON format"
+		if dec != codec.JSON {
+			message = "invalid request body format"
+		}
+		return &ValidationError{Message: message}
+	}
+	return nil
+}
+
+// MaxBodySize returns the largest request body a v1 handler accepts when
+// attached files may be maxAttachmentSize bytes, for middleware that reads
+// bodies before the handlers do. Each handler still applies its own limit.
+func MaxBodySize(maxAttachmentSize int64) int64 {
+	return max(maxImportBodySize, maxBatchBodySize, maxAttachmentSize+multipartOverhead)
+}
+
+// validationDetails describes each field that failed validation.
+func validationDetails(err error) map[string]string {
+	details := make(map[string]string)
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		for _, fieldError := range validationErrors {
+			field := fieldError.Field()
+			tag := fieldError.Tag()
+			details[field] = getValidationMessage(field, tag, fieldError.Param())
+		}
+	}
+	return details
+}
+
 // WriteValidationError writes a validation error response
 func WriteValidationError(w http.ResponseWriter, r *http.Request, err *ValidationError) {
+	writeValidationErrorStatus(w, r, http.StatusBadRequest, err)
+}
+
+// writeValidationErrorStatus writes a validation error response with a custom
+// status code, e.g. 422 when a well-formed patch yields an invalid todo.
+func writeValidationErrorStatus(w http.ResponseWriter, r *http.Request, code int, err *ValidationError) {
 	log := logger.FromContext(r.Context())
 	if log != nil {
 		log.Warn("validation error", zap.Any("details", err.Details))
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusBadRequest)
-	if encodeErr := json.NewEncoder(w).Encode(err); encodeErr != nil {
-		if log != nil {
-			log.Error("failed to encode validation error response", zap.Error(encodeErr))
-		}
-	}
+	p := problem.New(code, "VALIDATION_ERROR", err.Message).WithErrors(err.Details)
+	p.TraceID = getTraceID(r)
+
+	writeProblem(w, r, p, err)
 }
 
 func getValidationMessage(field, tag, param string) string {
@@ -84,6 +135,10 @@ func getValidationMessage(field, tag, param string) string {
 		return field + " must be a valid email address"
 	case "url":
 		return field + " must be a valid URL"
+	case "oneof":
+		return field + " must be one of: " + strings.ReplaceAll(param, " ", ", ")
+	case "required_without":
+		return field + " is required when " + param + " is not provided"
 	default:
 		return field + " is invalid"
 	}
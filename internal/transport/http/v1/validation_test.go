@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/codec"
 )
 
 func TestDecodeAndValidateJSON(t *testing.T) {
@@ -44,6 +46,97 @@ func TestDecodeAndValidateJSON(t *testing.T) {
 			wantErr:     true,
 			description: "should fail validation when title exceeds maximum length",
 		},
+		{
+			name:        "description at the limit",
+			body:        `{"title": "Test Todo", "description": "` + generateLongString(10000) + `"}`,
+			target:      &CreateTodoRequest{},
+			wantErr:     false,
+			description: "should accept a description of the maximum length",
+		},
+		{
+			name:        "description too long",
+			body:        `{"title": "Test Todo", "description": "` + generateLongString(10001) + `"}`,
+			target:      &CreateTodoRequest{},
+			wantErr:     true,
+			description: "should fail validation when description exceeds maximum length",
+		},
+		{
+			name:        "patch with description too long",
+			body:        `{"description": "` + generateLongString(10001) + `"}`,
+			target:      &PatchTodoRequest{},
+			wantErr:     true,
+			description: "should fail validation when a patch sets a description that is too long",
+		},
+		{
+			name:        "update missing completed",
+			body:        `{"title": "Test Todo"}`,
+			target:      &UpdateTodoRequest{},
+			wantErr:     true,
+			description: "should fail validation when a replacement omits completed",
+		},
+		{
+			name:        "valid update",
+			body:        `{"title": "Test Todo", "completed": false}`,
+			target:      &UpdateTodoRequest{},
+			wantErr:     false,
+			description: "should accept a full replacement with completed set to false",
+		},
+		{
+			name:        "patch without fields",
+			body:        `{}`,
+			target:      &PatchTodoRequest{},
+			wantErr:     false,
+			description: "should accept a patch that changes nothing",
+		},
+		{
+			name:        "patch with empty title",
+			body:        `{"title": ""}`,
+			target:      &PatchTodoRequest{},
+			wantErr:     true,
+			description: "should fail validation when a patch sets an empty title",
+		},
+		{
+			name:        "patch with tags",
+			body:        `{"tags": ["work", "p1"]}`,
+			target:      &PatchTodoRequest{},
+			wantErr:     false,
+			description: "should accept a patch that replaces the tags",
+		},
+		{
+			name:        "patch with empty tag",
+			body:        `{"tags": ["work", ""]}`,
+			target:      &PatchTodoRequest{},
+			wantErr:     true,
+			description: "should fail validation when a tag is empty",
+		},
+		{
+			name:        "patch with project",
+			body:        `{"project_id": 2, "parent_id": null}`,
+			target:      &PatchTodoRequest{},
+			wantErr:     false,
+			description: "should accept a patch that moves the todo and clears its parent",
+		},
+		{
+			name:        "patch with zero project",
+			body:        `{"project_id": 0}`,
+			target:      &PatchTodoRequest{},
+			wantErr:     true,
+			description: "should fail validation when a patch sets a project id that is not positive",
+		},
+		{
+			name:        "patch with negative parent",
+			body:        `{"parent_id": -1}`,
+			target:      &PatchTodoRequest{},
+			wantErr:     true,
+			description: "should fail validation when a patch sets a parent id that is not positive",
+		},
+		{
+			name:        "create with too many tags",
+			body:        `{"title": "Test Todo", "tags": ["a","b","c","d","e","f","g","h","i","j","k","l","m","n","o","p","q","r","s","t","u"]}`,
+			target:      &CreateTodoRequest{},
+			wantErr:     true,
+			description: "should fail validation when more than 20 tags are given",
+		},
 		{
 			name:        "invalid JSON",
 			body:        `{"title": }`,
@@ -84,6 +177,68 @@ func TestDecodeAndValidateJSON(t *testing.T) {
 					t.Errorf("DecodeAndValidateJSON() title = %v, want 'Test Todo'", req.Title)
 				}
 			}
+			if req, ok := tt.target.(*UpdateTodoRequest); ok {
+				if req.Title != "Test Todo" || req.Completed == nil {
+					t.Errorf("DecodeAndValidateJSON() = %+v, want title 'Test Todo' and completed set", req)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeAndValidateJSON_ContentType(t *testing.T) {
+	var msgpackBody bytes.Buffer
+	if err := codec.MessagePack.Encode(&msgpackBody, map[string]any{"title": "Test Todo", "due_at": nil}); err != nil {
+		t.Fatalf("invalid test body: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{name: "no content type", body: `{"title": "Test Todo", "due_at": null}`},
+		{
+			name:        "json with charset",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"title": "Test Todo", "due_at": null}`,
+		},
+		{name: "yaml", contentType: "application/yaml", body: "title: Test Todo\ndue_at: null\n"},
+		{name: "msgpack", contentType: "application/msgpack", body: msgpackBody.String()},
+		{name: "invalid yaml", contentType: "application/yaml", body: "title: [", wantStatus: http.StatusBadRequest},
+		{name: "mistyped yaml field", contentType: "application/yaml", body: "title: [a]", wantStatus: http.StatusBadRequest},
+		{name: "csv", contentType: "text/csv", body: "title\nTest Todo\n", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "xml", contentType: "application/xml", body: "<todo/>", wantStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/test", bytes.NewBufferString(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var target PatchTodoRequest
+			err := DecodeAndValidateJSON(req, &target)
+
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("DecodeAndValidateJSON() unexpected error = %v", err)
+				}
+				if target.Title == nil || *target.Title != "Test Todo" || !target.DueAt.Set || target.DueAt.Value != nil {
+					t.Errorf("DecodeAndValidateJSON() = %+v, want title and a cleared due_at", target)
+				}
+				return
+			}
+
+			status, _, _ := describeError(err)
+			if _, ok := err.(*ValidationError); ok {
+				status = http.StatusBadRequest
+			}
+			if status != tt.wantStatus {
+				t.Errorf("DecodeAndValidateJSON() error = %v (%d), want status %d", err, status, tt.wantStatus)
+			}
 		})
 	}
 }
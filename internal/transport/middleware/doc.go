@@ -1,8 +1,10 @@
 // Package middleware contains HTTP middleware used to augment incoming requests,
-// including request ID generation and context-aware logging. These middlewares
-// enrich requests with metadata and ensure structured, correlated logging
-// throughout the application.
+// including request ID generation, context-aware logging, Idempotency-Key
+// handling, content negotiation and the choice of error format. These
+// middlewares enrich requests with metadata and ensure structured,
+// correlated logging throughout the application.
 //
 // Middlewares in this package are transport-specific and should not contain
-// business logic or interact with repositories or services.
+// business logic or interact with repositories or services. Idempotent
+// responses are kept behind the IdempotencyStore interface defined here.
 package middleware
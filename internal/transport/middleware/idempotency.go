@@ -0,0 +1,296 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/problem"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the idempotency key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks responses that were replayed from the store
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength limits the size of a client-chosen key
+	maxIdempotencyKeyLength = 255
+	// idempotentBodyMemory is how much of a request body is kept in memory
+	// while it is hashed; the rest goes to a temporary file
+	idempotentBodyMemory = 1 << 20 // 1 MiB
+)
+
+// replayedHeaders lists the response headers stored and replayed along with
+// the status and body.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// IdempotencyStore persists the responses to requests made with an
+// idempotency key. Implementations must make Reserve atomic, so that only
+// one of several concurrent requests with the same key is processed.
+type IdempotencyStore interface {
+	// Reserve claims key for a request with the given hash for ttl. If an
+	// unexpired record already holds the key it is returned and nothing is
+	// reserved; otherwise the result is nil.
+	Reserve(ctx context.Context, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, error)
+	// Complete stores the response to the request that reserved the key.
+	Complete(ctx context.Context, rec domain.IdempotencyRecord) error
+	// Release drops an uncompleted reservation so that the request can be retried.
+	Release(ctx context.Context, key string) error
+	// DeleteExpired removes expired records and returns how many there were.
+	DeleteExpired(ctx context.Context) (int, error)
+}
+
+// Idempotency makes POST requests carrying an Idempotency-Key header safe to
+// retry. The first response for a key is stored for ttl and replayed for
+// later requests with the same key; reusing a key for a different request
+// is rejected with 422, and a retry that arrives while the first request is
+// still being processed with 409. Server errors are not stored, so that the
+// request can be retried with the same key. The body is read in full to
+// identify the request; maxBodySize should be the largest body any of the
+// handlers accepts, and larger bodies are rejected with 413.
+func Idempotency(store IdempotencyStore, ttl time.Duration, maxBodySize int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				writeError(w, r, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY",
+					"Idempotency-Key must be at most 255 characters long")
+				return
+			}
+
+			hash, cleanup, ok := readIdempotentBody(w, r, maxBodySize)
+			if !ok {
+				return
+			}
+			defer cleanup()
+
+			existing, err := store.Reserve(r.Context(), key, hash, ttl)
+			if err != nil {
+				if log := logger.FromContext(r.Context()); log != nil {
+					log.Error("failed to reserve idempotency key", zap.Error(err))
+				}
+				writeError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+				return
+			}
+
+			if existing != nil {
+				answerExisting(w, r, existing, hash)
+				return
+			}
+
+			serveAndStore(w, r, next, store, domain.IdempotencyRecord{Key: key, RequestHash: hash})
+		})
+	}
+}
+
+// readIdempotentBody hashes the method, target and body of the request,
+// which identify it, and puts the body back for the handler: in memory or,
+// past idempotentBodyMemory, in a temporary file that cleanup removes. It
+// writes an error response and reports false when the body cannot be read
+// or is larger than maxBodySize.
+func readIdempotentBody(w http.ResponseWriter, r *http.Request, maxBodySize int64) (string, func(), bool) {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	body := io.TeeReader(http.MaxBytesReader(w, r.Body, maxBodySize), h)
+
+	var head bytes.Buffer
+	n, err := io.CopyN(&head, body, idempotentBodyMemory+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		writeBodyError(w, r, err)
+		return "", nil, false
+	}
+	if n <= idempotentBodyMemory {
+		r.Body = io.NopCloser(&head)
+		return hex.EncodeToString(h.Sum(nil)), func() {}, true
+	}
+
+	f, err := os.CreateTemp("", "idempotent-body-*")
+	if err != nil {
+		if log := logger.FromContext(r.Context()); log != nil {
+			log.Error("failed to buffer request body", zap.Error(err))
+		}
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		return "", nil, false
+	}
+	cleanup := func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+
+	if _, err := io.Copy(f, io.MultiReader(&head, body)); err != nil {
+		cleanup()
+		writeBodyError(w, r, err)
+		return "", nil, false
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		return "", nil, false
+	}
+
+	r.Body = io.NopCloser(f)
+	return hex.EncodeToString(h.Sum(nil)), cleanup, true
+}
+
+// writeBodyError answers a request whose body could not be read.
+func writeBodyError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeError(w, r, http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE",
+			fmt.Sprintf("request body may be at most %d bytes", maxBytesErr.Limit))
+		return
+	}
+	writeError(w, r, http.StatusBadRequest, "INVALID_REQUEST_BODY", "invalid request body")
+}
+
+// answerExisting responds to a request whose key is already held by
+// existing: with the stored response if it was made for the same request,
+// and with an error otherwise.
+func answerExisting(w http.ResponseWriter, r *http.Request, existing *domain.IdempotencyRecord, hash string) {
+	log := logger.FromContext(r.Context())
+
+	switch {
+	case existing.RequestHash != hash:
+		if log != nil {
+			log.Warn("idempotency key reused for a different request", zap.String("key", existing.Key))
+		}
+		writeError(w, r, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED",
+			"Idempotency-Key has already been used for a different request")
+	case !existing.Completed():
+		writeError(w, r, http.StatusConflict, "IDEMPOTENCY_KEY_IN_USE",
+			"a request with this Idempotency-Key is still being processed")
+	default:
+		if log != nil {
+			log.Info("replaying idempotent response", zap.String("key", existing.Key))
+		}
+		replay(w, existing)
+	}
+}
+
+// serveAndStore runs the handler for a request whose key has just been
+// reserved and stores its response. The reservation is released instead
+// if the handler fails with a server error or panics.
+func serveAndStore(
+	w http.ResponseWriter, r *http.Request, next http.Handler, store IdempotencyStore, record domain.IdempotencyRecord,
+) {
+	log := logger.FromContext(r.Context())
+
+	// The outcome is stored even if the client goes away meanwhile
+	ctx := context.WithoutCancel(r.Context())
+
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		if err := store.Release(ctx, record.Key); err != nil && log != nil {
+			log.Error("failed to release idempotency key", zap.Error(err))
+		}
+	}()
+
+	rec := &responseRecorder{ResponseWriter: w}
+	next.ServeHTTP(rec, r)
+
+	if rec.statusCode() >= http.StatusInternalServerError {
+		return
+	}
+
+	record.StatusCode = rec.statusCode()
+	record.Body = rec.body.Bytes()
+	record.Header = make(map[string]string)
+	for _, name := range replayedHeaders {
+		if v := w.Header().Get(name); v != "" {
+			record.Header[name] = v
+		}
+	}
+
+	if err := store.Complete(ctx, record); err != nil {
+		if log != nil {
+			log.Error("failed to store idempotent response", zap.Error(err))
+		}
+		return
+	}
+	completed = true
+}
+
+// replay writes a stored response.
+func replay(w http.ResponseWriter, rec *domain.IdempotencyRecord) {
+	for name, value := range rec.Header {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(rec.StatusCode)
+	_, _ = w.Write(rec.Body)
+}
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// statusCode returns the status sent so far, which is 200 if the handler
+// did not set one.
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// ErrorResponse mirrors the legacy error body of the API handlers.
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Code    string `json:"code,omitempty"`
+	TraceID string `json:"trace_id,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	traceID := GetRequestID(r.Context())
+
+	p := problem.New(status, code, message)
+	p.TraceID = traceID
+
+	if err := problem.Write(w, r, p, ErrorResponse{Error: message, Code: code, TraceID: traceID}); err != nil {
+		// The status code has already been sent, so there is nothing left to do
+		return
+	}
+}
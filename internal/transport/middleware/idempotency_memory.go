@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/domain"
+)
+
+// MemoryIdempotencyStore keeps idempotency records in memory. It is meant
+// for single-instance deployments and tests: records do not survive a
+// restart and are not shared between instances.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+	now     func() time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		records: make(map[string]domain.IdempotencyRecord),
+		now:     time.Now,
+	}
+}
+
+// Reserve implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Reserve(
+	_ context.Context, key, requestHash string, ttl time.Duration,
+) (*domain.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if rec, ok := s.records[key]; ok && rec.ExpiresAt.After(now) {
+		return &rec, nil
+	}
+
+	s.records[key] = domain.IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   now.Add(ttl),
+	}
+	return nil, nil
+}
+
+// Complete implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, rec domain.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.records[rec.Key]
+	if !ok || current.RequestHash != rec.RequestHash {
+		return nil
+	}
+
+	rec.ExpiresAt = current.ExpiresAt
+	s.records[rec.Key] = rec
+	return nil
+}
+
+// Release implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rec, ok := s.records[key]; ok && !rec.Completed() {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// DeleteExpired implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) DeleteExpired(_ context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	count := 0
+	for key, rec := range s.records {
+		if !rec.ExpiresAt.After(now) {
+			delete(s.records, key)
+			count++
+		}
+	}
+	return count, nil
+}
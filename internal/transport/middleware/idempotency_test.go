@@ -0,0 +1,283 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testMaxBodySize is the largest body the handlers in these tests accept
+const testMaxBodySize = 4 << 20
+
+// countingHandler creates a todo on every call it receives and answers with
+// the number of calls so far, so that replays can be told apart from
+// repeated work.
+func countingHandler(calls *atomic.Int32, status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/api/v1/todos/"+strconv.Itoa(int(n)))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"id":` + strconv.Itoa(int(n)) + `}`))
+	})
+}
+
+func idempotentRequest(method, key, body string) *http.Request {
+	req := httptest.NewRequest(method, "/api/v1/todos", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return req
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	var calls atomic.Int32
+	handler := Idempotency(NewMemoryIdempotencyStore(), time.Hour, testMaxBodySize)(
+		countingHandler(&calls, http.StatusCreated))
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, idempotentRequest(http.MethodPost, "abc", `{"title":"Buy milk"}`))
+
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first response status = %d, want %d", first.Code, http.StatusCreated)
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("first response was marked as replayed")
+	}
+
+	replay := httptest.NewRecorder()
+	handler.ServeHTTP(replay, idempotentRequest(http.MethodPost, "abc", `{"title":"Buy milk"}`))
+
+	if calls.Load() != 1 {
+		t.Errorf("handler called %d times, want 1", calls.Load())
+	}
+	if replay.Code != first.Code || replay.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", replay.Code, replay.Body, first.Code, first.Body)
+	}
+	for _, name := range []string{"Content-Type", "Location"} {
+		if got, want := replay.Header().Get(name), first.Header().Get(name); got != want {
+			t.Errorf("replayed %s = %q, want %q", name, got, want)
+		}
+	}
+	if replay.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("replay was not marked as replayed")
+	}
+
+	// A different key is a different request
+	other := httptest.NewRecorder()
+	handler.ServeHTTP(other, idempotentRequest(http.MethodPost, "def", `{"title":"Buy milk"}`))
+	if calls.Load() != 2 {
+		t.Errorf("handler called %d times for a new key, want 2", calls.Load())
+	}
+}
+
+func TestIdempotency_KeyReused(t *testing.T) {
+	var calls atomic.Int32
+	handler := Idempotency(NewMemoryIdempotencyStore(), time.Hour, testMaxBodySize)(
+		countingHandler(&calls, http.StatusCreated))
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(http.MethodPost, "abc", `{"title":"Buy milk"}`))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "abc", `{"title":"Buy bread"}`))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if !strings.Contains(rec.Body.String(), "IDEMPOTENCY_KEY_REUSED") {
+		t.Errorf("body = %q, want code IDEMPOTENCY_KEY_REUSED", rec.Body)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler called %d times, want 1", calls.Load())
+	}
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	handler := Idempotency(store, time.Hour, testMaxBodySize)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(http.MethodPost, "abc", `{}`))
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "abc", `{}`))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status while in progress = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	close(release)
+	<-done
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "abc", `{}`))
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status after completion = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
+
+func TestIdempotency_ServerErrorReleasesKey(t *testing.T) {
+	var calls atomic.Int32
+	handler := Idempotency(NewMemoryIdempotencyStore(), time.Hour, testMaxBodySize)(
+		countingHandler(&calls, http.StatusInternalServerError))
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "abc", `{}`))
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+		}
+	}
+
+	if calls.Load() != 2 {
+		t.Errorf("handler called %d times, want every failed attempt to be retried", calls.Load())
+	}
+}
+
+func TestIdempotency_PanicReleasesKey(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	handler := Idempotency(store, time.Hour, testMaxBodySize)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+
+	func() {
+		defer func() { _ = recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(http.MethodPost, "abc", `{}`))
+	}()
+
+	if _, ok := store.records["abc"]; ok {
+		t.Errorf("key is still reserved after the handler panicked")
+	}
+}
+
+func TestIdempotency_Expiry(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryIdempotencyStore()
+	store.now = func() time.Time { return now }
+
+	var calls atomic.Int32
+	handler := Idempotency(store, time.Hour, testMaxBodySize)(countingHandler(&calls, http.StatusCreated))
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(http.MethodPost, "abc", `{}`))
+
+	now = now.Add(59 * time.Minute)
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(http.MethodPost, "abc", `{}`))
+	if calls.Load() != 1 {
+		t.Fatalf("handler called %d times before expiry, want 1", calls.Load())
+	}
+
+	now = now.Add(time.Minute)
+	if n, _ := store.DeleteExpired(t.Context()); n != 1 {
+		t.Errorf("DeleteExpired() = %d, want 1", n)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(http.MethodPost, "abc", `{"title":"Other"}`))
+	if calls.Load() != 2 {
+		t.Errorf("handler called %d times after expiry, want 2", calls.Load())
+	}
+}
+
+func TestIdempotency_PassThrough(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		key    string
+	}{
+		{name: "no key", method: http.MethodPost},
+		{name: "GET with key", method: http.MethodGet, key: "abc"},
+		{name: "PUT with key", method: http.MethodPut, key: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			handler := Idempotency(NewMemoryIdempotencyStore(), time.Hour, testMaxBodySize)(
+				countingHandler(&calls, http.StatusOK))
+
+			for range 2 {
+				handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(tt.method, tt.key, `{}`))
+			}
+
+			if calls.Load() != 2 {
+				t.Errorf("handler called %d times, want 2", calls.Load())
+			}
+		})
+	}
+}
+
+func TestIdempotency_InvalidKey(t *testing.T) {
+	var calls atomic.Int32
+	handler := Idempotency(NewMemoryIdempotencyStore(), time.Hour, testMaxBodySize)(
+		countingHandler(&calls, http.StatusCreated))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, strings.Repeat("k", maxIdempotencyKeyLength+1), `{}`))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("handler called %d times, want 0", calls.Load())
+	}
+}
+
+func TestIdempotency_LargeBody(t *testing.T) {
+	// The handler answers with the body it read, so that a body kept in a
+	// temporary file can be checked
+	var calls atomic.Int32
+	handler := Idempotency(NewMemoryIdempotencyStore(), time.Hour, testMaxBodySize)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("reading body: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write(body)
+		}))
+
+	body := strings.Repeat("a", idempotentBodyMemory) + strings.Repeat("b", idempotentBodyMemory)
+	for i := range 2 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "large", body))
+		if rec.Code != http.StatusCreated || rec.Body.String() != body {
+			t.Fatalf("request %d: status = %d, body of %d bytes, want 201 with the body sent", i, rec.Code,
+				rec.Body.Len())
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler called %d times, want 1", calls.Load())
+	}
+
+	// Bodies differing past the part kept in memory are told apart
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "large", body[:len(body)-1]+"c"))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "huge", strings.Repeat("a", testMaxBodySize+1)))
+	if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), "REQUEST_TOO_LARGE") {
+		t.Errorf("oversized body status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler called %d times, want 1", calls.Load())
+	}
+}
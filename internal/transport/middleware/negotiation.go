@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/codec"
+)
+
+// ContentNegotiation answers 406 Not Acceptable, before the request is
+// handled, when the Accept header admits none of the media types in
+// codecs. Refusing early keeps such requests from changing anything only
+// to have their response rejected. The error itself is written as JSON.
+func ContentNegotiation(codecs *codec.Registry) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !codecs.Acceptable(r.Header.Values("Accept")) {
+				writeError(w, r, http.StatusNotAcceptable, "NOT_ACCEPTABLE",
+					"none of the accepted media types can be produced: "+r.Header.Get("Accept"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
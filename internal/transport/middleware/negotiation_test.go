@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/codec"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	tests := []struct {
+		name       string
+		accept     string
+		wantStatus int
+	}{
+		{name: "no accept header", wantStatus: http.StatusOK},
+		{name: "browser", accept: "text/html,application/xhtml+xml,*/*;q=0.8", wantStatus: http.StatusOK},
+		{name: "csv", accept: "text/csv", wantStatus: http.StatusOK},
+		{name: "unsupported", accept: "application/xml", wantStatus: http.StatusNotAcceptable},
+		{name: "refused", accept: "application/json;q=0", wantStatus: http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/todos", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			ContentNegotiation(codec.Default)(next).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if called != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("handler called = %t, want %t", called, !called)
+			}
+
+			if tt.wantStatus == http.StatusNotAcceptable {
+				var resp ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Code != "NOT_ACCEPTABLE" {
+					t.Errorf("body = %s, want a NOT_ACCEPTABLE error", w.Body)
+				}
+			}
+		})
+	}
+}
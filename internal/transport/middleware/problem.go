@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/problem"
+)
+
+// ProblemDetails chooses the format error responses are written in for
+// each request: problem details for clients whose Accept header asks for
+// application/problem+json, and def for everyone else.
+func ProblemDetails(def problem.Format) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := problem.WithFormat(r.Context(), problem.Negotiate(r, def))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/problem"
+)
+
+func TestProblemDetails(t *testing.T) {
+	type request struct {
+		Title string `json:"title" validate:"required"`
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("handler called for an invalid request")
+	})
+
+	tests := []struct {
+		name            string
+		def             problem.Format
+		accept          string
+		wantContentType string
+	}{
+		{name: "legacy default", def: problem.FormatLegacy, wantContentType: "application/json"},
+		{name: "problem default", def: problem.FormatProblem, wantContentType: problem.MediaType},
+		{
+			name:            "problem requested",
+			def:             problem.FormatLegacy,
+			accept:          problem.MediaType,
+			wantContentType: problem.MediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := ProblemDetails(tt.def)(ValidateJSON(NewValidator(), &request{})(next))
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/todos", strings.NewReader(`{}`))
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tt.wantContentType {
+				t.Fatalf("Content-Type = %q, want %q", ct, tt.wantContentType)
+			}
+
+			if tt.wantContentType != problem.MediaType {
+				return
+			}
+			var p problem.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+				t.Fatalf("body is not a problem: %v", err)
+			}
+			if p.Code != "VALIDATION_ERROR" || len(p.Errors) != 1 || p.Errors[0].Field != "title" {
+				t.Errorf("problem = %+v, want a validation error for title", p)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/NoroSaroyan/go-rest-api-example/internal/pkg/logger"
+	"github.com/NoroSaroyan/go-rest-api-example/internal/transport/http/problem"
 )
 
 const (
@@ -70,7 +71,7 @@ func ValidateJSON(v Validator, target interface{}) func(http.Handler) http.Handl
 				if log != nil {
 					log.Warn("invalid JSON body", zap.Error(err))
 				}
-				writeValidationError(w, "invalid JSON format", nil)
+				writeValidationError(w, r, "invalid JSON format", nil)
 				return
 			}
 
@@ -89,7 +90,7 @@ func ValidateJSON(v Validator, target interface{}) func(http.Handler) http.Handl
 					}
 				}
 
-				writeValidationError(w, "validation failed", details)
+				writeValidationError(w, r, "validation failed", details)
 				return
 			}
 
@@ -98,16 +99,16 @@ func ValidateJSON(v Validator, target interface{}) func(http.Handler) http.Handl
 	}
 }
 
-func writeValidationError(w http.ResponseWriter, message string, details map[string]string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusBadRequest)
-
+func writeValidationError(w http.ResponseWriter, r *http.Request, message string, details map[string]string) {
 	response := ValidationErrorResponse{
 		Error:   message,
 		Details: details,
 	}
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	p := problem.New(http.StatusBadRequest, "VALIDATION_ERROR", message).WithErrors(details)
+	p.TraceID = GetRequestID(r.Context())
+
+	if err := problem.Write(w, r, p, response); err != nil {
 		// If we can't encode the validation error response, there's not much we can do
 		// except log the error - the status code has already been set
 		http.Error(w, "internal server error", http.StatusInternalServerError)
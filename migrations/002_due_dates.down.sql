@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_todos_due_at;
+
+ALTER TABLE todos
+    DROP COLUMN IF EXISTS remind_at,
+    DROP COLUMN IF EXISTS due_at;
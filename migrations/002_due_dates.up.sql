@@ -0,0 +1,7 @@
+-- Due dates and reminders
+ALTER TABLE todos
+    ADD COLUMN IF NOT EXISTS due_at    TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS remind_at TIMESTAMPTZ NULL;
+
+-- Index for overdue and upcoming queries
+CREATE INDEX IF NOT EXISTS idx_todos_due_at ON todos (due_at) WHERE due_at IS NOT NULL;
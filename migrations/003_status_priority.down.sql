@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_todos_priority;
+DROP INDEX IF EXISTS idx_todos_status;
+
+DROP INDEX IF EXISTS idx_todos_completed;
+ALTER TABLE todos DROP COLUMN completed;
+ALTER TABLE todos ADD COLUMN completed BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE todos SET completed = (status = 'done');
+CREATE INDEX IF NOT EXISTS idx_todos_completed ON todos (completed);
+
+ALTER TABLE todos
+    DROP COLUMN priority,
+    DROP COLUMN status;
+
+DROP TYPE IF EXISTS todo_priority;
+DROP TYPE IF EXISTS todo_status;
@@ -0,0 +1,20 @@
+-- Status workflow and priorities. Enum values are declared in workflow and
+-- urgency order so that sorting by them is meaningful.
+CREATE TYPE todo_status AS ENUM ('backlog', 'in_progress', 'blocked', 'done');
+CREATE TYPE todo_priority AS ENUM ('low', 'medium', 'high', 'urgent');
+
+ALTER TABLE todos
+    ADD COLUMN status   todo_status   NOT NULL DEFAULT 'backlog',
+    ADD COLUMN priority todo_priority NOT NULL DEFAULT 'medium';
+
+UPDATE todos SET status = 'done' WHERE completed;
+
+-- completed is now derived from status. It is kept as a generated column so
+-- that existing filters and idx_todos_completed keep working.
+DROP INDEX IF EXISTS idx_todos_completed;
+ALTER TABLE todos DROP COLUMN completed;
+ALTER TABLE todos ADD COLUMN completed BOOLEAN GENERATED ALWAYS AS (status = 'done') STORED;
+CREATE INDEX IF NOT EXISTS idx_todos_completed ON todos (completed);
+
+CREATE INDEX IF NOT EXISTS idx_todos_status ON todos (status);
+CREATE INDEX IF NOT EXISTS idx_todos_priority ON todos (priority);
@@ -0,0 +1,14 @@
+-- Free-form tags, shared between todos through a join table.
+CREATE TABLE IF NOT EXISTS tags (
+    id   SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS todo_tags (
+    todo_id INT NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    tag_id  INT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+-- The primary key covers lookups by todo; this covers lookups by tag.
+CREATE INDEX IF NOT EXISTS idx_todo_tags_tag_id ON todo_tags (tag_id);
@@ -0,0 +1,15 @@
+-- Projects group related todos. A project cannot be deleted while it still
+-- has todos; the application deletes them first when asked to cascade.
+CREATE TABLE IF NOT EXISTS projects
+(
+    id          SERIAL PRIMARY KEY,
+    name        TEXT      NOT NULL,
+    description TEXT      NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE todos
+    ADD COLUMN project_id INT REFERENCES projects (id) ON DELETE RESTRICT;
+
+-- Used to list and count the todos of a project
+CREATE INDEX IF NOT EXISTS idx_todos_project_id ON todos (project_id) WHERE project_id IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_todos_parent_id;
+
+ALTER TABLE todos
+    DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,6 @@
+-- Subtasks point at their parent todo and are deleted along with it.
+ALTER TABLE todos
+    ADD COLUMN parent_id INT REFERENCES todos (id) ON DELETE CASCADE;
+
+-- Used to walk the subtask tree
+CREATE INDEX IF NOT EXISTS idx_todos_parent_id ON todos (parent_id) WHERE parent_id IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Todos in the trash would reappear once the column is gone
+DELETE FROM todos WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_todos_deleted_at;
+
+ALTER TABLE todos
+    DROP COLUMN IF EXISTS deleted_at;
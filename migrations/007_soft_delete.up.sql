@@ -0,0 +1,7 @@
+-- Deleted todos are moved to the trash by setting deleted_at, and purged
+-- for good once they have been there longer than the retention period.
+ALTER TABLE todos
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL;
+
+-- Used to list the trash and find todos due for purging
+CREATE INDEX IF NOT EXISTS idx_todos_deleted_at ON todos (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Every change to a todo increments its version, which clients use as an
+-- ETag to detect concurrent edits.
+ALTER TABLE todos
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,16 @@
+-- Responses to requests made with an Idempotency-Key header, replayed when
+-- a client retries the same request. status_code is 0 while the first
+-- request is still being processed.
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    key          TEXT PRIMARY KEY,
+    request_hash TEXT        NOT NULL,
+    status_code  INT         NOT NULL DEFAULT 0,
+    header       JSONB       NOT NULL DEFAULT '{}',
+    body         BYTEA       NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMPTZ NOT NULL
+);
+
+-- Used to sweep expired keys
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
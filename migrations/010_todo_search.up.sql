@@ -0,0 +1,9 @@
+-- Full-text search over todos. The document is kept up to date by
+-- Postgres as a generated column; the text search configuration is named
+-- explicitly so that the expression is immutable, as generated columns
+-- require. Titles carry weight A so that later fields can rank below them.
+ALTER TABLE todos
+    ADD COLUMN search tsvector
+        GENERATED ALWAYS AS (setweight(to_tsvector('english', title), 'A')) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_todos_search ON todos USING GIN (search);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_todos_search;
+ALTER TABLE todos DROP COLUMN IF EXISTS search;
+
+ALTER TABLE todos
+    ADD COLUMN search tsvector
+        GENERATED ALWAYS AS (setweight(to_tsvector('english', title), 'A')) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_todos_search ON todos USING GIN (search);
+
+ALTER TABLE todos DROP COLUMN IF EXISTS description;
@@ -0,0 +1,17 @@
+-- Free-form notes on a todo, written in Markdown
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+
+-- Descriptions join the search document, ranked below titles. The
+-- expression of a generated column cannot be changed, so the column and
+-- its index are created again.
+DROP INDEX IF EXISTS idx_todos_search;
+ALTER TABLE todos DROP COLUMN IF EXISTS search;
+
+ALTER TABLE todos
+    ADD COLUMN search tsvector
+        GENERATED ALWAYS AS (
+            setweight(to_tsvector('english', title), 'A') ||
+            setweight(to_tsvector('english', description), 'B')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_todos_search ON todos USING GIN (search);
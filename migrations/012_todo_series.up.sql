@@ -0,0 +1,18 @@
+-- A series holds the recurrence rule of a recurring todo. Each occurrence
+-- is a todo of its own; completing one creates the next.
+CREATE TABLE IF NOT EXISTS todo_series
+(
+    id         SERIAL PRIMARY KEY,
+    rrule      TEXT        NOT NULL,
+    time_zone  TEXT        NOT NULL DEFAULT 'UTC',
+    starts_at  TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE todos
+    ADD COLUMN series_id INT REFERENCES todo_series (id) ON DELETE SET NULL;
+
+-- A series has at most one occurrence due at any time, so that completing
+-- an occurrence twice, or from two requests at once, creates the next one
+-- only once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todos_series_due ON todos (series_id, due_at) WHERE series_id IS NOT NULL;
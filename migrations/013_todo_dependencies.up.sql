@@ -0,0 +1,14 @@
+-- A dependency records that a todo is blocked by another todo, which has
+-- to be done first. The application keeps the graph free of cycles.
+CREATE TABLE IF NOT EXISTS todo_dependencies
+(
+    todo_id       INT       NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    blocked_by_id INT       NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (todo_id, blocked_by_id),
+    CHECK (todo_id <> blocked_by_id)
+);
+
+-- The primary key covers lookups of the todos blocking a todo; this covers
+-- lookups of the todos a todo blocks.
+CREATE INDEX IF NOT EXISTS idx_todo_dependencies_blocked_by_id ON todo_dependencies (blocked_by_id);
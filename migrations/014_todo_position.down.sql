@@ -0,0 +1,7 @@
+ALTER TABLE todos
+    DROP CONSTRAINT IF EXISTS todos_position_key;
+
+ALTER TABLE todos
+    DROP COLUMN IF EXISTS position;
+
+DROP SEQUENCE IF EXISTS todos_position_seq;
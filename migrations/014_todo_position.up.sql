@@ -0,0 +1,27 @@
+-- The position of a todo orders it within the list when sorting by
+-- position. New todos are appended with the next value of a sequence; a
+-- todo moved between two others takes the midpoint of their positions,
+-- and crowded stretches of the list are spread out again when the
+-- midpoints run out of precision. Existing todos keep their order by ID.
+CREATE SEQUENCE IF NOT EXISTS todos_position_seq;
+
+ALTER TABLE todos
+    ADD COLUMN position DOUBLE PRECISION;
+
+UPDATE todos
+SET position = id;
+
+SELECT setval('todos_position_seq', COALESCE((SELECT MAX(id) FROM todos), 0) + 1, false);
+
+ALTER TABLE todos
+    ALTER COLUMN position SET DEFAULT nextval('todos_position_seq'),
+    ALTER COLUMN position SET NOT NULL;
+
+ALTER SEQUENCE todos_position_seq OWNED BY todos.position;
+
+-- No two todos share a position, trashed ones included, so that
+-- concurrent moves into the same gap cannot both succeed. The constraint
+-- is checked at the end of each statement rather than row by row, which
+-- lets a single statement spread out a stretch of the list.
+ALTER TABLE todos
+    ADD CONSTRAINT todos_position_key UNIQUE (position) DEFERRABLE INITIALLY IMMEDIATE;
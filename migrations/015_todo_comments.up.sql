@@ -0,0 +1,14 @@
+-- Comments hold the discussion around a todo. They are kept while the todo
+-- is in the trash and deleted along with it once it is purged.
+CREATE TABLE IF NOT EXISTS todo_comments
+(
+    id         SERIAL PRIMARY KEY,
+    todo_id    INT         NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    author     TEXT        NOT NULL,
+    body       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NULL
+);
+
+-- Used to page through the comments on a todo, oldest first
+CREATE INDEX IF NOT EXISTS idx_todo_comments_todo_id ON todo_comments (todo_id, created_at, id);
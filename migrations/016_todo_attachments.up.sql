@@ -0,0 +1,19 @@
+-- Attachments are files uploaded to a todo, stored in a blob store under
+-- storage_key. They are kept while the todo is in the trash. Once the todo
+-- is purged todo_id is cleared, and the application deletes the stored
+-- file before the row itself.
+CREATE TABLE IF NOT EXISTS todo_attachments
+(
+    id           SERIAL PRIMARY KEY,
+    todo_id      INT         NULL REFERENCES todos (id) ON DELETE SET NULL,
+    filename     TEXT        NOT NULL,
+    content_type TEXT        NOT NULL,
+    size         BIGINT      NOT NULL,
+    storage_key  TEXT        NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Used to list the attachments of a todo
+CREATE INDEX IF NOT EXISTS idx_todo_attachments_todo_id ON todo_attachments (todo_id) WHERE todo_id IS NOT NULL;
+-- Used to find attachments whose files are left to delete
+CREATE INDEX IF NOT EXISTS idx_todo_attachments_orphans ON todo_attachments (id) WHERE todo_id IS NULL;